@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	vertexgenai "cloud.google.com/go/vertexai/genai"
+	"golang.org/x/oauth2"
+
+	"tryon-demo/internal/application/usecases"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/external"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/limiter"
+	"tryon-demo/internal/infrastructure/metering"
+	"tryon-demo/internal/infrastructure/retry"
+	"tryon-demo/internal/infrastructure/services"
+	"tryon-demo/internal/infrastructure/translationcache"
+)
+
+// aiBackend 生成AIサービス一式（BACKEND で切り替える）
+type aiBackend struct {
+	vertexAI   domainrepos.VertexAIService
+	imagen     domainrepos.ImagenAIService
+	veo        domainrepos.VeoAIService
+	nanobanana domainrepos.NanobananaAIService
+	text       domainrepos.TextAIService
+
+	closers []func() error
+}
+
+func (b *aiBackend) Close() {
+	// 生成した順と逆に閉じる
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if err := b.closers[i](); err != nil {
+			log.Printf("Failed to close backend: %v", err)
+		}
+	}
+}
+
+// withRetry 全ての生成AIサービスをリトライ付きにする。
+// Veoは呼び出し全体をやり直すと有料の動画生成を始め直すため、対応するサービスのみ
+// 生成の開始・状態確認・ダウンロードをそれぞれ再試行する
+func (b *aiBackend) withRetry(policy retry.Policy) {
+	if policy.MaxAttempts <= 1 {
+		return
+	}
+
+	b.vertexAI = retry.NewVertexAIService(b.vertexAI, policy)
+	b.imagen = retry.NewImagenAIService(b.imagen, policy)
+	if veo, ok := b.veo.(interface{ SetRetryPolicy(retry.Policy) }); ok {
+		veo.SetRetryPolicy(policy)
+	}
+	b.nanobanana = retry.NewNanobananaAIService(b.nanobanana, policy)
+	b.text = retry.NewTextAIService(b.text, policy)
+}
+
+// withLimiter 全ての生成AIサービスをモデルごとの同時実行数で制限する。
+// リトライの外側に置くため、順番待ちが満杯のエラーはリトライしない。
+func (b *aiBackend) withLimiter(l *limiter.Limiter, vtoModel string) {
+	b.vertexAI = limiter.NewVertexAIService(b.vertexAI, l, vtoModel)
+	b.imagen = limiter.NewImagenAIService(b.imagen, l)
+	b.veo = limiter.NewVeoAIService(b.veo, l)
+	b.nanobanana = limiter.NewNanobananaAIService(b.nanobanana, l)
+	b.text = limiter.NewTextAIService(b.text, l, external.GeminiTextModel)
+}
+
+// withMetering プロンプトの生成・翻訳などで使ったGeminiのトークン数を利用量に記録する
+func (b *aiBackend) withMetering(usage *usecases.UsageUseCase) {
+	b.text = metering.NewTextAIService(b.text, usage)
+}
+
+// withTranslationCache 同じプロンプトの英語への翻訳を使い回す。
+// 利用量の記録の外側に置くため、キャッシュから返した翻訳はトークン数を数えない。
+func (b *aiBackend) withTranslationCache(cache *translationcache.Cache) {
+	b.text = translationcache.NewTextAIService(
+		b.text, cache, external.TranslationFamily, external.TranslationTemplateVersion,
+	)
+}
+
+// newGoogleBackend Vertex AI / Gemini API を使う
+func newGoogleBackend(ctx context.Context, location, vtoModel string, useSDK bool) *aiBackend {
+	geminiApiKey := os.Getenv("GEMINI_API_KEY")
+	if geminiApiKey == "" {
+		log.Fatal("環境変数 GEMINI_API_KEY が未設定です")
+	}
+
+	projectID := os.Getenv("PROJECT_ID")
+	if projectID == "" {
+		log.Fatal("環境変数 PROJECT_ID が未設定です")
+	}
+
+	log.Printf("[boot] PROJECT_ID=%s", projectID)
+
+	backend := &aiBackend{}
+
+	// Client Pool Service初期化
+	clientPoolService := services.NewClientPoolService(projectID, location)
+	backend.closers = append(backend.closers, clientPoolService.Close)
+
+	// VertexAI Client取得 (TryOn用、SDKモードのみ)
+	var vertexClient *vertexgenai.Client
+	if useSDK {
+		client, err := clientPoolService.VertexAIPool().GetVertexAIClient(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get Vertex AI client: %v", err)
+		}
+		vertexClient = client
+		backend.closers = append(backend.closers, vertexClient.Close)
+	}
+
+	// GenAI Client取得 (Imagen/Veo用)
+	genaiClient, err := clientPoolService.GenAIPool().GetGenAIClient(ctx, geminiApiKey)
+	if err != nil {
+		log.Fatalf("Failed to get Gen AI client: %v", err)
+	}
+
+	// VertexAI Service初期化
+	backend.vertexAI = external.NewVertexAIService(
+		projectID, location, vtoModel, useSDK, vertexClient, vertexAIOptionsFromEnv()...,
+	)
+	backend.closers = append(backend.closers, backend.vertexAI.Close)
+
+	// Imagen AI Service初期化
+	backend.imagen = external.NewImagenAIService(genaiClient)
+	backend.closers = append(backend.closers, backend.imagen.Close)
+
+	// Veo AI Service初期化
+	backend.veo = external.NewVeoAIService(genaiClient)
+
+	// Nanobanana AI Service初期化
+	backend.nanobanana = external.NewNanobananaAIService(genaiClient)
+
+	backend.text = external.NewGeminiAIService(genaiClient)
+
+	return backend
+}
+
+// vertexAIOptionsFromEnv REST APIの接続先を差し替える（cmd/vertex-stub を使う場合など）
+func vertexAIOptionsFromEnv() []external.VertexAIOption {
+	var opts []external.VertexAIOption
+
+	if baseURL := os.Getenv("VERTEX_API_BASE_URL"); baseURL != "" {
+		log.Printf("[boot] VERTEX_API_BASE_URL=%s", baseURL)
+		opts = append(opts, external.WithVertexAIBaseURL(baseURL))
+	}
+
+	// 指定した場合はADCを使わず固定のトークンを送る
+	if token := os.Getenv("VERTEX_ACCESS_TOKEN"); token != "" {
+		opts = append(opts, external.WithVertexAITokenSource(
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+		))
+	}
+
+	return opts
+}
+
+// newFakeBackend 外部APIを呼ばずに決まった結果を返す（CI・画面開発用）
+func newFakeBackend(objectStorage domainrepos.ObjectStorage) *aiBackend {
+	config, err := fake.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load fake backend config: %v", err)
+	}
+
+	log.Printf("[boot] FAKE_LATENCY=%s, FAKE_FAIL_EVERY=%d", config.Latency, config.FailEvery)
+
+	return &aiBackend{
+		vertexAI:   fake.NewVertexAIService(config, objectStorage),
+		imagen:     fake.NewImagenAIService(config),
+		veo:        fake.NewVeoAIService(config),
+		nanobanana: fake.NewNanobananaAIService(config),
+		text:       fake.NewTextAIService(config),
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/repositories"
+	"tryon-demo/internal/infrastructure/vertexstub"
+)
+
+// Vertex AI Virtual Try-On の predict API をローカルで代替するサーバー。
+// 本体を VERTEX_API_BASE_URL=http://localhost:8090 で起動すると、REST API経由の試着がここに届く。
+func main() {
+	fakeConfig, err := fake.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load fake config: %v", err)
+	}
+
+	config := vertexstub.Config{
+		Fake:  fakeConfig,
+		Token: os.Getenv("STUB_TOKEN"),
+	}
+
+	if value := os.Getenv("STUB_FAIL_STATUS"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < 400 || status > 599 {
+			log.Fatalf("環境変数 STUB_FAIL_STATUS の値が不正です: %s (400〜599)", value)
+		}
+		config.FailStatus = status
+	}
+
+	if value := os.Getenv("STUB_RETRY_AFTER"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			log.Fatalf("環境変数 STUB_RETRY_AFTER の値が不正です: %s", value)
+		}
+		config.RetryAfter = seconds
+	}
+
+	// storageUri（local://）指定時の書き込み先。本体の OBJECT_STORAGE_DIR と同じディレクトリにする
+	if dir := os.Getenv("OBJECT_STORAGE_DIR"); dir != "" {
+		storage, err := repositories.NewLocalObjectStorage(dir)
+		if err != nil {
+			log.Fatalf("Failed to create object storage: %v", err)
+		}
+		config.Storage = storage
+		log.Printf("[boot] OBJECT_STORAGE_DIR=%s", dir)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8090"
+	}
+
+	log.Printf("[boot] FAKE_LATENCY=%s, FAKE_FAIL_EVERY=%d, STUB_FAIL_STATUS=%d", fakeConfig.Latency, fakeConfig.FailEvery, config.FailStatus)
+	log.Printf("Starting Vertex AI stub on port %s", port)
+
+	if err := http.ListenAndServe(":"+port, vertexstub.NewServer(config).Handler()); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
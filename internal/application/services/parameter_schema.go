@@ -0,0 +1,247 @@
+package services
+
+import (
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// ValidationMode パラメータの検証方法
+type ValidationMode string
+
+const (
+	// ValidationStrict 不正な値があれば項目ごとの検証エラーを返す
+	ValidationStrict ValidationMode = "strict"
+	// ValidationLenient 不正な値は黙って既定値（プリセットの値）に置き換える（従来の動作）
+	ValidationLenient ValidationMode = "lenient"
+)
+
+func (m ValidationMode) IsValid() bool {
+	return m == ValidationStrict || m == ValidationLenient
+}
+
+// パラメータの検証エラーの種類（FieldError.Code）
+const (
+	ParameterCodeInvalidNumber  = "invalid_number"
+	ParameterCodeInvalidBoolean = "invalid_boolean"
+	ParameterCodeOutOfRange     = "out_of_range"
+	ParameterCodeInvalidChoice  = "invalid_choice"
+	ParameterCodeInvalidFormat  = "invalid_format"
+	// 他の項目の設定により無視される値を指定した
+	ParameterCodeConflict = "conflict"
+)
+
+// ParameterType パラメータの値の型
+type ParameterType string
+
+const (
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeBoolean ParameterType = "boolean"
+)
+
+// ParameterRange 数値パラメータの範囲（両端を含む）
+type ParameterRange struct {
+	Min int64
+	Max int64
+}
+
+// ParameterField 1つのパラメータ（フォームの項目）が受け付ける値
+type ParameterField struct {
+	Name        string
+	Type        ParameterType
+	Default     any
+	Range       *ParameterRange
+	Choices     []string
+	Format      string // "uri" など、値の形式
+	Description string
+}
+
+// ParameterRule 複数の項目にまたがる規則
+type ParameterRule struct {
+	Fields      []string
+	Description string
+}
+
+// GeneratorParameterSchema 1つの生成機能のパラメータ
+type GeneratorParameterSchema struct {
+	Fields []ParameterField
+	Rules  []ParameterRule
+}
+
+// ParameterSchema 受け付けるパラメータの一覧
+type ParameterSchema struct {
+	Mode   ValidationMode
+	TryOn  GeneratorParameterSchema
+	Imagen GeneratorParameterSchema
+}
+
+var presetField = ParameterField{
+	Name:        "preset",
+	Type:        ParameterTypeString,
+	Description: "元にするプリセットのID。同時に指定したパラメータはプリセットの値より優先する",
+}
+
+// 試着のパラメータ（POST /tryon のフォームの項目）
+var (
+	tryOnWatermarkField = ParameterField{
+		Name:        "add_watermark",
+		Type:        ParameterTypeBoolean,
+		Description: "生成画像に電子透かしを入れる",
+	}
+	tryOnBaseStepsField = ParameterField{
+		Name:        "base_steps",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: valueobjects.MinBaseSteps, Max: valueobjects.MaxBaseSteps},
+		Description: "生成のステップ数。大きいほど詳細になるが時間がかかる",
+	}
+	tryOnPersonGenerationField = ParameterField{
+		Name:        "person_generation",
+		Type:        ParameterTypeString,
+		Choices:     toChoices(valueobjects.PersonGenerations),
+		Description: "人物の生成の制限",
+	}
+	tryOnSafetySettingField = ParameterField{
+		Name:        "safety_setting",
+		Type:        ParameterTypeString,
+		Choices:     toChoices(valueobjects.SafetySettings),
+		Description: "安全性フィルタの強さ",
+	}
+	tryOnSampleCountField = ParameterField{
+		Name:        "sample_count",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: valueobjects.MinSampleCount, Max: valueobjects.MaxSampleCount},
+		Description: "衣服1着あたりの生成枚数",
+	}
+	tryOnSeedField = ParameterField{
+		Name:        "seed",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: 0, Max: valueobjects.MaxSeed},
+		Description: "結果を再現するためのシード値。0はランダム",
+	}
+	tryOnStorageURIField = ParameterField{
+		Name:        "storage_uri",
+		Type:        ParameterTypeString,
+		Format:      "uri",
+		Description: "生成画像の保存先（scheme://bucket/prefix）。指定時は画像の代わりにURIを返す",
+	}
+	tryOnOutputMimeTypeField = ParameterField{
+		Name:        "output_mime_type",
+		Type:        ParameterTypeString,
+		Choices:     toChoices(valueobjects.OutputMimeTypes),
+		Description: "生成画像の形式",
+	}
+	tryOnCompressionQualityField = ParameterField{
+		Name:        "compression_quality",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: valueobjects.MinCompressionQuality, Max: valueobjects.MaxCompressionQuality},
+		Description: "JPEGの圧縮品質",
+	}
+)
+
+// Imagenのパラメータ（POST /imagen のフォームの項目）
+var (
+	imagenModelField = ParameterField{
+		Name:        "imagenModel",
+		Type:        ParameterTypeString,
+		Description: "ImagenのモデルID",
+	}
+	imagenNumberOfImagesField = ParameterField{
+		Name:        "numberOfImages",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: 1, Max: entities.MaxImagenNumberOfImages},
+		Description: "生成枚数",
+	}
+	imagenAspectRatioField = ParameterField{
+		Name:        "aspectRatio",
+		Type:        ParameterTypeString,
+		Choices:     entities.ImagenAspectRatios,
+		Description: "生成画像の縦横比",
+	}
+	imagenNegativePromptField = ParameterField{
+		Name:        "negativePrompt",
+		Type:        ParameterTypeString,
+		Description: "生成画像に含めたくない要素",
+	}
+	imagenSeedField = ParameterField{
+		Name:        "seed",
+		Type:        ParameterTypeInteger,
+		Range:       &ParameterRange{Min: 0, Max: valueobjects.MaxSeed},
+		Description: "結果を再現するためのシード値。0はランダム",
+	}
+	imagenIncludeRaiReasonField = ParameterField{
+		Name:        "includeRaiReason",
+		Type:        ParameterTypeBoolean,
+		Description: "Responsible AIのチェックで除外された場合に理由を含める",
+	}
+)
+
+// 項目をまたがる規則（strict では、無視される値を指定すると conflict になる）
+var (
+	tryOnSeedWatermarkRule = ParameterRule{
+		Fields:      []string{tryOnSeedField.Name, tryOnWatermarkField.Name},
+		Description: "add_watermark が true の場合 seed は使われない（strict では0以外の seed を指定するとエラー）",
+	}
+	tryOnCompressionMimeTypeRule = ParameterRule{
+		Fields:      []string{tryOnCompressionQualityField.Name, tryOnOutputMimeTypeField.Name},
+		Description: "compression_quality は output_mime_type が image/jpeg の場合のみ使われる（strict ではそれ以外の形式で0以外を指定するとエラー）",
+	}
+)
+
+// Schema 受け付けるパラメータの一覧と既定値を返す（Imagenのモデルの選択肢はモデルカタログから作る）
+func (s *ParameterService) Schema() ParameterSchema {
+	tryOn := usecases.DefaultTryOnParametersInput()
+	imagen := usecases.DefaultImagenParametersInput()
+
+	model := imagenModelField
+	for _, m := range s.models.Models(entities.GeneratorImagen) {
+		model.Choices = append(model.Choices, m.ID)
+	}
+	var defaultModel string
+	if m, ok := s.models.Default(entities.GeneratorImagen); ok {
+		defaultModel = m.ID
+	}
+
+	return ParameterSchema{
+		Mode: s.mode,
+		TryOn: GeneratorParameterSchema{
+			Fields: []ParameterField{
+				presetField,
+				withDefault(tryOnWatermarkField, tryOn.AddWatermark),
+				withDefault(tryOnBaseStepsField, tryOn.BaseSteps),
+				withDefault(tryOnPersonGenerationField, tryOn.PersonGeneration),
+				withDefault(tryOnSafetySettingField, tryOn.SafetySetting),
+				withDefault(tryOnSampleCountField, tryOn.SampleCount),
+				withDefault(tryOnSeedField, tryOn.Seed),
+				tryOnStorageURIField,
+				withDefault(tryOnOutputMimeTypeField, tryOn.OutputMimeType),
+				withDefault(tryOnCompressionQualityField, tryOn.CompressionQuality),
+			},
+			Rules: []ParameterRule{tryOnSeedWatermarkRule, tryOnCompressionMimeTypeRule},
+		},
+		Imagen: GeneratorParameterSchema{
+			Fields: []ParameterField{
+				presetField,
+				withDefault(model, defaultModel),
+				withDefault(imagenNumberOfImagesField, imagen.NumberOfImages),
+				withDefault(imagenAspectRatioField, imagen.AspectRatio),
+				imagenNegativePromptField,
+				withDefault(imagenSeedField, imagen.Seed),
+				withDefault(imagenIncludeRaiReasonField, imagen.IncludeRaiReason),
+			},
+		},
+	}
+}
+
+func withDefault(field ParameterField, value any) ParameterField {
+	field.Default = value
+	return field
+}
+
+func toChoices[T ~string](values []T) []string {
+	choices := make([]string, len(values))
+	for i, value := range values {
+		choices[i] = string(value)
+	}
+	return choices
+}
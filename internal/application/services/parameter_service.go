@@ -1,77 +1,292 @@
 package services
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 
 	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	domainservices "tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
-type ParameterService struct{}
+// ParameterValues パラメータの読み取り元（フォームの値、またはJSONのリクエストから組み立てた値）。
+// url.Values をそのまま渡せる。項目名はフォームと同じで、指定されていない項目は空文字を返す。
+type ParameterValues interface {
+	Get(key string) string
+}
+
+type ParameterService struct {
+	// preset= で指定されたプリセットを読み込む（nilの場合はプリセットを使えない）
+	presets *usecases.ParameterPresetUseCase
+	// モデルごとに指定できる範囲（枚数・縦横比・シード値）の検証に使う
+	models *domainservices.ModelCatalog
+	// storage_uri に書き込めるかの確認に使う（nilの場合は形式のみ確認する）
+	objectStorage repositories.ObjectStorage
+	mode          ValidationMode
+}
 
-func NewParameterService() *ParameterService {
-	return &ParameterService{}
+func NewParameterService(
+	presets *usecases.ParameterPresetUseCase,
+	models *domainservices.ModelCatalog,
+	objectStorage repositories.ObjectStorage,
+	mode ValidationMode,
+) *ParameterService {
+	return &ParameterService{
+		presets:       presets,
+		models:        models,
+		objectStorage: objectStorage,
+		mode:          mode,
+	}
 }
 
-func (s *ParameterService) ParseFromRequest(r *http.Request) *usecases.TryOnParametersInput {
+// ParseTryOn 試着のパラメータを読み取る。
+// preset を指定した場合はそのプリセット、指定しない場合は既定値を元に、送られた項目だけを上書きする。
+// strict では不正な値を項目ごとの検証エラー（KindInvalidInput）として返す。
+func (s *ParameterService) ParseTryOn(ctx context.Context, values ParameterValues) (*usecases.TryOnParametersInput, error) {
+	base := usecases.DefaultTryOnParametersInput()
+	if id := values.Get(presetField.Name); id != "" {
+		if s.presets == nil {
+			return nil, domainerrors.New(domainerrors.KindInvalidInput, "presets are not available")
+		}
+		preset, err := s.presets.TryOnParameters(ctx, entities.ParameterPresetID(id))
+		if err != nil {
+			return nil, s.presetError(id, err)
+		}
+		base = preset
+	}
+
+	reader := s.newReader(values)
 	params := &usecases.TryOnParametersInput{
-		AddWatermark:       s.getBool(r, "add_watermark", true),
-		BaseSteps:          s.getInt(r, "base_steps", 32, 1, 100),
-		PersonGeneration:   s.getString(r, "person_generation", "allow_adult"),
-		SafetySetting:      s.getString(r, "safety_setting", "block_medium_and_above"),
-		SampleCount:        s.getInt(r, "sample_count", 1, 1, 4),
-		Seed:               s.getInt(r, "seed", 0, 0, 0),
-		OutputMimeType:     s.getString(r, "output_mime_type", "image/png"),
-		CompressionQuality: s.getInt(r, "compression_quality", 75, 0, 100),
+		AddWatermark:       reader.bool(tryOnWatermarkField, base.AddWatermark),
+		BaseSteps:          int(reader.int(tryOnBaseStepsField, int64(base.BaseSteps))),
+		PersonGeneration:   reader.choice(tryOnPersonGenerationField, base.PersonGeneration),
+		SafetySetting:      reader.choice(tryOnSafetySettingField, base.SafetySetting),
+		SampleCount:        int(reader.int(tryOnSampleCountField, int64(base.SampleCount))),
+		Seed:               int(reader.int(tryOnSeedField, int64(base.Seed))),
+		StorageURI:         reader.storageURI(tryOnStorageURIField, base.StorageURI),
+		OutputMimeType:     reader.choice(tryOnOutputMimeTypeField, base.OutputMimeType),
+		CompressionQuality: int(reader.int(tryOnCompressionQualityField, int64(base.CompressionQuality))),
 	}
 
 	// PNG選択時はCompressionQualityを0に設定（APIの制限）
-	if params.OutputMimeType != "image/jpeg" {
+	if params.OutputMimeType != string(valueobjects.MimeTypeJPEG) {
+		reader.conflict(tryOnCompressionQualityField, params.CompressionQuality != 0,
+			"compression_quality is ignored unless output_mime_type is image/jpeg")
 		params.CompressionQuality = 0
 	}
 
 	// Watermarkが有効な場合、Seedを0に設定（APIの制限）
 	if params.AddWatermark {
+		reader.conflict(tryOnSeedField, params.Seed != 0, "seed is ignored while add_watermark is true")
 		params.Seed = 0
 	}
 
-	return params
+	// 使えない保存先（本物のVertex AIに送る local:// など）は、置き換える値がないため lenient でも拒否する
+	if params.StorageURI != "" && s.objectStorage != nil &&
+		valueobjects.ValidateStorageURI(params.StorageURI) == nil && !s.objectStorage.Supports(params.StorageURI) {
+		reader.reject(tryOnStorageURIField, ParameterCodeInvalidFormat,
+			fmt.Sprintf("storageURI %q is not supported by the current backend", params.StorageURI))
+	}
+
+	// 試着のモデルはサーバー側で固定（VTO_MODEL）のため、既定のモデルで検証する
+	if model, ok := s.models.Default(entities.GeneratorTryOn); ok {
+		params.SampleCount = reader.maxImages(tryOnSampleCountField, model, params.SampleCount, base.SampleCount)
+		params.Seed = int(reader.seed(tryOnSeedField, model, int64(params.Seed)))
+	}
+
+	if err := reader.err(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// ParseImagen Imagenの生成設定を読み取る（プリセットと検証の扱いは ParseTryOn と同じ）。
+// imagenModel が空の場合は既定のモデルを使い、モデルに合わない枚数・縦横比・シード値を検証する。
+// モデルカタログにないモデルは lenient でもエラーにする。
+func (s *ParameterService) ParseImagen(ctx context.Context, values ParameterValues) (*usecases.ImagenParametersInput, error) {
+	base := usecases.DefaultImagenParametersInput()
+	if id := values.Get(presetField.Name); id != "" {
+		if s.presets == nil {
+			return nil, domainerrors.New(domainerrors.KindInvalidInput, "presets are not available")
+		}
+		preset, err := s.presets.ImagenParameters(ctx, entities.ParameterPresetID(id))
+		if err != nil {
+			return nil, s.presetError(id, err)
+		}
+		base = preset
+	}
+
+	reader := s.newReader(values)
+	params := &usecases.ImagenParametersInput{
+		ImagenModel:      reader.string(imagenModelField, base.ImagenModel),
+		NumberOfImages:   int(reader.int(imagenNumberOfImagesField, int64(base.NumberOfImages))),
+		AspectRatio:      reader.choice(imagenAspectRatioField, base.AspectRatio),
+		NegativePrompt:   reader.string(imagenNegativePromptField, base.NegativePrompt),
+		Seed:             reader.int(imagenSeedField, base.Seed),
+		IncludeRaiReason: reader.bool(imagenIncludeRaiReasonField, base.IncludeRaiReason),
+	}
+
+	model, err := s.models.Resolve(entities.GeneratorImagen, params.ImagenModel)
+	if err != nil {
+		reader.reject(imagenModelField, ParameterCodeInvalidChoice, fmt.Sprintf("unsupported model: %q", params.ImagenModel))
+	} else {
+		params.ImagenModel = model.ID
+		params.NumberOfImages = reader.maxImages(imagenNumberOfImagesField, model, params.NumberOfImages, base.NumberOfImages)
+		params.AspectRatio = reader.aspectRatio(imagenAspectRatioField, model, params.AspectRatio)
+		params.Seed = reader.seed(imagenSeedField, model, params.Seed)
+	}
+
+	if err := reader.err(); err != nil {
+		return nil, err
+	}
+	return params, nil
 }
 
-func (s *ParameterService) getBool(r *http.Request, key string, defaultValue bool) bool {
-	value := r.FormValue(key)
+// presetError 存在しない・別の生成機能のプリセットは入力の誤りとして返す
+func (s *ParameterService) presetError(id string, err error) error {
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, usecases.ErrPresetGeneratorMismatch) {
+		return domainerrors.Wrap(domainerrors.KindInvalidInput, err, fmt.Sprintf("invalid preset %q", id))
+	}
+	return fmt.Errorf("failed to load preset %q: %w", id, err)
+}
+
+func (s *ParameterService) newReader(values ParameterValues) *parameterReader {
+	return &parameterReader{values: values, strict: s.mode != ValidationLenient}
+}
+
+// parameterReader パラメータを読み取り、検証エラーを集める。
+// 不正な値は元の値（既定値・プリセットの値）のままにし、strict の場合だけエラーとして記録する。
+type parameterReader struct {
+	values ParameterValues
+	strict bool
+	fields []domainerrors.FieldError
+}
+
+func (p *parameterReader) int(field ParameterField, current int64) int64 {
+	value := p.values.Get(field.Name)
 	if value == "" {
-		return defaultValue
+		return current
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		p.fail(field, ParameterCodeInvalidNumber, fmt.Sprintf("must be an integer, got %q", value))
+		return current
+	}
+	if field.Range != nil && (n < field.Range.Min || n > field.Range.Max) {
+		p.fail(field, ParameterCodeOutOfRange, fmt.Sprintf("must be between %d and %d, got %d", field.Range.Min, field.Range.Max, n))
+		return current
 	}
-	return value == "true"
+	return n
 }
 
-func (s *ParameterService) getInt(r *http.Request, key string, defaultValue, min, max int) int {
-	value := r.FormValue(key)
+func (p *parameterReader) bool(field ParameterField, current bool) bool {
+	value := p.values.Get(field.Name)
 	if value == "" {
-		return defaultValue
+		return current
 	}
 
-	intVal, err := strconv.Atoi(value)
+	if !p.strict {
+		return value == "true"
+	}
+	b, err := strconv.ParseBool(value)
 	if err != nil {
-		return defaultValue
+		p.fail(field, ParameterCodeInvalidBoolean, fmt.Sprintf("must be true or false, got %q", value))
+		return current
 	}
+	return b
+}
 
-	if max > 0 && (intVal < min || intVal > max) {
-		return defaultValue
+func (p *parameterReader) choice(field ParameterField, current string) string {
+	value := p.values.Get(field.Name)
+	if value == "" {
+		return current
 	}
 
-	if min > 0 && intVal < min {
-		return defaultValue
+	if !slices.Contains(field.Choices, value) {
+		p.fail(field, ParameterCodeInvalidChoice, fmt.Sprintf("must be one of %v, got %q", field.Choices, value))
+		return current
 	}
+	return value
+}
 
-	return intVal
+func (p *parameterReader) string(field ParameterField, current string) string {
+	if value := p.values.Get(field.Name); value != "" {
+		return value
+	}
+	return current
 }
 
-func (s *ParameterService) getString(r *http.Request, key, defaultValue string) string {
-	value := r.FormValue(key)
-	if value == "" {
-		return defaultValue
+// storageURI 保存先は形式の誤りも lenient で黙って無視せず、生成時の検証でエラーにする
+func (p *parameterReader) storageURI(field ParameterField, current string) string {
+	value := p.string(field, current)
+	if value != current {
+		if err := valueobjects.ValidateStorageURI(value); err != nil {
+			p.fail(field, ParameterCodeInvalidFormat, err.Error())
+		}
 	}
 	return value
 }
+
+// maxImages モデルが1回で生成できる枚数を超えていないか。
+// 超えた場合は元の値（上限まで）に戻し、リクエストで指定した値なら strict でエラーにする
+func (p *parameterReader) maxImages(field ParameterField, model entities.GenerativeModel, value, current int) int {
+	limit := model.Capabilities.MaxImages
+	if limit <= 0 || value <= limit {
+		return value
+	}
+	if p.values.Get(field.Name) != "" {
+		p.fail(field, ParameterCodeOutOfRange, fmt.Sprintf("%s generates at most %d images, got %d", model.ID, limit, value))
+	}
+	return min(current, limit)
+}
+
+// aspectRatio モデルが指定できる縦横比か。指定できない場合はモデルの最初の縦横比に置き換える
+func (p *parameterReader) aspectRatio(field ParameterField, model entities.GenerativeModel, value string) string {
+	ratios := model.Capabilities.AspectRatios
+	if len(ratios) == 0 || model.SupportsAspectRatio(value) {
+		return value
+	}
+	if p.values.Get(field.Name) != "" {
+		p.fail(field, ParameterCodeInvalidChoice, fmt.Sprintf("%s supports %v, got %q", model.ID, ratios, value))
+	}
+	return ratios[0]
+}
+
+// seed シード値を指定できないモデルでは0にする
+func (p *parameterReader) seed(field ParameterField, model entities.GenerativeModel, value int64) int64 {
+	if model.Capabilities.Seed {
+		return value
+	}
+	p.conflict(field, value != 0, fmt.Sprintf("seed is ignored because %s does not support it", model.ID))
+	return 0
+}
+
+// conflict 他の項目の設定により無視される値がリクエストで指定されていれば記録する
+func (p *parameterReader) conflict(field ParameterField, ignored bool, message string) {
+	if ignored && p.values.Get(field.Name) != "" {
+		p.fail(field, ParameterCodeConflict, message)
+	}
+}
+
+func (p *parameterReader) fail(field ParameterField, code, message string) {
+	if !p.strict {
+		return
+	}
+	p.fields = append(p.fields, domainerrors.FieldError{Field: field.Name, Code: code, Message: message})
+}
+
+// reject 検証方法によらずエラーとして記録する（lenient でも置き換える値がない場合）
+func (p *parameterReader) reject(field ParameterField, code, message string) {
+	p.fields = append(p.fields, domainerrors.FieldError{Field: field.Name, Code: code, Message: message})
+}
+
+func (p *parameterReader) err() error {
+	return domainerrors.NewValidation(p.fields)
+}
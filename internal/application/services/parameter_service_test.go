@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	domainservices "tryon-demo/internal/domain/services"
+)
+
+func TestParameterService_ParseFromRequest_StrictFieldErrors(t *testing.T) {
+	service := NewParameterService(nil, domainservices.DefaultModelCatalog(), nil, ValidationStrict)
+
+	_, err := service.ParseTryOn(context.Background(), url.Values{
+		"add_watermark":     {"yes"},
+		"base_steps":        {"abc"},
+		"sample_count":      {"9"},
+		"person_generation": {"everyone"},
+		"storage_uri":       {"bucket/prefix"},
+	})
+	if kind := domainerrors.KindOf(err); kind != domainerrors.KindInvalidInput {
+		t.Fatalf("KindOf(err) = %s, want %s (err = %v)", kind, domainerrors.KindInvalidInput, err)
+	}
+
+	want := map[string]string{
+		"add_watermark":     ParameterCodeInvalidBoolean,
+		"base_steps":        ParameterCodeInvalidNumber,
+		"sample_count":      ParameterCodeOutOfRange,
+		"person_generation": ParameterCodeInvalidChoice,
+		"storage_uri":       ParameterCodeInvalidFormat,
+	}
+	fields := domainerrors.FieldErrorsOf(err)
+	if len(fields) != len(want) {
+		t.Fatalf("FieldErrorsOf(err) = %v, want %d fields", fields, len(want))
+	}
+	for _, field := range fields {
+		if want[field.Field] != field.Code {
+			t.Errorf("field %s code = %s, want %s", field.Field, field.Code, want[field.Field])
+		}
+	}
+}
+
+// gcsOnlyObjectStorage gs:// だけを扱える保存先（本物のVertex AIを使う場合の構成）
+type gcsOnlyObjectStorage struct{}
+
+func (gcsOnlyObjectStorage) Supports(uri string) bool { return strings.HasPrefix(uri, "gs://") }
+
+func (gcsOnlyObjectStorage) Get(ctx context.Context, uri string) ([]byte, error) { return nil, nil }
+
+func (gcsOnlyObjectStorage) Put(ctx context.Context, uri string, data []byte, contentType string) error {
+	return nil
+}
+
+func TestParameterService_ParseTryOn_UnsupportedStorageURI(t *testing.T) {
+	for _, mode := range []ValidationMode{ValidationStrict, ValidationLenient} {
+		service := NewParameterService(nil, domainservices.DefaultModelCatalog(), gcsOnlyObjectStorage{}, mode)
+
+		_, err := service.ParseTryOn(context.Background(), url.Values{"storage_uri": {"local://bucket/prefix"}})
+		fields := domainerrors.FieldErrorsOf(err)
+		if len(fields) != 1 || fields[0].Field != "storage_uri" || fields[0].Code != ParameterCodeInvalidFormat {
+			t.Errorf("%s: FieldErrorsOf(err) = %v, want storage_uri %s", mode, fields, ParameterCodeInvalidFormat)
+		}
+
+		params, err := service.ParseTryOn(context.Background(), url.Values{"storage_uri": {"gs://bucket/prefix"}})
+		if err != nil {
+			t.Fatalf("%s: ParseTryOn() error = %v", mode, err)
+		}
+		if params.StorageURI != "gs://bucket/prefix" {
+			t.Errorf("%s: StorageURI = %q, want gs://bucket/prefix", mode, params.StorageURI)
+		}
+	}
+}
+
+func TestParameterService_ParseFromRequest_Conflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    url.Values
+		wantField string
+	}{
+		{"seed with watermark", url.Values{"add_watermark": {"true"}, "seed": {"42"}}, "seed"},
+		// Watermarkは既定で有効
+		{"seed with default watermark", url.Values{"seed": {"42"}}, "seed"},
+		{"compression with png", url.Values{"output_mime_type": {"image/png"}, "compression_quality": {"80"}}, "compression_quality"},
+		{"no conflict", url.Values{"add_watermark": {"false"}, "seed": {"42"}, "output_mime_type": {"image/jpeg"}, "compression_quality": {"80"}}, ""},
+		// 0は無視されても結果が変わらないため許可する
+		{"zero seed with watermark", url.Values{"add_watermark": {"true"}, "seed": {"0"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParameterService(nil, domainservices.DefaultModelCatalog(), nil, ValidationStrict).ParseTryOn(context.Background(), tt.values)
+			fields := domainerrors.FieldErrorsOf(err)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ParseTryOn() error = %v, want nil", err)
+				}
+				return
+			}
+			if len(fields) != 1 || fields[0].Field != tt.wantField || fields[0].Code != ParameterCodeConflict {
+				t.Errorf("FieldErrorsOf(err) = %v, want conflict on %s", fields, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestParameterService_ParseFromRequest_LenientFallsBack(t *testing.T) {
+	service := NewParameterService(nil, domainservices.DefaultModelCatalog(), nil, ValidationLenient)
+
+	params, err := service.ParseTryOn(context.Background(), url.Values{
+		"base_steps":          {"500"},
+		"sample_count":        {"two"},
+		"person_generation":   {"everyone"},
+		"seed":                {"42"},
+		"compression_quality": {"80"},
+	})
+	if err != nil {
+		t.Fatalf("ParseTryOn() error = %v", err)
+	}
+	if params.BaseSteps != 32 || params.SampleCount != 1 || params.PersonGeneration != "allow_adult" {
+		t.Errorf("ParseTryOn() = %+v, want defaults for invalid values", params)
+	}
+	// Watermark有効・PNGのため無視される
+	if params.Seed != 0 || params.CompressionQuality != 0 {
+		t.Errorf("Seed = %d, CompressionQuality = %d, want 0, 0", params.Seed, params.CompressionQuality)
+	}
+}
+
+func TestParameterService_ParseImagenFromRequest(t *testing.T) {
+	service := NewParameterService(nil, domainservices.DefaultModelCatalog(), nil, ValidationStrict)
+
+	params, err := service.ParseImagen(context.Background(), url.Values{
+		"numberOfImages":   {"3"},
+		"aspectRatio":      {"16:9"},
+		"seed":             {"7"},
+		"includeRaiReason": {"true"},
+	})
+	if err != nil {
+		t.Fatalf("ParseImagen() error = %v", err)
+	}
+	if params.NumberOfImages != 3 || params.AspectRatio != "16:9" || params.Seed != 7 || !params.IncludeRaiReason {
+		t.Errorf("ParseImagen() = %+v", params)
+	}
+
+	_, err = service.ParseImagen(context.Background(), url.Values{
+		"numberOfImages": {"5"},
+		"aspectRatio":    {"2:1"},
+		"seed":           {"-1"},
+	})
+	if fields := domainerrors.FieldErrorsOf(err); len(fields) != 3 {
+		t.Errorf("FieldErrorsOf(err) = %v, want 3 fields", fields)
+	}
+}
+
+func TestParameterService_ParseImagen_ModelCapabilities(t *testing.T) {
+	catalog := domainservices.NewModelCatalog()
+	if err := catalog.Register(entities.GenerativeModel{
+		ID:           "imagen-small",
+		Generator:    entities.GeneratorImagen,
+		Capabilities: entities.ModelCapabilities{MaxImages: 2, AspectRatios: []string{"1:1", "16:9"}},
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	values := url.Values{
+		"numberOfImages": {"3"},
+		"aspectRatio":    {"9:16"},
+		"seed":           {"7"},
+	}
+
+	_, err := NewParameterService(nil, catalog, nil, ValidationStrict).ParseImagen(context.Background(), values)
+	want := map[string]string{
+		"numberOfImages": ParameterCodeOutOfRange,
+		"aspectRatio":    ParameterCodeInvalidChoice,
+		"seed":           ParameterCodeConflict,
+	}
+	fields := domainerrors.FieldErrorsOf(err)
+	if len(fields) != len(want) {
+		t.Fatalf("FieldErrorsOf(err) = %v, want %d fields", fields, len(want))
+	}
+	for _, field := range fields {
+		if want[field.Field] != field.Code {
+			t.Errorf("field %s code = %s, want %s", field.Field, field.Code, want[field.Field])
+		}
+	}
+
+	params, err := NewParameterService(nil, catalog, nil, ValidationLenient).ParseImagen(context.Background(), values)
+	if err != nil {
+		t.Fatalf("ParseImagen() error = %v", err)
+	}
+	if params.ImagenModel != "imagen-small" || params.NumberOfImages != 1 || params.AspectRatio != "1:1" || params.Seed != 0 {
+		t.Errorf("ParseImagen() = %+v, want the model's limits", params)
+	}
+}
+
+func TestParameterService_ParseImagen_UnknownModel(t *testing.T) {
+	// カタログにないモデルは lenient でも置き換えずにエラーにする
+	service := NewParameterService(nil, domainservices.DefaultModelCatalog(), nil, ValidationLenient)
+
+	_, err := service.ParseImagen(context.Background(), url.Values{"imagenModel": {"veo-2.0-generate-001"}})
+	fields := domainerrors.FieldErrorsOf(err)
+	if len(fields) != 1 || fields[0].Field != "imagenModel" || fields[0].Code != ParameterCodeInvalidChoice {
+		t.Errorf("FieldErrorsOf(err) = %v, want invalid_choice on imagenModel", fields)
+	}
+}
@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tryon-demo/internal/domain/repositories"
+)
+
+// 生成ファイルのMIMEタイプと拡張子の対応
+var assetExtensions = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpg",
+	"image/webp": "webp",
+	"video/mp4":  "mp4",
+}
+
+// AssetUseCase 生成結果をサーバー側に保存し、URLで参照できるようにする
+type AssetUseCase struct {
+	blobStore repositories.BlobStore
+}
+
+func NewAssetUseCase(blobStore repositories.BlobStore) *AssetUseCase {
+	return &AssetUseCase{
+		blobStore: blobStore,
+	}
+}
+
+type AssetOutput struct {
+	// ID 内容のハッシュ値に拡張子を付けたもの（例: 3a7b...e1.png）
+	ID       string
+	MimeType string
+	Size     int
+}
+
+// Store ファイルを保存してIDを返す。同じ内容なら同じIDになる。
+func (uc *AssetUseCase) Store(ctx context.Context, data []byte, mimeType string) (*AssetOutput, error) {
+	ext, ok := assetExtensions[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported asset mime type: %s", mimeType)
+	}
+
+	key, err := uc.blobStore.Put(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store asset: %w", err)
+	}
+
+	return &AssetOutput{
+		ID:       key + "." + ext,
+		MimeType: mimeType,
+		Size:     len(data),
+	}, nil
+}
+
+// Get 保存済みのファイルを取得する。MIMEタイプはIDの拡張子から決める。
+func (uc *AssetUseCase) Get(ctx context.Context, id string) (*AssetDataOutput, error) {
+	key, ext, ok := strings.Cut(id, ".")
+	if !ok {
+		return nil, fmt.Errorf("asset %s: %w", id, repositories.ErrNotFound)
+	}
+
+	mimeType := ""
+	for candidate, candidateExt := range assetExtensions {
+		if candidateExt == ext {
+			mimeType = candidate
+			break
+		}
+	}
+	if mimeType == "" {
+		return nil, fmt.Errorf("asset %s: %w", id, repositories.ErrNotFound)
+	}
+
+	data, err := uc.blobStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetDataOutput{
+		Data:     data,
+		MimeType: mimeType,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestAssetUseCase_StoreAndGet(t *testing.T) {
+	uc := NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20))
+	ctx := context.Background()
+
+	asset, err := uc.Store(ctx, []byte("png"), "image/png")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if !strings.HasSuffix(asset.ID, ".png") || asset.Size != 3 {
+		t.Errorf("Store() = %+v, want a .png ID of size 3", asset)
+	}
+
+	// 同じ内容は同じID
+	again, err := uc.Store(ctx, []byte("png"), "image/png")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if again.ID != asset.ID {
+		t.Errorf("second ID = %s, want %s", again.ID, asset.ID)
+	}
+
+	got, err := uc.Get(ctx, asset.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got.Data) != "png" || got.MimeType != "image/png" {
+		t.Errorf("Get() = %q %s, want png image/png", got.Data, got.MimeType)
+	}
+
+	// 拡張子からMIMEタイプを決める
+	video, err := uc.Store(ctx, []byte("mp4"), "video/mp4")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if got, err := uc.Get(ctx, video.ID); err != nil || got.MimeType != "video/mp4" {
+		t.Errorf("Get(%s) = %v, %v, want video/mp4", video.ID, got, err)
+	}
+
+	if _, err := uc.Store(ctx, []byte("gif"), "image/gif"); err == nil {
+		t.Error("Store(image/gif) error = nil, want unsupported mime type")
+	}
+}
+
+func TestAssetUseCase_Get_NotFound(t *testing.T) {
+	uc := NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20))
+	ctx := context.Background()
+
+	asset, err := uc.Store(ctx, []byte("png"), "image/png")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	key := strings.TrimSuffix(asset.ID, ".png")
+
+	for _, id := range []string{
+		key,          // 拡張子なし
+		key + ".gif", // 未対応の拡張子
+		strings.Repeat("0", 64) + ".png",
+	} {
+		if _, err := uc.Get(ctx, id); !errors.Is(err, domainrepos.ErrNotFound) {
+			t.Errorf("Get(%s) error = %v, want ErrNotFound", id, err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 100
+)
+
+// ErrInvalidHistoryQuery 履歴の検索条件が不正な場合に返す
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+// HistoryUseCase 全生成機能の履歴を記録・参照する
+type HistoryUseCase struct {
+	recordRepo repositories.GenerationRecordRepository
+	blobStore  repositories.BlobStore
+}
+
+func NewHistoryUseCase(
+	recordRepo repositories.GenerationRecordRepository,
+	blobStore repositories.BlobStore,
+) *HistoryUseCase {
+	return &HistoryUseCase{
+		recordRepo: recordRepo,
+		blobStore:  blobStore,
+	}
+}
+
+type HistoryListInput struct {
+	GeneratorType string
+	Client        string
+	Page          int // 1始まり
+	PageSize      int
+}
+
+type HistoryListOutput struct {
+	Records  []*GenerationRecordOutput
+	Total    int
+	Page     int
+	PageSize int
+}
+
+type GenerationRecordOutput struct {
+	ID               entities.GenerationRecordID
+	GeneratorType    entities.GeneratorType
+	Client           string
+	Model            string
+	Prompt           string
+	TranslatedPrompt string
+	Parameters       map[string]string
+	Inputs           []GenerationAssetOutput
+	Outputs          []GenerationAssetOutput
+	Status           entities.GenerationStatus
+	Error            string
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	Duration         time.Duration
+}
+
+type GenerationAssetOutput struct {
+	Key      string
+	MimeType string
+	Size     int
+}
+
+// 履歴ファイルの種類
+type GenerationAssetRole string
+
+const (
+	AssetRoleInput  GenerationAssetRole = "inputs"
+	AssetRoleOutput GenerationAssetRole = "outputs"
+)
+
+// AssetDataOutput ダウンロード用のファイル
+type AssetDataOutput struct {
+	Data     []byte
+	MimeType string
+}
+
+// List 履歴を新しい順に返す
+func (uc *HistoryUseCase) List(ctx context.Context, input HistoryListInput) (*HistoryListOutput, error) {
+	generatorType := entities.GeneratorType(input.GeneratorType)
+	if generatorType != "" && !generatorType.IsValid() {
+		return nil, fmt.Errorf("%w: unknown generator type %q", ErrInvalidHistoryQuery, input.GeneratorType)
+	}
+
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = defaultHistoryPageSize
+	}
+	if pageSize > maxHistoryPageSize {
+		pageSize = maxHistoryPageSize
+	}
+
+	// 範囲を限るクライアントは、指定によらず自分の履歴のみ
+	client := input.Client
+	if scopedClient, ok := ScopedClient(ctx); ok {
+		client = scopedClient
+	}
+
+	records, total, err := uc.recordRepo.List(ctx, repositories.GenerationRecordFilter{
+		GeneratorType: generatorType,
+		Client:        client,
+		Offset:        (page - 1) * pageSize,
+		Limit:         pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	output := &HistoryListOutput{
+		Records:  make([]*GenerationRecordOutput, len(records)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i, record := range records {
+		output.Records[i] = toGenerationRecordOutput(record)
+	}
+
+	return output, nil
+}
+
+// Get 履歴を1件取得する
+func (uc *HistoryUseCase) Get(ctx context.Context, id entities.GenerationRecordID) (*GenerationRecordOutput, error) {
+	record, err := uc.findRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGenerationRecordOutput(record), nil
+}
+
+// GetAsset 履歴の入力・出力ファイルを取得する
+func (uc *HistoryUseCase) GetAsset(
+	ctx context.Context,
+	id entities.GenerationRecordID,
+	role GenerationAssetRole,
+	index int,
+) (*AssetDataOutput, error) {
+	record, err := uc.findRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []entities.GenerationAsset
+	switch role {
+	case AssetRoleInput:
+		assets = record.Inputs()
+	case AssetRoleOutput:
+		assets = record.Outputs()
+	default:
+		return nil, fmt.Errorf("%w: unknown asset role %q", ErrInvalidHistoryQuery, role)
+	}
+
+	if index < 0 || index >= len(assets) {
+		return nil, fmt.Errorf("%s[%d] of %s: %w", role, index, id, repositories.ErrNotFound)
+	}
+
+	asset := assets[index]
+	data, err := uc.blobStore.Get(ctx, asset.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetDataOutput{
+		Data:     data,
+		MimeType: asset.MimeType(),
+	}, nil
+}
+
+// findRecord 参照できない他のクライアントの履歴は存在しないものとして扱う
+func (uc *HistoryUseCase) findRecord(ctx context.Context, id entities.GenerationRecordID) (*entities.GenerationRecord, error) {
+	record, err := uc.recordRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(ctx, record.Client()) {
+		return nil, fmt.Errorf("generation record %s: %w", id, repositories.ErrNotFound)
+	}
+	return record, nil
+}
+
+// Start 生成1回分の記録を開始する（コンテキストのクライアントの生成として記録する）。
+// 履歴が無効（nil）の場合はnilを返し、記録は行わない。
+func (uc *HistoryUseCase) Start(
+	ctx context.Context,
+	generatorType entities.GeneratorType,
+	model string,
+	prompt string,
+) *GenerationRecording {
+	if uc == nil {
+		return nil
+	}
+
+	record, err := entities.NewGenerationRecord(generatorType, model, prompt, time.Now())
+	if err != nil {
+		slog.Error("Failed to start generation record", "error", err)
+		return nil
+	}
+	record.SetClient(ClientFromContext(ctx))
+
+	return &GenerationRecording{
+		history: uc,
+		record:  record,
+	}
+}
+
+// GenerationRecording 記録中の生成履歴。nilでも安全に呼び出せる。
+type GenerationRecording struct {
+	history *HistoryUseCase
+	record  *entities.GenerationRecord
+}
+
+func (r *GenerationRecording) SetParameter(key string, value any) {
+	if r == nil {
+		return
+	}
+	r.record.SetParameter(key, fmt.Sprint(value))
+}
+
+func (r *GenerationRecording) SetTranslatedPrompt(translatedPrompt string) {
+	if r == nil {
+		return
+	}
+	r.record.SetTranslatedPrompt(translatedPrompt)
+}
+
+func (r *GenerationRecording) AddInput(ctx context.Context, data []byte, mimeType string) {
+	if r == nil {
+		return
+	}
+	if asset, ok := r.putAsset(ctx, data, mimeType); ok {
+		r.record.AddInput(asset)
+	}
+}
+
+func (r *GenerationRecording) AddOutput(ctx context.Context, data []byte, mimeType string) {
+	if r == nil {
+		return
+	}
+	if asset, ok := r.putAsset(ctx, data, mimeType); ok {
+		r.record.AddOutput(asset)
+	}
+}
+
+// Finish 結果を確定して保存する。errがnilでなければ失敗として記録する。
+// 履歴の保存に失敗しても生成結果には影響させない。
+func (r *GenerationRecording) Finish(ctx context.Context, err error) {
+	if r == nil {
+		return
+	}
+
+	if err != nil {
+		r.record.Fail(err, time.Now())
+	} else {
+		r.record.Complete(time.Now())
+	}
+
+	// キャンセルされたリクエストでも履歴は残す
+	if saveErr := r.history.recordRepo.Save(context.WithoutCancel(ctx), r.record); saveErr != nil {
+		slog.Error("Failed to save generation record", "id", r.record.ID(), "error", saveErr)
+	}
+}
+
+func (r *GenerationRecording) putAsset(ctx context.Context, data []byte, mimeType string) (entities.GenerationAsset, bool) {
+	if len(data) == 0 {
+		return entities.GenerationAsset{}, false
+	}
+
+	key, err := r.history.blobStore.Put(context.WithoutCancel(ctx), data)
+	if err != nil {
+		slog.Error("Failed to save generation asset", "id", r.record.ID(), "error", err)
+		return entities.GenerationAsset{}, false
+	}
+
+	return entities.NewGenerationAsset(key, mimeType, len(data)), true
+}
+
+func toGenerationRecordOutput(record *entities.GenerationRecord) *GenerationRecordOutput {
+	return &GenerationRecordOutput{
+		ID:               record.ID(),
+		GeneratorType:    record.GeneratorType(),
+		Client:           record.Client(),
+		Model:            record.Model(),
+		Prompt:           record.Prompt(),
+		TranslatedPrompt: record.TranslatedPrompt(),
+		Parameters:       record.Parameters(),
+		Inputs:           toGenerationAssetOutputs(record.Inputs()),
+		Outputs:          toGenerationAssetOutputs(record.Outputs()),
+		Status:           record.Status(),
+		Error:            record.ErrorMessage(),
+		StartedAt:        record.StartedAt(),
+		FinishedAt:       record.FinishedAt(),
+		Duration:         record.Duration(),
+	}
+}
+
+func toGenerationAssetOutputs(assets []entities.GenerationAsset) []GenerationAssetOutput {
+	outputs := make([]GenerationAssetOutput, len(assets))
+	for i, asset := range assets {
+		outputs[i] = GenerationAssetOutput{
+			Key:      asset.Key(),
+			MimeType: asset.MimeType(),
+			Size:     asset.Size(),
+		}
+	}
+	return outputs
+}
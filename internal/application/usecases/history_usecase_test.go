@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestHistoryUseCase_ClientScope(t *testing.T) {
+	uc := NewHistoryUseCase(repositories.NewMemoryGenerationRecordRepository(), repositories.NewMemoryBlobStore(1<<20))
+
+	record := func(client string) entities.GenerationRecordID {
+		ctx := WithClient(context.Background(), client)
+		recording := uc.Start(ctx, entities.GeneratorImagen, "imagen", client+"のプロンプト")
+		recording.AddOutput(ctx, []byte(client), "image/png")
+		recording.Finish(ctx, nil)
+		return recording.record.ID()
+	}
+	aliceID := record("alice")
+	bobID := record("bob")
+
+	alice := WithClientScope(context.Background(), "alice")
+
+	// 他のクライアントを指定しても自分の履歴のみ
+	list, err := uc.List(alice, HistoryListInput{Client: "bob"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 1 || list.Records[0].ID != aliceID {
+		t.Errorf("List() = %d records, want only alice's", list.Total)
+	}
+
+	if _, err := uc.Get(alice, aliceID); err != nil {
+		t.Errorf("Get(own) error = %v", err)
+	}
+	if _, err := uc.Get(alice, bobID); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("Get(bob's) error = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.GetAsset(alice, bobID, AssetRoleOutput, 0); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("GetAsset(bob's) error = %v, want ErrNotFound", err)
+	}
+
+	// 範囲を限らない場合は全てのクライアントの履歴を参照できる
+	list, err = uc.List(WithClient(context.Background(), "admin"), HistoryListInput{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 2 {
+		t.Errorf("List() without a scope = %d records, want 2", list.Total)
+	}
+	if _, err := uc.GetAsset(context.Background(), bobID, AssetRoleOutput, 0); err != nil {
+		t.Errorf("GetAsset() without a scope error = %v", err)
+	}
+}
@@ -2,19 +2,27 @@ package usecases
 
 import (
 	"context"
+
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/services"
 )
 
 type ImagenUseCase struct {
 	domainService *services.ImagenDomainService
+	history       *HistoryUseCase
+	usage         *UsageUseCase
 }
 
 func NewImagenUseCase(
 	domainService *services.ImagenDomainService,
+	history *HistoryUseCase,
+	usage *UsageUseCase,
 ) *ImagenUseCase {
 	return &ImagenUseCase{
 		domainService: domainService,
+		history:       history,
+		usage:         usage,
 	}
 }
 
@@ -28,13 +36,41 @@ type ImagenInput struct {
 	IncludeRaiReason bool
 }
 
+// ImagenParametersInput プロンプト以外の生成設定（プリセットとリクエストの指定を合わせたもの）
+type ImagenParametersInput struct {
+	ImagenModel      string // 空の場合は既定のモデル
+	NumberOfImages   int
+	AspectRatio      string
+	NegativePrompt   string
+	Seed             int64
+	IncludeRaiReason bool
+}
+
+// DefaultImagenParametersInput - リクエストで指定されなかった項目に使う既定値
+func DefaultImagenParametersInput() *ImagenParametersInput {
+	return &ImagenParametersInput{
+		NumberOfImages: 1,
+		AspectRatio:    entities.ImagenAspectRatios[0],
+	}
+}
+
 type ImagenOutput struct {
 	Images []ImageOutput
 }
 
 func (uc *ImagenUseCase) Execute(ctx context.Context, input ImagenInput) (*ImagenOutput, error) {
+	if err := uc.usage.CheckBudget(ctx); err != nil {
+		return nil, err
+	}
 
-	request := entities.NewImagenRequestWithConfig(
+	recording := uc.history.Start(ctx, entities.GeneratorImagen, input.ImagenModel, input.Prompt)
+	recording.SetParameter("numberOfImages", input.NumberOfImages)
+	recording.SetParameter("aspectRatio", input.AspectRatio)
+	recording.SetParameter("negativePrompt", input.NegativePrompt)
+	recording.SetParameter("seed", input.Seed)
+	recording.SetParameter("includeRaiReason", input.IncludeRaiReason)
+
+	request, err := entities.NewImagenRequestWithConfig(
 		input.Prompt,
 		input.ImagenModel,
 		input.NumberOfImages,
@@ -43,12 +79,27 @@ func (uc *ImagenUseCase) Execute(ctx context.Context, input ImagenInput) (*Image
 		input.Seed,
 		input.IncludeRaiReason,
 	)
+	if err != nil {
+		err = domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid parameters")
+		recording.Finish(ctx, err)
+		return nil, err
+	}
 
 	result, err := uc.domainService.ProcessImagen(ctx, request)
 	if err != nil {
+		recording.Finish(ctx, err)
 		return nil, err
 	}
 
+	uc.usage.Record(ctx, entities.UsageItem{
+		Model:    request.ImagenModel(),
+		Unit:     entities.PriceUnitImage,
+		Quantity: float64(len(result.Images())),
+	})
+
+	// ドメインサービスで翻訳済みのプロンプト
+	recording.SetTranslatedPrompt(request.Prompt())
+
 	output := &ImagenOutput{
 		Images: make([]ImageOutput, len(result.Images())),
 	}
@@ -58,7 +109,10 @@ func (uc *ImagenUseCase) Execute(ctx context.Context, input ImagenInput) (*Image
 			Data: img.Data(),
 			Type: string(img.MimeType()),
 		}
+		recording.AddOutput(ctx, img.Data(), img.MimeType())
 	}
 
+	recording.Finish(ctx, nil)
+
 	return output, nil
 }
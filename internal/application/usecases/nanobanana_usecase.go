@@ -10,11 +10,19 @@ import (
 
 type NanobananaUseCase struct {
 	nanobananaService repositories.NanobananaAIService
+	history           *HistoryUseCase
+	usage             *UsageUseCase
 }
 
-func NewNanobananaUseCase(nanobananaService repositories.NanobananaAIService) *NanobananaUseCase {
+func NewNanobananaUseCase(
+	nanobananaService repositories.NanobananaAIService,
+	history *HistoryUseCase,
+	usage *UsageUseCase,
+) *NanobananaUseCase {
 	return &NanobananaUseCase{
 		nanobananaService: nanobananaService,
+		history:           history,
+		usage:             usage,
 	}
 }
 
@@ -30,13 +38,42 @@ type NanobananaOutput struct {
 }
 
 func (uc *NanobananaUseCase) ModifyImage(ctx context.Context, input NanobananaInput) (*NanobananaOutput, error) {
+	if err := uc.usage.CheckBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	request := entities.NewNanobananaModifyRequestWithMultipleImages(input.Model, input.Prompt, input.ImageDatas)
 
+	recording := uc.history.Start(ctx, entities.GeneratorNanobanana, request.Model(), input.Prompt)
+	recording.SetParameter("imageCount", len(input.ImageDatas))
+	for _, imageData := range input.ImageDatas {
+		recording.AddInput(ctx, imageData.Data(), imageData.MimeType())
+	}
+
 	result, err := uc.nanobananaService.ModifyImage(ctx, request)
 	if err != nil {
+		recording.Finish(ctx, err)
 		return nil, fmt.Errorf("failed to modify image: %w", err)
 	}
 
+	// 入力のトークン数と、出力画像の枚数で数える
+	usageItems := result.Usage().Items()
+	if result.ImageData() != nil {
+		usageItems = append(usageItems, entities.UsageItem{
+			Model:    request.Model(),
+			Unit:     entities.PriceUnitImage,
+			Quantity: 1,
+		})
+	}
+	uc.usage.Record(ctx, usageItems...)
+
+	// ドメインサービスで組み立てた最終プロンプト
+	recording.SetTranslatedPrompt(request.Prompt())
+	if result.ImageData() != nil {
+		recording.AddOutput(ctx, result.ImageData().Data(), result.ImageData().MimeType())
+	}
+	recording.Finish(ctx, nil)
+
 	return &NanobananaOutput{
 		Image:    result.ImageData(),
 		Response: result.Response(),
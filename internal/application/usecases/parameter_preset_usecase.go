@@ -0,0 +1,287 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// ErrPresetGeneratorMismatch 別の生成機能のプリセットを指定した場合に返す
+var ErrPresetGeneratorMismatch = errors.New("preset is for another generator")
+
+type ParameterPresetUseCase struct {
+	presetRepo repositories.ParameterPresetRepository
+	// 組み込みのプリセット（保存先には置かず、変更・削除できない）
+	builtIns []*entities.ParameterPreset
+}
+
+func NewParameterPresetUseCase(presetRepo repositories.ParameterPresetRepository) *ParameterPresetUseCase {
+	return &ParameterPresetUseCase{
+		presetRepo: presetRepo,
+		builtIns:   builtInParameterPresets(),
+	}
+}
+
+// builtInParameterPresets よく使う組み合わせ
+func builtInParameterPresets() []*entities.ParameterPreset {
+	var createdAt time.Time
+
+	ecommerce, _ := valueobjects.NewTryOnParameters(
+		false, 32, valueobjects.AllowAdult, valueobjects.BlockMediumAndAbove, 4, 0, "", valueobjects.MimeTypePNG, 0,
+	)
+	preview, _ := valueobjects.NewTryOnParameters(
+		true, 8, valueobjects.AllowAdult, valueobjects.BlockMediumAndAbove, 1, 0, "", valueobjects.MimeTypeJPEG, 60,
+	)
+
+	return []*entities.ParameterPreset{
+		entities.RestoreParameterPreset(
+			"ecommerce-png", "ECサイト向けPNG", "透かしなしのPNGを4枚生成する",
+			entities.GeneratorTryOn, ecommerce, nil, true, createdAt, createdAt,
+		),
+		entities.RestoreParameterPreset(
+			"fast-preview", "高速プレビュー", "ステップ数を減らしたJPEGを1枚生成する",
+			entities.GeneratorTryOn, preview, nil, true, createdAt, createdAt,
+		),
+		entities.RestoreParameterPreset(
+			"imagen-fast-preview", "Imagen高速プレビュー", "Imagen 4.0 Fastで正方形の画像を1枚生成する",
+			entities.GeneratorImagen, nil, &entities.ImagenSettings{
+				Model:          "imagen-4.0-fast-generate-001",
+				NumberOfImages: 1,
+				AspectRatio:    "1:1",
+			}, true, createdAt, createdAt,
+		),
+	}
+}
+
+// ParameterPresetInput プリセットの作成・更新内容。生成機能に対応する方のパラメータを指定する
+type ParameterPresetInput struct {
+	Name          string
+	Description   string
+	GeneratorType string
+	TryOn         *TryOnParametersInput
+	Imagen        *ImagenParametersInput
+}
+
+type ParameterPresetOutput struct {
+	ID            entities.ParameterPresetID
+	Name          string
+	Description   string
+	GeneratorType entities.GeneratorType
+	BuiltIn       bool
+	TryOn         *TryOnParametersInput
+	Imagen        *ImagenParametersInput
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// List 組み込みのプリセットに続けて、保存されたプリセットを作成順に返す
+func (uc *ParameterPresetUseCase) List(ctx context.Context, generatorType string) ([]*ParameterPresetOutput, error) {
+	filter := entities.GeneratorType(generatorType)
+	if filter != "" && !filter.IsValid() {
+		return nil, domainerrors.Newf(domainerrors.KindInvalidInput, "unsupported generator type: %s", generatorType)
+	}
+
+	presets, err := uc.presetRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]*ParameterPresetOutput, 0, len(uc.builtIns)+len(presets))
+	for _, preset := range append(slices.Clone(uc.builtIns), presets...) {
+		if filter != "" && preset.GeneratorType() != filter {
+			continue
+		}
+		outputs = append(outputs, toParameterPresetOutput(preset))
+	}
+
+	return outputs, nil
+}
+
+func (uc *ParameterPresetUseCase) Get(ctx context.Context, id entities.ParameterPresetID) (*ParameterPresetOutput, error) {
+	preset, err := uc.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toParameterPresetOutput(preset), nil
+}
+
+func (uc *ParameterPresetUseCase) Create(ctx context.Context, input ParameterPresetInput) (*ParameterPresetOutput, error) {
+	var (
+		preset *entities.ParameterPreset
+		err    error
+	)
+	switch entities.GeneratorType(input.GeneratorType) {
+	case entities.GeneratorTryOn:
+		var parameters *valueobjects.TryOnParameters
+		parameters, err = toPresetTryOnParameters(input)
+		if err == nil {
+			preset, err = entities.NewTryOnParameterPreset(input.Name, input.Description, parameters)
+		}
+	case entities.GeneratorImagen:
+		if input.Imagen == nil {
+			err = fmt.Errorf("imagen parameters are required")
+			break
+		}
+		preset, err = entities.NewImagenParameterPreset(input.Name, input.Description, toImagenSettings(input.Imagen))
+	default:
+		err = fmt.Errorf("presets are not supported for %q", input.GeneratorType)
+	}
+	if err != nil {
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid preset")
+	}
+
+	if err := uc.presetRepo.Save(ctx, preset); err != nil {
+		return nil, fmt.Errorf("failed to save preset: %w", err)
+	}
+
+	return toParameterPresetOutput(preset), nil
+}
+
+// Update 名前・説明・パラメータを置き換える。生成機能は作成時のものから変えられない
+func (uc *ParameterPresetUseCase) Update(ctx context.Context, id entities.ParameterPresetID, input ParameterPresetInput) (*ParameterPresetOutput, error) {
+	preset, err := uc.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if preset.IsBuiltIn() {
+		return nil, entities.ErrParameterPresetReadOnly
+	}
+
+	if input.GeneratorType != "" && entities.GeneratorType(input.GeneratorType) != preset.GeneratorType() {
+		return nil, domainerrors.Newf(domainerrors.KindInvalidInput, "generator type cannot be changed from %s", preset.GeneratorType())
+	}
+
+	var (
+		tryOn  *valueobjects.TryOnParameters
+		imagen *entities.ImagenSettings
+	)
+	switch preset.GeneratorType() {
+	case entities.GeneratorTryOn:
+		tryOn, err = toPresetTryOnParameters(input)
+		if err != nil {
+			return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid preset")
+		}
+	case entities.GeneratorImagen:
+		if input.Imagen != nil {
+			settings := toImagenSettings(input.Imagen)
+			imagen = &settings
+		}
+	}
+
+	if err := preset.Update(input.Name, input.Description, tryOn, imagen); err != nil {
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid preset")
+	}
+
+	if err := uc.presetRepo.Save(ctx, preset); err != nil {
+		return nil, fmt.Errorf("failed to save preset: %w", err)
+	}
+
+	return toParameterPresetOutput(preset), nil
+}
+
+func (uc *ParameterPresetUseCase) Delete(ctx context.Context, id entities.ParameterPresetID) error {
+	if uc.findBuiltIn(id) != nil {
+		return entities.ErrParameterPresetReadOnly
+	}
+	return uc.presetRepo.Delete(ctx, id)
+}
+
+// TryOnParameters - 試着のプリセットのパラメータ。リクエストの指定はこの上に重ねる
+func (uc *ParameterPresetUseCase) TryOnParameters(ctx context.Context, id entities.ParameterPresetID) (*TryOnParametersInput, error) {
+	preset, err := uc.findFor(ctx, id, entities.GeneratorTryOn)
+	if err != nil {
+		return nil, err
+	}
+	return fromTryOnParameters(preset.TryOnParameters()), nil
+}
+
+// ImagenParameters - Imagenのプリセットの設定。リクエストの指定はこの上に重ねる
+func (uc *ParameterPresetUseCase) ImagenParameters(ctx context.Context, id entities.ParameterPresetID) (*ImagenParametersInput, error) {
+	preset, err := uc.findFor(ctx, id, entities.GeneratorImagen)
+	if err != nil {
+		return nil, err
+	}
+	return fromImagenSettings(preset.ImagenSettings()), nil
+}
+
+func (uc *ParameterPresetUseCase) findFor(ctx context.Context, id entities.ParameterPresetID, generatorType entities.GeneratorType) (*entities.ParameterPreset, error) {
+	preset, err := uc.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if preset.GeneratorType() != generatorType {
+		return nil, fmt.Errorf("%w: %s is a %s preset", ErrPresetGeneratorMismatch, id, preset.GeneratorType())
+	}
+	return preset, nil
+}
+
+func (uc *ParameterPresetUseCase) find(ctx context.Context, id entities.ParameterPresetID) (*entities.ParameterPreset, error) {
+	if preset := uc.findBuiltIn(id); preset != nil {
+		return preset, nil
+	}
+	return uc.presetRepo.FindByID(ctx, id)
+}
+
+func (uc *ParameterPresetUseCase) findBuiltIn(id entities.ParameterPresetID) *entities.ParameterPreset {
+	for _, preset := range uc.builtIns {
+		if preset.ID() == id {
+			return preset
+		}
+	}
+	return nil
+}
+
+func toPresetTryOnParameters(input ParameterPresetInput) (*valueobjects.TryOnParameters, error) {
+	if input.TryOn == nil {
+		return nil, fmt.Errorf("try-on parameters are required")
+	}
+	return toTryOnParameters(input.TryOn)
+}
+
+func toImagenSettings(input *ImagenParametersInput) entities.ImagenSettings {
+	return entities.ImagenSettings{
+		Model:            input.ImagenModel,
+		NumberOfImages:   input.NumberOfImages,
+		AspectRatio:      input.AspectRatio,
+		NegativePrompt:   input.NegativePrompt,
+		Seed:             input.Seed,
+		IncludeRaiReason: input.IncludeRaiReason,
+	}
+}
+
+func fromImagenSettings(settings *entities.ImagenSettings) *ImagenParametersInput {
+	return &ImagenParametersInput{
+		ImagenModel:      settings.Model,
+		NumberOfImages:   settings.NumberOfImages,
+		AspectRatio:      settings.AspectRatio,
+		NegativePrompt:   settings.NegativePrompt,
+		Seed:             settings.Seed,
+		IncludeRaiReason: settings.IncludeRaiReason,
+	}
+}
+
+func toParameterPresetOutput(preset *entities.ParameterPreset) *ParameterPresetOutput {
+	output := &ParameterPresetOutput{
+		ID:            preset.ID(),
+		Name:          preset.Name(),
+		Description:   preset.Description(),
+		GeneratorType: preset.GeneratorType(),
+		BuiltIn:       preset.IsBuiltIn(),
+		CreatedAt:     preset.CreatedAt(),
+		UpdatedAt:     preset.UpdatedAt(),
+	}
+	if parameters := preset.TryOnParameters(); parameters != nil {
+		output.TryOn = fromTryOnParameters(parameters)
+	}
+	if settings := preset.ImagenSettings(); settings != nil {
+		output.Imagen = fromImagenSettings(settings)
+	}
+	return output
+}
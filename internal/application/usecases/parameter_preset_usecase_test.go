@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestParameterPresetUseCase_CreateAndLookup(t *testing.T) {
+	ctx := context.Background()
+	uc := NewParameterPresetUseCase(repositories.NewMemoryParameterPresetRepository())
+
+	parameters := DefaultTryOnParametersInput()
+	parameters.SampleCount = 2
+	parameters.OutputMimeType = "image/jpeg"
+	parameters.CompressionQuality = 80
+
+	created, err := uc.Create(ctx, ParameterPresetInput{
+		Name:          " 商品ページ用 ",
+		GeneratorType: string(entities.GeneratorTryOn),
+		TryOn:         parameters,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.Name != "商品ページ用" || created.BuiltIn {
+		t.Errorf("Create() = %+v, want trimmed name and not built-in", created)
+	}
+
+	got, err := uc.TryOnParameters(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("TryOnParameters() error = %v", err)
+	}
+	if *got != *parameters {
+		t.Errorf("TryOnParameters() = %+v, want %+v", got, parameters)
+	}
+
+	// 別の生成機能からは使えない
+	if _, err := uc.ImagenParameters(ctx, created.ID); !errors.Is(err, ErrPresetGeneratorMismatch) {
+		t.Errorf("ImagenParameters() error = %v, want ErrPresetGeneratorMismatch", err)
+	}
+	if _, err := uc.TryOnParameters(ctx, "missing"); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("TryOnParameters(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParameterPresetUseCase_CreateRejectsInvalidParameters(t *testing.T) {
+	uc := NewParameterPresetUseCase(repositories.NewMemoryParameterPresetRepository())
+
+	invalidTryOn := DefaultTryOnParametersInput()
+	invalidTryOn.SampleCount = 5
+
+	invalidImagen := DefaultImagenParametersInput()
+	invalidImagen.AspectRatio = "2:1"
+
+	tests := []struct {
+		name  string
+		input ParameterPresetInput
+	}{
+		{"empty name", ParameterPresetInput{GeneratorType: "tryon", TryOn: DefaultTryOnParametersInput()}},
+		{"invalid try-on parameters", ParameterPresetInput{Name: "a", GeneratorType: "tryon", TryOn: invalidTryOn}},
+		{"invalid imagen aspect ratio", ParameterPresetInput{Name: "a", GeneratorType: "imagen", Imagen: invalidImagen}},
+		{"missing parameters", ParameterPresetInput{Name: "a", GeneratorType: "imagen"}},
+		{"unsupported generator", ParameterPresetInput{Name: "a", GeneratorType: "veo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Create(context.Background(), tt.input)
+			if kind := domainerrors.KindOf(err); kind != domainerrors.KindInvalidInput {
+				t.Errorf("KindOf(err) = %s, want %s (err = %v)", kind, domainerrors.KindInvalidInput, err)
+			}
+		})
+	}
+}
+
+func TestParameterPresetUseCase_BuiltInIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	uc := NewParameterPresetUseCase(repositories.NewMemoryParameterPresetRepository())
+
+	_, err := uc.Update(ctx, "fast-preview", ParameterPresetInput{Name: "a", TryOn: DefaultTryOnParametersInput()})
+	if !errors.Is(err, entities.ErrParameterPresetReadOnly) {
+		t.Errorf("Update() error = %v, want ErrParameterPresetReadOnly", err)
+	}
+	if err := uc.Delete(ctx, "fast-preview"); !errors.Is(err, entities.ErrParameterPresetReadOnly) {
+		t.Errorf("Delete() error = %v, want ErrParameterPresetReadOnly", err)
+	}
+
+	settings, err := uc.ImagenParameters(ctx, "imagen-fast-preview")
+	if err != nil {
+		t.Fatalf("ImagenParameters() error = %v", err)
+	}
+	if settings.ImagenModel != "imagen-4.0-fast-generate-001" || settings.NumberOfImages != 1 {
+		t.Errorf("ImagenParameters() = %+v", settings)
+	}
+}
+
+func TestParameterPresetUseCase_ListAndDelete(t *testing.T) {
+	ctx := context.Background()
+	uc := NewParameterPresetUseCase(repositories.NewMemoryParameterPresetRepository())
+
+	created, err := uc.Create(ctx, ParameterPresetInput{
+		Name:          "横長",
+		GeneratorType: string(entities.GeneratorImagen),
+		Imagen:        &ImagenParametersInput{NumberOfImages: 2, AspectRatio: "16:9"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	presets, err := uc.List(ctx, "imagen")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var ids []entities.ParameterPresetID
+	for _, preset := range presets {
+		ids = append(ids, preset.ID)
+	}
+	if len(ids) != 2 || ids[0] != "imagen-fast-preview" || ids[1] != created.ID {
+		t.Errorf("List(imagen) ids = %v, want [imagen-fast-preview %s]", ids, created.ID)
+	}
+
+	if _, err := uc.List(ctx, "unknown"); domainerrors.KindOf(err) != domainerrors.KindInvalidInput {
+		t.Errorf("List(unknown) error = %v, want invalid input", err)
+	}
+
+	if err := uc.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := uc.Get(ctx, created.ID); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+}
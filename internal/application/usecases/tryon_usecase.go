@@ -3,8 +3,11 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
 	"tryon-demo/internal/domain/services"
@@ -14,27 +17,59 @@ import (
 type TryOnUseCase struct {
 	tryOnRepo     repositories.TryOnRepository
 	domainService *services.TryOnDomainService
+	// 試着前に入力画像を検証する（nilの場合は検証しない）
+	preflight *services.TryOnPreflightService
+	// 衣服の種類を自動判定する（nilの場合は判定しない）
+	classifier *services.GarmentClassificationService
+	// Storage URI指定時に生成画像を読み出す（nilの場合はStorage URIを受け付けない）
+	objectStorage repositories.ObjectStorage
+	history       *HistoryUseCase
+	usage         *UsageUseCase
+	// バーチャル試着のモデル名（履歴・利用量の記録に使う）
+	model string
 }
 
 func NewTryOnUseCase(
 	tryOnRepo repositories.TryOnRepository,
 	domainService *services.TryOnDomainService,
+	preflight *services.TryOnPreflightService,
+	classifier *services.GarmentClassificationService,
+	objectStorage repositories.ObjectStorage,
+	history *HistoryUseCase,
+	usage *UsageUseCase,
+	model string,
 ) *TryOnUseCase {
 	return &TryOnUseCase{
 		tryOnRepo:     tryOnRepo,
 		domainService: domainService,
+		preflight:     preflight,
+		classifier:    classifier,
+		objectStorage: objectStorage,
+		history:       history,
+		usage:         usage,
+		model:         model,
 	}
 }
 
 type GarmentImageData struct {
 	Data     []byte
-	MimeType string
+	Category string // tops / bottoms / shoes / accessories（省略時は自動判定）
 }
 
+// 複数の衣服の扱い方
+type TryOnMode string
+
+const (
+	// 衣服ごとに同じ人物へ別々に試着する
+	TryOnModeSeparate TryOnMode = "separate"
+	// 種類の順に重ねて着せ、1枚のコーデにする
+	TryOnModeOutfit TryOnMode = "outfit"
+)
+
 type TryOnInput struct {
 	PersonImageData  []byte
-	PersonMimeType   string
 	GarmentImageData []GarmentImageData
+	Mode             TryOnMode // 省略時は TryOnModeSeparate
 	Parameters       *TryOnParametersInput
 }
 
@@ -45,109 +80,432 @@ type TryOnParametersInput struct {
 	SafetySetting      string
 	SampleCount        int
 	Seed               int
+	StorageURI         string // 指定した場合、生成画像は保存先に書き込まれ、URIのみを返す
 	OutputMimeType     string
 	CompressionQuality int
 }
 
 type TryOnOutput struct {
-	RequestID entities.TryOnRequestID
-	Images    []ImageOutput
+	Mode      TryOnMode
+	RequestID entities.TryOnRequestID // 最初に成功した衣服のリクエストID
+	// separate: 成功した全衣服の画像（入力順）
+	// outfit: 最後に成功した衣服を着せた完成画像
+	Images []ImageOutput
+	// 衣服ごとの結果（処理順）。outfit では各段階の途中画像を含む
+	Garments []GarmentOutput
+}
+
+// Succeeded - 成功した衣服の数
+func (o *TryOnOutput) Succeeded() int {
+	succeeded := 0
+	for _, garment := range o.Garments {
+		if garment.Status == GarmentStatusSucceeded {
+			succeeded++
+		}
+	}
+	return succeeded
 }
 
+// 衣服ごとの処理結果
+type GarmentStatus string
+
+const (
+	GarmentStatusSucceeded GarmentStatus = "succeeded"
+	GarmentStatusFailed    GarmentStatus = "failed"
+)
+
+// GarmentOutput 衣服1着分の試着結果
+type GarmentOutput struct {
+	Index    int // 入力された衣服画像の順番（0始まり）
+	Category valueobjects.GarmentCategory
+	// 自動判定の結果（種類が指定された場合や判定しなかった場合はnil）
+	Classification *valueobjects.GarmentClassification
+	Warnings       []GarmentWarning
+	RequestID      entities.TryOnRequestID
+	Status         GarmentStatus
+	Err            error
+	Images         []ImageOutput
+}
+
+// 衣服の種類の判定に関する警告（試着は続ける）
+type GarmentWarning string
+
+const (
+	// 判定の呼び出しに失敗した
+	GarmentWarningClassificationFailed GarmentWarning = "classification_failed"
+	// 確信度が低く、種類を決められなかった
+	GarmentWarningLowConfidence GarmentWarning = "low_confidence"
+	// 衣服だが試着が想定されていない品目（帽子、バッグなど）
+	GarmentWarningUnsupportedItem GarmentWarning = "unsupported_item"
+	// 衣服ではない可能性がある
+	GarmentWarningNotGarment GarmentWarning = "not_garment"
+)
+
+// ImageOutput 生成画像。Storage URI指定時は Data の代わりに URI を持つ
 type ImageOutput struct {
 	Data []byte
+	URI  string
 	Type string
 }
 
-func (uc *TryOnUseCase) Execute(ctx context.Context, input TryOnInput) (*TryOnOutput, error) {
-	personImage, err := valueobjects.NewImageData(input.PersonImageData, input.PersonMimeType)
+func (uc *TryOnUseCase) Execute(ctx context.Context, input TryOnInput) (output *TryOnOutput, err error) {
+	if err := uc.usage.CheckBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	recording := uc.history.Start(ctx, entities.GeneratorTryOn, uc.model, "")
+	defer func() {
+		if output != nil {
+			// outfit の途中画像も1回分の生成として数える
+			var images int
+			for _, garment := range output.Garments {
+				images += len(garment.Images)
+			}
+			uc.usage.Record(ctx, entities.UsageItem{
+				Model:    uc.model,
+				Unit:     entities.PriceUnitImage,
+				Quantity: float64(images),
+			})
+
+			for _, img := range output.Images {
+				data := img.Data
+				if len(data) == 0 {
+					// 保存先に書き込まれた画像は読み出して履歴に残す（失敗しても結果は返す）
+					fetched, err := uc.objectStorage.Get(ctx, img.URI)
+					if err != nil {
+						slog.Warn("Failed to fetch output for history", "uri", img.URI, "error", err)
+						continue
+					}
+					data = fetched
+				}
+				recording.AddOutput(ctx, data, img.Type)
+			}
+		}
+		recording.Finish(ctx, err)
+	}()
+
+	personImage, err := valueobjects.NewImageData(input.PersonImageData)
 	if err != nil {
-		return nil, fmt.Errorf("invalid person image: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid person image")
+	}
+
+	if len(input.GarmentImageData) == 0 {
+		return nil, domainerrors.New(domainerrors.KindInvalidInput, "garment image is required")
+	}
+
+	mode := input.Mode
+	if mode == "" {
+		mode = TryOnModeSeparate
+	}
+	if mode != TryOnModeSeparate && mode != TryOnModeOutfit {
+		return nil, domainerrors.Newf(domainerrors.KindInvalidInput, "unsupported try-on mode: %s", mode)
 	}
 
-	var garmentImageDatas []*valueobjects.ImageData
-	for _, garmentImageData := range input.GarmentImageData {
-		garmentImage, err := valueobjects.NewImageData(garmentImageData.Data, garmentImageData.MimeType)
+	garmentInputs := make([]garmentInput, 0, len(input.GarmentImageData))
+	for i, garmentImageData := range input.GarmentImageData {
+		garmentImage, err := valueobjects.NewImageData(garmentImageData.Data)
 		if err != nil {
-			return nil, fmt.Errorf("invalid garment image: %w", err)
+			return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid garment image")
 		}
-		garmentImageDatas = append(garmentImageDatas, garmentImage)
+		garmentInputs = append(garmentInputs, garmentInput{
+			index:    i,
+			image:    garmentImage,
+			category: valueobjects.ParseGarmentCategory(garmentImageData.Category),
+		})
 	}
 
 	parameters, err := uc.convertParameters(input.Parameters)
 	if err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "invalid parameters")
 	}
 
-	var wg sync.WaitGroup
+	if storageURI := parameters.StorageURI(); storageURI != "" {
+		if uc.objectStorage == nil || !uc.objectStorage.Supports(storageURI) {
+			return nil, domainerrors.Newf(domainerrors.KindInvalidInput, "unsupported storageURI: %s", storageURI)
+		}
+	}
+
+	// 有料の試着APIを呼ぶ前に、解像度・縦横比・人物の有無を確認する
+	if uc.preflight != nil {
+		garmentImages := make([]*valueobjects.ImageData, 0, len(garmentInputs))
+		for _, garment := range garmentInputs {
+			garmentImages = append(garmentImages, garment.image)
+		}
+		if err := uc.preflight.ValidateImages(personImage, garmentImages); err != nil {
+			return nil, err
+		}
+
+		// 確認の呼び出し自体が失敗した場合は試着を妨げない
+		if err := uc.preflight.ValidatePerson(ctx, personImage); err != nil {
+			if domainerrors.FieldErrorsOf(err) != nil {
+				return nil, err
+			}
+			slog.Warn("Person detection failed", "error", err)
+		}
+	}
+
+	// 衣服ではない画像は試着を始める前に拒否する
+	uc.classifyGarments(ctx, garmentInputs)
+	var rejected []domainerrors.FieldError
+	for _, garment := range garmentInputs {
+		if garment.classification != nil && uc.classifier.IsRejected(garment.classification) {
+			rejected = append(rejected, domainerrors.FieldError{
+				Field:   services.PreflightGarmentField(garment.index),
+				Code:    services.PreflightCodeNotGarment,
+				Message: fmt.Sprintf("image does not appear to be a garment (%s)", garment.classification.Label()),
+			})
+		}
+	}
+	if err := domainerrors.NewValidation(rejected); err != nil {
+		return nil, err
+	}
+
+	recording.AddInput(ctx, personImage.Data(), personImage.MimeType())
+	for _, garment := range garmentInputs {
+		recording.AddInput(ctx, garment.image.Data(), garment.image.MimeType())
+	}
+	recording.SetParameter("mode", mode)
+	recording.SetParameter("addWatermark", parameters.AddWatermark())
+	recording.SetParameter("baseSteps", parameters.BaseSteps())
+	recording.SetParameter("personGeneration", parameters.PersonGeneration())
+	recording.SetParameter("safetySetting", parameters.SafetySetting())
+	recording.SetParameter("sampleCount", parameters.SampleCount())
+	recording.SetParameter("seed", parameters.Seed())
+	if parameters.StorageURI() != "" {
+		recording.SetParameter("storageURI", parameters.StorageURI())
+	}
+	recording.SetParameter("outputMimeType", parameters.OutputMimeType())
+	recording.SetParameter("compressionQuality", parameters.CompressionQuality())
+
+	output = &TryOnOutput{
+		Mode: mode,
+	}
+	if mode == TryOnModeOutfit {
+		output.Garments = uc.composeOutfit(ctx, personImage, garmentInputs, parameters)
+	} else {
+		output.Garments = uc.tryOnSeparately(ctx, personImage, garmentInputs, parameters)
+	}
+
+	var firstErr error
+	for _, garment := range output.Garments {
+		if garment.Err != nil {
+			if firstErr == nil {
+				firstErr = garment.Err
+			}
+			continue
+		}
+
+		if output.RequestID == "" {
+			output.RequestID = garment.RequestID
+		}
+		if mode == TryOnModeOutfit {
+			// 重ね着では後の段階ほど完成に近いため、最後に成功した段階の画像を残す
+			output.Images = garment.Images
+		} else {
+			output.Images = append(output.Images, garment.Images...)
+		}
+	}
+
+	// 1着も成功しなかった場合のみエラーとし、一部の失敗は衣服ごとの結果で返す
+	if output.Succeeded() == 0 {
+		return nil, firstErr
+	}
+
+	if failed := len(output.Garments) - output.Succeeded(); failed > 0 {
+		recording.SetParameter("failedGarments", failed)
+	}
 
-	// 結果を保存するチャネル
-	resultCh := make(chan *entities.TryOnResult)
-	errCh := make(chan error)
+	return output, nil
+}
+
+// garmentInput 試着する衣服1着分の入力
+type garmentInput struct {
+	index          int
+	image          *valueobjects.ImageData
+	category       valueobjects.GarmentCategory
+	classification *valueobjects.GarmentClassification
+	warnings       []GarmentWarning
+}
+
+// classifyGarments - 種類が指定されていない衣服を並行して自動判定する。
+// 判定できなかった場合は警告を付けて種類不明のまま続ける。
+func (uc *TryOnUseCase) classifyGarments(ctx context.Context, garmentInputs []garmentInput) {
+	if uc.classifier == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range garmentInputs {
+		if garmentInputs[i].category.IsKnown() {
+			continue
+		}
 
-	for _, garmentImage := range garmentImageDatas {
 		wg.Add(1)
-		go func(garmentImage *valueobjects.ImageData) {
+		go func(garment *garmentInput) {
 			defer wg.Done()
-			request, err := entities.NewTryOnRequest(personImage, garmentImage, parameters)
+
+			classification, err := uc.classifier.Classify(ctx, garment.image)
 			if err != nil {
-				errCh <- fmt.Errorf("failed to create request: %w", err)
+				slog.Warn("Garment classification failed", "index", garment.index, "error", err)
+				garment.warnings = append(garment.warnings, GarmentWarningClassificationFailed)
 				return
 			}
 
-			if err := uc.tryOnRepo.Save(ctx, request); err != nil {
-				errCh <- fmt.Errorf("failed to save request: %w", err)
-				return
+			garment.classification = classification
+			garment.category = classification.Category()
+			switch {
+			case !classification.IsGarment():
+				garment.warnings = append(garment.warnings, GarmentWarningNotGarment)
+			case classification.Confidence() < services.MinGarmentCategoryConfidence:
+				garment.warnings = append(garment.warnings, GarmentWarningLowConfidence)
+			case !classification.IsSupported():
+				garment.warnings = append(garment.warnings, GarmentWarningUnsupportedItem)
 			}
+		}(&garmentInputs[i])
+	}
+	wg.Wait()
+}
+
+// tryOnSeparately - 衣服ごとに元の人物画像へ並行して試着する
+func (uc *TryOnUseCase) tryOnSeparately(
+	ctx context.Context,
+	personImage *valueobjects.ImageData,
+	garmentInputs []garmentInput,
+	parameters *valueobjects.TryOnParameters,
+) []GarmentOutput {
+	garments := make([]GarmentOutput, len(garmentInputs))
+
+	// 衣服ごとの結果はインデックスで分けて書き込むため、チャネルやロックは不要
+	var wg sync.WaitGroup
+	for i, garment := range garmentInputs {
+		wg.Add(1)
+		go func(i int, garment garmentInput) {
+			defer wg.Done()
+			garments[i] = uc.processGarment(ctx, garment, personImage, parameters)
+		}(i, garment)
+	}
+	wg.Wait()
+
+	return garments
+}
 
-			result, err := uc.domainService.ProcessTryOn(ctx, request)
+// composeOutfit - 衣服を種類の順（トップス→ボトムス→シューズ→アクセサリー）に並べ、
+// 前の段階の生成画像を次の段階の人物画像として順番に着せる。
+// 失敗した衣服は飛ばし、直前に成功した画像から続ける。
+func (uc *TryOnUseCase) composeOutfit(
+	ctx context.Context,
+	personImage *valueobjects.ImageData,
+	garmentInputs []garmentInput,
+	parameters *valueobjects.TryOnParameters,
+) []GarmentOutput {
+	ordered := slices.Clone(garmentInputs)
+	slices.SortStableFunc(ordered, func(a, b garmentInput) int {
+		return a.category.Order() - b.category.Order()
+	})
+
+	garments := make([]GarmentOutput, 0, len(ordered))
+	current := personImage
+	for _, garment := range ordered {
+		result := uc.processGarment(ctx, garment, current, parameters)
+		if result.Err == nil {
+			// 複数枚生成した場合は1枚目を次の段階に使う
+			next, err := uc.loadImage(ctx, result.Images[0])
 			if err != nil {
-				errCh <- err
-				return
+				result.Status = GarmentStatusFailed
+				result.Err = fmt.Errorf("failed to use generated image as next person image: %w", err)
+			} else {
+				current = next
 			}
-			resultCh <- result
-		}(garmentImage)
+		}
+		garments = append(garments, result)
 	}
 
-	go func() {
-		wg.Wait()
-		close(resultCh)
-		close(errCh)
-	}()
+	return garments
+}
 
-	var output *TryOnOutput
+// processGarment - 衣服1着分の試着を行う。失敗してもエラーは結果に含めて返す
+func (uc *TryOnUseCase) processGarment(
+	ctx context.Context,
+	input garmentInput,
+	personImage *valueobjects.ImageData,
+	parameters *valueobjects.TryOnParameters,
+) GarmentOutput {
+	garment := GarmentOutput{
+		Index:          input.index,
+		Category:       input.category,
+		Classification: input.classification,
+		Warnings:       input.warnings,
+		Status:         GarmentStatusFailed,
+	}
 
-	for result := range resultCh {
-		if err := uc.tryOnRepo.SaveResult(ctx, result); err != nil {
-			return nil, fmt.Errorf("failed to save result: %w", err)
-		}
+	request, err := entities.NewTryOnRequest(personImage, input.image, parameters)
+	if err != nil {
+		garment.Err = domainerrors.Wrap(domainerrors.KindInvalidInput, err, "failed to create request")
+		return garment
+	}
+	garment.RequestID = request.ID()
 
-		if output == nil {
-			output = &TryOnOutput{
-				RequestID: result.RequestID(),
-			}
-		}
+	if err := uc.tryOnRepo.Save(ctx, request); err != nil {
+		garment.Err = fmt.Errorf("failed to save request: %w", err)
+		return garment
+	}
 
-		for _, img := range result.Images() {
-			output.Images = append(output.Images, ImageOutput{
-				Data: img.Data(),
-				Type: string(parameters.OutputMimeType()),
-			})
-		}
+	result, err := uc.domainService.ProcessTryOn(ctx, request)
+	if err != nil {
+		garment.Err = err
+		return garment
 	}
 
-	for err := range errCh {
-		return nil, err
+	if err := uc.tryOnRepo.SaveResult(ctx, result); err != nil {
+		garment.Err = fmt.Errorf("failed to save result: %w", err)
+		return garment
 	}
 
-	return output, nil
+	// 指定した形式と違う画像が返ることもあるため、受け取ったデータから判定した形式を返す
+	for _, img := range result.Images() {
+		garment.Images = append(garment.Images, ImageOutput{
+			Data: img.Data(),
+			Type: img.MimeType(),
+		})
+	}
+	// 保存先に書き込まれた画像はデータを読まないため、指定した形式を返す
+	for _, uri := range result.OutputURIs() {
+		garment.Images = append(garment.Images, ImageOutput{
+			URI:  uri,
+			Type: string(parameters.OutputMimeType()),
+		})
+	}
+	garment.Status = GarmentStatusSucceeded
+
+	return garment
+}
+
+// loadImage - 生成画像を次の入力に使えるようにする。保存先に書き込まれた画像は読み出す
+func (uc *TryOnUseCase) loadImage(ctx context.Context, img ImageOutput) (*valueobjects.ImageData, error) {
+	if len(img.Data) > 0 {
+		return valueobjects.NewImageData(img.Data)
+	}
+
+	data, err := uc.objectStorage.Get(ctx, img.URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", img.URI, err)
+	}
+	return valueobjects.NewImageData(data)
 }
 
 func (uc *TryOnUseCase) convertParameters(input *TryOnParametersInput) (*valueobjects.TryOnParameters, error) {
 	if input == nil {
 		return valueobjects.DefaultTryOnParameters(), nil
 	}
+	return toTryOnParameters(input)
+}
+
+// DefaultTryOnParametersInput - リクエストで指定されなかった項目に使う既定値
+func DefaultTryOnParametersInput() *TryOnParametersInput {
+	return fromTryOnParameters(valueobjects.DefaultTryOnParameters())
+}
 
+func toTryOnParameters(input *TryOnParametersInput) (*valueobjects.TryOnParameters, error) {
 	personGen := valueobjects.PersonGeneration(input.PersonGeneration)
 	safetySetting := valueobjects.SafetySetting(input.SafetySetting)
 	mimeType := valueobjects.MimeType(input.OutputMimeType)
@@ -159,8 +517,22 @@ func (uc *TryOnUseCase) convertParameters(input *TryOnParametersInput) (*valueob
 		safetySetting,
 		input.SampleCount,
 		input.Seed,
-		"", // Storage URI is removed
+		input.StorageURI,
 		mimeType,
 		input.CompressionQuality,
 	)
 }
+
+func fromTryOnParameters(parameters *valueobjects.TryOnParameters) *TryOnParametersInput {
+	return &TryOnParametersInput{
+		AddWatermark:       parameters.AddWatermark(),
+		BaseSteps:          parameters.BaseSteps(),
+		PersonGeneration:   string(parameters.PersonGeneration()),
+		SafetySetting:      string(parameters.SafetySetting()),
+		SampleCount:        parameters.SampleCount(),
+		Seed:               parameters.Seed(),
+		StorageURI:         parameters.StorageURI(),
+		OutputMimeType:     string(parameters.OutputMimeType()),
+		CompressionQuality: parameters.CompressionQuality(),
+	}
+}
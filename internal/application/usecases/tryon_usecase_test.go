@@ -0,0 +1,465 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+// garmentAIService 衣服画像の幅ごとに成否を切り替えるテスト用サービス。
+// 成功時は衣服画像をそのまま生成結果として返す。
+type garmentAIService struct {
+	failWidths map[int]error
+
+	mu    sync.Mutex
+	calls [][2]int // 呼び出しごとの {人物画像の幅, 衣服画像の幅}
+}
+
+func (s *garmentAIService) GenerateTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
+	person, _, err := image.DecodeConfig(bytes.NewReader(request.PersonImage().Data()))
+	if err != nil {
+		return nil, err
+	}
+	garment, _, err := image.DecodeConfig(bytes.NewReader(request.GarmentImage().Data()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, [2]int{person.Width, garment.Width})
+	s.mu.Unlock()
+
+	if err := s.failWidths[garment.Width]; err != nil {
+		return nil, err
+	}
+	return entities.NewTryOnResult(request.ID(), []*valueobjects.ImageData{request.GarmentImage()}), nil
+}
+
+func (s *garmentAIService) Close() error {
+	return nil
+}
+
+func encodeTestJPEG(t *testing.T, width int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, 1)), nil); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// executeTryOn 幅 i+1 の衣服画像を categories の数だけ用意して試着する
+func executeTryOn(t *testing.T, aiService *garmentAIService, mode TryOnMode, categories ...string) (*TryOnOutput, error) {
+	t.Helper()
+	return executeTryOnWithClassifier(t, aiService, nil, mode, categories...)
+}
+
+func executeTryOnWithClassifier(
+	t *testing.T,
+	aiService *garmentAIService,
+	classifier *services.GarmentClassificationService,
+	mode TryOnMode,
+	categories ...string,
+) (*TryOnOutput, error) {
+	t.Helper()
+
+	input := TryOnInput{
+		PersonImageData: encodeTestJPEG(t, 100),
+		Mode:            mode,
+	}
+	for i, category := range categories {
+		input.GarmentImageData = append(input.GarmentImageData, GarmentImageData{
+			Data:     encodeTestJPEG(t, i+1),
+			Category: category,
+		})
+	}
+
+	uc := NewTryOnUseCase(
+		repositories.NewMemoryTryOnRepository(),
+		services.NewTryOnDomainService(aiService, valueobjects.DefaultNormalizeOptions()),
+		nil,
+		classifier,
+		nil,
+		nil,
+		nil,
+		"",
+	)
+
+	type executeResult struct {
+		output *TryOnOutput
+		err    error
+	}
+	done := make(chan executeResult, 1)
+	go func() {
+		output, err := uc.Execute(context.Background(), input)
+		done <- executeResult{output, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.output, res.err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execute() did not return")
+		return nil, nil
+	}
+}
+
+func TestTryOnUseCase_Execute_PartialSuccess(t *testing.T) {
+	aiService := &garmentAIService{
+		failWidths: map[int]error{
+			2: domainerrors.New(domainerrors.KindSafetyBlocked, "blocked"),
+			4: errors.New("upstream failed"),
+		},
+	}
+
+	output, err := executeTryOn(t, aiService, TryOnModeSeparate, "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(output.Garments) != 5 {
+		t.Fatalf("len(Garments) = %d, want 5", len(output.Garments))
+	}
+	if output.Succeeded() != 3 {
+		t.Errorf("Succeeded() = %d, want 3", output.Succeeded())
+	}
+	if len(output.Images) != 3 {
+		t.Errorf("len(Images) = %d, want 3", len(output.Images))
+	}
+
+	for i, garment := range output.Garments {
+		if garment.Index != i {
+			t.Errorf("Garments[%d].Index = %d", i, garment.Index)
+		}
+
+		wantFailed := i == 1 || i == 3
+		if wantFailed {
+			if garment.Status != GarmentStatusFailed || garment.Err == nil {
+				t.Errorf("Garments[%d] = %s (%v), want failed", i, garment.Status, garment.Err)
+			}
+			if len(garment.Images) != 0 {
+				t.Errorf("Garments[%d] has %d images, want 0", i, len(garment.Images))
+			}
+			continue
+		}
+		if garment.Status != GarmentStatusSucceeded || garment.Err != nil {
+			t.Errorf("Garments[%d] = %s (%v), want succeeded", i, garment.Status, garment.Err)
+		}
+		if len(garment.Images) != 1 {
+			t.Errorf("Garments[%d] has %d images, want 1", i, len(garment.Images))
+		}
+	}
+
+	if kind := domainerrors.KindOf(output.Garments[1].Err); kind != domainerrors.KindSafetyBlocked {
+		t.Errorf("KindOf(Garments[1].Err) = %s, want %s", kind, domainerrors.KindSafetyBlocked)
+	}
+}
+
+func TestTryOnUseCase_Execute_ImageTypeFromData(t *testing.T) {
+	// 既定の出力形式はPNGだが、テスト用サービスはJPEGを返す
+	output, err := executeTryOn(t, &garmentAIService{}, TryOnModeSeparate, "")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	images := output.Garments[0].Images
+	if len(images) != 1 {
+		t.Fatalf("Garments[0] has %d images, want 1", len(images))
+	}
+	if images[0].Type != "image/jpeg" {
+		t.Errorf("Garments[0].Images[0].Type = %s, want image/jpeg", images[0].Type)
+	}
+}
+
+func TestTryOnUseCase_Execute_AllFailed(t *testing.T) {
+	aiService := &garmentAIService{
+		failWidths: map[int]error{
+			1: domainerrors.New(domainerrors.KindQuotaExhausted, "quota"),
+			2: domainerrors.New(domainerrors.KindQuotaExhausted, "quota"),
+			3: domainerrors.New(domainerrors.KindQuotaExhausted, "quota"),
+		},
+	}
+
+	output, err := executeTryOn(t, aiService, TryOnModeSeparate, "", "", "")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if output != nil {
+		t.Errorf("Execute() output = %v, want nil", output)
+	}
+	if kind := domainerrors.KindOf(err); kind != domainerrors.KindQuotaExhausted {
+		t.Errorf("KindOf(err) = %s, want %s", kind, domainerrors.KindQuotaExhausted)
+	}
+}
+
+func TestTryOnUseCase_Execute_Outfit(t *testing.T) {
+	aiService := &garmentAIService{}
+
+	// 幅 1:shoes, 2:tops, 3:不明, 4:pants
+	output, err := executeTryOn(t, aiService, TryOnModeOutfit, "shoes", "tops", "", "pants")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// トップス→ボトムス→シューズ→不明 の順に、前の結果を人物画像として着せる
+	wantCalls := [][2]int{{100, 2}, {2, 4}, {4, 1}, {1, 3}}
+	if !slices.Equal(aiService.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", aiService.calls, wantCalls)
+	}
+
+	wantIndexes := []int{1, 3, 0, 2}
+	for i, garment := range output.Garments {
+		if garment.Index != wantIndexes[i] {
+			t.Errorf("Garments[%d].Index = %d, want %d", i, garment.Index, wantIndexes[i])
+		}
+		if len(garment.Images) != 1 {
+			t.Errorf("Garments[%d] has %d intermediate images, want 1", i, len(garment.Images))
+		}
+	}
+	if output.Garments[1].Category != valueobjects.GarmentCategoryBottoms {
+		t.Errorf("Garments[1].Category = %q, want %q", output.Garments[1].Category, valueobjects.GarmentCategoryBottoms)
+	}
+
+	// 完成画像は最後の段階の画像
+	if len(output.Images) != 1 || !bytes.Equal(output.Images[0].Data, output.Garments[3].Images[0].Data) {
+		t.Errorf("Images should be the images of the last step")
+	}
+}
+
+func TestTryOnUseCase_Execute_OutfitSkipsFailedGarment(t *testing.T) {
+	aiService := &garmentAIService{
+		failWidths: map[int]error{
+			2: errors.New("upstream failed"),
+		},
+	}
+
+	output, err := executeTryOn(t, aiService, TryOnModeOutfit, "tops", "bottoms", "shoes")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// ボトムスが失敗しても、トップスを着た画像からシューズを続ける
+	wantCalls := [][2]int{{100, 1}, {1, 2}, {1, 3}}
+	if !slices.Equal(aiService.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", aiService.calls, wantCalls)
+	}
+	if output.Garments[1].Status != GarmentStatusFailed {
+		t.Errorf("Garments[1].Status = %s, want failed", output.Garments[1].Status)
+	}
+	if output.Succeeded() != 2 {
+		t.Errorf("Succeeded() = %d, want 2", output.Succeeded())
+	}
+}
+
+func TestTryOnUseCase_Execute_UnsupportedMode(t *testing.T) {
+	_, err := executeTryOn(t, &garmentAIService{}, TryOnMode("layered"), "tops")
+	if kind := domainerrors.KindOf(err); kind != domainerrors.KindInvalidInput {
+		t.Errorf("KindOf(err) = %s, want %s", kind, domainerrors.KindInvalidInput)
+	}
+}
+
+// classifierTextService 画像の幅ごとに決めた分類結果（JSON）を返すテスト用サービス
+type classifierTextService struct {
+	responses map[int]string
+}
+
+func (s *classifierTextService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(request.Images()[0].Data()))
+	if err != nil {
+		return nil, err
+	}
+	response, ok := s.responses[config.Width]
+	if !ok {
+		return nil, errors.New("classification failed")
+	}
+	return entities.NewTextResult(response), nil
+}
+
+func (s *classifierTextService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	return entities.NewTextResult(request.Prompt()), nil
+}
+
+func TestTryOnUseCase_Execute_ClassifiesGarments(t *testing.T) {
+	aiService := &garmentAIService{}
+	classifier := services.NewGarmentClassificationService(&classifierTextService{
+		responses: map[int]string{
+			1: `{"isGarment": true, "category": "shoes", "label": "sneakers", "confidence": 0.9}`,
+			3: `{"isGarment": true, "category": "other", "label": "hat", "confidence": 0.9}`,
+		},
+	})
+
+	// 幅 1: 自動判定で shoes、2: 指定どおり tops、3: 対応外、4: 判定失敗
+	output, err := executeTryOnWithClassifier(t, aiService, classifier, TryOnModeOutfit, "", "tops", "", "")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	wantCalls := [][2]int{{100, 2}, {2, 1}, {1, 3}, {3, 4}}
+	if !slices.Equal(aiService.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", aiService.calls, wantCalls)
+	}
+
+	byIndex := make(map[int]GarmentOutput)
+	for _, garment := range output.Garments {
+		byIndex[garment.Index] = garment
+	}
+	if byIndex[0].Category != valueobjects.GarmentCategoryShoes || byIndex[0].Classification == nil {
+		t.Errorf("garment 0 = %q (%v), want classified as shoes", byIndex[0].Category, byIndex[0].Classification)
+	}
+	if byIndex[1].Classification != nil {
+		t.Errorf("garment 1 should not be classified when the category is given")
+	}
+	if !slices.Equal(byIndex[2].Warnings, []GarmentWarning{GarmentWarningUnsupportedItem}) {
+		t.Errorf("garment 2 warnings = %v", byIndex[2].Warnings)
+	}
+	if !slices.Equal(byIndex[3].Warnings, []GarmentWarning{GarmentWarningClassificationFailed}) {
+		t.Errorf("garment 3 warnings = %v", byIndex[3].Warnings)
+	}
+}
+
+func TestTryOnUseCase_Execute_RejectsNonGarment(t *testing.T) {
+	aiService := &garmentAIService{}
+	classifier := services.NewGarmentClassificationService(&classifierTextService{
+		responses: map[int]string{
+			1: `{"isGarment": true, "category": "tops", "label": "shirt", "confidence": 0.9}`,
+			2: `{"isGarment": false, "category": "other", "label": "cat", "confidence": 0.95}`,
+		},
+	})
+
+	_, err := executeTryOnWithClassifier(t, aiService, classifier, TryOnModeSeparate, "", "")
+	if kind := domainerrors.KindOf(err); kind != domainerrors.KindInvalidInput {
+		t.Errorf("KindOf(err) = %s, want %s", kind, domainerrors.KindInvalidInput)
+	}
+	fields := domainerrors.FieldErrorsOf(err)
+	if len(fields) != 1 || fields[0].Field != services.PreflightGarmentField(1) || fields[0].Code != services.PreflightCodeNotGarment {
+		t.Errorf("FieldErrorsOf(err) = %v, want garmentImages[1] %s", fields, services.PreflightCodeNotGarment)
+	}
+	if len(aiService.calls) != 0 {
+		t.Errorf("try-on should not be called, got %d calls", len(aiService.calls))
+	}
+}
+
+func TestTryOnUseCase_Execute_PreflightRejectsBeforeTryOn(t *testing.T) {
+	aiService := &garmentAIService{}
+	uc := NewTryOnUseCase(
+		repositories.NewMemoryTryOnRepository(),
+		services.NewTryOnDomainService(aiService, valueobjects.DefaultNormalizeOptions()),
+		services.NewTryOnPreflightService(services.PreflightRules{MinEdge: 1, MaxEdge: 50}, valueobjects.NormalizeOptions{}, nil),
+		nil,
+		nil,
+		nil,
+		nil,
+		"",
+	)
+
+	_, err := uc.Execute(context.Background(), TryOnInput{
+		PersonImageData: encodeTestJPEG(t, 100),
+		GarmentImageData: []GarmentImageData{
+			{Data: encodeTestJPEG(t, 10)},
+			{Data: encodeTestJPEG(t, 60)},
+		},
+	})
+
+	var got []string
+	for _, field := range domainerrors.FieldErrorsOf(err) {
+		got = append(got, field.Field+":"+field.Code)
+	}
+	want := []string{"personImage:too_large", "garmentImages[1]:too_large"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FieldErrorsOf(err) = %v, want %v", got, want)
+	}
+	if len(aiService.calls) != 0 {
+		t.Errorf("try-on should not be called, got %d calls", len(aiService.calls))
+	}
+}
+
+func TestTryOnUseCase_Execute_StorageURI(t *testing.T) {
+	storage, err := repositories.NewLocalObjectStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalObjectStorage() error = %v", err)
+	}
+	uc := NewTryOnUseCase(
+		repositories.NewMemoryTryOnRepository(),
+		services.NewTryOnDomainService(fake.NewVertexAIService(fake.Config{}, storage), valueobjects.DefaultNormalizeOptions()),
+		nil,
+		nil,
+		storage,
+		nil,
+		nil,
+		"",
+	)
+
+	parameters := &TryOnParametersInput{
+		BaseSteps:        32,
+		PersonGeneration: string(valueobjects.AllowAdult),
+		SafetySetting:    string(valueobjects.BlockMediumAndAbove),
+		SampleCount:      1,
+		StorageURI:       "local://outputs/tryon/",
+		OutputMimeType:   string(valueobjects.MimeTypePNG),
+	}
+
+	t.Run("URIで返す", func(t *testing.T) {
+		output, err := uc.Execute(context.Background(), TryOnInput{
+			PersonImageData: encodeTestJPEG(t, 100),
+			GarmentImageData: []GarmentImageData{
+				{Data: encodeTestJPEG(t, 10), Category: "tops"},
+				{Data: encodeTestJPEG(t, 20), Category: "bottoms"},
+			},
+			Mode:       TryOnModeOutfit,
+			Parameters: parameters,
+		})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		// 重ね着の2段階目は、1段階目の画像を保存先から読み出して続ける
+		if output.Succeeded() != 2 {
+			t.Fatalf("Succeeded() = %d, want 2", output.Succeeded())
+		}
+		if len(output.Images) != 1 {
+			t.Fatalf("len(Images) = %d, want 1", len(output.Images))
+		}
+		img := output.Images[0]
+		if len(img.Data) != 0 {
+			t.Errorf("Images[0].Data has %d bytes, want none", len(img.Data))
+		}
+		if !strings.HasPrefix(img.URI, "local://outputs/tryon/") {
+			t.Errorf("Images[0].URI = %q, want prefix local://outputs/tryon/", img.URI)
+		}
+		data, err := storage.Get(context.Background(), img.URI)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", img.URI, err)
+		}
+		if _, err := valueobjects.NewImageData(data); err != nil {
+			t.Errorf("stored object is not an image: %v", err)
+		}
+	})
+
+	t.Run("扱えないスキーム", func(t *testing.T) {
+		unsupported := *parameters
+		unsupported.StorageURI = "gs://my-bucket/tryon/"
+
+		_, err := uc.Execute(context.Background(), TryOnInput{
+			PersonImageData:  encodeTestJPEG(t, 100),
+			GarmentImageData: []GarmentImageData{{Data: encodeTestJPEG(t, 10)}},
+			Parameters:       &unsupported,
+		})
+		if kind := domainerrors.KindOf(err); kind != domainerrors.KindInvalidInput {
+			t.Errorf("KindOf(err) = %s, want %s", kind, domainerrors.KindInvalidInput)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/services"
+)
+
+// 一度に集計できる最大日数
+const maxUsageQueryDays = 366
+
+// ErrInvalidUsageQuery 利用量の検索条件が不正な場合に返す
+var ErrInvalidUsageQuery = errors.New("invalid usage query")
+
+type clientKey struct{}
+
+// WithClient 利用量を集計するクライアントをコンテキストに設定する
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext コンテキストのクライアント（ない場合は entities.AnonymousClient）
+func ClientFromContext(ctx context.Context) string {
+	if client, _ := ctx.Value(clientKey{}).(string); client != "" {
+		return client
+	}
+	return entities.AnonymousClient
+}
+
+type clientScopeKey struct{}
+
+// WithClientScope クライアントを設定し、参照できる生成ジョブ・履歴・利用量をそのクライアントのものに限る
+// （認証したAPIキーのリクエスト）
+func WithClientScope(ctx context.Context, client string) context.Context {
+	return context.WithValue(WithClient(ctx, client), clientScopeKey{}, client)
+}
+
+// ScopedClient 参照できる範囲を限るクライアント（限らない場合は false）
+func ScopedClient(ctx context.Context) (string, bool) {
+	client, ok := ctx.Value(clientScopeKey{}).(string)
+	return client, ok
+}
+
+// canAccess ownerのクライアントのものを参照できるか
+func canAccess(ctx context.Context, owner string) bool {
+	client, scoped := ScopedClient(ctx)
+	return !scoped || client == owner
+}
+
+// UsageBudget 1日あたりの利用額の上限（USD、0は無制限）
+type UsageBudget struct {
+	// 全クライアントの合計
+	Daily float64
+	// クライアントごと
+	ClientDaily float64
+}
+
+// UsageUseCase 生成で使った利用量と料金の見積もりを集計し、1日の上限を超えたリクエストを断る。
+// nilの場合は集計も上限の確認も行わない。
+type UsageUseCase struct {
+	repo   repositories.UsageRepository
+	prices *services.PriceTable
+	budget UsageBudget
+	now    func() time.Time
+}
+
+func NewUsageUseCase(
+	repo repositories.UsageRepository,
+	prices *services.PriceTable,
+	budget UsageBudget,
+) *UsageUseCase {
+	return &UsageUseCase{
+		repo:   repo,
+		prices: prices,
+		budget: budget,
+		now:    time.Now,
+	}
+}
+
+type UsageQuery struct {
+	// 集計日の範囲（YYYY-MM-DD、両端を含む）。省略時は今日
+	From string
+	To   string
+	// 空の場合は全てのクライアント
+	Client string
+}
+
+type UsageSummaryOutput struct {
+	From   string
+	To     string
+	Client string
+	// 集計日・クライアント・モデル・単位の順
+	Totals []UsageTotalOutput
+	// 期間内の料金の見積もりの合計（USD）
+	TotalCost float64
+	Today     UsageTodayOutput
+}
+
+type UsageTotalOutput struct {
+	Day      string
+	Client   string
+	Model    string
+	Unit     entities.PriceUnit
+	Quantity float64
+	Cost     float64
+	Priced   bool
+}
+
+// UsageTodayOutput 今日の利用額と上限
+type UsageTodayOutput struct {
+	Day string
+	// 全クライアントの合計
+	Cost   float64
+	Budget float64
+	// Client を指定した場合のみ
+	ClientCost   float64
+	ClientBudget float64
+}
+
+// CheckBudget 今日の利用額が上限に達していれば KindBudgetExceeded のエラーを返す。
+// 集計を読めない場合は生成を止めない。
+func (uc *UsageUseCase) CheckBudget(ctx context.Context) error {
+	if uc == nil || (uc.budget.Daily <= 0 && uc.budget.ClientDaily <= 0) {
+		return nil
+	}
+
+	client := ClientFromContext(ctx)
+	totals, err := uc.repo.List(ctx, repositories.UsageFilter{
+		From: entities.UsageDay(uc.now()),
+		To:   entities.UsageDay(uc.now()),
+	})
+	if err != nil {
+		slog.Error("Failed to load usage for budget check", "error", err)
+		return nil
+	}
+
+	cost, clientCost := sumUsageCost(totals, client)
+	if uc.budget.Daily > 0 && cost >= uc.budget.Daily {
+		return domainerrors.Newf(domainerrors.KindBudgetExceeded,
+			"daily budget of $%.2f exceeded (spent $%.2f)", uc.budget.Daily, cost)
+	}
+	if uc.budget.ClientDaily > 0 && clientCost >= uc.budget.ClientDaily {
+		return domainerrors.Newf(domainerrors.KindBudgetExceeded,
+			"daily budget of $%.2f for client %q exceeded (spent $%.2f)", uc.budget.ClientDaily, client, clientCost)
+	}
+	return nil
+}
+
+// Record 生成で使った利用量をコンテキストのクライアントの今日の集計に加える。
+// 集計の保存に失敗しても生成結果には影響させない。
+func (uc *UsageUseCase) Record(ctx context.Context, items ...entities.UsageItem) {
+	if uc == nil {
+		return
+	}
+
+	day := entities.UsageDay(uc.now())
+	client := ClientFromContext(ctx)
+
+	var totals []entities.UsageTotal
+	for _, item := range items {
+		if item.Model == "" || item.Quantity <= 0 {
+			continue
+		}
+		cost, priced := uc.prices.Cost(item)
+		totals = append(totals, entities.UsageTotal{
+			Day:      day,
+			Client:   client,
+			Model:    item.Model,
+			Unit:     item.Unit,
+			Quantity: item.Quantity,
+			Cost:     cost,
+			Priced:   priced,
+		})
+	}
+	if len(totals) == 0 {
+		return
+	}
+
+	// キャンセルされたリクエストでも使った分は数える
+	if err := uc.repo.Add(context.WithoutCancel(ctx), totals); err != nil {
+		slog.Error("Failed to record usage", "client", client, "error", err)
+	}
+}
+
+// Summary 期間内の利用量と、今日の利用額・上限を返す
+func (uc *UsageUseCase) Summary(ctx context.Context, query UsageQuery) (*UsageSummaryOutput, error) {
+	today := entities.UsageDay(uc.now())
+
+	// 範囲を限るクライアントは、指定によらず自分の利用量のみ
+	scopedClient, scoped := ScopedClient(ctx)
+	if scoped {
+		query.Client = scopedClient
+	}
+
+	from, to := query.From, query.To
+	if from == "" && to == "" {
+		from, to = today, today
+	} else if from == "" {
+		from = to
+	} else if to == "" {
+		to = from
+	}
+
+	fromDate, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be YYYY-MM-DD, got %q", ErrInvalidUsageQuery, from)
+	}
+	toDate, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD, got %q", ErrInvalidUsageQuery, to)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidUsageQuery)
+	}
+	if days := int(toDate.Sub(fromDate).Hours()/24) + 1; days > maxUsageQueryDays {
+		return nil, fmt.Errorf("%w: range must be at most %d days, got %d", ErrInvalidUsageQuery, maxUsageQueryDays, days)
+	}
+
+	totals, err := uc.repo.List(ctx, repositories.UsageFilter{From: from, To: to, Client: query.Client})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+
+	output := &UsageSummaryOutput{
+		From:   from,
+		To:     to,
+		Client: query.Client,
+		Totals: make([]UsageTotalOutput, len(totals)),
+		Today: UsageTodayOutput{
+			Day:    today,
+			Budget: uc.budget.Daily,
+		},
+	}
+	for i, total := range totals {
+		output.Totals[i] = UsageTotalOutput(total)
+		output.TotalCost += total.Cost
+	}
+
+	todayTotals, err := uc.repo.List(ctx, repositories.UsageFilter{From: today, To: today})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	output.Today.Cost, output.Today.ClientCost = sumUsageCost(todayTotals, query.Client)
+	if query.Client != "" {
+		output.Today.ClientBudget = uc.budget.ClientDaily
+	}
+	// 他のクライアントを含む合計は見せない
+	if scoped {
+		output.Today.Cost, output.Today.Budget = output.Today.ClientCost, output.Today.ClientBudget
+	}
+
+	return output, nil
+}
+
+// sumUsageCost 全クライアントと、指定したクライアントの料金の合計
+func sumUsageCost(totals []entities.UsageTotal, client string) (cost, clientCost float64) {
+	for _, total := range totals {
+		cost += total.Cost
+		if total.Client == client {
+			clientCost += total.Cost
+		}
+	}
+	return cost, clientCost
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/services"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func newTestUsageUseCase(t *testing.T, budget UsageBudget, now time.Time) *UsageUseCase {
+	t.Helper()
+
+	prices := services.NewPriceTable()
+	if err := prices.Set("imagen", entities.PriceUnitImage, 0.04); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	uc := NewUsageUseCase(repositories.NewMemoryUsageRepository(), prices, budget)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestUsageUseCase_RecordAndSummary(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	uc := newTestUsageUseCase(t, UsageBudget{Daily: 1}, now)
+
+	alice := WithClient(context.Background(), "alice")
+	uc.Record(alice, entities.UsageItem{Model: "imagen", Unit: entities.PriceUnitImage, Quantity: 2})
+	uc.Record(alice, entities.UsageItem{Model: "imagen", Unit: entities.PriceUnitImage, Quantity: 1})
+	uc.Record(context.Background(),
+		entities.UsageItem{Model: "imagen", Unit: entities.PriceUnitImage, Quantity: 1},
+		// 料金表にない利用量も数量は残す
+		entities.UsageItem{Model: "gemini", Unit: entities.PriceUnitInputToken, Quantity: 100},
+		// 数量が0の利用量は記録しない
+		entities.UsageItem{Model: "gemini", Unit: entities.PriceUnitOutputToken},
+	)
+
+	summary, err := uc.Summary(context.Background(), UsageQuery{})
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if summary.From != "2025-09-01" || summary.To != "2025-09-01" {
+		t.Errorf("range = %s..%s, want today", summary.From, summary.To)
+	}
+	if len(summary.Totals) != 3 {
+		t.Fatalf("totals = %+v, want 3", summary.Totals)
+	}
+	if got := summary.Totals[0]; got.Client != "alice" || got.Quantity != 3 || math.Abs(got.Cost-0.12) > 1e-9 {
+		t.Errorf("alice total = %+v, want 3 images for $0.12", got)
+	}
+	if got := summary.Totals[1]; got.Client != entities.AnonymousClient || got.Priced {
+		t.Errorf("anonymous token total = %+v, want an unpriced total", got)
+	}
+	if math.Abs(summary.TotalCost-0.16) > 1e-9 || math.Abs(summary.Today.Cost-0.16) > 1e-9 {
+		t.Errorf("cost = %v, today = %v, want 0.16", summary.TotalCost, summary.Today.Cost)
+	}
+
+	summary, err = uc.Summary(context.Background(), UsageQuery{Client: "alice"})
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if len(summary.Totals) != 1 || math.Abs(summary.Today.ClientCost-0.12) > 1e-9 {
+		t.Errorf("alice summary = %+v", summary)
+	}
+}
+
+func TestUsageUseCase_Summary_ClientScope(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	uc := newTestUsageUseCase(t, UsageBudget{Daily: 10, ClientDaily: 1}, now)
+	image := entities.UsageItem{Model: "imagen", Unit: entities.PriceUnitImage, Quantity: 1}
+
+	uc.Record(WithClient(context.Background(), "alice"), image)
+	uc.Record(WithClient(context.Background(), "bob"), image, image)
+
+	// 他のクライアントを指定しても自分の利用量のみ
+	summary, err := uc.Summary(WithClientScope(context.Background(), "alice"), UsageQuery{Client: "bob"})
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if summary.Client != "alice" || len(summary.Totals) != 1 || summary.Totals[0].Client != "alice" {
+		t.Errorf("totals = %+v, want only alice's", summary.Totals)
+	}
+	if math.Abs(summary.TotalCost-0.04) > 1e-9 {
+		t.Errorf("total cost = %v, want 0.04", summary.TotalCost)
+	}
+	// 全クライアントの合計と上限は見せない
+	if today := summary.Today; math.Abs(today.Cost-0.04) > 1e-9 || today.Budget != 1 {
+		t.Errorf("today = %+v, want alice's cost and budget", today)
+	}
+}
+
+func TestUsageUseCase_Summary_InvalidQuery(t *testing.T) {
+	uc := newTestUsageUseCase(t, UsageBudget{}, time.Now())
+
+	for _, query := range []UsageQuery{
+		{From: "2025/09/01"},
+		{From: "2025-09-02", To: "2025-09-01"},
+		{From: "2024-01-01", To: "2025-12-31"},
+	} {
+		if _, err := uc.Summary(context.Background(), query); !errors.Is(err, ErrInvalidUsageQuery) {
+			t.Errorf("Summary(%+v) error = %v, want ErrInvalidUsageQuery", query, err)
+		}
+	}
+}
+
+func TestUsageUseCase_CheckBudget(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	image := entities.UsageItem{Model: "imagen", Unit: entities.PriceUnitImage, Quantity: 1}
+
+	t.Run("daily", func(t *testing.T) {
+		uc := newTestUsageUseCase(t, UsageBudget{Daily: 0.1}, now)
+		ctx := context.Background()
+
+		uc.Record(ctx, image, image)
+		if err := uc.CheckBudget(ctx); err != nil {
+			t.Fatalf("CheckBudget() under the budget error = %v", err)
+		}
+		uc.Record(ctx, image)
+		if err := uc.CheckBudget(ctx); domainerrors.KindOf(err) != domainerrors.KindBudgetExceeded {
+			t.Errorf("CheckBudget() error = %v, want budget exceeded", err)
+		}
+
+		// 翌日は使った分を数え直す
+		uc.now = func() time.Time { return now.AddDate(0, 0, 1) }
+		if err := uc.CheckBudget(ctx); err != nil {
+			t.Errorf("CheckBudget() on the next day error = %v", err)
+		}
+	})
+
+	t.Run("per client", func(t *testing.T) {
+		uc := newTestUsageUseCase(t, UsageBudget{ClientDaily: 0.04}, now)
+		alice := WithClient(context.Background(), "alice")
+
+		uc.Record(alice, image)
+		if err := uc.CheckBudget(alice); domainerrors.KindOf(err) != domainerrors.KindBudgetExceeded {
+			t.Errorf("CheckBudget(alice) error = %v, want budget exceeded", err)
+		}
+		if err := uc.CheckBudget(WithClient(context.Background(), "bob")); err != nil {
+			t.Errorf("CheckBudget(bob) error = %v", err)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var uc *UsageUseCase
+		uc.Record(context.Background(), image)
+		if err := uc.CheckBudget(context.Background()); err != nil {
+			t.Errorf("CheckBudget() error = %v", err)
+		}
+	})
+}
@@ -2,33 +2,63 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
 	"tryon-demo/internal/domain/services"
 	"tryon-demo/internal/domain/valueobjects"
 )
 
+// 1ジョブあたりの最大実行時間
+const veoJobTimeout = 15 * time.Minute
+
+// 長さを読み取れない動画の利用量に使う長さ（Veoの既定の長さ）
+const defaultVideoDuration = 8 * time.Second
+
+// ErrVeoJobFinished 終了済みのジョブを操作しようとした場合に返す
+var ErrVeoJobFinished = errors.New("veo job is already finished")
+
 type VeoUseCase struct {
 	veoDomainService    *services.VeoDomainService
 	imagenDomainService *services.ImagenDomainService
+	jobRepo             repositories.VeoJobRepository
+	history             *HistoryUseCase
+	usage               *UsageUseCase
+
+	// 実行中ジョブのキャンセル関数
+	cancels map[entities.VeoJobID]context.CancelFunc
+	// ジョブ状態の遷移を直列化するためのロック
+	mu sync.Mutex
 }
 
 func NewVeoUseCase(
 	veoDomainService *services.VeoDomainService,
 	imagenDomainService *services.ImagenDomainService,
+	jobRepo repositories.VeoJobRepository,
+	history *HistoryUseCase,
+	usage *UsageUseCase,
 ) *VeoUseCase {
 	return &VeoUseCase{
 		veoDomainService:    veoDomainService,
 		imagenDomainService: imagenDomainService,
+		jobRepo:             jobRepo,
+		history:             history,
+		usage:               usage,
+		cancels:             make(map[entities.VeoJobID]context.CancelFunc),
 	}
 }
 
 type VeoInput struct {
 	// 画像生成用
-	ImagenPrompt  string
-	ImagenModel   string
-	ImageData     []byte
-	ImageMimeType string
+	ImagenPrompt string
+	ImagenModel  string
+	ImageData    []byte
 
 	// 動画生成用
 	VideoPrompt string
@@ -39,9 +69,43 @@ type VeoOutput struct {
 	Videos [][]byte
 }
 
+// VeoJobOutput ジョブの状態
+type VeoJobOutput struct {
+	ID        entities.VeoJobID
+	Model     string
+	Status    entities.VeoJobStatus
+	Stage     entities.VeoJobStage
+	Progress  int
+	Videos    [][]byte
+	Error     string
+	ErrorKind domainerrors.Kind
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// 進捗通知用のコールバック
+type veoProgressFunc func(stage entities.VeoJobStage, progress int)
+
+// Execute 動画生成を同期的に実行する
 func (uc *VeoUseCase) Execute(ctx context.Context, input VeoInput) (*VeoOutput, error) {
+	if err := uc.usage.CheckBudget(ctx); err != nil {
+		return nil, err
+	}
+	return uc.execute(ctx, input, func(entities.VeoJobStage, int) {})
+}
+
+func (uc *VeoUseCase) execute(ctx context.Context, input VeoInput, onProgress veoProgressFunc) (output *VeoOutput, err error) {
+	recording := uc.history.Start(ctx, entities.GeneratorVeo, input.VideoModel, input.VideoPrompt)
+	recording.SetParameter("imagenPrompt", input.ImagenPrompt)
+	recording.SetParameter("imagenModel", input.ImagenModel)
+	defer func() {
+		recording.Finish(ctx, err)
+	}()
+
 	// ImageDataがnilかつImagenPromptが入力されている場合は画像生成を行う
 	if input.ImageData == nil && input.ImagenPrompt != "" {
+		onProgress(entities.VeoJobStageGeneratingImage, 10)
+
 		slog.Info("Execute Image Generation", "ImagenPrompt", input.ImagenPrompt, "ImagenModel", input.ImagenModel)
 		imagenRequest := entities.NewImagenRequest(input.ImagenPrompt, input.ImagenModel)
 		imagenOutput, err := uc.imagenDomainService.ProcessImagen(ctx, imagenRequest)
@@ -50,17 +114,24 @@ func (uc *VeoUseCase) Execute(ctx context.Context, input VeoInput) (*VeoOutput,
 		}
 
 		slog.Info("Successfully generated image")
+		uc.usage.Record(ctx, entities.UsageItem{
+			Model:    imagenRequest.ImagenModel(),
+			Unit:     entities.PriceUnitImage,
+			Quantity: float64(len(imagenOutput.Images())),
+		})
 
 		input.ImageData = imagenOutput.Images()[0].Data()
-		input.ImageMimeType = string(imagenOutput.Images()[0].MimeType())
 	}
 
-	imageData, err := valueobjects.NewImageData(input.ImageData, input.ImageMimeType)
+	imageData, err := valueobjects.NewImageData(input.ImageData)
 	if err != nil {
 		return nil, err
 	}
+	recording.AddInput(ctx, imageData.Data(), imageData.MimeType())
 
 	// 動画生成を行う
+	onProgress(entities.VeoJobStageGeneratingVideo, 30)
+
 	slog.Info("Execute Video Generation", "VideoPrompt", input.VideoPrompt, "VideoModel", input.VideoModel)
 	veoRequest := entities.NewVeoRequest(imageData, input.VideoModel, input.VideoPrompt)
 	veoResults, err := uc.veoDomainService.ProcessVeo(ctx, veoRequest)
@@ -68,14 +139,187 @@ func (uc *VeoUseCase) Execute(ctx context.Context, input VeoInput) (*VeoOutput,
 		return nil, err
 	}
 
+	// ドメインサービスで翻訳済みのプロンプト
+	recording.SetTranslatedPrompt(veoRequest.VideoPrompt())
+
 	slog.Info("Successfully generated video", "count", len(veoResults))
 
 	videos := make([][]byte, len(veoResults))
+	var seconds float64
 	for i, veoResult := range veoResults {
 		videos[i] = veoResult.Video().Data()
+		recording.AddOutput(ctx, videos[i], "video/mp4")
+		seconds += videoSeconds(veoResult.Video())
 	}
+	uc.usage.Record(ctx, entities.UsageItem{
+		Model:    veoRequest.VeoModel(),
+		Unit:     entities.PriceUnitSecond,
+		Quantity: seconds,
+	})
 
 	return &VeoOutput{
 		Videos: videos,
 	}, nil
 }
+
+// videoSeconds 動画の長さ（秒）。MP4から読み取れない場合は既定の長さとみなす
+func videoSeconds(video *valueobjects.VideoData) float64 {
+	if duration, ok := video.Duration(); ok {
+		return duration.Seconds()
+	}
+	return defaultVideoDuration.Seconds()
+}
+
+// Submit 動画生成ジョブを登録し、バックグラウンドで実行を開始する
+func (uc *VeoUseCase) Submit(ctx context.Context, input VeoInput) (*VeoJobOutput, error) {
+	// 上限を超えている場合はジョブを作らずに断る
+	if err := uc.usage.CheckBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	job := entities.NewVeoJob(input.VideoModel)
+	job.SetClient(ClientFromContext(ctx))
+	if err := uc.jobRepo.Save(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	// リクエストのキャンセルとは切り離して実行する（順番待ちのチケットなどの値は引き継ぐ）
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), veoJobTimeout)
+
+	uc.mu.Lock()
+	uc.cancels[job.ID()] = cancel
+	uc.mu.Unlock()
+
+	go uc.runJob(jobCtx, job.ID(), input)
+
+	return toVeoJobOutput(job), nil
+}
+
+// GetJob ジョブの状態を取得する
+func (uc *VeoUseCase) GetJob(ctx context.Context, id entities.VeoJobID) (*VeoJobOutput, error) {
+	job, err := uc.findJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toVeoJobOutput(job), nil
+}
+
+// findJob 参照できない他のクライアントのジョブは存在しないものとして扱う
+func (uc *VeoUseCase) findJob(ctx context.Context, id entities.VeoJobID) (*entities.VeoJob, error) {
+	job, err := uc.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(ctx, job.Client()) {
+		return nil, fmt.Errorf("job %s: %w", id, repositories.ErrNotFound)
+	}
+	return job, nil
+}
+
+// CancelJob 実行中のジョブをキャンセルする
+func (uc *VeoUseCase) CancelJob(ctx context.Context, id entities.VeoJobID) (*VeoJobOutput, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	job, err := uc.findJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.IsFinished() {
+		return nil, fmt.Errorf("%w: %s", ErrVeoJobFinished, job.Status())
+	}
+
+	if err := job.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.jobRepo.Save(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	if cancel, ok := uc.cancels[id]; ok {
+		cancel()
+	}
+
+	slog.Info("Veo job canceled", "jobID", id)
+
+	return toVeoJobOutput(job), nil
+}
+
+// runJob バックグラウンドでジョブを実行し、結果をジョブに反映する
+func (uc *VeoUseCase) runJob(ctx context.Context, id entities.VeoJobID, input VeoInput) {
+	defer func() {
+		uc.mu.Lock()
+		if cancel, ok := uc.cancels[id]; ok {
+			cancel()
+			delete(uc.cancels, id)
+		}
+		uc.mu.Unlock()
+	}()
+
+	slog.Info("Veo job started", "jobID", id)
+
+	output, err := uc.execute(ctx, input, func(stage entities.VeoJobStage, progress int) {
+		uc.updateJob(id, func(job *entities.VeoJob) error {
+			job.UpdateProgress(stage, progress)
+			return nil
+		})
+	})
+
+	uc.updateJob(id, func(job *entities.VeoJob) error {
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			return job.Cancel()
+		case err != nil:
+			slog.Error("Veo job failed", "jobID", id, "error", err)
+			return job.Fail(err)
+		default:
+			slog.Info("Veo job succeeded", "jobID", id, "videos", len(output.Videos))
+			return job.Succeed(output.Videos)
+		}
+	})
+}
+
+// updateJob 最新のジョブを取得して更新する。終了済みのジョブは更新しない。
+func (uc *VeoUseCase) updateJob(id entities.VeoJobID, update func(job *entities.VeoJob) error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	ctx := context.Background()
+
+	job, err := uc.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		slog.Error("Failed to find veo job", "jobID", id, "error", err)
+		return
+	}
+
+	if job.IsFinished() {
+		return
+	}
+
+	if err := update(job); err != nil {
+		slog.Error("Failed to update veo job", "jobID", id, "error", err)
+		return
+	}
+
+	if err := uc.jobRepo.Save(ctx, job); err != nil {
+		slog.Error("Failed to save veo job", "jobID", id, "error", err)
+	}
+}
+
+func toVeoJobOutput(job *entities.VeoJob) *VeoJobOutput {
+	return &VeoJobOutput{
+		ID:        job.ID(),
+		Model:     job.Model(),
+		Status:    job.Status(),
+		Stage:     job.Stage(),
+		Progress:  job.Progress(),
+		Videos:    job.Videos(),
+		Error:     job.ErrorMessage(),
+		ErrorKind: job.ErrorKind(),
+		CreatedAt: job.CreatedAt(),
+		UpdatedAt: job.UpdatedAt(),
+	}
+}
@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestVeoUseCase_ClientScope(t *testing.T) {
+	jobRepo := repositories.NewMemoryVeoJobRepository()
+	uc := NewVeoUseCase(nil, nil, jobRepo, nil, nil)
+
+	job := entities.NewVeoJob("veo-3.0-generate-001")
+	job.SetClient("alice")
+	if err := jobRepo.Save(context.Background(), job); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	bob := WithClientScope(context.Background(), "bob")
+	if _, err := uc.GetJob(bob, job.ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("GetJob(bob) error = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.CancelJob(bob, job.ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("CancelJob(bob) error = %v, want ErrNotFound", err)
+	}
+
+	alice := WithClientScope(context.Background(), "alice")
+	if _, err := uc.GetJob(alice, job.ID()); err != nil {
+		t.Errorf("GetJob(alice) error = %v", err)
+	}
+	output, err := uc.CancelJob(alice, job.ID())
+	if err != nil {
+		t.Fatalf("CancelJob(alice) error = %v", err)
+	}
+	if output.Status != entities.VeoJobStatusCanceled {
+		t.Errorf("status = %v, want canceled", output.Status)
+	}
+}
@@ -0,0 +1,143 @@
+package domainerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind エラーの分類。APIレスポンスの code としてそのまま返す。
+type Kind string
+
+const (
+	KindInvalidInput     Kind = "invalid_input"
+	KindQuotaExhausted   Kind = "quota_exhausted"
+	KindSafetyBlocked    Kind = "safety_blocked"
+	KindUpstreamTimeout  Kind = "upstream_timeout"
+	KindModelUnavailable Kind = "model_unavailable"
+	KindServerBusy       Kind = "server_busy"
+	KindBudgetExceeded   Kind = "budget_exceeded" // 1日の利用額の上限（予算）を超えた
+	KindInternal         Kind = "internal"
+)
+
+// 種類の判定用。errors.Is(err, domainerrors.ErrQuotaExhausted) のように使う。
+var (
+	ErrInvalidInput     = &Error{kind: KindInvalidInput, message: "invalid input"}
+	ErrQuotaExhausted   = &Error{kind: KindQuotaExhausted, message: "quota exhausted"}
+	ErrSafetyBlocked    = &Error{kind: KindSafetyBlocked, message: "blocked by safety filter"}
+	ErrUpstreamTimeout  = &Error{kind: KindUpstreamTimeout, message: "upstream timeout"}
+	ErrModelUnavailable = &Error{kind: KindModelUnavailable, message: "model unavailable"}
+	ErrServerBusy       = &Error{kind: KindServerBusy, message: "server busy"}
+	ErrBudgetExceeded   = &Error{kind: KindBudgetExceeded, message: "daily budget exceeded"}
+)
+
+// Error 種類付きのドメインエラー
+type Error struct {
+	kind    Kind
+	message string
+	err     error
+}
+
+// New 種類付きのエラーを生成する
+func New(kind Kind, message string) error {
+	return &Error{kind: kind, message: message}
+}
+
+// Wrap 原因のエラーに種類を付ける。errがnilの場合はnilを返す。
+func Wrap(kind Kind, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{kind: kind, message: message, err: err}
+}
+
+// Newf 書式付きで種類付きのエラーを生成する
+func Newf(kind Kind, format string, args ...any) error {
+	return &Error{kind: kind, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+func (e *Error) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	if e.message == "" {
+		return e.err.Error()
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is 種類が同じエラーを同一とみなす
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.kind == e.kind
+}
+
+// KindOf エラーの種類を返す。種類が付いていないエラーは、
+// タイムアウトなら KindUpstreamTimeout、それ以外は KindInternal とする。
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindUpstreamTimeout
+	}
+	return KindInternal
+}
+
+// FieldError 入力項目ごとの検証エラー
+type FieldError struct {
+	Field   string // 項目名（例: personImage, garmentImages[0]）
+	Code    string // 検証エラーの種類（例: too_small）
+	Message string
+}
+
+// ValidationError 複数の入力項目の検証エラーをまとめたもの
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return "validation failed"
+	}
+
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// NewValidation 項目ごとの検証エラーを KindInvalidInput のエラーとして返す。fieldsが空の場合はnilを返す。
+func NewValidation(fields []FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &Error{kind: KindInvalidInput, message: "validation failed", err: &ValidationError{Fields: fields}}
+}
+
+// FieldErrorsOf エラーに含まれる項目ごとの検証エラーを返す
+func FieldErrorsOf(err error) []FieldError {
+	var v *ValidationError
+	if errors.As(err, &v) {
+		return v.Fields
+	}
+	return nil
+}
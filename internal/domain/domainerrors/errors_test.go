@@ -0,0 +1,96 @@
+package domainerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "種類付き", err: New(KindSafetyBlocked, "blocked"), want: KindSafetyBlocked},
+		{
+			name: "ラップされた種類付き",
+			err:  fmt.Errorf("try-on generation failed: %w", Wrap(KindQuotaExhausted, errors.New("429"), "vertex ai")),
+			want: KindQuotaExhausted,
+		},
+		{name: "タイムアウト", err: fmt.Errorf("request: %w", context.DeadlineExceeded), want: KindUpstreamTimeout},
+		{name: "種類なし", err: errors.New("boom"), want: KindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("Error 429, Status: RESOURCE_EXHAUSTED")
+	err := fmt.Errorf("imagen generation failed: %w", Wrap(KindQuotaExhausted, cause, "generate images"))
+
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("errors.Is(err, ErrQuotaExhausted) = false, want true")
+	}
+	if errors.Is(err, ErrSafetyBlocked) {
+		t.Errorf("errors.Is(err, ErrSafetyBlocked) = true, want false")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(err, cause) = false, want true")
+	}
+}
+
+func TestWrap_Nil(t *testing.T) {
+	if err := Wrap(KindInternal, nil, "noop"); err != nil {
+		t.Errorf("Wrap(nil) = %v, want nil", err)
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	err := Wrap(KindInvalidInput, errors.New("sampleCount must be between 1 and 4"), "request validation failed")
+
+	want := "request validation failed: sampleCount must be between 1 and 4"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	if err := NewValidation(nil); err != nil {
+		t.Errorf("NewValidation(nil) = %v, want nil", err)
+	}
+
+	fields := []FieldError{
+		{Field: "personImage", Code: "too_small", Message: "image is too small"},
+		{Field: "garmentImages[1]", Code: "aspect_ratio", Message: "aspect ratio is too extreme"},
+	}
+	err := fmt.Errorf("preflight: %w", NewValidation(fields))
+
+	if KindOf(err) != KindInvalidInput {
+		t.Errorf("KindOf() = %v, want %v", KindOf(err), KindInvalidInput)
+	}
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("errors.Is(err, ErrInvalidInput) = false, want true")
+	}
+
+	got := FieldErrorsOf(err)
+	if len(got) != 2 || got[0] != fields[0] || got[1] != fields[1] {
+		t.Errorf("FieldErrorsOf() = %v, want %v", got, fields)
+	}
+	if FieldErrorsOf(New(KindInvalidInput, "bad")) != nil {
+		t.Errorf("FieldErrorsOf() should be nil for errors without fields")
+	}
+
+	want := "preflight: validation failed: personImage: image is too small; garmentImages[1]: aspect ratio is too extreme"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
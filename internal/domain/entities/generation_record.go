@@ -0,0 +1,236 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+type GenerationRecordID string
+
+// 生成機能の種類
+type GeneratorType string
+
+const (
+	GeneratorTryOn      GeneratorType = "tryon"
+	GeneratorImagen     GeneratorType = "imagen"
+	GeneratorVeo        GeneratorType = "veo"
+	GeneratorNanobanana GeneratorType = "nanobanana"
+)
+
+// IsValid 定義済みの生成機能かどうか
+func (t GeneratorType) IsValid() bool {
+	switch t {
+	case GeneratorTryOn, GeneratorImagen, GeneratorVeo, GeneratorNanobanana:
+		return true
+	default:
+		return false
+	}
+}
+
+type GenerationStatus string
+
+const (
+	GenerationStatusSucceeded GenerationStatus = "succeeded"
+	GenerationStatusFailed    GenerationStatus = "failed"
+)
+
+// GenerationAsset 生成の入力・出力ファイルへの参照（実データはBlobStoreに保存する）
+type GenerationAsset struct {
+	key      string
+	mimeType string
+	size     int
+}
+
+func NewGenerationAsset(key, mimeType string, size int) GenerationAsset {
+	return GenerationAsset{
+		key:      key,
+		mimeType: mimeType,
+		size:     size,
+	}
+}
+
+func (a GenerationAsset) Key() string {
+	return a.key
+}
+
+func (a GenerationAsset) MimeType() string {
+	return a.mimeType
+}
+
+func (a GenerationAsset) Size() int {
+	return a.size
+}
+
+// GenerationRecord 1回の生成リクエストの履歴
+type GenerationRecord struct {
+	id            GenerationRecordID
+	generatorType GeneratorType
+	// 生成を依頼したクライアント（APIキーのIDなど）
+	client           string
+	model            string
+	prompt           string
+	translatedPrompt string
+	parameters       map[string]string
+	inputs           []GenerationAsset
+	outputs          []GenerationAsset
+	status           GenerationStatus
+	errorMessage     string
+	startedAt        time.Time
+	finishedAt       time.Time
+}
+
+func NewGenerationRecord(
+	generatorType GeneratorType,
+	model string,
+	prompt string,
+	startedAt time.Time,
+) (*GenerationRecord, error) {
+	if !generatorType.IsValid() {
+		return nil, fmt.Errorf("invalid generator type: %s", generatorType)
+	}
+
+	// 開始時刻順に並ぶよう時刻の桁数を固定し、同じ時刻でも重ならないよう乱数を付ける
+	id := GenerationRecordID(fmt.Sprintf("gen_%020d_%s", startedAt.UnixNano(), newRandomID()))
+
+	return &GenerationRecord{
+		id:            id,
+		generatorType: generatorType,
+		model:         model,
+		prompt:        prompt,
+		parameters:    make(map[string]string),
+		startedAt:     startedAt,
+	}, nil
+}
+
+// RestoreGenerationRecord 保存済みのデータから履歴を復元する
+func RestoreGenerationRecord(
+	id GenerationRecordID,
+	generatorType GeneratorType,
+	client string,
+	model string,
+	prompt string,
+	translatedPrompt string,
+	parameters map[string]string,
+	inputs []GenerationAsset,
+	outputs []GenerationAsset,
+	status GenerationStatus,
+	errorMessage string,
+	startedAt time.Time,
+	finishedAt time.Time,
+) *GenerationRecord {
+	if parameters == nil {
+		parameters = make(map[string]string)
+	}
+
+	return &GenerationRecord{
+		id:               id,
+		generatorType:    generatorType,
+		client:           client,
+		model:            model,
+		prompt:           prompt,
+		translatedPrompt: translatedPrompt,
+		parameters:       parameters,
+		inputs:           inputs,
+		outputs:          outputs,
+		status:           status,
+		errorMessage:     errorMessage,
+		startedAt:        startedAt,
+		finishedAt:       finishedAt,
+	}
+}
+
+func (r *GenerationRecord) ID() GenerationRecordID {
+	return r.id
+}
+
+func (r *GenerationRecord) GeneratorType() GeneratorType {
+	return r.generatorType
+}
+
+func (r *GenerationRecord) Client() string {
+	return r.client
+}
+
+func (r *GenerationRecord) SetClient(client string) {
+	r.client = client
+}
+
+func (r *GenerationRecord) Model() string {
+	return r.model
+}
+
+func (r *GenerationRecord) Prompt() string {
+	return r.prompt
+}
+
+func (r *GenerationRecord) TranslatedPrompt() string {
+	return r.translatedPrompt
+}
+
+func (r *GenerationRecord) SetTranslatedPrompt(translatedPrompt string) {
+	r.translatedPrompt = translatedPrompt
+}
+
+func (r *GenerationRecord) Parameters() map[string]string {
+	return r.parameters
+}
+
+func (r *GenerationRecord) SetParameter(key, value string) {
+	r.parameters[key] = value
+}
+
+func (r *GenerationRecord) Inputs() []GenerationAsset {
+	return r.inputs
+}
+
+func (r *GenerationRecord) AddInput(asset GenerationAsset) {
+	r.inputs = append(r.inputs, asset)
+}
+
+func (r *GenerationRecord) Outputs() []GenerationAsset {
+	return r.outputs
+}
+
+func (r *GenerationRecord) AddOutput(asset GenerationAsset) {
+	r.outputs = append(r.outputs, asset)
+}
+
+func (r *GenerationRecord) Status() GenerationStatus {
+	return r.status
+}
+
+func (r *GenerationRecord) ErrorMessage() string {
+	return r.errorMessage
+}
+
+func (r *GenerationRecord) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *GenerationRecord) FinishedAt() time.Time {
+	return r.finishedAt
+}
+
+// Duration 生成にかかった時間
+func (r *GenerationRecord) Duration() time.Duration {
+	if r.finishedAt.IsZero() {
+		return 0
+	}
+	return r.finishedAt.Sub(r.startedAt)
+}
+
+// Complete 生成成功として記録する
+func (r *GenerationRecord) Complete(finishedAt time.Time) {
+	r.status = GenerationStatusSucceeded
+	r.errorMessage = ""
+	r.finishedAt = finishedAt
+}
+
+// Fail 生成失敗として記録する
+func (r *GenerationRecord) Fail(err error, finishedAt time.Time) {
+	r.status = GenerationStatusFailed
+	if err != nil {
+		r.errorMessage = err.Error()
+	}
+	r.finishedAt = finishedAt
+}
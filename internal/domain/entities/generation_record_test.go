@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewGenerationRecord(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	record, err := NewGenerationRecord(GeneratorImagen, "imagen-4.0-generate-001", "赤いドレス", startedAt)
+	if err != nil {
+		t.Fatalf("NewGenerationRecord() error = %v", err)
+	}
+
+	if record.ID() == "" {
+		t.Errorf("Expected non-empty ID")
+	}
+	if record.GeneratorType() != GeneratorImagen {
+		t.Errorf("Expected generator type imagen, got %v", record.GeneratorType())
+	}
+	if record.Parameters() == nil {
+		t.Errorf("Expected parameters to be initialized")
+	}
+	if record.Duration() != 0 {
+		t.Errorf("Expected zero duration before completion, got %v", record.Duration())
+	}
+}
+
+func TestNewGenerationRecord_IDOrderedByStartedAt(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first, _ := NewGenerationRecord(GeneratorImagen, "", "", startedAt)
+	same, _ := NewGenerationRecord(GeneratorImagen, "", "", startedAt)
+	later, _ := NewGenerationRecord(GeneratorImagen, "", "", startedAt.Add(time.Nanosecond))
+
+	if first.ID() == same.ID() {
+		t.Errorf("records started at the same time share ID %s", first.ID())
+	}
+	if first.ID() >= later.ID() || same.ID() >= later.ID() {
+		t.Errorf("IDs %s, %s should sort before %s", first.ID(), same.ID(), later.ID())
+	}
+}
+
+func TestNewGenerationRecord_InvalidType(t *testing.T) {
+	if _, err := NewGenerationRecord("unknown", "", "", time.Now()); err == nil {
+		t.Errorf("Expected error for unknown generator type")
+	}
+}
+
+func TestGenerationRecord_Complete(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	record, _ := NewGenerationRecord(GeneratorVeo, "veo-3.0-generate-preview", "歩く人", startedAt)
+
+	record.SetTranslatedPrompt("a person walking")
+	record.SetParameter("aspectRatio", "16:9")
+	record.AddInput(NewGenerationAsset("in", "image/png", 10))
+	record.AddOutput(NewGenerationAsset("out", "video/mp4", 20))
+	record.Complete(startedAt.Add(90 * time.Second))
+
+	if record.Status() != GenerationStatusSucceeded {
+		t.Errorf("Expected status succeeded, got %v", record.Status())
+	}
+	if record.Duration() != 90*time.Second {
+		t.Errorf("Expected duration 90s, got %v", record.Duration())
+	}
+	if record.TranslatedPrompt() != "a person walking" {
+		t.Errorf("Unexpected translated prompt: %q", record.TranslatedPrompt())
+	}
+	if record.Parameters()["aspectRatio"] != "16:9" {
+		t.Errorf("Unexpected parameters: %v", record.Parameters())
+	}
+	if len(record.Inputs()) != 1 || len(record.Outputs()) != 1 {
+		t.Fatalf("Expected 1 input and 1 output, got %d and %d", len(record.Inputs()), len(record.Outputs()))
+	}
+	if record.Outputs()[0].MimeType() != "video/mp4" || record.Outputs()[0].Size() != 20 {
+		t.Errorf("Unexpected output asset: %+v", record.Outputs()[0])
+	}
+}
+
+func TestGenerationRecord_Fail(t *testing.T) {
+	startedAt := time.Now()
+	record, _ := NewGenerationRecord(GeneratorNanobanana, "", "", startedAt)
+
+	record.Fail(errors.New("quota exceeded"), startedAt.Add(time.Second))
+
+	if record.Status() != GenerationStatusFailed {
+		t.Errorf("Expected status failed, got %v", record.Status())
+	}
+	if record.ErrorMessage() != "quota exceeded" {
+		t.Errorf("Unexpected error message: %q", record.ErrorMessage())
+	}
+}
+
+func TestGeneratorType_IsValid(t *testing.T) {
+	for _, generatorType := range []GeneratorType{GeneratorTryOn, GeneratorImagen, GeneratorVeo, GeneratorNanobanana} {
+		if !generatorType.IsValid() {
+			t.Errorf("Expected %q to be valid", generatorType)
+		}
+	}
+	if GeneratorType("").IsValid() {
+		t.Errorf("Expected empty generator type to be invalid")
+	}
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// 料金の単位
+type PriceUnit string
+
+const (
+	// 生成した画像1枚あたり
+	PriceUnitImage PriceUnit = "image"
+	// 生成した動画1秒あたり
+	PriceUnitSecond PriceUnit = "second"
+	// 1回の呼び出しあたり
+	PriceUnitRequest PriceUnit = "request"
+	// Geminiの入力トークン1つあたり
+	PriceUnitInputToken PriceUnit = "input_token"
+	// Geminiの出力トークン（思考トークンを含む）1つあたり
+	PriceUnitOutputToken PriceUnit = "output_token"
+)
+
+// IsValid 定義済みの単位かどうか
+func (u PriceUnit) IsValid() bool {
+	switch u {
+	case PriceUnitImage, PriceUnitSecond, PriceUnitRequest, PriceUnitInputToken, PriceUnitOutputToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// ModelCapabilities モデルごとに指定できる範囲（0・空の場合はその機能を持たない、または制限しない）
+type ModelCapabilities struct {
+	// 1回で生成できる画像の最大枚数（Imagenの numberOfImages、試着の sample_count）
+	MaxImages int
+	// 1回で入力できる画像の最大枚数（画像編集の images など）
+	MaxInputImages int
+	// 指定できる縦横比（空の場合は指定できない）
+	AspectRatios []string
+	// seed を指定して同じ結果を再現できる
+	Seed bool
+	// 動画に音声を付けられる
+	Audio bool
+	// 1回で返す候補（動画・応答）の最大数
+	CandidateCount int
+}
+
+// ModelPrice 料金の目安（USD）
+type ModelPrice struct {
+	Amount float64
+	Unit   PriceUnit
+}
+
+// GenerativeModel 生成に使えるモデル。モデルカタログに登録して使う
+type GenerativeModel struct {
+	ID           string
+	Name         string
+	Description  string
+	Generator    GeneratorType
+	Capabilities ModelCapabilities
+	// 料金が分からない場合は nil
+	Price *ModelPrice
+}
+
+// Validate カタログに登録できる内容かどうか
+func (m GenerativeModel) Validate() error {
+	var errs []error
+	if m.ID == "" {
+		errs = append(errs, errors.New("id is required"))
+	}
+	if !m.Generator.IsValid() {
+		errs = append(errs, fmt.Errorf("unsupported generator: %q", m.Generator))
+	}
+	if m.Capabilities.MaxImages < 0 || m.Capabilities.MaxInputImages < 0 || m.Capabilities.CandidateCount < 0 {
+		errs = append(errs, errors.New("capabilities must not be negative"))
+	}
+	// 画像編集は入力画像が必須のため、受け付ける枚数を決めておく
+	if m.Generator == GeneratorNanobanana && m.Capabilities.MaxInputImages < 1 {
+		errs = append(errs, errors.New("nanobanana models require maxInputImages"))
+	}
+	if m.Price != nil {
+		if m.Price.Amount < 0 {
+			errs = append(errs, fmt.Errorf("price must not be negative, got %v", m.Price.Amount))
+		}
+		if !m.Price.Unit.IsValid() {
+			errs = append(errs, fmt.Errorf("unsupported price unit: %q", m.Price.Unit))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid model %q: %w", m.ID, err)
+	}
+	return nil
+}
+
+// SupportsAspectRatio 縦横比を指定できるかどうか
+func (m GenerativeModel) SupportsAspectRatio(aspectRatio string) bool {
+	return slices.Contains(m.Capabilities.AspectRatios, aspectRatio)
+}
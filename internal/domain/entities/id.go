@@ -0,0 +1,13 @@
+package entities
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newRandomID 推測されにくく、同時に作っても重ならないIDの一部（128ビットの乱数）
+func newRandomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
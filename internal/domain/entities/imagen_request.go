@@ -1,5 +1,17 @@
 package entities
 
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ImagenAspectRatios Imagenで指定できる縦横比
+var ImagenAspectRatios = []string{"1:1", "3:4", "4:3", "9:16", "16:9"}
+
+// Imagenで1回に生成できる枚数の上限
+const MaxImagenNumberOfImages = 4
+
 type ImagenRequest struct {
 	prompt           string
 	imagenModel      string
@@ -22,7 +34,22 @@ func NewImagenRequest(prompt, imagenModel string) *ImagenRequest {
 	}
 }
 
-func NewImagenRequestWithConfig(prompt, imagenModel string, numberOfImages int, aspectRatio, negativePrompt string, seed int64, includeRaiReason bool) *ImagenRequest {
+// NewImagenRequestWithConfig 生成枚数・縦横比・シードを検証してリクエストを作る。
+// プロンプトは翻訳後に確定するため、ここでは検証しない。
+func NewImagenRequestWithConfig(prompt, imagenModel string, numberOfImages int, aspectRatio, negativePrompt string, seed int64, includeRaiReason bool) (*ImagenRequest, error) {
+	if numberOfImages < 1 || numberOfImages > MaxImagenNumberOfImages {
+		return nil, fmt.Errorf("numberOfImages must be between 1 and %d, got %d", MaxImagenNumberOfImages, numberOfImages)
+	}
+
+	if !slices.Contains(ImagenAspectRatios, aspectRatio) {
+		return nil, fmt.Errorf("unsupported aspectRatio: %q", aspectRatio)
+	}
+
+	// APIにはint32で渡すため、その範囲に収める（0は指定なし）
+	if seed < 0 || seed > math.MaxInt32 {
+		return nil, fmt.Errorf("seed must be between 0 and %d, got %d", math.MaxInt32, seed)
+	}
+
 	return &ImagenRequest{
 		prompt:           prompt,
 		imagenModel:      imagenModel,
@@ -31,7 +58,7 @@ func NewImagenRequestWithConfig(prompt, imagenModel string, numberOfImages int,
 		negativePrompt:   negativePrompt,
 		seed:             seed,
 		includeRaiReason: includeRaiReason,
-	}
+	}, nil
 }
 
 func (r *ImagenRequest) Prompt() string {
@@ -1,8 +1,12 @@
 package entities
 
-import "tryon-demo/internal/domain/valueobjects"
+import (
+	"fmt"
 
-// 画像加工リクエスト
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// 画像加工リクエスト（モデルはモデルカタログから決めて渡す）
 type NanobananaModifyRequest struct {
 	model       string
 	prompt      string
@@ -11,11 +15,6 @@ type NanobananaModifyRequest struct {
 }
 
 func NewNanobananaModifyRequest(model string, prompt string, imageDatas []*valueobjects.ImageData) *NanobananaModifyRequest {
-	if model == "" {
-		// デフォルトモデル
-		model = "gemini-2.5-flash-image-preview"
-	}
-
 	return &NanobananaModifyRequest{
 		model:       model,
 		prompt:      prompt,
@@ -26,11 +25,6 @@ func NewNanobananaModifyRequest(model string, prompt string, imageDatas []*value
 
 // 複数画像対応の新しいコンストラクタ
 func NewNanobananaModifyRequestWithMultipleImages(model string, prompt string, imageDatas []*valueobjects.ImageData) *NanobananaModifyRequest {
-	if model == "" {
-		// デフォルトモデル
-		model = "gemini-2.5-flash-image-preview"
-	}
-
 	return &NanobananaModifyRequest{
 		model:       model,
 		prompt:      prompt,
@@ -71,3 +65,17 @@ func (r *NanobananaModifyRequest) ImageCount() int {
 	}
 	return 0
 }
+
+// PrepareImages 外部APIに送る前に全ての画像を整える
+func (r *NanobananaModifyRequest) PrepareImages(opts valueobjects.NormalizeOptions) error {
+	images := make([]*valueobjects.ImageData, len(r.imageDatas))
+	for i, imageData := range r.imageDatas {
+		image, err := imageData.Normalize(opts)
+		if err != nil {
+			return fmt.Errorf("failed to normalize image %d: %w", i+1, err)
+		}
+		images[i] = image
+	}
+	r.imageDatas = images
+	return nil
+}
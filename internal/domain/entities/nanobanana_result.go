@@ -5,6 +5,7 @@ import "tryon-demo/internal/domain/valueobjects"
 type NanobananaResult struct {
 	response  string
 	imageData *valueobjects.ImageData
+	usage     TokenUsage
 }
 
 func NewNanobananaResult(response string, imageData *valueobjects.ImageData) *NanobananaResult {
@@ -29,3 +30,12 @@ func (r *NanobananaResult) ImageData() *valueobjects.ImageData {
 func (r *NanobananaResult) SetImageData(imageData *valueobjects.ImageData) {
 	r.imageData = imageData
 }
+
+// Usage 生成に使ったトークン数（分からない場合はゼロ値）
+func (r *NanobananaResult) Usage() TokenUsage {
+	return r.usage
+}
+
+func (r *NanobananaResult) SetUsage(usage TokenUsage) {
+	r.usage = usage
+}
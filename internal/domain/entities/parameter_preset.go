@@ -0,0 +1,169 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// ErrParameterPresetReadOnly 組み込みのプリセットを変更・削除しようとした場合に返す
+var ErrParameterPresetReadOnly = errors.New("built-in preset cannot be modified")
+
+type ParameterPresetID string
+
+// ImagenSettings Imagenのプリセットに保存する設定（プロンプト以外）
+type ImagenSettings struct {
+	// 空の場合は既定のモデルを使う
+	Model            string
+	NumberOfImages   int
+	AspectRatio      string
+	NegativePrompt   string
+	Seed             int64
+	IncludeRaiReason bool
+}
+
+// ParameterPreset 名前を付けて保存した生成パラメータ。
+// 試着では tryOn、Imagenでは imagen のどちらか一方を持つ。
+type ParameterPreset struct {
+	id            ParameterPresetID
+	name          string
+	description   string
+	generatorType GeneratorType
+	tryOn         *valueobjects.TryOnParameters
+	imagen        *ImagenSettings
+	// 組み込みのプリセットは変更・削除できない
+	builtIn   bool
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// NewTryOnParameterPreset 試着のプリセットを作る
+func NewTryOnParameterPreset(name, description string, parameters *valueobjects.TryOnParameters) (*ParameterPreset, error) {
+	preset := newParameterPreset(GeneratorTryOn)
+	if err := preset.Update(name, description, parameters, nil); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// NewImagenParameterPreset Imagenのプリセットを作る
+func NewImagenParameterPreset(name, description string, settings ImagenSettings) (*ParameterPreset, error) {
+	preset := newParameterPreset(GeneratorImagen)
+	if err := preset.Update(name, description, nil, &settings); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+func newParameterPreset(generatorType GeneratorType) *ParameterPreset {
+	now := time.Now()
+	return &ParameterPreset{
+		id:            ParameterPresetID(fmt.Sprintf("preset_%d", now.UnixNano())),
+		generatorType: generatorType,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+}
+
+// RestoreParameterPreset 保存済みのデータ、または組み込みの定義からプリセットを復元する
+func RestoreParameterPreset(
+	id ParameterPresetID,
+	name string,
+	description string,
+	generatorType GeneratorType,
+	tryOn *valueobjects.TryOnParameters,
+	imagen *ImagenSettings,
+	builtIn bool,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *ParameterPreset {
+	return &ParameterPreset{
+		id:            id,
+		name:          name,
+		description:   description,
+		generatorType: generatorType,
+		tryOn:         tryOn,
+		imagen:        imagen,
+		builtIn:       builtIn,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+// Update 名前・説明・パラメータを置き換える。生成機能は変更できない。
+func (p *ParameterPreset) Update(name, description string, tryOn *valueobjects.TryOnParameters, imagen *ImagenSettings) error {
+	if p.builtIn {
+		return ErrParameterPresetReadOnly
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch p.generatorType {
+	case GeneratorTryOn:
+		if tryOn == nil || imagen != nil {
+			return fmt.Errorf("try-on preset requires try-on parameters only")
+		}
+	case GeneratorImagen:
+		if imagen == nil || tryOn != nil {
+			return fmt.Errorf("imagen preset requires imagen settings only")
+		}
+		// プロンプト以外の設定をリクエストと同じ規則で検証する
+		if _, err := NewImagenRequestWithConfig("", imagen.Model, imagen.NumberOfImages, imagen.AspectRatio,
+			imagen.NegativePrompt, imagen.Seed, imagen.IncludeRaiReason); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("presets are not supported for %s", p.generatorType)
+	}
+
+	p.name = name
+	p.description = strings.TrimSpace(description)
+	p.tryOn = tryOn
+	p.imagen = imagen
+	p.updatedAt = time.Now()
+	return nil
+}
+
+func (p *ParameterPreset) ID() ParameterPresetID {
+	return p.id
+}
+
+func (p *ParameterPreset) Name() string {
+	return p.name
+}
+
+func (p *ParameterPreset) Description() string {
+	return p.description
+}
+
+func (p *ParameterPreset) GeneratorType() GeneratorType {
+	return p.generatorType
+}
+
+// TryOnParameters 試着のパラメータ（Imagenのプリセットではnil）
+func (p *ParameterPreset) TryOnParameters() *valueobjects.TryOnParameters {
+	return p.tryOn
+}
+
+// ImagenSettings Imagenの設定（試着のプリセットではnil）
+func (p *ParameterPreset) ImagenSettings() *ImagenSettings {
+	return p.imagen
+}
+
+func (p *ParameterPreset) IsBuiltIn() bool {
+	return p.builtIn
+}
+
+func (p *ParameterPreset) CreatedAt() time.Time {
+	return p.createdAt
+}
+
+func (p *ParameterPreset) UpdatedAt() time.Time {
+	return p.updatedAt
+}
@@ -1,10 +1,18 @@
 package entities
 
+import "tryon-demo/internal/domain/valueobjects"
+
 type TextRequest struct {
 	prompt string
 
 	// 対象とするモデル
 	model string
+
+	// プロンプトと一緒に渡す画像（画像の内容を問い合わせる場合）
+	images []*valueobjects.ImageData
+
+	// 応答の形式（例: application/json）。空の場合はテキスト
+	responseMimeType string
 }
 
 func NewTextRequest(prompt string, model string) *TextRequest {
@@ -14,6 +22,21 @@ func NewTextRequest(prompt string, model string) *TextRequest {
 	}
 }
 
+// NewImageTextRequest 画像について問い合わせるリクエストを作成する
+func NewImageTextRequest(
+	prompt string,
+	model string,
+	images []*valueobjects.ImageData,
+	responseMimeType string,
+) *TextRequest {
+	return &TextRequest{
+		prompt:           prompt,
+		model:            model,
+		images:           images,
+		responseMimeType: responseMimeType,
+	}
+}
+
 func (r *TextRequest) Prompt() string {
 	return r.prompt
 }
@@ -21,3 +44,11 @@ func (r *TextRequest) Prompt() string {
 func (r *TextRequest) Model() string {
 	return r.model
 }
+
+func (r *TextRequest) Images() []*valueobjects.ImageData {
+	return r.images
+}
+
+func (r *TextRequest) ResponseMimeType() string {
+	return r.responseMimeType
+}
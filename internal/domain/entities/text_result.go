@@ -1,7 +1,8 @@
 package entities
 
 type TextResult struct {
-	text string
+	text  string
+	usage TokenUsage
 }
 
 func NewTextResult(text string) *TextResult {
@@ -13,3 +14,12 @@ func NewTextResult(text string) *TextResult {
 func (r *TextResult) Text() string {
 	return r.text
 }
+
+// Usage 生成に使ったトークン数（分からない場合はゼロ値）
+func (r *TextResult) Usage() TokenUsage {
+	return r.usage
+}
+
+func (r *TextResult) SetUsage(usage TokenUsage) {
+	r.usage = usage
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"tryon-demo/internal/domain/valueobjects"
@@ -45,6 +46,23 @@ func NewTryOnRequest(
 	}, nil
 }
 
+// RestoreTryOnRequest 保存済みのデータからリクエストを復元する
+func RestoreTryOnRequest(
+	id TryOnRequestID,
+	personImage *valueobjects.ImageData,
+	garmentImage *valueobjects.ImageData,
+	parameters *valueobjects.TryOnParameters,
+	createdAt time.Time,
+) *TryOnRequest {
+	return &TryOnRequest{
+		id:           id,
+		personImage:  personImage,
+		garmentImage: garmentImage,
+		parameters:   parameters,
+		createdAt:    createdAt,
+	}
+}
+
 func (r *TryOnRequest) ID() TryOnRequestID {
 	return r.id
 }
@@ -61,22 +79,33 @@ func (r *TryOnRequest) Parameters() *valueobjects.TryOnParameters {
 	return r.parameters
 }
 
+// OutputURIPrefix 生成画像の書き込み先。Storage URIの下にリクエストごとのディレクトリを作る。
+// Storage URIが未指定の場合は空文字を返す。
+func (r *TryOnRequest) OutputURIPrefix() string {
+	storageURI := r.parameters.StorageURI()
+	if storageURI == "" {
+		return ""
+	}
+	return strings.TrimRight(storageURI, "/") + "/" + string(r.id) + "/"
+}
+
 func (r *TryOnRequest) CreatedAt() time.Time {
 	return r.createdAt
 }
 
-func (r *TryOnRequest) PrepareImages() error {
+// PrepareImages 外部APIに送る前に人物画像と衣服画像を整える
+func (r *TryOnRequest) PrepareImages(opts valueobjects.NormalizeOptions) error {
 	var err error
-	
-	r.personImage, err = r.personImage.ToJPEG()
+
+	r.personImage, err = r.personImage.Normalize(opts)
 	if err != nil {
-		return fmt.Errorf("failed to convert person image to JPEG: %w", err)
+		return fmt.Errorf("failed to normalize person image: %w", err)
 	}
 
-	r.garmentImage, err = r.garmentImage.ToJPEG()
+	r.garmentImage, err = r.garmentImage.Normalize(opts)
 	if err != nil {
-		return fmt.Errorf("failed to convert garment image to JPEG: %w", err)
+		return fmt.Errorf("failed to normalize garment image: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
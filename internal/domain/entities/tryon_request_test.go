@@ -17,7 +17,7 @@ func createTestImageData(t *testing.T) *valueobjects.ImageData {
 		t.Fatalf("Failed to create test image: %v", err)
 	}
 
-	imageData, err := valueobjects.NewImageData(buf.Bytes(), "image/jpeg")
+	imageData, err := valueobjects.NewImageData(buf.Bytes())
 	if err != nil {
 		t.Fatalf("Failed to create ImageData: %v", err)
 	}
@@ -102,7 +102,7 @@ func TestTryOnRequest_PrepareImages(t *testing.T) {
 		t.Fatalf("Failed to create request: %v", err)
 	}
 
-	err = request.PrepareImages()
+	err = request.PrepareImages(valueobjects.DefaultNormalizeOptions())
 	if err != nil {
 		t.Errorf("PrepareImages() error = %v", err)
 	}
@@ -13,12 +13,14 @@ type TryOnResult struct {
 	id        TryOnResultID
 	requestID TryOnRequestID
 	images    []*valueobjects.ImageData
-	createdAt time.Time
+	// 保存先（Storage URI）を指定した場合の生成画像のURI。画像データは含まない
+	outputURIs []string
+	createdAt  time.Time
 }
 
 func NewTryOnResult(requestID TryOnRequestID, images []*valueobjects.ImageData) *TryOnResult {
 	id := TryOnResultID(fmt.Sprintf("result_%d", time.Now().UnixNano()))
-	
+
 	return &TryOnResult{
 		id:        id,
 		requestID: requestID,
@@ -27,6 +29,35 @@ func NewTryOnResult(requestID TryOnRequestID, images []*valueobjects.ImageData)
 	}
 }
 
+// NewTryOnResultWithURIs 生成画像がオブジェクトストレージに保存された場合の結果
+func NewTryOnResultWithURIs(requestID TryOnRequestID, outputURIs []string) *TryOnResult {
+	id := TryOnResultID(fmt.Sprintf("result_%d", time.Now().UnixNano()))
+
+	return &TryOnResult{
+		id:         id,
+		requestID:  requestID,
+		outputURIs: outputURIs,
+		createdAt:  time.Now(),
+	}
+}
+
+// RestoreTryOnResult 保存済みのデータから結果を復元する
+func RestoreTryOnResult(
+	id TryOnResultID,
+	requestID TryOnRequestID,
+	images []*valueobjects.ImageData,
+	outputURIs []string,
+	createdAt time.Time,
+) *TryOnResult {
+	return &TryOnResult{
+		id:         id,
+		requestID:  requestID,
+		images:     images,
+		outputURIs: outputURIs,
+		createdAt:  createdAt,
+	}
+}
+
 func (r *TryOnResult) ID() TryOnResultID {
 	return r.id
 }
@@ -39,10 +70,20 @@ func (r *TryOnResult) Images() []*valueobjects.ImageData {
 	return r.images
 }
 
+// OutputURIs 保存先に書き込まれた生成画像のURI
+func (r *TryOnResult) OutputURIs() []string {
+	return r.outputURIs
+}
+
 func (r *TryOnResult) CreatedAt() time.Time {
 	return r.createdAt
 }
 
 func (r *TryOnResult) HasImages() bool {
 	return len(r.images) > 0
-}
\ No newline at end of file
+}
+
+// HasOutputs 画像データまたは保存先のURIのどちらかがあるかどうか
+func (r *TryOnResult) HasOutputs() bool {
+	return len(r.images) > 0 || len(r.outputURIs) > 0
+}
@@ -0,0 +1,51 @@
+package entities
+
+import "time"
+
+// AnonymousClient クライアントを識別できないリクエストの集計先
+const AnonymousClient = "anonymous"
+
+// TokenUsage Geminiの呼び出し1回で使ったトークン数（レスポンスの usage metadata）
+type TokenUsage struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Items 課金対象の数量にする（0の項目は含めない）
+func (u TokenUsage) Items() []UsageItem {
+	var items []UsageItem
+	if u.InputTokens > 0 {
+		items = append(items, UsageItem{Model: u.Model, Unit: PriceUnitInputToken, Quantity: float64(u.InputTokens)})
+	}
+	if u.OutputTokens > 0 {
+		items = append(items, UsageItem{Model: u.Model, Unit: PriceUnitOutputToken, Quantity: float64(u.OutputTokens)})
+	}
+	return items
+}
+
+// UsageItem 課金対象の数量（生成した画像の枚数、動画の秒数、トークン数など）
+type UsageItem struct {
+	Model    string
+	Unit     PriceUnit
+	Quantity float64
+}
+
+// UsageTotal 1日・1クライアント・1モデル・1単位ごとの利用量の合計
+type UsageTotal struct {
+	// 集計日（YYYY-MM-DD）
+	Day      string
+	Client   string
+	Model    string
+	Unit     PriceUnit
+	Quantity float64
+	// 料金の見積もり（USD）。料金表にない場合は0
+	Cost float64
+	// 料金表に単価があったか
+	Priced bool
+}
+
+// UsageDay 集計日の表記
+func UsageDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
@@ -0,0 +1,174 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+type VeoJobID string
+
+// 動画生成ジョブの状態
+type VeoJobStatus string
+
+const (
+	VeoJobStatusQueued    VeoJobStatus = "queued"
+	VeoJobStatusRunning   VeoJobStatus = "running"
+	VeoJobStatusSucceeded VeoJobStatus = "succeeded"
+	VeoJobStatusFailed    VeoJobStatus = "failed"
+	VeoJobStatusCanceled  VeoJobStatus = "canceled"
+)
+
+// 動画生成ジョブの処理段階
+type VeoJobStage string
+
+const (
+	VeoJobStageQueued          VeoJobStage = "queued"
+	VeoJobStageGeneratingImage VeoJobStage = "generating_image"
+	VeoJobStageGeneratingVideo VeoJobStage = "generating_video"
+	VeoJobStageCompleted       VeoJobStage = "completed"
+)
+
+// VeoJob 非同期で実行される動画生成ジョブ
+type VeoJob struct {
+	id        VeoJobID
+	model     string
+	client    string // ジョブを登録したクライアント（APIキーのIDなど）
+	status    VeoJobStatus
+	stage     VeoJobStage
+	progress  int // 0〜100
+	videos    [][]byte
+	errorMsg  string
+	errorKind domainerrors.Kind
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func NewVeoJob(model string) *VeoJob {
+	now := time.Now()
+	return &VeoJob{
+		id:        VeoJobID("veojob_" + newRandomID()),
+		model:     model,
+		status:    VeoJobStatusQueued,
+		stage:     VeoJobStageQueued,
+		progress:  0,
+		createdAt: now,
+		updatedAt: now,
+	}
+}
+
+func (j *VeoJob) ID() VeoJobID {
+	return j.id
+}
+
+func (j *VeoJob) Model() string {
+	return j.model
+}
+
+func (j *VeoJob) Client() string {
+	return j.client
+}
+
+func (j *VeoJob) SetClient(client string) {
+	j.client = client
+}
+
+func (j *VeoJob) Status() VeoJobStatus {
+	return j.status
+}
+
+func (j *VeoJob) Stage() VeoJobStage {
+	return j.stage
+}
+
+func (j *VeoJob) Progress() int {
+	return j.progress
+}
+
+func (j *VeoJob) Videos() [][]byte {
+	return j.videos
+}
+
+func (j *VeoJob) ErrorMessage() string {
+	return j.errorMsg
+}
+
+// ErrorKind 失敗したジョブのエラーの種類
+func (j *VeoJob) ErrorKind() domainerrors.Kind {
+	return j.errorKind
+}
+
+func (j *VeoJob) CreatedAt() time.Time {
+	return j.createdAt
+}
+
+func (j *VeoJob) UpdatedAt() time.Time {
+	return j.updatedAt
+}
+
+// IsFinished 終了状態（成功・失敗・キャンセル）かどうか
+func (j *VeoJob) IsFinished() bool {
+	switch j.status {
+	case VeoJobStatusSucceeded, VeoJobStatusFailed, VeoJobStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// UpdateProgress 処理段階と進捗率を更新する。終了済みのジョブは変更しない。
+func (j *VeoJob) UpdateProgress(stage VeoJobStage, progress int) {
+	if j.IsFinished() {
+		return
+	}
+
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+
+	j.status = VeoJobStatusRunning
+	j.stage = stage
+	j.progress = progress
+	j.updatedAt = time.Now()
+}
+
+func (j *VeoJob) Succeed(videos [][]byte) error {
+	if j.IsFinished() {
+		return fmt.Errorf("job %s is already finished: %s", j.id, j.status)
+	}
+
+	j.status = VeoJobStatusSucceeded
+	j.stage = VeoJobStageCompleted
+	j.progress = 100
+	j.videos = videos
+	j.updatedAt = time.Now()
+	return nil
+}
+
+func (j *VeoJob) Fail(err error) error {
+	if j.IsFinished() {
+		return fmt.Errorf("job %s is already finished: %s", j.id, j.status)
+	}
+
+	j.status = VeoJobStatusFailed
+	if err != nil {
+		j.errorMsg = err.Error()
+		j.errorKind = domainerrors.KindOf(err)
+	}
+	j.updatedAt = time.Now()
+	return nil
+}
+
+func (j *VeoJob) Cancel() error {
+	if j.IsFinished() {
+		return fmt.Errorf("job %s is already finished: %s", j.id, j.status)
+	}
+
+	j.status = VeoJobStatusCanceled
+	j.updatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+func TestNewVeoJob(t *testing.T) {
+	job := NewVeoJob("veo-3.0-generate-preview")
+
+	if job.ID() == "" {
+		t.Errorf("Expected non-empty ID")
+	}
+	if job.Status() != VeoJobStatusQueued {
+		t.Errorf("Expected status queued, got %v", job.Status())
+	}
+	if job.Progress() != 0 {
+		t.Errorf("Expected progress 0, got %d", job.Progress())
+	}
+	if job.IsFinished() {
+		t.Errorf("New job should not be finished")
+	}
+}
+
+func TestNewVeoJob_UniqueIDs(t *testing.T) {
+	seen := make(map[VeoJobID]bool)
+	for range 1000 {
+		id := NewVeoJob("veo-3.0-generate-preview").ID()
+		if seen[id] {
+			t.Fatalf("duplicate job ID %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestVeoJob_UpdateProgress(t *testing.T) {
+	job := NewVeoJob("veo-3.0-generate-preview")
+
+	job.UpdateProgress(VeoJobStageGeneratingVideo, 150)
+
+	if job.Status() != VeoJobStatusRunning {
+		t.Errorf("Expected status running, got %v", job.Status())
+	}
+	if job.Stage() != VeoJobStageGeneratingVideo {
+		t.Errorf("Expected stage generating_video, got %v", job.Stage())
+	}
+	if job.Progress() != 100 {
+		t.Errorf("Expected progress to be clamped to 100, got %d", job.Progress())
+	}
+}
+
+func TestVeoJob_Transitions(t *testing.T) {
+	t.Run("succeed", func(t *testing.T) {
+		job := NewVeoJob("veo-3.0-generate-preview")
+		if err := job.Succeed([][]byte{{0x00}}); err != nil {
+			t.Fatalf("Succeed() error = %v", err)
+		}
+		if job.Status() != VeoJobStatusSucceeded || job.Progress() != 100 {
+			t.Errorf("Unexpected state: status=%v progress=%d", job.Status(), job.Progress())
+		}
+		if len(job.Videos()) != 1 {
+			t.Errorf("Expected 1 video, got %d", len(job.Videos()))
+		}
+	})
+
+	t.Run("fail keeps error message", func(t *testing.T) {
+		job := NewVeoJob("veo-3.0-generate-preview")
+		if err := job.Fail(errors.New("boom")); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+		if job.Status() != VeoJobStatusFailed || job.ErrorMessage() != "boom" {
+			t.Errorf("Unexpected state: status=%v error=%q", job.Status(), job.ErrorMessage())
+		}
+		if job.ErrorKind() != domainerrors.KindInternal {
+			t.Errorf("Expected error kind internal, got %v", job.ErrorKind())
+		}
+	})
+
+	t.Run("failed job keeps error kind", func(t *testing.T) {
+		job := NewVeoJob("veo-3.0-generate-preview")
+		cause := domainerrors.New(domainerrors.KindSafetyBlocked, "all videos were filtered")
+		if err := job.Fail(fmt.Errorf("veo generation failed: %w", cause)); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+		if job.ErrorKind() != domainerrors.KindSafetyBlocked {
+			t.Errorf("Expected error kind safety_blocked, got %v", job.ErrorKind())
+		}
+	})
+
+	t.Run("finished job cannot change", func(t *testing.T) {
+		job := NewVeoJob("veo-3.0-generate-preview")
+		if err := job.Cancel(); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		if err := job.Succeed(nil); err == nil {
+			t.Errorf("Expected error when succeeding a canceled job")
+		}
+		job.UpdateProgress(VeoJobStageGeneratingVideo, 50)
+		if job.Status() != VeoJobStatusCanceled {
+			t.Errorf("Canceled job should stay canceled, got %v", job.Status())
+		}
+	})
+}
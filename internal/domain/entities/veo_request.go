@@ -1,6 +1,10 @@
 package entities
 
-import "tryon-demo/internal/domain/valueobjects"
+import (
+	"fmt"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
 
 type VeoRequest struct {
 	// 複数画像は非対応。動画を生成する初期画像を指定するのみ。
@@ -39,3 +43,13 @@ func (r *VeoRequest) SetVideoPrompt(videoPrompt string) {
 func (r *VeoRequest) VeoModel() string {
 	return r.veoModel
 }
+
+// PrepareImages 外部APIに送る前に初期画像を整える
+func (r *VeoRequest) PrepareImages(opts valueobjects.NormalizeOptions) error {
+	image, err := r.images.Normalize(opts)
+	if err != nil {
+		return fmt.Errorf("failed to normalize image: %w", err)
+	}
+	r.images = image
+	return nil
+}
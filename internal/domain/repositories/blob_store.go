@@ -0,0 +1,12 @@
+package repositories
+
+import "context"
+
+// BlobStore 画像・動画などのバイナリをコンテンツアドレス（ハッシュ値）で保存する
+type BlobStore interface {
+	// Put データを保存し、内容から算出したキーを返す。同じ内容は同じキーになる。
+	Put(ctx context.Context, data []byte) (string, error)
+
+	// Get キーに対応するデータを取得する
+	Get(ctx context.Context, key string) ([]byte, error)
+}
@@ -0,0 +1,6 @@
+package repositories
+
+import "errors"
+
+// ErrNotFound 対象のデータが存在しない場合に返す
+var ErrNotFound = errors.New("not found")
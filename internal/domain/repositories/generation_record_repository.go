@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// 生成履歴の検索条件
+type GenerationRecordFilter struct {
+	// 空の場合は全ての生成機能を対象とする
+	GeneratorType entities.GeneratorType
+	// 空の場合は全てのクライアントを対象とする
+	Client string
+	Offset int
+	Limit  int
+}
+
+// 生成履歴の保存先
+type GenerationRecordRepository interface {
+	Save(ctx context.Context, record *entities.GenerationRecord) error
+	FindByID(ctx context.Context, id entities.GenerationRecordID) (*entities.GenerationRecord, error)
+
+	// List 新しい順に履歴を返す。2つ目の戻り値はフィルタに一致する総件数。
+	List(ctx context.Context, filter GenerationRecordFilter) ([]*entities.GenerationRecord, int, error)
+}
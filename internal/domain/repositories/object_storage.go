@@ -0,0 +1,15 @@
+package repositories
+
+import "context"
+
+// ObjectStorage URI（例: gs://bucket/path）で指定するオブジェクトストレージ
+type ObjectStorage interface {
+	// Supports URIのスキームを扱えるかどうか
+	Supports(uri string) bool
+
+	// Get オブジェクトを取得する。存在しない場合は ErrNotFound を返す
+	Get(ctx context.Context, uri string) ([]byte, error)
+
+	// Put オブジェクトを保存する。同じURIのオブジェクトは上書きする
+	Put(ctx context.Context, uri string, data []byte, contentType string) error
+}
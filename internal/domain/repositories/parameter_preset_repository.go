@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// ParameterPresetRepository 利用者が保存したプリセットの保存先（組み込みのプリセットは含まない）
+type ParameterPresetRepository interface {
+	Save(ctx context.Context, preset *entities.ParameterPreset) error
+	FindByID(ctx context.Context, id entities.ParameterPresetID) (*entities.ParameterPreset, error)
+
+	// List 作成順にプリセットを返す。generatorType が空の場合は全ての生成機能を対象とする
+	List(ctx context.Context, generatorType entities.GeneratorType) ([]*entities.ParameterPreset, error)
+
+	// Delete プリセットを削除する。存在しない場合は ErrNotFound を返す
+	Delete(ctx context.Context, id entities.ParameterPresetID) error
+}
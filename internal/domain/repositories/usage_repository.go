@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// 利用量の集計の検索条件
+type UsageFilter struct {
+	// 集計日の範囲（YYYY-MM-DD、両端を含む）
+	From string
+	To   string
+	// 空の場合は全てのクライアントを対象とする
+	Client string
+}
+
+// 1日・クライアントごとの利用量の集計の保存先
+type UsageRepository interface {
+	// Add 集計に加える。同じ日・クライアント・モデル・単位の集計があれば数量と料金を足す
+	Add(ctx context.Context, totals []entities.UsageTotal) error
+
+	// List 集計日・クライアント・モデル・単位の順に返す
+	List(ctx context.Context, filter UsageFilter) ([]entities.UsageTotal, error)
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// 動画生成ジョブの保存先
+type VeoJobRepository interface {
+	Save(ctx context.Context, job *entities.VeoJob) error
+	FindByID(ctx context.Context, id entities.VeoJobID) (*entities.VeoJob, error)
+}
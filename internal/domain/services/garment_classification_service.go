@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+const (
+	// この確信度以上で「衣服ではない」と判定された画像は試着前に拒否する
+	NonGarmentRejectConfidence = 0.8
+	// この確信度未満の種類は使わず、種類不明として扱う
+	MinGarmentCategoryConfidence = 0.5
+)
+
+const garmentClassificationPrompt = `You are classifying a product image for a virtual try-on service.
+Decide whether the image shows a single wearable item and which category it belongs to.
+
+Categories:
+- "tops": shirts, t-shirts, blouses, sweaters, jackets, coats, dresses
+- "bottoms": pants, jeans, shorts, skirts
+- "shoes": any footwear
+- "accessories": necklaces, bracelets, earrings, scarves
+- "other": wearable items that do not fit the categories above (e.g. hats, bags, glasses)
+
+Respond with JSON only, in this exact form:
+{"isGarment": true, "category": "tops", "label": "t-shirt", "confidence": 0.95}
+
+- "isGarment" is false if the image does not show a wearable item (e.g. a person, an animal, a landscape, a document).
+- "label" is a short English name of the item.
+- "confidence" is a number between 0 and 1 for the category (or for "isGarment": false).`
+
+// GarmentClassificationService 衣服画像の種類を画像認識で判定する
+type GarmentClassificationService struct {
+	textAIService repositories.TextAIService
+}
+
+func NewGarmentClassificationService(textAIService repositories.TextAIService) *GarmentClassificationService {
+	return &GarmentClassificationService{
+		textAIService: textAIService,
+	}
+}
+
+// garmentClassificationResponse モデルが返すJSON
+type garmentClassificationResponse struct {
+	IsGarment  *bool   `json:"isGarment"`
+	Category   string  `json:"category"`
+	Label      string  `json:"label"`
+	Confidence float64 `json:"confidence"`
+}
+
+func (s *GarmentClassificationService) Classify(
+	ctx context.Context,
+	image *valueobjects.ImageData,
+) (*valueobjects.GarmentClassification, error) {
+	if image == nil {
+		return nil, fmt.Errorf("image is required")
+	}
+
+	request := entities.NewImageTextRequest(
+		garmentClassificationPrompt,
+		"",
+		[]*valueobjects.ImageData{image},
+		"application/json",
+	)
+
+	result, err := s.textAIService.GenerateText(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("garment classification failed: %w", err)
+	}
+
+	return parseGarmentClassification(result.Text())
+}
+
+// IsRejected 試着前に拒否すべき（衣服ではないと確信できる）分類結果か
+func (s *GarmentClassificationService) IsRejected(classification *valueobjects.GarmentClassification) bool {
+	return !classification.IsGarment() && classification.Confidence() >= NonGarmentRejectConfidence
+}
+
+func parseGarmentClassification(text string) (*valueobjects.GarmentClassification, error) {
+	var response garmentClassificationResponse
+	if err := json.Unmarshal([]byte(trimJSONResponse(text)), &response); err != nil {
+		return nil, fmt.Errorf("invalid classification response: %w", err)
+	}
+	if response.IsGarment == nil {
+		return nil, fmt.Errorf("invalid classification response: isGarment is missing")
+	}
+
+	category := valueobjects.ParseGarmentCategory(response.Category)
+	if response.Confidence < MinGarmentCategoryConfidence {
+		category = valueobjects.GarmentCategoryUnknown
+	}
+
+	return valueobjects.NewGarmentClassification(category, response.Confidence, *response.IsGarment, response.Label)
+}
+
+// trimJSONResponse モデルの応答からJSON部分を取り出す（コードブロックで囲まれて返ってくる場合がある）
+func trimJSONResponse(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	return strings.TrimSpace(text)
+}
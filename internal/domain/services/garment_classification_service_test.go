@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+type mockTextAIService struct {
+	text    string
+	err     error
+	request *entities.TextRequest
+}
+
+func (m *mockTextAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	m.request = request
+	if m.err != nil {
+		return nil, m.err
+	}
+	return entities.NewTextResult(m.text), nil
+}
+
+func (m *mockTextAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	return entities.NewTextResult(request.Prompt()), nil
+}
+
+func TestGarmentClassificationService_Classify(t *testing.T) {
+	image := createTestImageData(t)
+
+	tests := []struct {
+		name          string
+		text          string
+		err           error
+		wantErr       bool
+		wantCategory  valueobjects.GarmentCategory
+		wantGarment   bool
+		wantSupported bool
+		wantRejected  bool
+	}{
+		{
+			name:          "tops",
+			text:          `{"isGarment": true, "category": "tops", "label": "t-shirt", "confidence": 0.93}`,
+			wantCategory:  valueobjects.GarmentCategoryTops,
+			wantGarment:   true,
+			wantSupported: true,
+		},
+		{
+			name:          "code fenced response",
+			text:          "```json\n{\"isGarment\": true, \"category\": \"pants\", \"label\": \"jeans\", \"confidence\": 0.8}\n```",
+			wantCategory:  valueobjects.GarmentCategoryBottoms,
+			wantGarment:   true,
+			wantSupported: true,
+		},
+		{
+			name:         "unsupported item",
+			text:         `{"isGarment": true, "category": "other", "label": "hat", "confidence": 0.9}`,
+			wantCategory: valueobjects.GarmentCategoryUnknown,
+			wantGarment:  true,
+		},
+		{
+			name:         "low confidence category is ignored",
+			text:         `{"isGarment": true, "category": "shoes", "label": "boots", "confidence": 0.3}`,
+			wantCategory: valueobjects.GarmentCategoryUnknown,
+			wantGarment:  true,
+		},
+		{
+			name:         "not a garment",
+			text:         `{"isGarment": false, "category": "other", "label": "dog", "confidence": 0.97}`,
+			wantCategory: valueobjects.GarmentCategoryUnknown,
+			wantRejected: true,
+		},
+		{
+			name:         "uncertain non-garment is not rejected",
+			text:         `{"isGarment": false, "category": "other", "label": "pattern", "confidence": 0.6}`,
+			wantCategory: valueobjects.GarmentCategoryUnknown,
+		},
+		{
+			name:    "missing isGarment",
+			text:    `{"category": "tops", "confidence": 0.9}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			text:    "It is a shirt.",
+			wantErr: true,
+		},
+		{
+			name:    "confidence out of range",
+			text:    `{"isGarment": true, "category": "tops", "confidence": 1.5}`,
+			wantErr: true,
+		},
+		{
+			name:    "text service error",
+			err:     errors.New("upstream failed"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			textService := &mockTextAIService{text: tt.text, err: tt.err}
+			service := NewGarmentClassificationService(textService)
+
+			classification, err := service.Classify(context.Background(), image)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Classify() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+
+			if got := classification.Category(); got != tt.wantCategory {
+				t.Errorf("Category() = %q, want %q", got, tt.wantCategory)
+			}
+			if got := classification.IsGarment(); got != tt.wantGarment {
+				t.Errorf("IsGarment() = %v, want %v", got, tt.wantGarment)
+			}
+			if got := classification.IsSupported(); got != tt.wantSupported {
+				t.Errorf("IsSupported() = %v, want %v", got, tt.wantSupported)
+			}
+			if got := service.IsRejected(classification); got != tt.wantRejected {
+				t.Errorf("IsRejected() = %v, want %v", got, tt.wantRejected)
+			}
+
+			if len(textService.request.Images()) != 1 || textService.request.ResponseMimeType() != "application/json" {
+				t.Errorf("request should include the image and ask for JSON")
+			}
+		})
+	}
+}
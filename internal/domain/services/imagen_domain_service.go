@@ -2,9 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
 )
@@ -29,7 +30,7 @@ func (s *ImagenDomainService) ProcessImagen(
 	request *entities.ImagenRequest,
 ) (*entities.ImagenResult, error) {
 	if err := s.validateRequest(request); err != nil {
-		return nil, fmt.Errorf("request validation failed: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "request validation failed")
 	}
 
 	// プロンプトを英語に翻訳
@@ -43,7 +44,7 @@ func (s *ImagenDomainService) ProcessImagen(
 
 	result, err := s.imageAIService.GenerateImage(ctx, request)
 	if err != nil {
-		if s.isQuotaError(err) {
+		if errors.Is(err, domainerrors.ErrQuotaExhausted) {
 			return nil, fmt.Errorf("service temporarily unavailable due to high demand: %w", err)
 		}
 		return nil, fmt.Errorf("imagen generation failed: %w", err)
@@ -65,11 +66,3 @@ func (s *ImagenDomainService) validateRequest(request *entities.ImagenRequest) e
 	return nil
 }
 
-func (s *ImagenDomainService) isQuotaError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "quota exceeded") ||
-		strings.Contains(errStr, "resourceexhausted")
-}
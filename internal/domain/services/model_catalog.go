@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+var (
+	// ErrModelNotFound カタログに登録されていないモデル
+	ErrModelNotFound = errors.New("model not found")
+	// ErrModelGeneratorMismatch 別の生成機能のモデル
+	ErrModelGeneratorMismatch = errors.New("model belongs to another generator")
+)
+
+// ModelCatalog 生成機能ごとに使えるモデルと既定のモデル。
+// 入力の検証・画面の選択肢・モデル一覧のAPIはすべてここを参照する。起動後に登録・削除しても安全に使える。
+type ModelCatalog struct {
+	mu sync.RWMutex
+	// 登録順（画面の選択肢の並び順）
+	models []entities.GenerativeModel
+	// 生成機能ごとの既定のモデル（未設定の場合は最初に登録したモデル）
+	defaults map[entities.GeneratorType]string
+}
+
+func NewModelCatalog() *ModelCatalog {
+	return &ModelCatalog{
+		defaults: make(map[entities.GeneratorType]string),
+	}
+}
+
+// DefaultModelCatalog 組み込みのモデル。料金はリストの価格の目安
+func DefaultModelCatalog() *ModelCatalog {
+	imagenCapabilities := entities.ModelCapabilities{
+		MaxImages:    entities.MaxImagenNumberOfImages,
+		AspectRatios: entities.ImagenAspectRatios,
+		Seed:         true,
+	}
+
+	catalog := NewModelCatalog()
+	for _, model := range []entities.GenerativeModel{
+		{
+			ID:           "virtual-try-on-preview-08-04",
+			Name:         "Virtual Try-On Preview",
+			Description:  "バーチャル試着",
+			Generator:    entities.GeneratorTryOn,
+			Capabilities: entities.ModelCapabilities{MaxImages: 4, Seed: true},
+			// 1回あたり約20円
+			Price: &entities.ModelPrice{Amount: 0.13, Unit: entities.PriceUnitImage},
+		},
+		{
+			ID:           "imagen-4.0-ultra-generate-001",
+			Name:         "Imagen 4.0 Ultra",
+			Description:  "最高品質・最新モデル（処理時間長）",
+			Generator:    entities.GeneratorImagen,
+			Capabilities: imagenCapabilities,
+			Price:        &entities.ModelPrice{Amount: 0.06, Unit: entities.PriceUnitImage},
+		},
+		{
+			ID:           "imagen-4.0-fast-generate-001",
+			Name:         "Imagen 4.0 Fast",
+			Description:  "高品質・高速処理",
+			Generator:    entities.GeneratorImagen,
+			Capabilities: imagenCapabilities,
+			Price:        &entities.ModelPrice{Amount: 0.02, Unit: entities.PriceUnitImage},
+		},
+		{
+			ID:           "imagen-4.0-generate-001",
+			Name:         "Imagen 4.0",
+			Description:  "高品質・標準処理",
+			Generator:    entities.GeneratorImagen,
+			Capabilities: imagenCapabilities,
+			Price:        &entities.ModelPrice{Amount: 0.04, Unit: entities.PriceUnitImage},
+		},
+		{
+			ID:           "imagen-3.0-generate-002",
+			Name:         "Imagen 3.0 v2",
+			Description:  "安定版（推奨）",
+			Generator:    entities.GeneratorImagen,
+			Capabilities: imagenCapabilities,
+			Price:        &entities.ModelPrice{Amount: 0.04, Unit: entities.PriceUnitImage},
+		},
+		{
+			ID:          "veo-3.0-generate-preview",
+			Name:        "Veo 3.0 Preview",
+			Description: "最新動画生成モデル（プレビュー版）",
+			Generator:   entities.GeneratorVeo,
+			Capabilities: entities.ModelCapabilities{
+				MaxInputImages: 1, AspectRatios: []string{"16:9"}, Seed: true, Audio: true, CandidateCount: 1,
+			},
+			Price: &entities.ModelPrice{Amount: 0.75, Unit: entities.PriceUnitSecond},
+		},
+		{
+			ID:          "veo-3.0-fast-generate-preview",
+			Name:        "Veo 3.0 Fast",
+			Description: "最新動画生成モデル（高速版）",
+			Generator:   entities.GeneratorVeo,
+			Capabilities: entities.ModelCapabilities{
+				MaxInputImages: 1, AspectRatios: []string{"16:9"}, Seed: true, Audio: true, CandidateCount: 1,
+			},
+			Price: &entities.ModelPrice{Amount: 0.40, Unit: entities.PriceUnitSecond},
+		},
+		{
+			ID:          "veo-2.0-generate-001",
+			Name:        "Veo 2.0",
+			Description: "動画生成モデル（旧バージョン）",
+			Generator:   entities.GeneratorVeo,
+			Capabilities: entities.ModelCapabilities{
+				MaxInputImages: 1, AspectRatios: []string{"16:9", "9:16"}, Seed: true, CandidateCount: 1,
+			},
+			Price: &entities.ModelPrice{Amount: 0.50, Unit: entities.PriceUnitSecond},
+		},
+		{
+			ID:           "gemini-2.5-flash-image-preview",
+			Name:         "Gemini 2.5 Flash Image",
+			Description:  "複数の画像とプロンプトによる画像編集",
+			Generator:    entities.GeneratorNanobanana,
+			Capabilities: entities.ModelCapabilities{MaxImages: 1, MaxInputImages: 3, CandidateCount: 1},
+			Price:        &entities.ModelPrice{Amount: 0.039, Unit: entities.PriceUnitImage},
+		},
+	} {
+		if err := catalog.Register(model); err != nil {
+			panic(err)
+		}
+	}
+
+	catalog.defaults[entities.GeneratorImagen] = "imagen-3.0-generate-002"
+	return catalog
+}
+
+// Register モデルを登録する。同じIDのモデルがある場合は並び順を保ったまま置き換える
+func (c *ModelCatalog) Register(model entities.GenerativeModel) error {
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	model.Capabilities.AspectRatios = slices.Clone(model.Capabilities.AspectRatios)
+	if model.Price != nil {
+		price := *model.Price
+		model.Price = &price
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i := c.indexOf(model.ID); i >= 0 {
+		// 別の生成機能に変わった場合、元の生成機能の既定からは外す
+		if previous := c.models[i]; previous.Generator != model.Generator && c.defaults[previous.Generator] == model.ID {
+			delete(c.defaults, previous.Generator)
+		}
+		c.models[i] = model
+		return nil
+	}
+	c.models = append(c.models, model)
+	return nil
+}
+
+// Unregister モデルを削除する。既定のモデルだった場合は、その生成機能の最初のモデルが既定になる
+func (c *ModelCatalog) Unregister(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrModelNotFound, id)
+	}
+	if generator := c.models[i].Generator; c.defaults[generator] == id {
+		delete(c.defaults, generator)
+	}
+	c.models = slices.Delete(c.models, i, i+1)
+	return nil
+}
+
+// SetDefault 生成機能の既定のモデルを変える
+func (c *ModelCatalog) SetDefault(generator entities.GeneratorType, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrModelNotFound, id)
+	}
+	if c.models[i].Generator != generator {
+		return fmt.Errorf("%w: %q is a %s model", ErrModelGeneratorMismatch, id, c.models[i].Generator)
+	}
+	c.defaults[generator] = id
+	return nil
+}
+
+// Find IDでモデルを探す
+func (c *ModelCatalog) Find(id string) (entities.GenerativeModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if i := c.indexOf(id); i >= 0 {
+		return c.models[i], true
+	}
+	return entities.GenerativeModel{}, false
+}
+
+// Models 生成機能のモデルを登録順に返す（generator が空の場合はすべて）
+func (c *ModelCatalog) Models(generator entities.GeneratorType) []entities.GenerativeModel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var models []entities.GenerativeModel
+	for _, model := range c.models {
+		if generator == "" || model.Generator == generator {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// Default 生成機能の既定のモデル（1つも登録されていない場合は false）
+func (c *ModelCatalog) Default(generator entities.GeneratorType) (entities.GenerativeModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if i := c.indexOf(c.defaults[generator]); i >= 0 {
+		return c.models[i], true
+	}
+	for _, model := range c.models {
+		if model.Generator == generator {
+			return model, true
+		}
+	}
+	return entities.GenerativeModel{}, false
+}
+
+// Resolve リクエストで指定されたモデルを返す。id が空の場合は既定のモデルを使う
+func (c *ModelCatalog) Resolve(generator entities.GeneratorType, id string) (entities.GenerativeModel, error) {
+	if id == "" {
+		model, ok := c.Default(generator)
+		if !ok {
+			return entities.GenerativeModel{}, fmt.Errorf("%w: no %s model is registered", ErrModelNotFound, generator)
+		}
+		return model, nil
+	}
+
+	model, ok := c.Find(id)
+	if !ok {
+		return entities.GenerativeModel{}, fmt.Errorf("%w: %q", ErrModelNotFound, id)
+	}
+	if model.Generator != generator {
+		return entities.GenerativeModel{}, fmt.Errorf("%w: %q is a %s model", ErrModelGeneratorMismatch, id, model.Generator)
+	}
+	return model, nil
+}
+
+func (c *ModelCatalog) indexOf(id string) int {
+	return slices.IndexFunc(c.models, func(model entities.GenerativeModel) bool {
+		return model.ID == id
+	})
+}
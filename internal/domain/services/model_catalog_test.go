@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func TestDefaultModelCatalog(t *testing.T) {
+	catalog := DefaultModelCatalog()
+
+	defaults := map[entities.GeneratorType]string{
+		entities.GeneratorTryOn:      "virtual-try-on-preview-08-04",
+		entities.GeneratorImagen:     "imagen-3.0-generate-002",
+		entities.GeneratorVeo:        "veo-3.0-generate-preview",
+		entities.GeneratorNanobanana: "gemini-2.5-flash-image-preview",
+	}
+	for generator, want := range defaults {
+		model, ok := catalog.Default(generator)
+		if !ok || model.ID != want {
+			t.Errorf("Default(%s) = %q, %v, want %q", generator, model.ID, ok, want)
+		}
+	}
+
+	for _, model := range catalog.Models("") {
+		if err := model.Validate(); err != nil {
+			t.Errorf("built-in model is invalid: %v", err)
+		}
+		if model.Price == nil {
+			t.Errorf("built-in model %q has no price", model.ID)
+		}
+	}
+
+	if got := len(catalog.Models(entities.GeneratorImagen)); got != 4 {
+		t.Errorf("imagen models = %d, want 4", got)
+	}
+}
+
+func TestModelCatalog_Resolve(t *testing.T) {
+	catalog := DefaultModelCatalog()
+
+	tests := []struct {
+		name      string
+		generator entities.GeneratorType
+		id        string
+		want      string
+		wantErr   error
+	}{
+		{name: "default", generator: entities.GeneratorImagen, want: "imagen-3.0-generate-002"},
+		{name: "explicit", generator: entities.GeneratorVeo, id: "veo-2.0-generate-001", want: "veo-2.0-generate-001"},
+		{name: "unknown", generator: entities.GeneratorImagen, id: "imagen-9", wantErr: ErrModelNotFound},
+		{name: "other generator", generator: entities.GeneratorImagen, id: "veo-2.0-generate-001", wantErr: ErrModelGeneratorMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := catalog.Resolve(tt.generator, tt.id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if model.ID != tt.want {
+				t.Errorf("Resolve() = %q, want %q", model.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelCatalog_RegisterAndUnregister(t *testing.T) {
+	catalog := NewModelCatalog()
+
+	if err := catalog.Register(entities.GenerativeModel{ID: "a", Generator: "unknown"}); err == nil {
+		t.Fatal("Register() with an unknown generator should fail")
+	}
+
+	for _, id := range []string{"a", "b"} {
+		if err := catalog.Register(entities.GenerativeModel{ID: id, Generator: entities.GeneratorImagen}); err != nil {
+			t.Fatalf("Register(%q) error = %v", id, err)
+		}
+	}
+	if err := catalog.SetDefault(entities.GeneratorImagen, "b"); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if err := catalog.SetDefault(entities.GeneratorVeo, "a"); !errors.Is(err, ErrModelGeneratorMismatch) {
+		t.Errorf("SetDefault() with another generator error = %v", err)
+	}
+
+	// 置き換えても並び順は変わらない
+	if err := catalog.Register(entities.GenerativeModel{ID: "a", Name: "A", Generator: entities.GeneratorImagen}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	models := catalog.Models(entities.GeneratorImagen)
+	if len(models) != 2 || models[0].ID != "a" || models[0].Name != "A" {
+		t.Errorf("Models() = %+v", models)
+	}
+
+	if err := catalog.Unregister("b"); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if model, _ := catalog.Default(entities.GeneratorImagen); model.ID != "a" {
+		t.Errorf("Default() after removing the default = %q, want a", model.ID)
+	}
+	if err := catalog.Unregister("b"); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Unregister() twice error = %v", err)
+	}
+}
@@ -4,22 +4,28 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
 type NanobananaDomainService struct {
 	nanobananaService repositories.NanobananaAIService
 	textAIService     repositories.TextAIService
+	// 外部APIに送る前の画像の整え方
+	normalizeOptions valueobjects.NormalizeOptions
 }
 
 func NewNanobananaDomainService(
 	nanobananaService repositories.NanobananaAIService,
 	textAIService repositories.TextAIService,
+	normalizeOptions valueobjects.NormalizeOptions,
 ) repositories.NanobananaAIService {
 	return &NanobananaDomainService{
 		nanobananaService: nanobananaService,
 		textAIService:     textAIService,
+		normalizeOptions:  normalizeOptions,
 	}
 }
 
@@ -28,7 +34,11 @@ func (s *NanobananaDomainService) ModifyImage(
 	request *entities.NanobananaModifyRequest,
 ) (*entities.NanobananaResult, error) {
 	if err := s.validateRequest(request); err != nil {
-		return nil, fmt.Errorf("request validation failed: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "request validation failed")
+	}
+
+	if err := request.PrepareImages(s.normalizeOptions); err != nil {
+		return nil, fmt.Errorf("image preparation failed: %w", err)
 	}
 
 	if request.Prompt() != "" && request.IsTranslate() {
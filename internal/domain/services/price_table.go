@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// PriceTable モデルと単位ごとの単価（USD）。利用量から料金を見積もるのに使う
+type PriceTable struct {
+	mu     sync.RWMutex
+	prices map[priceKey]float64
+}
+
+type priceKey struct {
+	model string
+	unit  entities.PriceUnit
+}
+
+func NewPriceTable() *PriceTable {
+	return &PriceTable{
+		prices: make(map[priceKey]float64),
+	}
+}
+
+// 組み込みのトークンの単価（USD、100万トークンあたりの価格から換算）
+var defaultTokenPrices = []struct {
+	model  string
+	unit   entities.PriceUnit
+	amount float64
+}{
+	// プロンプトの生成・翻訳・画像の判定に使うモデル
+	{"gemini-2.5-flash", entities.PriceUnitInputToken, 0.30 / 1e6},
+	{"gemini-2.5-flash", entities.PriceUnitOutputToken, 2.50 / 1e6},
+	// 画像編集の出力は画像1枚あたりの料金で数える
+	{"gemini-2.5-flash-image-preview", entities.PriceUnitInputToken, 0.30 / 1e6},
+}
+
+// DefaultPriceTable モデルカタログの料金と、組み込みのトークンの単価からなる料金表
+func DefaultPriceTable(catalog *ModelCatalog) *PriceTable {
+	table := NewPriceTable()
+	for _, price := range defaultTokenPrices {
+		table.prices[priceKey{price.model, price.unit}] = price.amount
+	}
+	for _, model := range catalog.Models("") {
+		if model.Price != nil {
+			table.prices[priceKey{model.ID, model.Price.Unit}] = model.Price.Amount
+		}
+	}
+	return table
+}
+
+// Set 単価を設定する（同じモデル・単位の単価は置き換える）
+func (t *PriceTable) Set(model string, unit entities.PriceUnit, amount float64) error {
+	if model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if !unit.IsValid() {
+		return fmt.Errorf("unsupported price unit: %q", unit)
+	}
+	if amount < 0 {
+		return fmt.Errorf("price must not be negative, got %v", amount)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prices[priceKey{model, unit}] = amount
+	return nil
+}
+
+// Price 単価（料金表にない場合は false）
+func (t *PriceTable) Price(model string, unit entities.PriceUnit) (float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	amount, ok := t.prices[priceKey{model, unit}]
+	return amount, ok
+}
+
+// Cost 利用量の料金。料金表にない場合は0と false を返す
+func (t *PriceTable) Cost(item entities.UsageItem) (float64, bool) {
+	amount, ok := t.Price(item.Model, item.Unit)
+	if !ok {
+		return 0, false
+	}
+	return amount * item.Quantity, true
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func TestDefaultPriceTable(t *testing.T) {
+	table := DefaultPriceTable(DefaultModelCatalog())
+
+	tests := []struct {
+		name   string
+		item   entities.UsageItem
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "imagen images",
+			item:   entities.UsageItem{Model: "imagen-4.0-fast-generate-001", Unit: entities.PriceUnitImage, Quantity: 3},
+			want:   0.06,
+			wantOK: true,
+		},
+		{
+			name:   "veo seconds",
+			item:   entities.UsageItem{Model: "veo-2.0-generate-001", Unit: entities.PriceUnitSecond, Quantity: 8},
+			want:   4,
+			wantOK: true,
+		},
+		{
+			name:   "gemini output tokens",
+			item:   entities.UsageItem{Model: "gemini-2.5-flash", Unit: entities.PriceUnitOutputToken, Quantity: 1e6},
+			want:   2.5,
+			wantOK: true,
+		},
+		{
+			name: "unknown model",
+			item: entities.UsageItem{Model: "imagen-9", Unit: entities.PriceUnitImage, Quantity: 1},
+		},
+		{
+			name: "unit without a price",
+			item: entities.UsageItem{Model: "imagen-3.0-generate-002", Unit: entities.PriceUnitSecond, Quantity: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := table.Cost(tt.item)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPriceTable_Set(t *testing.T) {
+	table := NewPriceTable()
+
+	if err := table.Set("model", "tokens", 1); err == nil {
+		t.Error("Set() with an unknown unit should fail")
+	}
+	if err := table.Set("model", entities.PriceUnitRequest, -1); err == nil {
+		t.Error("Set() with a negative price should fail")
+	}
+	if err := table.Set("model", entities.PriceUnitRequest, 0.5); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, ok := table.Price("model", entities.PriceUnitRequest); !ok || got != 0.5 {
+		t.Errorf("Price() = %v, %v, want 0.5, true", got, ok)
+	}
+}
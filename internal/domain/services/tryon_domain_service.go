@@ -2,41 +2,49 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
 type TryOnDomainService struct {
 	aiService repositories.VertexAIService
+	// 外部APIに送る前の画像の整え方
+	normalizeOptions valueobjects.NormalizeOptions
 }
 
-func NewTryOnDomainService(aiService repositories.VertexAIService) *TryOnDomainService {
+func NewTryOnDomainService(
+	aiService repositories.VertexAIService,
+	normalizeOptions valueobjects.NormalizeOptions,
+) *TryOnDomainService {
 	return &TryOnDomainService{
-		aiService: aiService,
+		aiService:        aiService,
+		normalizeOptions: normalizeOptions,
 	}
 }
 
 func (s *TryOnDomainService) ProcessTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
 	if err := s.validateRequest(request); err != nil {
-		return nil, fmt.Errorf("request validation failed: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "request validation failed")
 	}
 
-	if err := request.PrepareImages(); err != nil {
+	if err := request.PrepareImages(s.normalizeOptions); err != nil {
 		return nil, fmt.Errorf("image preparation failed: %w", err)
 	}
 
 	result, err := s.aiService.GenerateTryOn(ctx, request)
 	if err != nil {
-		if s.isQuotaError(err) {
+		if errors.Is(err, domainerrors.ErrQuotaExhausted) {
 			return nil, fmt.Errorf("service temporarily unavailable due to high demand: %w", err)
 		}
 		return nil, fmt.Errorf("try-on generation failed: %w", err)
 	}
 
-	if !result.HasImages() {
+	if !result.HasOutputs() {
 		return nil, fmt.Errorf("no images generated")
 	}
 
@@ -59,11 +67,3 @@ func (s *TryOnDomainService) validateRequest(request *entities.TryOnRequest) err
 	return nil
 }
 
-func (s *TryOnDomainService) isQuotaError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "quota exceeded") ||
-		strings.Contains(errStr, "resourceexhausted")
-}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/valueobjects"
 )
@@ -41,7 +42,7 @@ func TestTryOnDomainService_ProcessTryOn(t *testing.T) {
 			err:    nil,
 		}
 
-		service := NewTryOnDomainService(mockAI)
+		service := NewTryOnDomainService(mockAI, valueobjects.DefaultNormalizeOptions())
 		result, err := service.ProcessTryOn(context.Background(), validRequest)
 
 		if err != nil {
@@ -61,7 +62,7 @@ func TestTryOnDomainService_ProcessTryOn(t *testing.T) {
 			err:    errors.New("AI service failed"),
 		}
 
-		service := NewTryOnDomainService(mockAI)
+		service := NewTryOnDomainService(mockAI, valueobjects.DefaultNormalizeOptions())
 		result, err := service.ProcessTryOn(context.Background(), validRequest)
 
 		if err == nil {
@@ -75,10 +76,10 @@ func TestTryOnDomainService_ProcessTryOn(t *testing.T) {
 	t.Run("quota error handling", func(t *testing.T) {
 		mockAI := &mockAIService{
 			result: nil,
-			err:    errors.New("quota exceeded"),
+			err:    domainerrors.Wrap(domainerrors.KindQuotaExhausted, errors.New("Error 429, Status: RESOURCE_EXHAUSTED"), "predict request failed"),
 		}
 
-		service := NewTryOnDomainService(mockAI)
+		service := NewTryOnDomainService(mockAI, valueobjects.DefaultNormalizeOptions())
 		result, err := service.ProcessTryOn(context.Background(), validRequest)
 
 		if err == nil {
@@ -91,6 +92,34 @@ func TestTryOnDomainService_ProcessTryOn(t *testing.T) {
 		if !strings.Contains(err.Error(), "service temporarily unavailable due to high demand") {
 			t.Errorf("Expected quota error message, got %v", err.Error())
 		}
+		if !errors.Is(err, domainerrors.ErrQuotaExhausted) {
+			t.Errorf("Expected quota exhausted error kind, got %v", domainerrors.KindOf(err))
+		}
+	})
+
+	t.Run("quota-like message without kind", func(t *testing.T) {
+		mockAI := &mockAIService{
+			result: nil,
+			err:    errors.New("quota exceeded"),
+		}
+
+		service := NewTryOnDomainService(mockAI, valueobjects.DefaultNormalizeOptions())
+		_, err := service.ProcessTryOn(context.Background(), validRequest)
+
+		if errors.Is(err, domainerrors.ErrQuotaExhausted) {
+			t.Errorf("Untyped errors should not be treated as quota errors")
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		service := NewTryOnDomainService(&mockAIService{}, valueobjects.DefaultNormalizeOptions())
+		invalidRequest := entities.RestoreTryOnRequest(validRequest.ID(), nil, garmentImage, validRequest.Parameters(), validRequest.CreatedAt())
+
+		_, err := service.ProcessTryOn(context.Background(), invalidRequest)
+
+		if !errors.Is(err, domainerrors.ErrInvalidInput) {
+			t.Errorf("Expected invalid input error kind, got %v", domainerrors.KindOf(err))
+		}
 	})
 
 	t.Run("no images generated", func(t *testing.T) {
@@ -99,7 +128,7 @@ func TestTryOnDomainService_ProcessTryOn(t *testing.T) {
 			err:    nil,
 		}
 
-		service := NewTryOnDomainService(mockAI)
+		service := NewTryOnDomainService(mockAI, valueobjects.DefaultNormalizeOptions())
 		result, err := service.ProcessTryOn(context.Background(), validRequest)
 
 		if err == nil {
@@ -115,13 +144,13 @@ func createTestImageData(t *testing.T) *valueobjects.ImageData {
 	// Create minimal valid JPEG bytes
 	jpegBytes := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 0x4A, 0x46, 0x49, 0x46, 0xFF, 0xD9}
 
-	imageData, err := valueobjects.NewImageData(jpegBytes, "image/jpeg")
+	imageData, err := valueobjects.NewImageData(jpegBytes)
 	if err != nil {
 		// If that fails, create a simple test image
 		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
 		var buf bytes.Buffer
 		jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
-		imageData, err = valueobjects.NewImageData(buf.Bytes(), "image/jpeg")
+		imageData, err = valueobjects.NewImageData(buf.Bytes())
 		if err != nil {
 			t.Fatalf("Failed to create test image data: %v", err)
 		}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// 入力画像の検証エラーの種類（FieldError.Code）
+const (
+	PreflightCodeUnreadable     = "unreadable"
+	PreflightCodeTooSmall       = "too_small"
+	PreflightCodeTooLarge       = "too_large"
+	PreflightCodeAspectRatio    = "aspect_ratio"
+	PreflightCodeNoPerson       = "no_person"
+	PreflightCodeMultiplePeople = "multiple_people"
+	PreflightCodeNotGarment     = "not_garment"
+)
+
+// 検証エラーの項目名
+const (
+	PreflightFieldPersonImage = "personImage"
+)
+
+// PreflightGarmentField 衣服画像の項目名（0始まり）
+func PreflightGarmentField(index int) string {
+	return fmt.Sprintf("garmentImages[%d]", index)
+}
+
+// PreflightRules 試着前に手元で確認する画像の条件
+type PreflightRules struct {
+	// 短辺の最小ピクセル数
+	MinEdge int
+	// 長辺の最大ピクセル数
+	MaxEdge int
+	// 長辺÷短辺の最大値
+	MaxAspectRatio float64
+}
+
+// DefaultPreflightRules 既定の条件
+func DefaultPreflightRules() PreflightRules {
+	return PreflightRules{
+		MinEdge:        256,
+		MaxEdge:        8192,
+		MaxAspectRatio: 4,
+	}
+}
+
+const personDetectionPrompt = `You are checking a photo before it is used for a virtual try-on.
+Count the people who are clearly visible in the image. Ignore people in posters, screens or reflections.
+
+Respond with JSON only, in this exact form:
+{"personCount": 1}`
+
+// personDetectionResponse モデルが返すJSON
+type personDetectionResponse struct {
+	PersonCount *int `json:"personCount"`
+}
+
+// TryOnPreflightService 有料の試着APIを呼ぶ前に入力画像を検証する
+type TryOnPreflightService struct {
+	rules PreflightRules
+	// 外部APIに送る前の画像の整え方（解像度は整えた後の大きさで確認する）
+	normalizeOptions valueobjects.NormalizeOptions
+	// 人物が写っているかを画像認識で確認する（nilの場合は確認しない）
+	textAIService repositories.TextAIService
+}
+
+func NewTryOnPreflightService(
+	rules PreflightRules,
+	normalizeOptions valueobjects.NormalizeOptions,
+	textAIService repositories.TextAIService,
+) *TryOnPreflightService {
+	return &TryOnPreflightService{
+		rules:            rules,
+		normalizeOptions: normalizeOptions,
+		textAIService:    textAIService,
+	}
+}
+
+// ValidateImages 人物画像と衣服画像の解像度と縦横比を手元で確認し、問題があれば項目ごとの検証エラーを返す
+func (s *TryOnPreflightService) ValidateImages(
+	personImage *valueobjects.ImageData,
+	garmentImages []*valueobjects.ImageData,
+) error {
+	fields := s.checkImage(PreflightFieldPersonImage, personImage)
+	for i, garmentImage := range garmentImages {
+		fields = append(fields, s.checkImage(PreflightGarmentField(i), garmentImage)...)
+	}
+	return domainerrors.NewValidation(fields)
+}
+
+// checkImage 外部APIに送る前に縮小した後の大きさで、解像度と縦横比を確認する
+func (s *TryOnPreflightService) checkImage(field string, image *valueobjects.ImageData) []domainerrors.FieldError {
+	originalWidth, originalHeight, err := image.Dimensions()
+	if err != nil {
+		return []domainerrors.FieldError{{
+			Field:   field,
+			Code:    PreflightCodeUnreadable,
+			Message: "image cannot be read",
+		}}
+	}
+
+	width, height := s.normalizeOptions.ScaledSize(originalWidth, originalHeight)
+	size := fmt.Sprintf("%dx%d", width, height)
+	if width != originalWidth || height != originalHeight {
+		size = fmt.Sprintf("%dx%d (%dx%d before downscaling)", width, height, originalWidth, originalHeight)
+	}
+
+	shortEdge, longEdge := min(width, height), max(width, height)
+
+	var fields []domainerrors.FieldError
+	if s.rules.MinEdge > 0 && shortEdge < s.rules.MinEdge {
+		fields = append(fields, domainerrors.FieldError{
+			Field:   field,
+			Code:    PreflightCodeTooSmall,
+			Message: fmt.Sprintf("image is %s, the shorter edge must be at least %dpx", size, s.rules.MinEdge),
+		})
+	}
+	if s.rules.MaxEdge > 0 && longEdge > s.rules.MaxEdge {
+		fields = append(fields, domainerrors.FieldError{
+			Field:   field,
+			Code:    PreflightCodeTooLarge,
+			Message: fmt.Sprintf("image is %s, the longer edge must be at most %dpx", size, s.rules.MaxEdge),
+		})
+	}
+	// 縦横比は縮小で変わらないため、丸めの影響を受けない元の大きさで確認する
+	if ratio := aspectRatio(originalWidth, originalHeight); s.rules.MaxAspectRatio > 0 && ratio > s.rules.MaxAspectRatio {
+		fields = append(fields, domainerrors.FieldError{
+			Field:   field,
+			Code:    PreflightCodeAspectRatio,
+			Message: fmt.Sprintf("image is %s, the aspect ratio must be at most %g:1", size, s.rules.MaxAspectRatio),
+		})
+	}
+
+	return fields
+}
+
+// aspectRatio 長辺÷短辺（短辺が0の場合は0）
+func aspectRatio(width, height int) float64 {
+	if min(width, height) <= 0 {
+		return 0
+	}
+	return float64(max(width, height)) / float64(min(width, height))
+}
+
+// ValidatePerson 人物がちょうど1人写っているかを画像認識で確認する。
+// 写っていない場合は検証エラー、確認の呼び出し自体に失敗した場合はそれ以外のエラーを返す。
+func (s *TryOnPreflightService) ValidatePerson(ctx context.Context, personImage *valueobjects.ImageData) error {
+	if s.textAIService == nil {
+		return nil
+	}
+
+	request := entities.NewImageTextRequest(
+		personDetectionPrompt,
+		"",
+		[]*valueobjects.ImageData{personImage},
+		"application/json",
+	)
+
+	result, err := s.textAIService.GenerateText(ctx, request)
+	if err != nil {
+		return fmt.Errorf("person detection failed: %w", err)
+	}
+
+	personCount, err := parsePersonCount(result.Text())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case personCount == 0:
+		return domainerrors.NewValidation([]domainerrors.FieldError{{
+			Field:   PreflightFieldPersonImage,
+			Code:    PreflightCodeNoPerson,
+			Message: "no person was detected in the image",
+		}})
+	case personCount > 1:
+		return domainerrors.NewValidation([]domainerrors.FieldError{{
+			Field:   PreflightFieldPersonImage,
+			Code:    PreflightCodeMultiplePeople,
+			Message: fmt.Sprintf("%d people were detected, the image must show one person", personCount),
+		}})
+	}
+
+	return nil
+}
+
+func parsePersonCount(text string) (int, error) {
+	var response personDetectionResponse
+	if err := json.Unmarshal([]byte(trimJSONResponse(text)), &response); err != nil {
+		return 0, fmt.Errorf("invalid person detection response: %w", err)
+	}
+	if response.PersonCount == nil || *response.PersonCount < 0 {
+		return 0, fmt.Errorf("invalid person detection response: personCount is missing")
+	}
+
+	return *response.PersonCount, nil
+}
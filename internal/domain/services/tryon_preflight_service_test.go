@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"slices"
+	"testing"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+func createSizedImageData(t *testing.T, width, height int) *valueobjects.ImageData {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	imageData, err := valueobjects.NewImageData(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create test image data: %v", err)
+	}
+	return imageData
+}
+
+// fieldCodes 検証エラーを "項目名:種類" の一覧にする
+func fieldCodes(err error) []string {
+	var codes []string
+	for _, field := range domainerrors.FieldErrorsOf(err) {
+		codes = append(codes, field.Field+":"+field.Code)
+	}
+	return codes
+}
+
+func TestTryOnPreflightService_ValidateImages(t *testing.T) {
+	rules := PreflightRules{MinEdge: 64, MaxEdge: 512, MaxAspectRatio: 3}
+	service := NewTryOnPreflightService(rules, valueobjects.NormalizeOptions{}, nil)
+
+	tests := []struct {
+		name    string
+		person  [2]int
+		garment [][2]int
+		want    []string
+	}{
+		{
+			name:    "valid images",
+			person:  [2]int{300, 400},
+			garment: [][2]int{{64, 64}, {512, 200}},
+		},
+		{
+			name:    "person too small",
+			person:  [2]int{40, 80},
+			garment: [][2]int{{100, 100}},
+			want:    []string{"personImage:too_small"},
+		},
+		{
+			name:    "garment too large and too wide",
+			person:  [2]int{300, 400},
+			garment: [][2]int{{100, 100}, {600, 100}},
+			want:    []string{"garmentImages[1]:too_large", "garmentImages[1]:aspect_ratio"},
+		},
+		{
+			name:    "all fields are reported",
+			person:  [2]int{100, 400},
+			garment: [][2]int{{32, 32}},
+			want:    []string{"personImage:aspect_ratio", "garmentImages[0]:too_small"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := createSizedImageData(t, tt.person[0], tt.person[1])
+			var garments []*valueobjects.ImageData
+			for _, size := range tt.garment {
+				garments = append(garments, createSizedImageData(t, size[0], size[1]))
+			}
+
+			err := service.ValidateImages(person, garments)
+			if got := fieldCodes(err); !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateImages() fields = %v, want %v", got, tt.want)
+			}
+			if len(tt.want) > 0 && domainerrors.KindOf(err) != domainerrors.KindInvalidInput {
+				t.Errorf("KindOf() = %v, want %v", domainerrors.KindOf(err), domainerrors.KindInvalidInput)
+			}
+		})
+	}
+}
+
+// 外部APIに送る前に縮小される画像は、縮小後の大きさで確認する
+func TestTryOnPreflightService_ValidateImages_AfterNormalization(t *testing.T) {
+	rules := PreflightRules{MinEdge: 64, MaxEdge: 512, MaxAspectRatio: 3}
+	service := NewTryOnPreflightService(rules, valueobjects.NormalizeOptions{MaxEdge: 200}, nil)
+
+	// 長辺が上限を超えても、縮小すれば収まるので受け付ける。
+	// 縦横比は丸めの影響を受けない（900x300 は縮小すると 200x66 になるが 3:1 として扱う）
+	if err := service.ValidateImages(createSizedImageData(t, 900, 600), []*valueobjects.ImageData{createSizedImageData(t, 900, 300)}); err != nil {
+		t.Errorf("ValidateImages() error = %v, want nil", err)
+	}
+
+	// 縮小すると短辺が下限を下回る
+	rules.MaxAspectRatio = 4
+	service = NewTryOnPreflightService(rules, valueobjects.NormalizeOptions{MaxEdge: 200}, nil)
+	err := service.ValidateImages(createSizedImageData(t, 300, 400), []*valueobjects.ImageData{createSizedImageData(t, 800, 240)})
+	want := []string{"garmentImages[0]:too_small"}
+	if got := fieldCodes(err); !slices.Equal(got, want) {
+		t.Fatalf("ValidateImages() fields = %v, want %v", got, want)
+	}
+	if message := domainerrors.FieldErrorsOf(err)[0].Message; message != "image is 200x60 (800x240 before downscaling), the shorter edge must be at least 64px" {
+		t.Errorf("Message = %q", message)
+	}
+}
+
+func TestTryOnPreflightService_ValidatePerson(t *testing.T) {
+	person := createSizedImageData(t, 300, 400)
+
+	tests := []struct {
+		name       string
+		text       string
+		err        error
+		want       []string
+		wantOther  bool
+		noDetector bool
+	}{
+		{name: "one person", text: `{"personCount": 1}`},
+		{name: "no person", text: `{"personCount": 0}`, want: []string{"personImage:no_person"}},
+		{name: "multiple people", text: "```json\n{\"personCount\": 3}\n```", want: []string{"personImage:multiple_people"}},
+		{name: "invalid response", text: "one person", wantOther: true},
+		{name: "detection failed", err: errors.New("upstream failed"), wantOther: true},
+		{name: "detection disabled", noDetector: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTryOnPreflightService(DefaultPreflightRules(), valueobjects.DefaultNormalizeOptions(), &mockTextAIService{text: tt.text, err: tt.err})
+			if tt.noDetector {
+				service = NewTryOnPreflightService(DefaultPreflightRules(), valueobjects.DefaultNormalizeOptions(), nil)
+			}
+
+			err := service.ValidatePerson(context.Background(), person)
+			if got := fieldCodes(err); !slices.Equal(got, tt.want) {
+				t.Errorf("ValidatePerson() fields = %v, want %v", got, tt.want)
+			}
+			if tt.wantOther != (err != nil && domainerrors.FieldErrorsOf(err) == nil) {
+				t.Errorf("ValidatePerson() error = %v, want non-validation error: %v", err, tt.wantOther)
+			}
+		})
+	}
+}
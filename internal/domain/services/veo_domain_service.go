@@ -2,22 +2,31 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
 type VeoDomainService struct {
 	veoAIService  repositories.VeoAIService
 	textAIService repositories.TextAIService
+	// 外部APIに送る前の画像の整え方
+	normalizeOptions valueobjects.NormalizeOptions
 }
 
-func NewVeoDomainService(veoAIService repositories.VeoAIService, textAIService repositories.TextAIService) *VeoDomainService {
+func NewVeoDomainService(
+	veoAIService repositories.VeoAIService,
+	textAIService repositories.TextAIService,
+	normalizeOptions valueobjects.NormalizeOptions,
+) *VeoDomainService {
 	return &VeoDomainService{
-		veoAIService:  veoAIService,
-		textAIService: textAIService,
+		veoAIService:     veoAIService,
+		textAIService:    textAIService,
+		normalizeOptions: normalizeOptions,
 	}
 }
 
@@ -26,7 +35,11 @@ func (s *VeoDomainService) ProcessVeo(
 	request *entities.VeoRequest,
 ) ([]*entities.VeoResult, error) {
 	if err := s.validateRequest(request); err != nil {
-		return nil, fmt.Errorf("request validation failed: %w", err)
+		return nil, domainerrors.Wrap(domainerrors.KindInvalidInput, err, "request validation failed")
+	}
+
+	if err := request.PrepareImages(s.normalizeOptions); err != nil {
+		return nil, fmt.Errorf("image preparation failed: %w", err)
 	}
 
 	if request.VideoPrompt() != "" {
@@ -41,7 +54,7 @@ func (s *VeoDomainService) ProcessVeo(
 
 	results, err := s.veoAIService.GenerateVideo(ctx, request)
 	if err != nil {
-		if s.isQuotaError(err) {
+		if errors.Is(err, domainerrors.ErrQuotaExhausted) {
 			return nil, fmt.Errorf("service temporarily unavailable due to high demand: %w", err)
 		}
 		return nil, fmt.Errorf("veo generation failed: %w", err)
@@ -63,11 +76,3 @@ func (s *VeoDomainService) validateRequest(request *entities.VeoRequest) error {
 	return nil
 }
 
-func (s *VeoDomainService) isQuotaError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "quota exceeded") ||
-		strings.Contains(errStr, "resourceexhausted")
-}
@@ -0,0 +1,65 @@
+package valueobjects
+
+import (
+	"strings"
+)
+
+// GarmentCategory 衣服の種類。コーデ（重ね着）モードではこの順番で着せる
+type GarmentCategory string
+
+const (
+	GarmentCategoryTops        GarmentCategory = "tops"
+	GarmentCategoryBottoms     GarmentCategory = "bottoms"
+	GarmentCategoryShoes       GarmentCategory = "shoes"
+	GarmentCategoryAccessories GarmentCategory = "accessories"
+	// 種類が分からない衣服は最後に、入力された順番のまま着せる
+	GarmentCategoryUnknown GarmentCategory = ""
+)
+
+// 着せる順番
+var garmentCategoryOrder = map[GarmentCategory]int{
+	GarmentCategoryTops:        0,
+	GarmentCategoryBottoms:     1,
+	GarmentCategoryShoes:       2,
+	GarmentCategoryAccessories: 3,
+}
+
+// 別名（サンプル画像のIDやUIの表記）
+var garmentCategoryAliases = map[string]GarmentCategory{
+	"top":       GarmentCategoryTops,
+	"shirt":     GarmentCategoryTops,
+	"bottom":    GarmentCategoryBottoms,
+	"pants":     GarmentCategoryBottoms,
+	"skirt":     GarmentCategoryBottoms,
+	"shoe":      GarmentCategoryShoes,
+	"accessory": GarmentCategoryAccessories,
+	"necklace":  GarmentCategoryAccessories,
+	"neckless":  GarmentCategoryAccessories,
+}
+
+// ParseGarmentCategory 文字列から衣服の種類を決める。該当しない場合は GarmentCategoryUnknown
+func ParseGarmentCategory(value string) GarmentCategory {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	category := GarmentCategory(value)
+	if _, ok := garmentCategoryOrder[category]; ok {
+		return category
+	}
+	if alias, ok := garmentCategoryAliases[value]; ok {
+		return alias
+	}
+	return GarmentCategoryUnknown
+}
+
+// Order 着せる順番（小さいほど先）。不明な種類は最後になる
+func (c GarmentCategory) Order() int {
+	if order, ok := garmentCategoryOrder[c]; ok {
+		return order
+	}
+	return len(garmentCategoryOrder)
+}
+
+func (c GarmentCategory) IsKnown() bool {
+	_, ok := garmentCategoryOrder[c]
+	return ok
+}
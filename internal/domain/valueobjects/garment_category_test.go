@@ -0,0 +1,43 @@
+package valueobjects
+
+import (
+	"testing"
+)
+
+func TestParseGarmentCategory(t *testing.T) {
+	tests := []struct {
+		value string
+		want  GarmentCategory
+	}{
+		{"tops", GarmentCategoryTops},
+		{" Bottoms ", GarmentCategoryBottoms},
+		{"pants", GarmentCategoryBottoms},
+		{"shoes", GarmentCategoryShoes},
+		{"neckless", GarmentCategoryAccessories},
+		{"hat-or-something", GarmentCategoryUnknown},
+		{"", GarmentCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseGarmentCategory(tt.value); got != tt.want {
+			t.Errorf("ParseGarmentCategory(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGarmentCategory_Order(t *testing.T) {
+	ordered := []GarmentCategory{
+		GarmentCategoryTops,
+		GarmentCategoryBottoms,
+		GarmentCategoryShoes,
+		GarmentCategoryAccessories,
+		GarmentCategoryUnknown,
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1].Order() >= ordered[i].Order() {
+			t.Errorf("%q.Order() = %d, want less than %q.Order() = %d",
+				ordered[i-1], ordered[i-1].Order(), ordered[i], ordered[i].Order())
+		}
+	}
+}
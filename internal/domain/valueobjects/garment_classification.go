@@ -0,0 +1,55 @@
+package valueobjects
+
+import "fmt"
+
+// GarmentClassification 衣服画像の分類結果
+type GarmentClassification struct {
+	category   GarmentCategory
+	confidence float64 // 0〜1
+	isGarment  bool
+	label      string // 判定した品目（例: t-shirt, hat）
+}
+
+func NewGarmentClassification(
+	category GarmentCategory,
+	confidence float64,
+	isGarment bool,
+	label string,
+) (*GarmentClassification, error) {
+	if confidence < 0 || confidence > 1 {
+		return nil, fmt.Errorf("confidence must be between 0 and 1, got %v", confidence)
+	}
+
+	// 衣服でなければ種類は持たない
+	if !isGarment {
+		category = GarmentCategoryUnknown
+	}
+
+	return &GarmentClassification{
+		category:   category,
+		confidence: confidence,
+		isGarment:  isGarment,
+		label:      label,
+	}, nil
+}
+
+func (c *GarmentClassification) Category() GarmentCategory {
+	return c.category
+}
+
+func (c *GarmentClassification) Confidence() float64 {
+	return c.confidence
+}
+
+func (c *GarmentClassification) IsGarment() bool {
+	return c.isGarment
+}
+
+func (c *GarmentClassification) Label() string {
+	return c.label
+}
+
+// IsSupported 試着できる種類の衣服かどうか
+func (c *GarmentClassification) IsSupported() bool {
+	return c.isGarment && c.category.IsKnown()
+}
@@ -3,10 +3,12 @@ package valueobjects
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
-	_ "image/gif"
-	"image/jpeg"
+	"image/color"
+	"image/gif"
+	_ "image/jpeg"
 	_ "image/png"
 
 	_ "golang.org/x/image/webp"
@@ -21,31 +23,56 @@ const (
 	WEBP ImageFormat = "webp"
 )
 
+// MimeType 形式に対応するMIMEタイプ
+func (f ImageFormat) MimeType() string {
+	return "image/" + string(f)
+}
+
+// 対応していない画像の判定用。errors.Is(err, valueobjects.ErrAnimatedImage) のように使う。
+var (
+	ErrUnsupportedImageFormat = errors.New("unsupported image format")
+	ErrAnimatedImage          = fmt.Errorf("%w: animated image", ErrUnsupportedImageFormat)
+	ErrAnimatedGIF            = fmt.Errorf("%w: GIF", ErrAnimatedImage)
+	// 展開すると大きすぎる画像（HEIC/HEIF・AVIFの変換時に確認する）
+	ErrTooManyPixels = errors.New("image has too many pixels")
+)
+
 type ImageData struct {
-	data     []byte
-	format   ImageFormat
-	mimeType string
+	data   []byte
+	format ImageFormat
 }
 
-func NewImageData(data []byte, mimeType string) (*ImageData, error) {
+// NewImageData 画像データの中身から形式を判定して生成する。MIMEタイプは判定した形式から決まる。
+// HEIC/HEIF・AVIFはこの時点でJPEG（透過がある場合はPNG）に変換する（SetHEIFDecoders でデコーダーを設定した場合のみ）。
+func NewImageData(data []byte) (*ImageData, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("image data cannot be empty")
 	}
 
+	if kind := detectHEIF(data); kind != heifKindNone {
+		converted, format, err := convertHEIF(data, kind)
+		if err != nil {
+			return nil, err
+		}
+		return &ImageData{
+			data:   converted,
+			format: format,
+		}, nil
+	}
+
 	format, err := detectFormat(data)
 	if err != nil {
-		return nil, fmt.Errorf("unsupported image format: %w", err)
+		return nil, err
 	}
 
 	return &ImageData{
-		data:     data,
-		format:   format,
-		mimeType: mimeType,
+		data:   data,
+		format: format,
 	}, nil
 }
 
 func (i *ImageData) MimeType() string {
-	return i.mimeType
+	return i.format.MimeType()
 }
 
 func (i *ImageData) Data() []byte {
@@ -56,31 +83,22 @@ func (i *ImageData) Format() ImageFormat {
 	return i.format
 }
 
+// Dimensions 画像をデコードせずに幅と高さを返す
+func (i *ImageData) Dimensions() (width, height int, err error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(i.data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read image dimensions: %w", err)
+	}
+	return config.Width, config.Height, nil
+}
+
 func (i *ImageData) IsJPEG() bool {
 	return i.format == JPEG
 }
 
+// ToJPEG JPEG形式に変換する（縮小はしない）
 func (i *ImageData) ToJPEG() (*ImageData, error) {
-	if i.IsJPEG() {
-		return i, nil
-	}
-
-	reader := bytes.NewReader(i.data)
-	img, _, err := image.Decode(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
-	}
-
-	var buf bytes.Buffer
-	opts := &jpeg.Options{Quality: 90}
-	if err := jpeg.Encode(&buf, img, opts); err != nil {
-		return nil, fmt.Errorf("failed to encode to JPEG: %w", err)
-	}
-
-	return &ImageData{
-		data:   buf.Bytes(),
-		format: JPEG,
-	}, nil
+	return i.Normalize(NormalizeOptions{Background: color.White, Quality: defaultJPEGQuality})
 }
 
 func (i *ImageData) ToBase64() string {
@@ -88,10 +106,9 @@ func (i *ImageData) ToBase64() string {
 }
 
 func detectFormat(data []byte) (ImageFormat, error) {
-	reader := bytes.NewReader(data)
-	_, format, err := image.DecodeConfig(reader)
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedImageFormat, err)
 	}
 
 	switch format {
@@ -100,10 +117,18 @@ func detectFormat(data []byte) (ImageFormat, error) {
 	case "png":
 		return PNG, nil
 	case "gif":
+		// アニメーションGIFは先頭のコマだけが使われて意図しない結果になるため受け付けない
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnsupportedImageFormat, err)
+		}
+		if len(animation.Image) > 1 {
+			return "", ErrAnimatedGIF
+		}
 		return GIF, nil
 	case "webp":
 		return WEBP, nil
 	default:
-		return "", fmt.Errorf("unsupported format: %s", format)
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImageFormat, format)
 	}
 }
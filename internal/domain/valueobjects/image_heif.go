@@ -0,0 +1,146 @@
+package valueobjects
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sync/atomic"
+)
+
+// heifKind ISOBMFFのftypボックスから判定したHEIF系の形式
+type heifKind string
+
+const (
+	heifKindNone heifKind = ""
+	heifKindHEIC heifKind = "heic"
+	heifKindAVIF heifKind = "avif"
+	// 連続画像（アニメーションAVIF）
+	heifKindAVIFSequence heifKind = "avis"
+)
+
+// heicBrands HEIC/HEIFのftypボックスに記録されるブランド
+var heicBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "hevm": true, "hevs": true,
+	"mif1": true, "msf1": true,
+}
+
+// detectHEIF メジャーブランドと互換ブランドからHEIC/HEIF・AVIFかどうかを判定する。
+// AVIFも互換ブランドに mif1 を持つため、AVIFのブランドを優先する。
+func detectHEIF(data []byte) heifKind {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return heifKindNone
+	}
+
+	size := int(binary.BigEndian.Uint32(data[0:4]))
+	if size < 16 || size > len(data) {
+		size = len(data)
+	}
+
+	// メジャーブランドと互換ブランド（マイナーバージョンの4バイトは飛ばす）
+	brands := []string{string(data[8:12])}
+	for pos := 16; pos+4 <= size; pos += 4 {
+		brands = append(brands, string(data[pos:pos+4]))
+	}
+
+	kind := heifKindNone
+	for _, brand := range brands {
+		switch {
+		case brand == "avis":
+			return heifKindAVIFSequence
+		case brand == "avif":
+			kind = heifKindAVIF
+		case heicBrands[brand] && kind == heifKindNone:
+			kind = heifKindHEIC
+		}
+	}
+	return kind
+}
+
+// maxHEIFPixels 変換するHEIC/HEIF・AVIFの最大画素数（5000万画素。48MPのスマートフォンの写真が収まる）。
+// 展開すると1画素あたり4バイト以上を使うため、ファイルに記録された大きさで展開前に確認する。
+const maxHEIFPixels = 50_000_000
+
+// ImageDecoder 標準ライブラリで読めない画像形式のデコーダー
+type ImageDecoder interface {
+	// DecodeConfig 画素を展開せずに、ファイルに記録された幅と高さを読む
+	DecodeConfig(r io.Reader) (image.Config, error)
+	Decode(r io.Reader) (image.Image, error)
+}
+
+// HEIFDecoders HEIC/HEIF・AVIFのデコーダー（nilの形式は対応していない形式として扱う）
+type HEIFDecoders struct {
+	HEIC ImageDecoder
+	AVIF ImageDecoder
+}
+
+// heifDecoders ドメイン層はデコーダーの実装を持たず、起動時にインフラ層の実装を設定する
+var heifDecoders atomic.Pointer[HEIFDecoders]
+
+// SetHEIFDecoders NewImageData でHEIC/HEIF・AVIFを変換するデコーダーを設定する
+func SetHEIFDecoders(decoders HEIFDecoders) {
+	heifDecoders.Store(&decoders)
+}
+
+// heifDecoder 形式に対応するデコーダー（設定されていない場合はnil）
+func heifDecoder(kind heifKind) ImageDecoder {
+	decoders := heifDecoders.Load()
+	if decoders == nil {
+		return nil
+	}
+	switch kind {
+	case heifKindHEIC:
+		return decoders.HEIC
+	case heifKindAVIF:
+		return decoders.AVIF
+	default:
+		return nil
+	}
+}
+
+// convertHEIF HEIC/HEIF・AVIFを外部APIや標準ライブラリで扱える形式に変換する。
+// 透過がある場合はPNG、ない場合はJPEGにする。
+func convertHEIF(data []byte, kind heifKind) ([]byte, ImageFormat, error) {
+	if kind == heifKindAVIFSequence {
+		return nil, "", fmt.Errorf("%w: AVIF", ErrAnimatedImage)
+	}
+	decoder := heifDecoder(kind)
+	if decoder == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedImageFormat, kind)
+	}
+
+	config, err := decoder.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to decode %s: %v", ErrUnsupportedImageFormat, kind, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", fmt.Errorf("%w: %s has no size", ErrUnsupportedImageFormat, kind)
+	}
+	if config.Width*config.Height > maxHEIFPixels {
+		return nil, "", fmt.Errorf("%w: %s is %dx%d, at most %d pixels are supported",
+			ErrTooManyPixels, kind, config.Width, config.Height, maxHEIFPixels)
+	}
+
+	img, err := decoder.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to decode %s: %v", ErrUnsupportedImageFormat, kind, err)
+	}
+
+	var buf bytes.Buffer
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", fmt.Errorf("failed to encode %s to PNG: %w", kind, err)
+		}
+		return buf.Bytes(), PNG, nil
+	}
+
+	// 変換後に縮小・再エンコードされることが多いため、画質を優先する
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		return nil, "", fmt.Errorf("failed to encode %s to JPEG: %w", kind, err)
+	}
+	return buf.Bytes(), JPEG, nil
+}
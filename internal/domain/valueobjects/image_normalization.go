@@ -0,0 +1,260 @@
+package valueobjects
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"golang.org/x/image/draw"
+)
+
+// NormalizeOptions 外部APIに送る前の画像の整え方
+type NormalizeOptions struct {
+	// 長辺の最大ピクセル数（超える場合は縦横比を保って縮小する。0以下は縮小しない）
+	MaxEdge int
+	// 透過部分を塗りつぶす背景色
+	Background color.Color
+	// JPEGの品質（1〜100。0以下は90）
+	Quality int
+}
+
+// DefaultNormalizeOptions 既定の整え方（長辺2048px、白背景、品質90）
+func DefaultNormalizeOptions() NormalizeOptions {
+	return NormalizeOptions{
+		MaxEdge:    2048,
+		Background: color.White,
+		Quality:    90,
+	}
+}
+
+const defaultJPEGQuality = 90
+
+// ScaledSize 整えた後の幅と高さ（長辺が MaxEdge 以下の場合はそのまま）
+func (o NormalizeOptions) ScaledSize(width, height int) (int, int) {
+	longEdge := max(width, height)
+	if o.MaxEdge <= 0 || longEdge <= o.MaxEdge {
+		return width, height
+	}
+	return max(1, width*o.MaxEdge/longEdge), max(1, height*o.MaxEdge/longEdge)
+}
+
+// Normalize 外部APIに送る形に画像を整えてJPEGで返す。
+// EXIFの向きに合わせて回転し、メタデータを取り除き、長辺を MaxEdge まで縮小し、透過部分を背景色で塗りつぶす。
+// 既に整った状態のJPEGの場合は再エンコードせずにそのまま返す。
+func (i *ImageData) Normalize(opts NormalizeOptions) (*ImageData, error) {
+	if i.IsJPEG() {
+		normalized, err := i.isNormalizedJPEG(opts)
+		if err != nil {
+			return nil, err
+		}
+		if normalized {
+			return i, nil
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(i.data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	orientation := 1
+	if i.IsJPEG() {
+		orientation = scanJPEGMetadata(i.data).orientation
+	}
+
+	// JPEGは透過を持てないため、先に背景色で塗りつぶす
+	normalized := orient(flatten(img, opts.Background), orientation)
+	normalized = downscale(normalized, opts)
+
+	quality := opts.Quality
+	if quality <= 0 || quality > 100 {
+		quality = defaultJPEGQuality
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, normalized, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode to JPEG: %w", err)
+	}
+
+	return &ImageData{
+		data:   buf.Bytes(),
+		format: JPEG,
+	}, nil
+}
+
+// isNormalizedJPEG 回転・メタデータ除去・縮小のいずれも不要なJPEGかどうか
+func (i *ImageData) isNormalizedJPEG(opts NormalizeOptions) (bool, error) {
+	width, height, err := i.Dimensions()
+	if err != nil {
+		return false, err
+	}
+	if opts.MaxEdge > 0 && max(width, height) > opts.MaxEdge {
+		return false, nil
+	}
+
+	metadata := scanJPEGMetadata(i.data)
+	return !metadata.hasMetadata && metadata.orientation <= 1, nil
+}
+
+// flatten 透過部分を背景色で塗りつぶした画像を返す
+func flatten(img image.Image, background color.Color) *image.RGBA {
+	if background == nil {
+		background = color.White
+	}
+
+	bounds := img.Bounds()
+	flattened := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flattened, flattened.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(flattened, flattened.Bounds(), img, bounds.Min, draw.Over)
+	return flattened
+}
+
+// orient EXIFの向き（1〜8）に合わせて回転・反転した画像を返す
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	oriented := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 左右反転
+				dx, dy = width-1-x, y
+			case 3: // 180度回転
+				dx, dy = width-1-x, height-1-y
+			case 4: // 上下反転
+				dx, dy = x, height-1-y
+			case 5: // 左上から右下の対角線で反転
+				dx, dy = y, x
+			case 6: // 時計回りに90度回転
+				dx, dy = height-1-y, x
+			case 7: // 右上から左下の対角線で反転
+				dx, dy = height-1-y, width-1-x
+			case 8: // 反時計回りに90度回転
+				dx, dy = y, width-1-x
+			}
+			src := img.PixOffset(x, y)
+			dst := oriented.PixOffset(dx, dy)
+			copy(oriented.Pix[dst:dst+4], img.Pix[src:src+4])
+		}
+	}
+
+	return oriented
+}
+
+// downscale 長辺が opts.MaxEdge を超える場合に縦横比を保って縮小する
+func downscale(img *image.RGBA, opts NormalizeOptions) *image.RGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	dstWidth, dstHeight := opts.ScaledSize(width, height)
+	if dstWidth == width && dstHeight == height {
+		return img
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return scaled
+}
+
+// jpegMetadata JPEGのヘッダーから読み取った情報
+type jpegMetadata struct {
+	// EXIFの向き（記録がない場合は0）
+	orientation int
+	// EXIF・ICCプロファイル・コメントなど、画素以外のデータを含むかどうか
+	hasMetadata bool
+}
+
+// scanJPEGMetadata 画像データの手前までのセグメントを読み、メタデータの有無とEXIFの向きを返す
+func scanJPEGMetadata(data []byte) jpegMetadata {
+	var metadata jpegMetadata
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return metadata
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// 埋め草のバイト
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// 画像データの開始・終了
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		switch {
+		case marker == 0xE1:
+			metadata.hasMetadata = true
+			if bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				metadata.orientation = exifOrientation(segment[6:])
+			}
+		case marker == 0xE0 && bytes.HasPrefix(segment, []byte("JFIF\x00")):
+			// JFIFヘッダーは画素の解釈に必要なので残す
+		case marker >= 0xE0 && marker <= 0xEF, marker == 0xFE:
+			metadata.hasMetadata = true
+		}
+
+		pos += 2 + length
+	}
+
+	return metadata
+}
+
+// exifOrientation EXIF（TIFF形式）の0th IFDから向き（タグ0x0112）を読み取る
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 0
+			}
+			return orientation
+		}
+	}
+
+	return 0
+}
@@ -2,8 +2,14 @@ package valueobjects
 
 import (
 	"bytes"
+	"errors"
 	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
 	"image/jpeg"
+	"image/png"
+	"io"
 	"testing"
 )
 
@@ -32,7 +38,7 @@ func TestNewImageData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := NewImageData(tt.data, "image/jpeg")
+			_, err := NewImageData(tt.data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewImageData() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -49,7 +55,7 @@ func TestImageData_ToJPEG(t *testing.T) {
 		t.Fatalf("Failed to create test JPEG: %v", err)
 	}
 
-	imageData, err := NewImageData(buf.Bytes(), "image/jpeg")
+	imageData, err := NewImageData(buf.Bytes())
 	if err != nil {
 		t.Fatalf("Failed to create ImageData: %v", err)
 	}
@@ -76,3 +82,299 @@ func TestImageData_ToJPEG(t *testing.T) {
 		}
 	})
 }
+
+// exifJPEG 向き（orientation）を記録したEXIFを埋め込んだJPEGを返す
+func exifJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to create test JPEG: %v", err)
+	}
+	data := buf.Bytes()
+
+	// ビッグエンディアンのTIFFヘッダーと、向きだけを持つ0th IFD
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation >> 8), byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestImageData_Normalize(t *testing.T) {
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+
+	t.Run("rotates by EXIF orientation and strips metadata", func(t *testing.T) {
+		// 左上だけ赤い 40x20 の画像を、時計回りに90度回転して表示する指定
+		img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		draw.Draw(img, image.Rect(0, 0, 10, 10), image.NewUniform(red), image.Point{}, draw.Src)
+		imageData, err := NewImageData(exifJPEG(t, img, 6))
+		if err != nil {
+			t.Fatalf("Failed to create ImageData: %v", err)
+		}
+
+		normalized, err := imageData.Normalize(DefaultNormalizeOptions())
+		if err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+
+		width, height, _ := normalized.Dimensions()
+		if width != 20 || height != 40 {
+			t.Errorf("Dimensions() = %dx%d, want 20x40", width, height)
+		}
+		if metadata := scanJPEGMetadata(normalized.Data()); metadata.hasMetadata {
+			t.Errorf("metadata should be stripped")
+		}
+
+		decoded, _, err := image.Decode(bytes.NewReader(normalized.Data()))
+		if err != nil {
+			t.Fatalf("Failed to decode normalized image: %v", err)
+		}
+		// 回転後は右上が赤くなる
+		if r, g, _, _ := decoded.At(15, 4).RGBA(); r>>8 < 0xC0 || g>>8 > 0x40 {
+			t.Errorf("top-right pixel should be red after rotation")
+		}
+	})
+
+	t.Run("downscales to max edge", func(t *testing.T) {
+		imageData, err := NewImageData(encodePNG(t, image.NewRGBA(image.Rect(0, 0, 300, 150))))
+		if err != nil {
+			t.Fatalf("Failed to create ImageData: %v", err)
+		}
+
+		normalized, err := imageData.Normalize(NormalizeOptions{MaxEdge: 100})
+		if err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+
+		width, height, _ := normalized.Dimensions()
+		if width != 100 || height != 50 {
+			t.Errorf("Dimensions() = %dx%d, want 100x50", width, height)
+		}
+		if normalized.Format() != JPEG || normalized.MimeType() != "image/jpeg" {
+			t.Errorf("Format() = %s, MimeType() = %s, want JPEG image/jpeg", normalized.Format(), normalized.MimeType())
+		}
+	})
+
+	t.Run("flattens transparency onto background", func(t *testing.T) {
+		imageData, err := NewImageData(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 8, 8))))
+		if err != nil {
+			t.Fatalf("Failed to create ImageData: %v", err)
+		}
+
+		normalized, err := imageData.Normalize(NormalizeOptions{Background: red})
+		if err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+
+		decoded, _, err := image.Decode(bytes.NewReader(normalized.Data()))
+		if err != nil {
+			t.Fatalf("Failed to decode normalized image: %v", err)
+		}
+		if r, g, b, _ := decoded.At(4, 4).RGBA(); r>>8 < 0xC0 || g>>8 > 0x40 || b>>8 > 0x40 {
+			t.Errorf("transparent pixel should be filled with background, got %d,%d,%d", r>>8, g>>8, b>>8)
+		}
+	})
+
+	t.Run("normalized JPEG is returned as is", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10)), nil); err != nil {
+			t.Fatalf("Failed to create test JPEG: %v", err)
+		}
+		imageData, err := NewImageData(buf.Bytes())
+		if err != nil {
+			t.Fatalf("Failed to create ImageData: %v", err)
+		}
+
+		normalized, err := imageData.Normalize(DefaultNormalizeOptions())
+		if err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+		if normalized != imageData {
+			t.Errorf("Expected same instance for normalized JPEG")
+		}
+	})
+}
+
+func TestNewImageData_DetectsFormat(t *testing.T) {
+	frame := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	encodeGIF := func(frames int) []byte {
+		animation := &gif.GIF{}
+		for i := 0; i < frames; i++ {
+			animation.Image = append(animation.Image, frame)
+			animation.Delay = append(animation.Delay, 10)
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, animation); err != nil {
+			t.Fatalf("Failed to create test GIF: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	// ftypボックスだけで画像データのないHEIC
+	brokenHEIC := []byte{0x00, 0x00, 0x00, 0x18, 'f', 't', 'y', 'p', 'm', 'i', 'f', '1', 0x00, 0x00, 0x00, 0x00, 'm', 'i', 'f', '1', 'h', 'e', 'i', 'c'}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantMime string
+		wantErr  error
+	}{
+		{name: "PNG", data: encodePNG(t, image.NewRGBA(image.Rect(0, 0, 4, 4))), wantMime: "image/png"},
+		{name: "still GIF", data: encodeGIF(1), wantMime: "image/gif"},
+		{name: "animated GIF", data: encodeGIF(2), wantErr: ErrAnimatedGIF},
+		{name: "broken HEIC", data: brokenHEIC, wantErr: ErrUnsupportedImageFormat},
+		{name: "unknown", data: []byte("not an image"), wantErr: ErrUnsupportedImageFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageData, err := NewImageData(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewImageData() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewImageData() error = %v", err)
+			}
+			if imageData.MimeType() != tt.wantMime {
+				t.Errorf("MimeType() = %s, want %s", imageData.MimeType(), tt.wantMime)
+			}
+		})
+	}
+}
+
+// stubDecoder 決まった大きさ・画像を返すデコーダー
+type stubDecoder struct {
+	config  image.Config
+	img     image.Image
+	err     error
+	decoded int
+}
+
+func (d *stubDecoder) DecodeConfig(r io.Reader) (image.Config, error) {
+	return d.config, d.err
+}
+
+func (d *stubDecoder) Decode(r io.Reader) (image.Image, error) {
+	d.decoded++
+	return d.img, d.err
+}
+
+// setStubHEIFDecoders テストの間だけデコーダーを設定する
+func setStubHEIFDecoders(t *testing.T, decoders HEIFDecoders) {
+	t.Helper()
+
+	SetHEIFDecoders(decoders)
+	t.Cleanup(func() { SetHEIFDecoders(HEIFDecoders{}) })
+}
+
+// heifHeader ftypボックスだけのHEIF（中身はデコーダーが読む）
+func heifHeader(majorBrand string, compatibleBrands ...string) []byte {
+	data := []byte{0x00, 0x00, 0x00, byte(16 + 4*len(compatibleBrands)), 'f', 't', 'y', 'p'}
+	data = append(data, majorBrand...)
+	data = append(data, 0x00, 0x00, 0x00, 0x00)
+	for _, brand := range compatibleBrands {
+		data = append(data, brand...)
+	}
+	return data
+}
+
+func TestNewImageData_ConvertsHEIF(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.RGBA{R: 200, A: 255}), image.Point{}, draw.Src)
+	transparent := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+
+	config := image.Config{Width: 64, Height: 48}
+	heic := &stubDecoder{config: config, img: opaque}
+	avif := &stubDecoder{config: config, img: transparent}
+	setStubHEIFDecoders(t, HEIFDecoders{HEIC: heic, AVIF: avif})
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFormat ImageFormat
+	}{
+		{name: "HEIC", data: heifHeader("heic", "mif1", "heic"), wantFormat: JPEG},
+		{name: "HEIC with mif1 major brand", data: heifHeader("mif1", "mif1", "heic"), wantFormat: JPEG},
+		{name: "AVIF with transparency", data: heifHeader("avif", "mif1", "avif"), wantFormat: PNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageData, err := NewImageData(tt.data)
+			if err != nil {
+				t.Fatalf("NewImageData() error = %v", err)
+			}
+			if imageData.Format() != tt.wantFormat || imageData.MimeType() != tt.wantFormat.MimeType() {
+				t.Errorf("Format() = %s, MimeType() = %s, want %s", imageData.Format(), imageData.MimeType(), tt.wantFormat)
+			}
+			if detectHEIF(imageData.Data()) != heifKindNone {
+				t.Errorf("converted data should not be HEIF")
+			}
+
+			width, height, err := imageData.Dimensions()
+			if err != nil || width != 64 || height != 48 {
+				t.Errorf("Dimensions() = %dx%d, %v, want 64x48", width, height, err)
+			}
+		})
+	}
+
+	t.Run("animated AVIF is rejected", func(t *testing.T) {
+		if _, err := NewImageData(heifHeader("avis", "avif", "avis")); !errors.Is(err, ErrAnimatedImage) {
+			t.Errorf("NewImageData() error = %v, want %v", err, ErrAnimatedImage)
+		}
+	})
+}
+
+func TestNewImageData_HEIFTooManyPixels(t *testing.T) {
+	decoder := &stubDecoder{config: image.Config{Width: 10000, Height: 8000}, img: image.NewRGBA(image.Rect(0, 0, 1, 1))}
+	setStubHEIFDecoders(t, HEIFDecoders{HEIC: decoder})
+
+	// 記録された大きさで判定し、展開しない
+	if _, err := NewImageData(heifHeader("heic", "mif1", "heic")); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("NewImageData() error = %v, want %v", err, ErrTooManyPixels)
+	}
+	if decoder.decoded != 0 {
+		t.Errorf("Decode() called %d times, want 0", decoder.decoded)
+	}
+}
+
+func TestNewImageData_HEIFWithoutDecoder(t *testing.T) {
+	broken := &stubDecoder{err: errors.New("broken")}
+
+	tests := []struct {
+		name     string
+		decoders HEIFDecoders
+	}{
+		{name: "no decoders"},
+		{name: "AVIF decoder only", decoders: HEIFDecoders{AVIF: broken}},
+		{name: "broken file", decoders: HEIFDecoders{HEIC: broken}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setStubHEIFDecoders(t, tt.decoders)
+			if _, err := NewImageData(heifHeader("heic", "mif1", "heic")); !errors.Is(err, ErrUnsupportedImageFormat) {
+				t.Errorf("NewImageData() error = %v, want %v", err, ErrUnsupportedImageFormat)
+			}
+		})
+	}
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to create test PNG: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -2,6 +2,10 @@ package valueobjects
 
 import (
 	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
 )
 
 type PersonGeneration string
@@ -26,6 +30,25 @@ const (
 	MimeTypeJPEG MimeType = "image/jpeg"
 )
 
+// 指定できる値の一覧（パラメータのスキーマにもそのまま使う）
+var (
+	PersonGenerations = []PersonGeneration{AllowAdult, AllowAll, DontAllow}
+	SafetySettings    = []SafetySetting{BlockMediumAndAbove, BlockLowAndAbove, BlockOnlyHigh, BlockNone}
+	OutputMimeTypes   = []MimeType{MimeTypePNG, MimeTypeJPEG}
+)
+
+// 数値パラメータの範囲
+const (
+	MinBaseSteps          = 1
+	MaxBaseSteps          = 100
+	MinSampleCount        = 1
+	MaxSampleCount        = 4
+	MinCompressionQuality = 0
+	MaxCompressionQuality = 100
+	// APIにはint32で渡す（0は指定なし）
+	MaxSeed = math.MaxInt32
+)
+
 type TryOnParameters struct {
 	addWatermark       bool
 	baseSteps          int
@@ -49,16 +72,38 @@ func NewTryOnParameters(
 	outputMimeType MimeType,
 	compressionQuality int,
 ) (*TryOnParameters, error) {
-	if baseSteps < 1 || baseSteps > 100 {
-		return nil, fmt.Errorf("baseSteps must be between 1 and 100, got %d", baseSteps)
+	if baseSteps < MinBaseSteps || baseSteps > MaxBaseSteps {
+		return nil, fmt.Errorf("baseSteps must be between %d and %d, got %d", MinBaseSteps, MaxBaseSteps, baseSteps)
+	}
+
+	if !slices.Contains(PersonGenerations, personGeneration) {
+		return nil, fmt.Errorf("unsupported personGeneration: %q", personGeneration)
+	}
+
+	if !slices.Contains(SafetySettings, safetySetting) {
+		return nil, fmt.Errorf("unsupported safetySetting: %q", safetySetting)
+	}
+
+	if sampleCount < MinSampleCount || sampleCount > MaxSampleCount {
+		return nil, fmt.Errorf("sampleCount must be between %d and %d, got %d", MinSampleCount, MaxSampleCount, sampleCount)
 	}
 
-	if sampleCount < 1 || sampleCount > 4 {
-		return nil, fmt.Errorf("sampleCount must be between 1 and 4, got %d", sampleCount)
+	if seed < 0 || seed > MaxSeed {
+		return nil, fmt.Errorf("seed must be between 0 and %d, got %d", MaxSeed, seed)
 	}
 
-	if compressionQuality < 0 || compressionQuality > 100 {
-		return nil, fmt.Errorf("compressionQuality must be between 0 and 100, got %d", compressionQuality)
+	if !slices.Contains(OutputMimeTypes, outputMimeType) {
+		return nil, fmt.Errorf("unsupported outputMimeType: %q", outputMimeType)
+	}
+
+	if compressionQuality < MinCompressionQuality || compressionQuality > MaxCompressionQuality {
+		return nil, fmt.Errorf("compressionQuality must be between %d and %d, got %d", MinCompressionQuality, MaxCompressionQuality, compressionQuality)
+	}
+
+	if storageURI != "" {
+		if err := ValidateStorageURI(storageURI); err != nil {
+			return nil, err
+		}
 	}
 
 	return &TryOnParameters{
@@ -74,6 +119,24 @@ func NewTryOnParameters(
 	}, nil
 }
 
+// ValidateStorageURI 保存先は scheme://bucket/prefix の形式（例: gs://my-bucket/tryon/）にする
+func ValidateStorageURI(storageURI string) error {
+	u, err := url.Parse(storageURI)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("storageURI must be in the form scheme://bucket/prefix, got %q", storageURI)
+	}
+	if strings.Contains(u.Path, "..") {
+		return fmt.Errorf("storageURI must not contain \"..\", got %q", storageURI)
+	}
+	return nil
+}
+
+// StorageSampleURI 保存先に書き込む index 番目の生成画像のURI（prefix/sample_N.拡張子）。
+// フェイクバックエンドとスタブで同じ配置にするため、prefix の末尾の "/" の有無は問わない
+func StorageSampleURI(prefix string, index int, format ImageFormat) string {
+	return fmt.Sprintf("%s/sample_%d.%s", strings.TrimRight(prefix, "/"), index, format)
+}
+
 func DefaultTryOnParameters() *TryOnParameters {
 	params, _ := NewTryOnParameters(
 		true,
@@ -113,6 +176,7 @@ func (p *TryOnParameters) Seed() int {
 	return p.seed
 }
 
+// StorageURI 生成画像の保存先。空の場合は画像をレスポンスで受け取る
 func (p *TryOnParameters) StorageURI() string {
 	return p.storageURI
 }
@@ -10,6 +10,9 @@ func TestNewTryOnParameters(t *testing.T) {
 		baseSteps          int
 		sampleCount        int
 		compressionQuality int
+		storageURI         string
+		seed               int
+		mimeType           MimeType
 		wantErr            bool
 	}{
 		{
@@ -61,19 +64,63 @@ func TestNewTryOnParameters(t *testing.T) {
 			compressionQuality: 101,
 			wantErr:            true,
 		},
+		{
+			name:               "valid storageURI",
+			baseSteps:          32,
+			sampleCount:        1,
+			compressionQuality: 75,
+			storageURI:         "gs://my-bucket/tryon/",
+			wantErr:            false,
+		},
+		{
+			name:               "storageURI without scheme",
+			baseSteps:          32,
+			sampleCount:        1,
+			compressionQuality: 75,
+			storageURI:         "my-bucket/tryon/",
+			wantErr:            true,
+		},
+		{
+			name:               "storageURI with parent directory",
+			baseSteps:          32,
+			sampleCount:        1,
+			compressionQuality: 75,
+			storageURI:         "local://outputs/../secrets/",
+			wantErr:            true,
+		},
+		{
+			name:               "negative seed",
+			baseSteps:          32,
+			sampleCount:        1,
+			compressionQuality: 75,
+			seed:               -1,
+			wantErr:            true,
+		},
+		{
+			name:               "unsupported mime type",
+			baseSteps:          32,
+			sampleCount:        1,
+			compressionQuality: 75,
+			mimeType:           "image/gif",
+			wantErr:            true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mimeType := tt.mimeType
+			if mimeType == "" {
+				mimeType = MimeTypePNG
+			}
 			_, err := NewTryOnParameters(
 				true,
 				tt.baseSteps,
 				AllowAdult,
 				BlockMediumAndAbove,
 				tt.sampleCount,
-				0,
-				"",
-				MimeTypePNG,
+				tt.seed,
+				tt.storageURI,
+				mimeType,
 				tt.compressionQuality,
 			)
 			if (err != nil) != tt.wantErr {
@@ -105,4 +152,12 @@ func TestDefaultTryOnParameters(t *testing.T) {
 	if params.OutputMimeType() != MimeTypePNG {
 		t.Errorf("Expected OutputMimeType PNG, got %v", params.OutputMimeType())
 	}
-}
\ No newline at end of file
+}
+
+func TestStorageSampleURI(t *testing.T) {
+	for _, prefix := range []string{"gs://bucket/out/req_1", "gs://bucket/out/req_1/", "gs://bucket/out/req_1//"} {
+		if got := StorageSampleURI(prefix, 2, PNG); got != "gs://bucket/out/req_1/sample_2.png" {
+			t.Errorf("StorageSampleURI(%q) = %s, want gs://bucket/out/req_1/sample_2.png", prefix, got)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package valueobjects
 
+import (
+	"encoding/binary"
+	"time"
+)
+
 type VideoData struct {
 	data []byte
 }
@@ -11,3 +16,62 @@ func NewVideoData(data []byte) *VideoData {
 func (v *VideoData) Data() []byte {
 	return v.data
 }
+
+// Duration MP4の長さ（moov/mvhd の値）。読み取れない場合は false
+func (v *VideoData) Duration() (time.Duration, bool) {
+	moov, ok := findMP4Box(v.data, "moov")
+	if !ok {
+		return 0, false
+	}
+	mvhd, ok := findMP4Box(moov, "mvhd")
+	if !ok || len(mvhd) < 4 {
+		return 0, false
+	}
+
+	var timescale, duration uint64
+	switch version := mvhd[0]; {
+	case version == 1 && len(mvhd) >= 32:
+		// version(1) flags(3) creation(8) modification(8) timescale(4) duration(8)
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	case version == 0 && len(mvhd) >= 20:
+		// version(1) flags(3) creation(4) modification(4) timescale(4) duration(4)
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	default:
+		return 0, false
+	}
+	if timescale == 0 {
+		return 0, false
+	}
+
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), true
+}
+
+// findMP4Box 同じ階層のボックスから type が一致するものの中身を返す
+func findMP4Box(data []byte, boxType string) ([]byte, bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		header := uint64(8)
+		switch size {
+		case 0:
+			// ファイルの終わりまで
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return nil, false
+		}
+
+		if string(data[4:8]) == boxType {
+			return data[header:size], true
+		}
+		data = data[size:]
+	}
+	return nil, false
+}
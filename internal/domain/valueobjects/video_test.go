@@ -0,0 +1,66 @@
+package valueobjects
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// mp4Box ボックスを組み立てる
+func mp4Box(boxType string, payload ...[]byte) []byte {
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+	box := binary.BigEndian.AppendUint32(nil, uint32(size))
+	box = append(box, boxType...)
+	for _, p := range payload {
+		box = append(box, p...)
+	}
+	return box
+}
+
+func TestVideoData_Duration(t *testing.T) {
+	// version 0: creation, modification, timescale=1000, duration=8000
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:20], 8000)
+
+	// version 1: 64bitの creation, modification, duration
+	mvhdV1 := make([]byte, 32)
+	mvhdV1[0] = 1
+	binary.BigEndian.PutUint32(mvhdV1[20:24], 90000)
+	binary.BigEndian.PutUint64(mvhdV1[24:32], 90000*5/2)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "version 0",
+			data:   append(mp4Box("ftyp", []byte("isom")), mp4Box("moov", mp4Box("mvhd", mvhd), mp4Box("trak"))...),
+			want:   8 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "version 1",
+			data:   mp4Box("moov", mp4Box("mvhd", mvhdV1)),
+			want:   2500 * time.Millisecond,
+			wantOK: true,
+		},
+		{name: "no moov", data: mp4Box("ftyp", []byte("isom"))},
+		{name: "truncated", data: mp4Box("moov", mp4Box("mvhd", mvhd))[:20]},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewVideoData(tt.data).Duration()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Duration() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
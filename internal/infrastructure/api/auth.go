@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/infrastructure/auth"
+)
+
+// APIKeyHeader APIキーを送るヘッダー（Authorization: Bearer でもよい）
+const APIKeyHeader = "X-API-Key"
+
+// APIキーなしで使えるルート（"METHOD パステンプレート"）。
+// 画面・静的ファイル・ヘルスチェックと、URLを知っていれば取得できる生成結果ファイル
+var publicRoutes = map[string]bool{
+	"GET /":                                true,
+	"GET /healthz":                         true,
+	"GET /imagen":                          true,
+	"GET /veo":                             true,
+	"GET /nanobanana/image-editing":        true,
+	"GET /history":                         true,
+	"GET /static/":                         true,
+	"GET /api/sample-images":               true,
+	"GET /api/sample-image":                true,
+	"GET /api/assets/{id}":                 true,
+	"HEAD /api/assets/{id}":                true,
+	"GET " + apiV1Prefix + "/openapi.json": true,
+}
+
+// 生成を行うルート。APIキーの生成機能ごとの1日あたりの上限で数える
+var generationRoutes = map[string]entities.GeneratorType{
+	"POST /tryon":                                       entities.GeneratorTryOn,
+	"POST " + apiV1Prefix + "/tryon":                    entities.GeneratorTryOn,
+	"POST /imagen":                                      entities.GeneratorImagen,
+	"POST " + apiV1Prefix + "/imagen":                   entities.GeneratorImagen,
+	"POST /veo":                                         entities.GeneratorVeo,
+	"POST " + apiV1Prefix + "/veo":                      entities.GeneratorVeo,
+	"POST /nanobanana/image-editing":                    entities.GeneratorNanobanana,
+	"POST " + apiV1Prefix + "/nanobanana/image-editing": entities.GeneratorNanobanana,
+}
+
+// AuthMiddleware APIキーを確かめ、キーごとの1分あたりのリクエスト数と生成機能ごとの1日の生成数を制限する。
+// 認証したキーのIDを利用量・履歴のクライアントとしてコンテキストに設定し、
+// 管理者でないキーが参照できる生成ジョブ・履歴・利用量をそのキーのものに限る。
+type AuthMiddleware struct {
+	keys   *auth.KeyStore
+	quotas *auth.Quotas
+}
+
+func NewAuthMiddleware(keys *auth.KeyStore, quotas *auth.Quotas) *AuthMiddleware {
+	return &AuthMiddleware{
+		keys:   keys,
+		quotas: quotas,
+	}
+}
+
+// Middleware - gorilla/mux のミドルウェア（ルートが決まった後に呼ばれる）
+func (m *AuthMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := currentRoute(r)
+
+		secret, ok := apiKeyFromRequest(r)
+		if !ok {
+			if publicRoutes[route] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tryon-demo"`)
+			sendErrorCode(w, "APIキーが必要です。X-API-Key ヘッダーか Authorization: Bearer で指定してください。",
+				http.StatusUnauthorized, errorCodeUnauthorized)
+			return
+		}
+
+		key, ok := m.keys.Lookup(secret)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tryon-demo", error="invalid_token"`)
+			sendErrorCode(w, "APIキーが正しくありません。", http.StatusUnauthorized, errorCodeUnauthorized)
+			return
+		}
+
+		status, err := m.quotas.AllowRequest(key)
+		if status.Limit > 0 {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(status.Reset.Seconds())))
+		}
+		if err != nil {
+			sendLimitError(w, err)
+			return
+		}
+
+		// 管理者でないキーには自分の生成ジョブ・履歴・利用量のみを見せる
+		ctx := usecases.WithClientScope(r.Context(), key.ID)
+		if key.Admin {
+			ctx = usecases.WithClient(r.Context(), key.ID)
+		}
+		r = r.WithContext(ctx)
+
+		generator, ok := generationRoutes[route]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// 1日の生成数は受け付けた生成リクエストのみ数える。
+		// 検証エラー・予算超過・順番待ちの満杯などで断った場合（4xx・5xx）は取り消す
+		release, err := m.quotas.AllowGeneration(key, generator)
+		if err != nil {
+			sendLimitError(w, err)
+			return
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if recorder.status >= http.StatusBadRequest {
+				release()
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// statusRecorder ハンドラーが返したステータスコードを記録する
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap http.ResponseController から元の ResponseWriter を使えるようにする
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// currentRoute - 一致したルートの "METHOD パステンプレート"
+func currentRoute(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return r.Method + " " + template
+}
+
+// apiKeyFromRequest - X-API-Key または Authorization: Bearer のキー
+func apiKeyFromRequest(r *http.Request) (string, bool) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, true
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		return strings.TrimSpace(token), true
+	}
+	return "", false
+}
+
+// sendLimitError - APIキーの上限に達した場合の429
+func sendLimitError(w http.ResponseWriter, err error) {
+	var limitErr *auth.LimitError
+	if !errors.As(err, &limitErr) {
+		sendError(w, "リクエストの受け付けに失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	retryAfter := ceilSeconds(limitErr.RetryAfter().Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	if limitErr.Kind == auth.LimitDaily {
+		sendErrorCode(w, fmt.Sprintf(
+			"このAPIキーの本日の%sの回数（%d回）の上限に達しました。明日以降に再試行してください。",
+			generatorLabel(limitErr.Generator), limitErr.Max,
+		), http.StatusTooManyRequests, errorCodeDailyQuotaExceeded)
+		return
+	}
+	sendErrorCode(w, fmt.Sprintf(
+		"リクエストが多すぎます。このAPIキーは1分あたり%d回までです。%d秒後に再試行してください。",
+		limitErr.Max, retryAfter,
+	), http.StatusTooManyRequests, errorCodeRateLimited)
+}
+
+// generatorLabel - 生成機能の表示名
+func generatorLabel(generator entities.GeneratorType) string {
+	switch generator {
+	case entities.GeneratorTryOn:
+		return "バーチャル試着"
+	case entities.GeneratorImagen:
+		return "画像生成"
+	case entities.GeneratorVeo:
+		return "動画生成"
+	case entities.GeneratorNanobanana:
+		return "画像編集"
+	default:
+		return string(generator)
+	}
+}
+
+func ceilSeconds(seconds float64) int {
+	return max(1, int(math.Ceil(seconds)))
+}
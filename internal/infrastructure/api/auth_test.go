@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/infrastructure/auth"
+)
+
+func newAuthTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+
+	keys := auth.NewKeyStore()
+	if err := keys.Add("web-secret", auth.Key{
+		ID:            "web",
+		RatePerMinute: 3,
+		DailyQuota:    map[entities.GeneratorType]int{entities.GeneratorImagen: 1},
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// 利用量・履歴のクライアントをそのまま返す
+	echoClient := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(usecases.ClientFromContext(r.Context())))
+	}
+
+	r := mux.NewRouter()
+	r.Use(NewAuthMiddleware(keys, auth.NewQuotas()).Middleware)
+	r.HandleFunc("/healthz", echoClient).Methods("GET")
+	r.HandleFunc("/imagen", echoClient).Methods("POST")
+	r.HandleFunc("/api/history", echoClient).Methods("GET")
+	return r
+}
+
+func serveAuthTest(r *mux.Router, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCodeOf(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var response ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid error response %q: %v", rec.Body.String(), err)
+	}
+	return response.Code
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	r := newAuthTestRouter(t)
+	withKey := http.Header{}
+	withKey.Set(APIKeyHeader, "web-secret")
+
+	// 公開しているルートはキーなしでも使える
+	if rec := serveAuthTest(r, http.MethodGet, "/healthz", nil); rec.Code != http.StatusOK || rec.Body.String() != entities.AnonymousClient {
+		t.Errorf("GET /healthz without a key: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec := serveAuthTest(r, http.MethodGet, "/api/history", nil)
+	if rec.Code != http.StatusUnauthorized || errorCodeOf(t, rec) != errorCodeUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("GET /api/history without a key: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = serveAuthTest(r, http.MethodGet, "/healthz", http.Header{"Authorization": {"Bearer wrong"}})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /healthz with a wrong key: %d", rec.Code)
+	}
+
+	// キーのIDをクライアントとして扱う
+	rec = serveAuthTest(r, http.MethodPost, "/imagen", http.Header{"Authorization": {"Bearer web-secret"}})
+	if rec.Code != http.StatusOK || rec.Body.String() != "web" {
+		t.Errorf("POST /imagen with a bearer token: %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-RateLimit-Remaining"); got != "2" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 2", got)
+	}
+
+	// 生成機能ごとの1日の上限
+	rec = serveAuthTest(r, http.MethodPost, "/imagen", withKey)
+	if rec.Code != http.StatusTooManyRequests || errorCodeOf(t, rec) != errorCodeDailyQuotaExceeded {
+		t.Errorf("POST /imagen over the daily quota: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 1分あたりの上限（3回目までは受け付ける）
+	if rec := serveAuthTest(r, http.MethodGet, "/api/history", withKey); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/history with a key: %d", rec.Code)
+	}
+	rec = serveAuthTest(r, http.MethodGet, "/api/history", withKey)
+	if rec.Code != http.StatusTooManyRequests || errorCodeOf(t, rec) != errorCodeRateLimited {
+		t.Errorf("GET /api/history over the rate limit: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") != "20" {
+		t.Errorf("Retry-After = %q, want 20", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestAuthMiddleware_ClientScope(t *testing.T) {
+	keys := auth.NewKeyStore()
+	for secret, key := range map[string]auth.Key{
+		"web-secret":   {ID: "web"},
+		"admin-secret": {ID: "ops", Admin: true},
+	} {
+		if err := keys.Add(secret, key); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	// 参照できる範囲を限るクライアント（限らない場合は "*"）を返す
+	r := mux.NewRouter()
+	r.Use(NewAuthMiddleware(keys, auth.NewQuotas()).Middleware)
+	r.HandleFunc("/api/history", func(w http.ResponseWriter, r *http.Request) {
+		client, scoped := usecases.ScopedClient(r.Context())
+		if !scoped {
+			client = "*"
+		}
+		w.Write([]byte(client))
+	}).Methods("GET")
+
+	for secret, want := range map[string]string{"web-secret": "web", "admin-secret": "*"} {
+		header := http.Header{}
+		header.Set(APIKeyHeader, secret)
+		if rec := serveAuthTest(r, http.MethodGet, "/api/history", header); rec.Body.String() != want {
+			t.Errorf("scope for %s = %q, want %q", secret, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestAuthMiddleware_DailyQuotaCountsAcceptedGenerations(t *testing.T) {
+	keys := auth.NewKeyStore()
+	if err := keys.Add("web-secret", auth.Key{
+		ID:         "web",
+		DailyQuota: map[entities.GeneratorType]int{entities.GeneratorImagen: 1},
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// ?status= のステータスコードを返す
+	r := mux.NewRouter()
+	r.Use(NewAuthMiddleware(keys, auth.NewQuotas()).Middleware)
+	r.HandleFunc("/imagen", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("status") {
+		case "400":
+			sendError(w, "invalid", http.StatusBadRequest)
+		case "503":
+			sendError(w, "busy", http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}).Methods("POST")
+
+	withKey := http.Header{}
+	withKey.Set(APIKeyHeader, "web-secret")
+
+	// 断ったリクエストは1日の生成数に数えない
+	for _, status := range []int{http.StatusBadRequest, http.StatusServiceUnavailable} {
+		if rec := serveAuthTest(r, http.MethodPost, fmt.Sprintf("/imagen?status=%d", status), withKey); rec.Code != status {
+			t.Errorf("POST /imagen?status=%d: %d %s", status, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serveAuthTest(r, http.MethodPost, "/imagen", withKey); rec.Code != http.StatusOK {
+		t.Errorf("POST /imagen after rejected requests: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 受け付けた分で上限に達する
+	rec := serveAuthTest(r, http.MethodPost, "/imagen", withKey)
+	if rec.Code != http.StatusTooManyRequests || errorCodeOf(t, rec) != errorCodeDailyQuotaExceeded {
+		t.Errorf("POST /imagen over the daily quota: %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	appservices "tryon-demo/internal/application/services"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/services"
+)
+
+// エラーレスポンスの code（ドメインエラーの種類以外）
+const (
+	errorCodeNotFound         = "not_found"
+	errorCodeMethodNotAllowed = "method_not_allowed"
+	errorCodeConflict         = "conflict"
+	errorCodePayloadTooLarge  = "payload_too_large"
+	// 対応していない画像形式（HEIC・アニメーションGIFなど）
+	errorCodeUnsupportedMediaType = "unsupported_media_type"
+	// APIキーがない・正しくない
+	errorCodeUnauthorized = "unauthorized"
+	// APIキーの1分あたりのリクエスト数の上限に達した
+	errorCodeRateLimited = "rate_limited"
+	// APIキーの生成機能ごとの1日あたりの上限に達した
+	errorCodeDailyQuotaExceeded = "daily_quota_exceeded"
+)
+
+// ErrorResponse 全ハンドラー共通のエラーレスポンス
+type ErrorResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	// 入力項目ごとの検証エラー（invalid_input の場合のみ）
+	Fields []FieldErrorResponse `json:"fields,omitempty"`
+}
+
+// FieldErrorResponse 入力項目ごとの検証エラー
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// sendError - エラーレスポンスを送信（code はステータスコードから決める）
+func sendError(w http.ResponseWriter, message string, statusCode int) {
+	sendErrorCode(w, message, statusCode, errorCodeForStatus(statusCode))
+}
+
+// sendErrorCode - code を指定してエラーレスポンスを送信
+func sendErrorCode(w http.ResponseWriter, message string, statusCode int, code string) {
+	writeErrorResponse(w, statusCode, ErrorResponse{Error: message, Code: code})
+}
+
+func writeErrorResponse(w http.ResponseWriter, statusCode int, response ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode error response: %v", err)
+	}
+}
+
+// retryAfterError 再試行までの目安を持つエラー（順番待ちが満杯の場合など）
+type retryAfterError interface {
+	RetryAfter() time.Duration
+}
+
+// sendGenerationError - 生成処理のエラーを種類に応じたステータスとメッセージで返す
+func sendGenerationError(w http.ResponseWriter, err error, action string) {
+	kind := domainerrors.KindOf(err)
+
+	var retryAfter retryAfterError
+	if kind == domainerrors.KindServerBusy && errors.As(err, &retryAfter) && retryAfter.RetryAfter() > 0 {
+		seconds := int(math.Ceil(retryAfter.RetryAfter().Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	if fields := domainerrors.FieldErrorsOf(err); len(fields) > 0 {
+		writeErrorResponse(w, statusForKind(kind), validationErrorResponse(fields))
+		return
+	}
+	sendErrorCode(w, generationErrorMessage(kind, err, action), statusForKind(kind), string(kind))
+}
+
+// validationErrorResponse - 入力項目ごとの検証エラーを利用者向けのメッセージにする
+func validationErrorResponse(fields []domainerrors.FieldError) ErrorResponse {
+	response := ErrorResponse{
+		Code:   string(domainerrors.KindInvalidInput),
+		Fields: make([]FieldErrorResponse, 0, len(fields)),
+	}
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		message := fieldLabel(field.Field) + ": " + fieldErrorMessage(field.Code)
+		messages = append(messages, message)
+		response.Fields = append(response.Fields, FieldErrorResponse{
+			Field:   field.Field,
+			Code:    field.Code,
+			Message: message,
+			Detail:  field.Message,
+		})
+	}
+	heading := "入力内容を確認してください。"
+	if isImageField(fields[0].Field) {
+		heading = "入力画像を確認してください。"
+	}
+	response.Error = heading + strings.Join(messages, " / ")
+
+	return response
+}
+
+// isImageField - 入力画像の項目かどうか（パラメータの項目は名前をそのまま表示する）
+func isImageField(field string) bool {
+	return field == services.PreflightFieldPersonImage || strings.HasPrefix(field, "garmentImages[")
+}
+
+// fieldLabel - 入力項目の表示名
+func fieldLabel(field string) string {
+	if field == services.PreflightFieldPersonImage {
+		return "人物画像"
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(field, "garmentImages[%d]", &index); err == nil {
+		return fmt.Sprintf("衣服画像%d", index+1)
+	}
+	return field
+}
+
+// fieldErrorMessage - 検証エラーの種類ごとのメッセージ
+func fieldErrorMessage(code string) string {
+	switch code {
+	case services.PreflightCodeUnreadable:
+		return "画像を読み込めませんでした"
+	case services.PreflightCodeTooSmall:
+		return "解像度が低すぎます。より大きな画像を使ってください"
+	case services.PreflightCodeTooLarge:
+		return "解像度が高すぎます。縮小してから再度お試しください"
+	case services.PreflightCodeAspectRatio:
+		return "縦横比が極端です。トリミングしてから再度お試しください"
+	case services.PreflightCodeNoPerson:
+		return "人物が写っていません"
+	case services.PreflightCodeMultiplePeople:
+		return "複数の人物が写っています。1人だけ写った画像を使ってください"
+	case services.PreflightCodeNotGarment:
+		return "衣服の画像ではないようです"
+	case appservices.ParameterCodeInvalidNumber:
+		return "整数で指定してください"
+	case appservices.ParameterCodeInvalidBoolean:
+		return "true または false で指定してください"
+	case appservices.ParameterCodeOutOfRange:
+		return "指定できる範囲を超えています"
+	case appservices.ParameterCodeInvalidChoice:
+		return "指定できない値です"
+	case appservices.ParameterCodeInvalidFormat:
+		return "形式が正しくありません"
+	case appservices.ParameterCodeConflict:
+		return "他の設定により使われないため指定できません"
+	case fieldCodeInvalidType:
+		return "値の型が正しくありません"
+	default:
+		return "入力内容が不正です"
+	}
+}
+
+// statusForKind - ドメインエラーの種類に対応するHTTPステータス
+func statusForKind(kind domainerrors.Kind) int {
+	switch kind {
+	case domainerrors.KindInvalidInput:
+		return http.StatusBadRequest
+	case domainerrors.KindQuotaExhausted:
+		return http.StatusTooManyRequests
+	case domainerrors.KindSafetyBlocked:
+		return http.StatusUnprocessableEntity
+	case domainerrors.KindUpstreamTimeout:
+		return http.StatusGatewayTimeout
+	case domainerrors.KindModelUnavailable, domainerrors.KindServerBusy:
+		return http.StatusServiceUnavailable
+	case domainerrors.KindBudgetExceeded:
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// generationErrorMessage - 利用者向けのエラーメッセージ
+func generationErrorMessage(kind domainerrors.Kind, err error, action string) string {
+	switch kind {
+	case domainerrors.KindInvalidInput:
+		return fmt.Sprintf("%sに失敗しました。入力内容を確認してください: %v", action, err)
+	case domainerrors.KindQuotaExhausted:
+		return "現在サーバーが混雑しています。しばらく待ってから再試行してください。"
+	case domainerrors.KindSafetyBlocked:
+		return "安全性フィルタにより生成されませんでした。露出や著名人・ロゴ類を避け、プロンプトや画像を変更して再試行してください。"
+	case domainerrors.KindUpstreamTimeout:
+		return fmt.Sprintf("%sがタイムアウトしました。しばらく待ってから再試行してください。", action)
+	case domainerrors.KindModelUnavailable:
+		return "選択したモデルは現在利用できません。別のモデルを選ぶか、しばらく待ってから再試行してください。"
+	case domainerrors.KindServerBusy:
+		return "順番待ちのリクエストが上限に達しました。しばらく待ってから再試行してください。"
+	case domainerrors.KindBudgetExceeded:
+		return "本日の利用額が上限に達しました。明日以降に再試行してください。"
+	default:
+		return fmt.Sprintf("%sに失敗しました: %v", action, err)
+	}
+}
+
+// errorCodeForStatus - ステータスコードに対応する code
+func errorCodeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return string(domainerrors.KindInvalidInput)
+	case http.StatusUnauthorized:
+		return errorCodeUnauthorized
+	case http.StatusNotFound:
+		return errorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return errorCodeMethodNotAllowed
+	case http.StatusConflict:
+		return errorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return errorCodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return errorCodeUnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return string(domainerrors.KindQuotaExhausted)
+	case http.StatusPaymentRequired:
+		return string(domainerrors.KindBudgetExceeded)
+	case http.StatusServiceUnavailable:
+		return string(domainerrors.KindModelUnavailable)
+	case http.StatusGatewayTimeout:
+		return string(domainerrors.KindUpstreamTimeout)
+	default:
+		return string(domainerrors.KindInternal)
+	}
+}
@@ -1,7 +1,7 @@
 package api
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,11 +10,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"tryon-demo/internal/application/services"
 	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	domainservices "tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
 const maxFileSize = 10 * 1024 * 1024 // 10MB
@@ -22,102 +25,73 @@ const maxFileSize = 10 * 1024 * 1024 // 10MB
 type TryOnHandler struct {
 	tryOnUseCase     *usecases.TryOnUseCase
 	parameterService *services.ParameterService
+	imageFetcher     ImageFetcher
+	results          *resultResponder
 	location         string // Vertex AIのリージョン情報
 }
 
 type ImagenHandler struct {
-	imagenUseCase *usecases.ImagenUseCase
-	location      string // Vertex AIのリージョン情報
+	imagenUseCase    *usecases.ImagenUseCase
+	parameterService *services.ParameterService
+	models           *domainservices.ModelCatalog
+	results          *resultResponder
+	location         string // Vertex AIのリージョン情報
 }
 
 type VeoHandler struct {
-	veoUseCase *usecases.VeoUseCase
-	location   string // Vertex AIのリージョン情報
-}
-
-// ImagenModel represents an available Imagen model
-type ImagenModel struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
-
-// VeoModel represents an available Veo model
-type VeoModel struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
-
-// サポートされるImagenモデル一覧
-var supportedImagenModels = []ImagenModel{
-	{
-		ID:          "imagen-4.0-ultra-generate-001",
-		Name:        "Imagen 4.0 Ultra",
-		Description: "最高品質・最新モデル（処理時間長）",
-	},
-	{
-		ID:          "imagen-4.0-fast-generate-001",
-		Name:        "Imagen 4.0 Fast",
-		Description: "高品質・高速処理",
-	},
-	{
-		ID:          "imagen-4.0-generate-001",
-		Name:        "Imagen 4.0",
-		Description: "高品質・標準処理",
-	},
-	{
-		ID:          "imagen-3.0-generate-002",
-		Name:        "Imagen 3.0 v2",
-		Description: "安定版（推奨）",
-	},
+	veoUseCase   *usecases.VeoUseCase
+	models       *domainservices.ModelCatalog
+	imageFetcher ImageFetcher
+	results      *resultResponder
+	location     string // Vertex AIのリージョン情報
 }
 
 func NewTryOnHandler(
 	tryOnUseCase *usecases.TryOnUseCase,
 	parameterService *services.ParameterService,
+	assetUseCase *usecases.AssetUseCase,
+	imageFetcher ImageFetcher,
 	location string,
 ) *TryOnHandler {
 	return &TryOnHandler{
 		tryOnUseCase:     tryOnUseCase,
 		parameterService: parameterService,
+		imageFetcher:     imageFetcher,
+		results:          newResultResponder(assetUseCase),
 		location:         location,
 	}
 }
 
 func NewImagenHandler(
 	imagenUseCase *usecases.ImagenUseCase,
+	parameterService *services.ParameterService,
+	models *domainservices.ModelCatalog,
+	assetUseCase *usecases.AssetUseCase,
 	location string,
 ) *ImagenHandler {
 	return &ImagenHandler{
-		imagenUseCase: imagenUseCase,
-		location:      location,
+		imagenUseCase:    imagenUseCase,
+		parameterService: parameterService,
+		models:           models,
+		results:          newResultResponder(assetUseCase),
+		location:         location,
 	}
 }
 
 func NewVeoHandler(
 	veoUseCase *usecases.VeoUseCase,
+	models *domainservices.ModelCatalog,
+	assetUseCase *usecases.AssetUseCase,
+	imageFetcher ImageFetcher,
 	location string,
 ) *VeoHandler {
 	return &VeoHandler{
-		veoUseCase: veoUseCase,
-		location:   location,
-	}
-}
-
-// isValidImagenModel - 指定されたモデルIDが有効かどうかチェック
-func (h *ImagenHandler) isValidImagenModel(modelID string) bool {
-	for _, model := range supportedImagenModels {
-		if model.ID == modelID {
-			return true
-		}
+		veoUseCase:   veoUseCase,
+		models:       models,
+		imageFetcher: imageFetcher,
+		results:      newResultResponder(assetUseCase),
+		location:     location,
 	}
-	return false
-}
-
-// getDefaultImagenModel - デフォルトのImagenモデルIDを取得
-func (h *ImagenHandler) getDefaultImagenModel() string {
-	return "imagen-3.0-generate-002" // 安定版を推奨
 }
 
 // 画像生成を行わず、サンプル画像を返す
@@ -194,153 +168,327 @@ func (h *TryOnHandler) getSampleImages(sampleCount int) ([]usecases.ImageOutput,
 }
 
 func (h *TryOnHandler) HandleTryOn(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
-	if err := r.ParseMultipartForm(maxFileSize); err != nil {
-		h.sendError(w, "画像が大きすぎます（10MBまで対応）", http.StatusRequestEntityTooLarge)
+	// multipart/form-data とJSONのどちらも同じ入力にして試着する
+	var (
+		input usecases.TryOnInput
+		ok    bool
+	)
+	if isJSONRequest(r) {
+		input, ok = h.parseTryOnJSON(w, r)
+	} else {
+		input, ok = h.parseTryOnForm(w, r)
+	}
+	if !ok {
+		return
+	}
+
+	output, err := h.tryOnUseCase.Execute(r.Context(), input)
+	if err != nil {
+		log.Printf("Virtual Try-On failed: %v", err)
+		sendGenerationError(w, err, "生成")
+		return
+	}
+
+	if output == nil {
+		log.Printf("Virtual Try-On returned nil output")
+		sendError(w, "生成に失敗しました: 結果が取得できませんでした", http.StatusInternalServerError)
 		return
 	}
 
-	personFile, personFileHeader, err := r.FormFile("person_image")
+	mode := negotiateResultMode(r)
+	files := h.createResultFiles(output.Images)
+
+	garments, stepFiles := h.createGarmentEntries(output)
+
+	response, err := h.createResponse(r.Context(), mode, files)
+	if err == nil && len(stepFiles) > 0 {
+		response.Intermediates, err = h.results.fileEntries(r.Context(), mode, stepFiles)
+	}
 	if err != nil {
-		h.sendError(w, "人物画像を選んでください", http.StatusBadRequest)
+		log.Printf("Failed to create response: %v", err)
+		sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	response.Mode = output.Mode
+	response.Garments = garments
+	response.Partial = output.Succeeded() < len(output.Garments)
+
+	if err := h.results.write(w, mode, response, append(files, stepFiles...)); err != nil {
+		log.Printf("Failed to write response: %v", err)
 		return
 	}
-	// mimeTypeを取得
-	personMimeType := personFileHeader.Header.Get("Content-Type")
-	defer personFile.Close()
+}
+
+// parseTryOnForm - multipart/form-data の試着リクエストを読み取る。失敗した場合はエラーレスポンスを送信する
+func (h *TryOnHandler) parseTryOnForm(w http.ResponseWriter, r *http.Request) (usecases.TryOnInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
+	if err := r.ParseMultipartForm(maxFileSize); err != nil {
+		sendError(w, "画像が大きすぎます（10MBまで対応）", http.StatusRequestEntityTooLarge)
+		return usecases.TryOnInput{}, false
+	}
+
+	personImage, err := formImageUpload(r, "person_image")
+	if err != nil {
+		sendUploadError(w, err, "人物画像")
+		return usecases.TryOnInput{}, false
+	}
+	if personImage == nil {
+		sendError(w, "人物画像を選んでください", http.StatusBadRequest)
+		return usecases.TryOnInput{}, false
+	}
+	slog.Info("personFileData", "mimeType", personImage.MimeType(), "dataSize", len(personImage.Data()))
 
 	// 複数ファイルを受け取るように修正
 	garmentFiles := r.MultipartForm.File["garment_image"]
 	if len(garmentFiles) == 0 {
-		h.sendError(w, "衣服画像を選んでください", http.StatusBadRequest)
-		return
+		sendError(w, "衣服画像を選んでください", http.StatusBadRequest)
+		return usecases.TryOnInput{}, false
 	}
 
 	var garmentFileData []usecases.GarmentImageData
-	for _, file := range garmentFiles {
-		garmentFile, err := file.Open()
+	for i, file := range garmentFiles {
+		garmentImage, err := readImageUpload(file)
 		if err != nil {
-			h.sendError(w, "衣服画像の読み込みに失敗しました", http.StatusInternalServerError)
-			return
-		}
-
-		defer garmentFile.Close()
-		data, err := io.ReadAll(garmentFile)
-		if err != nil {
-			h.sendError(w, "衣服画像の読み込みに失敗しました", http.StatusInternalServerError)
-			return
+			sendUploadError(w, err, fmt.Sprintf("衣服画像%d", i+1))
+			return usecases.TryOnInput{}, false
 		}
-		slog.Info("garmentFileData", "garmentFileData", file.Header.Get("Content-Type"), "dataSize", len(data))
+		slog.Info("garmentFileData", "mimeType", garmentImage.MimeType(), "dataSize", len(garmentImage.Data()))
 
 		garmentFileData = append(garmentFileData, usecases.GarmentImageData{
-			Data:     data,
-			MimeType: file.Header.Get("Content-Type"),
+			Data: garmentImage.Data(),
 		})
 	}
 
-	personFileData, err := io.ReadAll(personFile)
-	if err != nil {
-		h.sendError(w, "人物画像の読み込みに失敗しました", http.StatusInternalServerError)
-		return
+	// 衣服の種類は garment_image と同じ順番で指定する（省略可）
+	for i, category := range r.MultipartForm.Value["garment_category"] {
+		if i < len(garmentFileData) {
+			garmentFileData[i].Category = category
+		}
 	}
 
-	slog.Info("personFileData", "personFileData", personMimeType, "dataSize", len(personFileData))
-
-	parameters := h.parameterService.ParseFromRequest(r)
+	parameters, err := h.parameterService.ParseTryOn(r.Context(), r.Form)
+	if err != nil {
+		log.Printf("Failed to parse parameters: %v", err)
+		sendGenerationError(w, err, "パラメータの読み込み")
+		return usecases.TryOnInput{}, false
+	}
 
-	input := usecases.TryOnInput{
-		PersonImageData:  personFileData,
-		PersonMimeType:   personMimeType,
+	return usecases.TryOnInput{
+		PersonImageData:  personImage.Data(),
 		GarmentImageData: garmentFileData,
+		Mode:             usecases.TryOnMode(r.FormValue("tryon_mode")),
 		Parameters:       parameters,
+	}, true
+}
+
+// parseTryOnJSON - JSONの試着リクエストを読み取る。画像はBase64またはURLで指定する
+func (h *TryOnHandler) parseTryOnJSON(w http.ResponseWriter, r *http.Request) (usecases.TryOnInput, bool) {
+	var request TryOnRequest
+	if !decodeJSONRequest(w, r, maxJSONBodySize, &request) {
+		return usecases.TryOnInput{}, false
 	}
 
-	output, err := h.tryOnUseCase.Execute(r.Context(), input)
+	personImage, err := loadImageSource(r.Context(), h.imageFetcher, request.PersonImage)
 	if err != nil {
-		log.Printf("Virtual Try-On failed: %v", err)
-
-		if h.isQuotaError(err) {
-			h.sendError(w, "現在サーバーが混雑しています。しばらく待ってから再試行してください。", http.StatusTooManyRequests)
-			return
-		}
-
-		hint := "ヒント: 露出や著名人・ロゴ類・過度な加工を避け、人物と衣服がはっきり写る画像で再試行してください。"
-		h.sendError(w, fmt.Sprintf("生成に失敗しました: %v %s", err, hint), http.StatusInternalServerError)
-		return
+		sendUploadError(w, err, "人物画像")
+		return usecases.TryOnInput{}, false
+	}
+	if personImage == nil {
+		sendError(w, "人物画像（person_image）を指定してください", http.StatusBadRequest)
+		return usecases.TryOnInput{}, false
 	}
 
-	if output == nil {
-		log.Printf("Virtual Try-On returned nil output")
-		h.sendError(w, "生成に失敗しました: 結果が取得できませんでした", http.StatusInternalServerError)
-		return
+	if len(request.GarmentImages) == 0 {
+		sendError(w, "衣服画像（garment_images）を指定してください", http.StatusBadRequest)
+		return usecases.TryOnInput{}, false
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	garmentImageData := make([]usecases.GarmentImageData, 0, len(request.GarmentImages))
+	for i, source := range request.GarmentImages {
+		label := fmt.Sprintf("衣服画像%d", i+1)
+		garmentImage, err := loadImageSource(r.Context(), h.imageFetcher, source.ImageSource)
+		if err != nil {
+			sendUploadError(w, err, label)
+			return usecases.TryOnInput{}, false
+		}
+		if garmentImage == nil {
+			sendError(w, label+"の data または url を指定してください", http.StatusBadRequest)
+			return usecases.TryOnInput{}, false
+		}
 
-	response := h.createResponse(output.Images)
+		garmentImageData = append(garmentImageData, usecases.GarmentImageData{
+			Data:     garmentImage.Data(),
+			Category: source.Category,
+		})
+	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode JSON response: %v", err)
-		h.sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
-		return
+	parameters, err := h.parameterService.ParseTryOn(r.Context(), request.parameterValues())
+	if err != nil {
+		log.Printf("Failed to parse parameters: %v", err)
+		sendGenerationError(w, err, "パラメータの読み込み")
+		return usecases.TryOnInput{}, false
 	}
-}
 
-func (h *TryOnHandler) createResponse(imagesOutput []usecases.ImageOutput) map[string]any {
-	log.Printf("[DEBUG] createResponse called with %d images", len(imagesOutput))
+	return usecases.TryOnInput{
+		PersonImageData:  personImage.Data(),
+		GarmentImageData: garmentImageData,
+		Mode:             usecases.TryOnMode(request.TryOnMode),
+		Parameters:       parameters,
+	}, true
+}
 
-	var images []map[string]string
+// createResultFiles - 生成画像をレスポンス用のファイルに変換
+func (h *TryOnHandler) createResultFiles(imagesOutput []usecases.ImageOutput) []resultFile {
+	var files []resultFile
 	for i, img := range imagesOutput {
 		// 空のImageOutputをスキップ（防御的プログラミング）
-		if len(img.Data) == 0 {
+		if len(img.Data) == 0 && img.URI == "" {
 			log.Printf("[WARNING] Skipping empty image at index %d", i)
 			continue
 		}
 
-		log.Printf("[DEBUG] Processing image %d: size=%d bytes, type=%s", i, len(img.Data), img.Type)
+		files = append(files, resultFile{
+			ID:       fmt.Sprintf("image_%d", i),
+			Data:     img.Data,
+			MimeType: img.Type,
+			URI:      img.URI,
+		})
+	}
 
-		base64Data := base64.StdEncoding.EncodeToString(img.Data)
-		log.Printf("[DEBUG] Base64 encoded length: %d characters", len(base64Data))
+	return files
+}
 
-		// Base64データの最初の100文字をログ出力（デバッグ用）
-		preview := base64Data
-		if len(preview) > 100 {
-			preview = preview[:100] + "..."
-		}
-		log.Printf("[DEBUG] Base64 preview: %s", preview)
+// TryOnResponse - 試着の結果
+type TryOnResponse struct {
+	Success bool               `json:"success"`
+	Mode    usecases.TryOnMode `json:"mode" enum:"separate,outfit"`
+	// 一部の衣服だけが失敗した場合は true
+	Partial bool                    `json:"partial"`
+	Images  []GeneratedFileResponse `json:"images"`
+	// outfit の各段階の途中画像（step_S_N）
+	Intermediates []GeneratedFileResponse `json:"intermediates,omitempty"`
+	Garments      []GarmentResultResponse `json:"garments"`
+}
 
-		images = append(images, map[string]string{
-			"id":   fmt.Sprintf("image_%d", i),
-			"data": base64Data,
-			"type": img.Type,
-		})
-	}
+// GarmentResultResponse - 衣服ごとの試着結果
+type GarmentResultResponse struct {
+	Index          int                            `json:"index"`
+	Category       valueobjects.GarmentCategory   `json:"category" enum:",tops,bottoms,shoes,accessories"`
+	Status         usecases.GarmentStatus         `json:"status" enum:"succeeded,failed"`
+	ImageIDs       []string                       `json:"imageIds"`
+	RequestID      string                         `json:"requestId,omitempty"`
+	Classification *GarmentClassificationResponse `json:"classification,omitempty"`
+	Warnings       []GarmentWarningResponse       `json:"warnings,omitempty"`
+	Error          string                         `json:"error,omitempty"`
+	Code           domainerrors.Kind              `json:"code,omitempty"`
+}
+
+// GarmentClassificationResponse - 衣服の種類の自動判定結果
+type GarmentClassificationResponse struct {
+	IsGarment  bool    `json:"isGarment"`
+	Label      string  `json:"label"`
+	Confidence float64 `json:"confidence"`
+}
 
-	log.Printf("[DEBUG] Final response will contain %d images", len(images))
+// GarmentWarningResponse - 試着は続けるものの注意が必要な点
+type GarmentWarningResponse struct {
+	Code    usecases.GarmentWarning `json:"code"`
+	Message string                  `json:"message"`
+}
 
-	response := map[string]any{
-		"success": true,
-		"images":  images,
+func (h *TryOnHandler) createResponse(ctx context.Context, mode resultMode, files []resultFile) (*TryOnResponse, error) {
+	images, err := h.results.fileEntries(ctx, mode, files)
+	if err != nil {
+		return nil, err
 	}
 
-	return response
+	slog.Debug("Created result response", "images", len(images), "mode", mode)
+
+	return &TryOnResponse{
+		Success: true,
+		Images:  images,
+	}, nil
 }
 
-func (h *TryOnHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ok"))
+// createGarmentEntries - 衣服ごとの結果。
+// separate では createResultFiles と同じ順番の ID（image_N）で画像を参照する。
+// outfit では各段階の途中画像を step_S_N として別に返す。
+func (h *TryOnHandler) createGarmentEntries(output *usecases.TryOnOutput) ([]GarmentResultResponse, []resultFile) {
+	entries := make([]GarmentResultResponse, 0, len(output.Garments))
+	var stepFiles []resultFile
+	imageIndex := 0
+	for step, garment := range output.Garments {
+		imageIDs := make([]string, 0, len(garment.Images))
+		for i, img := range garment.Images {
+			if output.Mode == usecases.TryOnModeOutfit {
+				id := fmt.Sprintf("step_%d_%d", step, i)
+				stepFiles = append(stepFiles, resultFile{
+					ID:       id,
+					Data:     img.Data,
+					MimeType: img.Type,
+					URI:      img.URI,
+				})
+				imageIDs = append(imageIDs, id)
+				continue
+			}
+
+			if len(img.Data) > 0 || img.URI != "" {
+				imageIDs = append(imageIDs, fmt.Sprintf("image_%d", imageIndex))
+			}
+			imageIndex++
+		}
+
+		entry := GarmentResultResponse{
+			Index:     garment.Index,
+			Category:  garment.Category,
+			Status:    garment.Status,
+			ImageIDs:  imageIDs,
+			RequestID: string(garment.RequestID),
+		}
+		if c := garment.Classification; c != nil {
+			entry.Classification = &GarmentClassificationResponse{
+				IsGarment:  c.IsGarment(),
+				Label:      c.Label(),
+				Confidence: c.Confidence(),
+			}
+		}
+		for _, warning := range garment.Warnings {
+			entry.Warnings = append(entry.Warnings, GarmentWarningResponse{
+				Code:    warning,
+				Message: garmentWarningMessage(warning),
+			})
+		}
+		if garment.Err != nil {
+			kind := domainerrors.KindOf(garment.Err)
+			entry.Error = generationErrorMessage(kind, garment.Err, "生成")
+			entry.Code = kind
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, stepFiles
 }
 
-func (h *TryOnHandler) isQuotaError(err error) bool {
-	return fmt.Sprintf("%v", err) != "" &&
-		(fmt.Sprintf("%v", err) == "service temporarily unavailable due to high demand")
+// garmentWarningMessage - 衣服の種類の判定に関する警告の利用者向けメッセージ
+func garmentWarningMessage(warning usecases.GarmentWarning) string {
+	switch warning {
+	case usecases.GarmentWarningClassificationFailed:
+		return "衣服の種類を判定できませんでした"
+	case usecases.GarmentWarningLowConfidence:
+		return "衣服の種類を判定しきれませんでした。結果が不自然な場合は別の画像を試してください"
+	case usecases.GarmentWarningUnsupportedItem:
+		return "試着に対応していない品目の可能性があります"
+	case usecases.GarmentWarningNotGarment:
+		return "衣服ではない画像の可能性があります"
+	default:
+		return string(warning)
+	}
 }
 
-func (h *TryOnHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *TryOnHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
 }
 
 // SampleImage represents a sample image metadata
@@ -349,7 +497,15 @@ type SampleImage struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
-	Category    string `json:"category"`
+	Category    string `json:"category" enum:"person,garment"`
+	// 衣服の種類（tops / bottoms / shoes / accessories）。人物画像では空
+	GarmentCategory string `json:"garmentCategory,omitempty" enum:"tops,bottoms,shoes,accessories"`
+}
+
+// SampleImagesResponse サンプル画像の一覧
+type SampleImagesResponse struct {
+	Success bool          `json:"success"`
+	Samples []SampleImage `json:"samples"`
 }
 
 // HandleSampleImages サンプル画像一覧を返すAPIエンドポイント
@@ -357,12 +513,12 @@ func (h *TryOnHandler) HandleSampleImages(w http.ResponseWriter, r *http.Request
 	// カテゴリパラメータを取得（person または garment）
 	category := r.URL.Query().Get("category")
 	if category == "" {
-		h.sendError(w, "categoryパラメータが必要です (person または garment)", http.StatusBadRequest)
+		sendError(w, "categoryパラメータが必要です (person または garment)", http.StatusBadRequest)
 		return
 	}
 
 	if category != "person" && category != "garment" {
-		h.sendError(w, "categoryは 'person' または 'garment' である必要があります", http.StatusBadRequest)
+		sendError(w, "categoryは 'person' または 'garment' である必要があります", http.StatusBadRequest)
 		return
 	}
 
@@ -402,46 +558,52 @@ func (h *TryOnHandler) HandleSampleImages(w http.ResponseWriter, r *http.Request
 	} else {
 		samples = []SampleImage{
 			{
-				ID:          "garment_tops",
-				Name:        "トップス (ベーシック)",
-				Description: "シンプルなデザインのトップス",
-				URL:         "/api/sample-image?category=garment&id=garment_tops",
-				Category:    "garment",
+				ID:              "garment_tops",
+				Name:            "トップス (ベーシック)",
+				Description:     "シンプルなデザインのトップス",
+				URL:             "/api/sample-image?category=garment&id=garment_tops",
+				Category:        "garment",
+				GarmentCategory: "tops",
 			},
 			{
-				ID:          "garment_tops_hade",
-				Name:        "トップス (派手)",
-				Description: "カラフルで目立つデザインのトップス",
-				URL:         "/api/sample-image?category=garment&id=garment_tops_hade",
-				Category:    "garment",
+				ID:              "garment_tops_hade",
+				Name:            "トップス (派手)",
+				Description:     "カラフルで目立つデザインのトップス",
+				URL:             "/api/sample-image?category=garment&id=garment_tops_hade",
+				Category:        "garment",
+				GarmentCategory: "tops",
 			},
 			{
-				ID:          "garment_pants",
-				Name:        "パンツ",
-				Description: "カジュアルなパンツ",
-				URL:         "/api/sample-image?category=garment&id=garment_pants",
-				Category:    "garment",
+				ID:              "garment_pants",
+				Name:            "パンツ",
+				Description:     "カジュアルなパンツ",
+				URL:             "/api/sample-image?category=garment&id=garment_pants",
+				Category:        "garment",
+				GarmentCategory: "bottoms",
 			},
 			{
-				ID:          "garment_shoes",
-				Name:        "シューズ",
-				Description: "スタイリッシュなシューズ",
-				URL:         "/api/sample-image?category=garment&id=garment_shoes",
-				Category:    "garment",
+				ID:              "garment_shoes",
+				Name:            "シューズ",
+				Description:     "スタイリッシュなシューズ",
+				URL:             "/api/sample-image?category=garment&id=garment_shoes",
+				Category:        "garment",
+				GarmentCategory: "shoes",
 			},
 			{
-				ID:          "garment_shoes_double",
-				Name:        "シューズ（両足）",
-				Description: "スタイリッシュなシューズ（両足）",
-				URL:         "/api/sample-image?category=garment&id=garment_shoes_double",
-				Category:    "garment",
+				ID:              "garment_shoes_double",
+				Name:            "シューズ（両足）",
+				Description:     "スタイリッシュなシューズ（両足）",
+				URL:             "/api/sample-image?category=garment&id=garment_shoes_double",
+				Category:        "garment",
+				GarmentCategory: "shoes",
 			},
 			{
-				ID:          "garment_neckless",
-				Name:        "ネックレス",
-				Description: "エレガントなネックレス",
-				URL:         "/api/sample-image?category=garment&id=garment_neckless",
-				Category:    "garment",
+				ID:              "garment_neckless",
+				Name:            "ネックレス",
+				Description:     "エレガントなネックレス",
+				URL:             "/api/sample-image?category=garment&id=garment_neckless",
+				Category:        "garment",
+				GarmentCategory: "accessories",
 			},
 		}
 	}
@@ -449,14 +611,14 @@ func (h *TryOnHandler) HandleSampleImages(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "public, max-age=3600") // 1時間キャッシュ
 
-	response := map[string]interface{}{
-		"success": true,
-		"samples": samples,
+	response := SampleImagesResponse{
+		Success: true,
+		Samples: samples,
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Failed to encode sample images response: %v", err)
-		h.sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+		sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
 		return
 	}
 }
@@ -467,12 +629,12 @@ func (h *TryOnHandler) HandleSampleImage(w http.ResponseWriter, r *http.Request)
 	id := r.URL.Query().Get("id")
 
 	if category == "" || id == "" {
-		h.sendError(w, "categoryとidパラメータが必要です", http.StatusBadRequest)
+		sendError(w, "categoryとidパラメータが必要です", http.StatusBadRequest)
 		return
 	}
 
 	if category != "person" && category != "garment" {
-		h.sendError(w, "categoryは 'person' または 'garment' である必要があります", http.StatusBadRequest)
+		sendError(w, "categoryは 'person' または 'garment' である必要があります", http.StatusBadRequest)
 		return
 	}
 
@@ -490,7 +652,7 @@ func (h *TryOnHandler) HandleSampleImage(w http.ResponseWriter, r *http.Request)
 		case "person_women_70":
 			imageURL = "https://storage.googleapis.com/try-on-generated-central/sample/person/sample_women_70.png"
 		default:
-			h.sendError(w, "無効なperson ID", http.StatusBadRequest)
+			sendError(w, "無効なperson ID", http.StatusBadRequest)
 			return
 		}
 	} else {
@@ -508,7 +670,7 @@ func (h *TryOnHandler) HandleSampleImage(w http.ResponseWriter, r *http.Request)
 		case "garment_neckless":
 			imageURL = "https://storage.googleapis.com/try-on-generated-central/sample/garment/sample_neckless.png"
 		default:
-			h.sendError(w, "無効なgarment ID", http.StatusBadRequest)
+			sendError(w, "無効なgarment ID", http.StatusBadRequest)
 			return
 		}
 	}
@@ -517,14 +679,14 @@ func (h *TryOnHandler) HandleSampleImage(w http.ResponseWriter, r *http.Request)
 	resp, err := http.Get(imageURL)
 	if err != nil {
 		log.Printf("Failed to fetch sample image from %s: %v", imageURL, err)
-		h.sendError(w, "サンプル画像の取得に失敗しました", http.StatusInternalServerError)
+		sendError(w, "サンプル画像の取得に失敗しました", http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Sample image fetch failed with status %d from %s", resp.StatusCode, imageURL)
-		h.sendError(w, "サンプル画像が見つかりません", http.StatusNotFound)
+		sendError(w, "サンプル画像が見つかりません", http.StatusNotFound)
 		return
 	}
 
@@ -601,6 +763,10 @@ Veo動画生成
 <svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z"/></svg>
 Nanobanana画像編集
 </button>
+<button onclick="location.href='/history'" class="px-4 py-2 bg-gray-600 text-white rounded-lg hover:bg-gray-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>
+生成履歴
+</button>
 </div>
 </nav>
 
@@ -614,7 +780,7 @@ Nanobanana画像編集
 <div>
 <label class="block text-lg font-semibold mb-2 text-gray-700">1. 人物画像をアップロード</label>
 <div class="image-upload-area p-8 text-center" id="person-upload-area">
-<input type="file" id="person-image" name="person_image" accept="image/*" class="hidden" required>
+<input type="file" id="person-image" name="person_image" accept="image/*,.heic,.heif,.avif" class="hidden" required>
 <div id="person-upload-content">
 <svg class="mx-auto h-12 w-12 text-gray-400 mb-4" stroke="currentColor" fill="none" viewBox="0 0 48 48">
 <path d="M28 8H12a4 4 0 00-4 4v20m32-12v8m0 0v8a4 4 0 01-4 4H12a4 4 0 01-4-4v-4m32-4l-3.172-3.172a4 4 0 00-5.656 0L28 28M8 32l9.172-9.172a4 4 0 015.656 0L28 28m0 0l4 4m4-24h8m-4-4v8m-12 4h.02" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"/>
@@ -636,7 +802,7 @@ Nanobanana画像編集
 <div>
 <label class="block text-lg font-semibold mb-2 text-gray-700">2. 衣服画像をアップロード（最大5枚まで）</label>
 <div class="image-upload-area p-8 text-center" id="garment-upload-area">
-<input type="file" id="garment-image" name="garment_image" accept="image/*" multiple class="hidden" required>
+<input type="file" id="garment-image" name="garment_image" accept="image/*,.heic,.heif,.avif" multiple class="hidden" required>
 <div id="garment-upload-content">
 <svg class="mx-auto h-12 w-12 text-gray-400 mb-4" stroke="currentColor" fill="none" viewBox="0 0 48 48">
 <path d="M28 8H12a4 4 0 00-4 4v20m32-12v8m0 0v8a4 4 0 01-4 4H12a4 4 0 01-4-4v-4m32-4l-3.172-3.172a4 4 0 00-5.656 0L28 28M8 32l9.172-9.172a4 4 0 015.656 0L28 28m0 0l4 4m4-24h8m-4-4v8m-12 4h.02" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"/>
@@ -664,6 +830,19 @@ Nanobanana画像編集
 <div class="grid grid-cols-1 md:grid-cols-2 gap-4">
 <div>
 <label class="block text-sm font-medium mb-1 text-gray-600">
+複数衣服の試着方法
+<div class="tooltip">
+<span class="info-icon">?</span>
+<span class="tooltiptext">「別々に試着」は衣服ごとに元の人物へ試着します。「コーデ（重ね着）」はトップス→ボトムス→シューズ→アクセサリーの順に重ねて着せ、1枚のコーデ画像と途中経過を返します。</span>
+</div>
+</label>
+<select name="tryon_mode" class="w-full px-3 py-2 border border-gray-300 rounded-md">
+<option value="separate">別々に試着</option>
+<option value="outfit">コーデ（重ね着）</option>
+</select>
+</div>
+<div>
+<label class="block text-sm font-medium mb-1 text-gray-600">
 Watermark追加
 <div class="tooltip">
 <span class="info-icon">?</span>
@@ -821,6 +1000,7 @@ class="px-6 py-2 text-sm rounded-lg border border-gray-300 text-gray-600 hover:b
 
 
 </div>
+` + queueStatusScript + `
 <script>
 const form = document.getElementById('tryon-form');
 const personInput = document.getElementById('person-image');
@@ -1309,6 +1489,7 @@ clearBtn.addEventListener('click', () => {
     garmentInput.setAttribute('required', 'required');
     
     // 詳細設定もリセット
+    document.querySelector('select[name="tryon_mode"]').value = 'separate';
     document.querySelector('select[name="add_watermark"]').value = 'true';
     document.querySelector('input[name="base_steps"]').value = '32';
     document.querySelector('select[name="person_generation"]').value = 'allow_adult';
@@ -1396,6 +1577,7 @@ form.addEventListener('submit', async (event) => {
                 const response = await fetch(sample.url);
                 const blob = await response.blob();
                 formData.append('garment_image', blob, 'sample_garment_' + i + '.png');
+                formData.append('garment_category', sample.garmentCategory || '');
             }
         } catch (error) {
             console.error('Failed to load garment sample image:', error);
@@ -1411,14 +1593,17 @@ form.addEventListener('submit', async (event) => {
     }
     
     // フォームの全てのパラメータを追加
+    // 無効化された項目（Watermark有効時のSeedなど）は使われないため送らない
     formElements.forEach(element => {
-        if (element.name && element.value) {
+        if (element.name && element.value && !element.disabled) {
             formData.append(element.name, element.value);
         }
     });
 
     try {
-        const resp = await fetch('/tryon', { method: 'POST', body: formData });
+        const resp = await fetchWithQueue('/tryon', { method: 'POST', body: formData }, status => {
+            submitBtn.textContent = status ? queueStatusText(status) : '生成中...';
+        });
         if (!resp.ok) {
             let msg = 'HTTP ' + resp.status;
             try {
@@ -1437,7 +1622,10 @@ form.addEventListener('submit', async (event) => {
                     multipleResults.style.display = 'grid';
                     multipleResults.innerHTML = '';
                     
-                    data.images.forEach((img, index) => {
+                    // コーデモードでは完成画像のあとに途中経過の画像を並べる
+                    const results = data.images.map((img, i) => ({ img, text: '画像 ' + (i + 1) }))
+                        .concat((data.intermediates || []).map((img, i) => ({ img, text: '途中経過 ' + (i + 1) })));
+                    results.forEach(({ img, text }, index) => {
                     const imgContainer = document.createElement('div');
                     imgContainer.className = 'relative';
                     
@@ -1447,7 +1635,7 @@ form.addEventListener('submit', async (event) => {
                     imgElement.className = 'w-full h-auto rounded-lg shadow-md';
                     
                     const label = document.createElement('div');
-                    label.textContent = '画像 ' + (index + 1);
+                    label.textContent = text;
                     label.className = 'absolute top-2 left-2 bg-black bg-opacity-50 text-white px-2 py-1 rounded text-sm';
                     
                     const saveBtn = document.createElement('button');
@@ -1494,6 +1682,17 @@ form.addEventListener('submit', async (event) => {
                     imgContainer.appendChild(saveBtn);
                     multipleResults.appendChild(imgContainer);
                 });
+
+                // 一部の衣服が失敗した場合や警告がある場合は、成功分を表示したうえで知らせる
+                const notices = [];
+                (data.garments || []).forEach(g => {
+                    if (g.status === 'failed') notices.push('衣服 ' + (g.index + 1) + ': ' + g.error);
+                    (g.warnings || []).forEach(w => notices.push('衣服 ' + (g.index + 1) + ': ' + w.message));
+                });
+                if (notices.length > 0) {
+                    errorMessage.textContent = notices.join(' / ');
+                    errorMessage.classList.remove('hidden');
+                }
                 } else {
                     throw new Error('画像の生成に失敗しました');
                 }
@@ -1580,96 +1779,80 @@ form.addEventListener('submit', async (event) => {
 // HandleImagen - imagen画像生成API
 func (h *ImagenHandler) HandleImagen(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		h.sendError(w, "POST method required", http.StatusMethodNotAllowed)
+		sendError(w, "POST method required", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// パラメータの取得
-	prompt := r.FormValue("prompt")
+	// multipart/form-data・フォーム・JSONのどれでも同じ項目名で受け付ける
+	var (
+		prompt string
+		values services.ParameterValues
+	)
+	if isJSONRequest(r) {
+		var request ImagenRequest
+		if !decodeJSONRequest(w, r, maxJSONBodySize, &request) {
+			return
+		}
+		prompt, values = request.Prompt, request.parameterValues()
+	} else {
+		prompt = r.FormValue("prompt")
+		values = r.Form
+	}
 	if prompt == "" {
-		h.sendError(w, "promptパラメータが必要です", http.StatusBadRequest)
+		sendError(w, "promptパラメータが必要です", http.StatusBadRequest)
 		return
 	}
 
-	imagenModel := r.FormValue("imagenModel")
-	if imagenModel == "" {
-		imagenModel = h.getDefaultImagenModel()
-	}
-
-	// モデルIDのバリデーション
-	if !h.isValidImagenModel(imagenModel) {
-		log.Printf("[WARNING] Invalid modelo ID requested: %s", imagenModel)
-		h.sendError(w, fmt.Sprintf("サポートされていないモデルです: %s", imagenModel), http.StatusBadRequest)
+	// 詳細設定パラメータの取得と解析（preset の指定があればその上に重ねる）
+	parameters, err := h.parameterService.ParseImagen(r.Context(), values)
+	if err != nil {
+		log.Printf("Failed to parse parameters: %v", err)
+		sendGenerationError(w, err, "パラメータの読み込み")
 		return
 	}
 
-	// 詳細設定パラメータの取得と解析
-	numberOfImages := 1
-	if numStr := r.FormValue("numberOfImages"); numStr != "" {
-		if num, err := strconv.Atoi(numStr); err == nil && num >= 1 && num <= 4 {
-			numberOfImages = num
-		}
-	}
-
-	aspectRatio := r.FormValue("aspectRatio")
-	if aspectRatio == "" {
-		aspectRatio = "1:1"
-	}
-
-	negativePrompt := r.FormValue("negativePrompt")
-
-	seed := int64(0)
-	if seedStr := r.FormValue("seed"); seedStr != "" {
-		if seedVal, err := strconv.ParseInt(seedStr, 10, 64); err == nil {
-			seed = seedVal
-		}
-	}
-
-	includeRaiReason := r.FormValue("includeRaiReason") == "true"
+	// モデルは ParseImagen でモデルカタログから決まる（未指定の場合は既定のモデル）
+	imagenModel := parameters.ImagenModel
 
 	log.Printf("[INFO] Imagen generation request - prompt: %s, model: %s, numberOfImages: %d, aspectRatio: %s",
-		prompt, imagenModel, numberOfImages, aspectRatio)
+		prompt, imagenModel, parameters.NumberOfImages, parameters.AspectRatio)
 
 	input := usecases.ImagenInput{
 		Prompt:           prompt,
 		ImagenModel:      imagenModel,
-		NumberOfImages:   numberOfImages,
-		AspectRatio:      aspectRatio,
-		NegativePrompt:   negativePrompt,
-		Seed:             seed,
-		IncludeRaiReason: includeRaiReason,
+		NumberOfImages:   parameters.NumberOfImages,
+		AspectRatio:      parameters.AspectRatio,
+		NegativePrompt:   parameters.NegativePrompt,
+		Seed:             parameters.Seed,
+		IncludeRaiReason: parameters.IncludeRaiReason,
 	}
 
 	output, err := h.imagenUseCase.Execute(r.Context(), input)
 	if err != nil {
 		log.Printf("Imagen generation failed: %v", err)
-
-		if h.isQuotaError(err) {
-			h.sendError(w, "現在サーバーが混雑しています。しばらく待ってから再試行してください。", http.StatusTooManyRequests)
-			return
-		}
-
-		h.sendError(w, fmt.Sprintf("画像生成に失敗しました: %v", err), http.StatusInternalServerError)
+		sendGenerationError(w, err, "画像生成")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	mode := negotiateResultMode(r)
+	files := h.createImagenResultFiles(output.Images)
 
-	response := h.createImagenResponse(output.Images)
+	response, err := h.createImagenResponse(r.Context(), mode, files)
+	if err != nil {
+		log.Printf("Failed to create response: %v", err)
+		sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+		return
+	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode JSON response: %v", err)
-		h.sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+	if err := h.results.write(w, mode, response, files); err != nil {
+		log.Printf("Failed to write response: %v", err)
 		return
 	}
 }
 
-// createImagenResponse - Imagen用のレスポンスを生成
-func (h *ImagenHandler) createImagenResponse(imagesOutput []usecases.ImageOutput) map[string]any {
-	log.Printf("[DEBUG] createImagenResponse called with %d images", len(imagesOutput))
-
-	var images []map[string]string
+// createImagenResultFiles - Imagenの生成画像をレスポンス用のファイルに変換
+func (h *ImagenHandler) createImagenResultFiles(imagesOutput []usecases.ImageOutput) []resultFile {
+	var files []resultFile
 	for i, img := range imagesOutput {
 		// 空のImageOutputをスキップ（防御的プログラミング）
 		if len(img.Data) == 0 {
@@ -1677,39 +1860,35 @@ func (h *ImagenHandler) createImagenResponse(imagesOutput []usecases.ImageOutput
 			continue
 		}
 
-		log.Printf("[DEBUG] Processing image %d: size=%d bytes, type=%s", i, len(img.Data), img.Type)
-
-		base64Data := base64.StdEncoding.EncodeToString(img.Data)
-		log.Printf("[DEBUG] Base64 encoded length: %d characters", len(base64Data))
-
-		images = append(images, map[string]string{
-			"id":   fmt.Sprintf("imagen_%d", i),
-			"data": base64Data,
-			"type": img.Type,
+		files = append(files, resultFile{
+			ID:       fmt.Sprintf("imagen_%d", i),
+			Data:     img.Data,
+			MimeType: img.Type,
 		})
 	}
 
-	log.Printf("[DEBUG] Final response will contain %d images", len(images))
-
-	response := map[string]any{
-		"success": true,
-		"images":  images,
-	}
-
-	return response
+	return files
 }
 
-// isQuotaError - クォータエラーかどうかを判定
-func (h *ImagenHandler) isQuotaError(err error) bool {
-	return fmt.Sprintf("%v", err) != "" &&
-		(fmt.Sprintf("%v", err) == "service temporarily unavailable due to high demand")
+// ImagenResponse - Imagenの生成結果
+type ImagenResponse struct {
+	Success bool                    `json:"success"`
+	Images  []GeneratedFileResponse `json:"images"`
 }
 
-// sendError - エラーレスポンスを送信
-func (h *ImagenHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+// createImagenResponse - Imagen用のレスポンスを生成
+func (h *ImagenHandler) createImagenResponse(ctx context.Context, mode resultMode, files []resultFile) (*ImagenResponse, error) {
+	images, err := h.results.fileEntries(ctx, mode, files)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Created result response", "images", len(images), "mode", mode)
+
+	return &ImagenResponse{
+		Success: true,
+		Images:  images,
+	}, nil
 }
 
 // HandleImagenIndex - Imagen画像生成画面を表示
@@ -1717,24 +1896,8 @@ func (h *ImagenHandler) HandleImagenIndex(w http.ResponseWriter, r *http.Request
 	// 現在のVertex AIリージョン情報をツールチップに含める
 	locationInfo := fmt.Sprintf(" 現在のVertex AIリージョン: %s", h.location)
 
-	// モデル選択肢を動的に生成
-	var modelOptions strings.Builder
-	for i, model := range supportedImagenModels {
-		selected := ""
-		if model.ID == h.getDefaultImagenModel() {
-			selected = " selected"
-		}
-
-		modelOptions.WriteString(fmt.Sprintf(
-			`<option value="%s"%s>%s</option>`,
-			model.ID,
-			selected,
-			fmt.Sprintf("%s - %s", model.Name, model.Description),
-		))
-		if i < len(supportedImagenModels)-1 {
-			modelOptions.WriteString("\n")
-		}
-	}
+	// モデル選択肢はモデルカタログから生成
+	modelOptions := modelOptionsHTML(h.models, entities.GeneratorImagen)
 
 	html := `<!DOCTYPE html>
 <html lang="ja">
@@ -1777,6 +1940,10 @@ Veo動画生成
 <svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z"/></svg>
 Nanobanana画像編集
 </button>
+<button onclick="location.href='/history'" class="px-4 py-2 bg-gray-600 text-white rounded-lg hover:bg-gray-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>
+生成履歴
+</button>
 </div>
 </nav>
 
@@ -1808,7 +1975,7 @@ Imagenモデル
 </div>
 </label>
 <select id="imagenModel" name="imagenModel" class="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-indigo-500">
-` + modelOptions.String() + `
+` + modelOptions + `
 </select>
 </div>
 </div>
@@ -1908,6 +2075,7 @@ class="px-6 py-2 text-sm rounded-lg border border-gray-300 text-gray-600 hover:b
 </main>
 
 </div>
+` + queueStatusScript + `
 <script>
 const form = document.getElementById('imagen-form');
 const promptInput = document.getElementById('prompt');
@@ -1988,7 +2156,9 @@ form.addEventListener('submit', async (event) => {
     });
 
     try {
-        const resp = await fetch('/imagen', { method: 'POST', body: formData });
+        const resp = await fetchWithQueue('/imagen', { method: 'POST', body: formData }, status => {
+            submitBtn.textContent = status ? queueStatusText(status) : '生成中...';
+        });
         if (!resp.ok) {
             let msg = 'HTTP ' + resp.status;
             try {
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/repositories"
+)
+
+type AssetHandler struct {
+	assetUseCase *usecases.AssetUseCase
+}
+
+func NewAssetHandler(assetUseCase *usecases.AssetUseCase) *AssetHandler {
+	return &AssetHandler{
+		assetUseCase: assetUseCase,
+	}
+}
+
+// HandleAsset - 保存済みの生成ファイルを返す（Rangeリクエスト対応）
+func (h *AssetHandler) HandleAsset(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	asset, err := h.assetUseCase.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			sendError(w, "ファイルが見つかりません", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to get asset: %v", err)
+		sendError(w, "ファイルの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	// IDは内容のハッシュ値なので、同じIDの内容は変わらない
+	w.Header().Set("Content-Type", asset.MimeType)
+	w.Header().Set("ETag", `"`+id+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	// Content-Length・Range・If-None-Match は ServeContent が処理する
+	http.ServeContent(w, r, id, time.Time{}, bytes.NewReader(asset.Data))
+}
+
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func newAssetTestRouter(t *testing.T) (*mux.Router, string) {
+	t.Helper()
+
+	assetUseCase := usecases.NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20))
+	asset, err := assetUseCase.Store(context.Background(), []byte("0123456789abcdefghij"), "video/mp4")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/assets/{id}", NewAssetHandler(assetUseCase).HandleAsset).Methods("GET", "HEAD")
+	return r, asset.ID
+}
+
+func TestAssetHandler_HandleAsset(t *testing.T) {
+	r, id := newAssetTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/api/assets/"+id, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Body.String() != "0123456789abcdefghij" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("Content-Type = %s, want video/mp4", got)
+	}
+	if got := rec.Header().Get("ETag"); got != `"`+id+`"` {
+		t.Errorf("ETag = %s", got)
+	}
+
+	// 内容は変わらないので、ETagが一致すれば本体を返さない
+	req := httptest.NewRequest("GET", "/api/assets/"+id, nil)
+	req.Header.Set("If-None-Match", `"`+id+`"`)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status = %d, want 304", rec.Code)
+	}
+}
+
+func TestAssetHandler_HandleAsset_Range(t *testing.T) {
+	r, id := newAssetTestRouter(t)
+
+	req := httptest.NewRequest("GET", "/api/assets/"+id, nil)
+	req.Header.Set("Range", "bytes=0-9")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 0-9/20" {
+		t.Errorf("Content-Range = %s, want bytes 0-9/20", got)
+	}
+	if rec.Body.String() != "0123456789" {
+		t.Errorf("body = %q, want the first 10 bytes", rec.Body.String())
+	}
+}
+
+func TestAssetHandler_HandleAsset_NotFound(t *testing.T) {
+	r, id := newAssetTestRouter(t)
+
+	for _, path := range []string{
+		"/api/assets/" + strings.Repeat("0", 64) + ".mp4",
+		"/api/assets/" + strings.TrimSuffix(id, ".mp4") + ".gif",
+		"/api/assets/unknown",
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want 404", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+type HistoryHandler struct {
+	historyUseCase *usecases.HistoryUseCase
+}
+
+func NewHistoryHandler(historyUseCase *usecases.HistoryUseCase) *HistoryHandler {
+	return &HistoryHandler{
+		historyUseCase: historyUseCase,
+	}
+}
+
+// HandleListHistory - 生成履歴を一覧で返す（?type=&client=&page=&pageSize=）
+func (h *HistoryHandler) HandleListHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, err := h.parseOptionalInt(query.Get("page"))
+	if err != nil {
+		sendError(w, "page は数値で指定してください", http.StatusBadRequest)
+		return
+	}
+	pageSize, err := h.parseOptionalInt(query.Get("pageSize"))
+	if err != nil {
+		sendError(w, "pageSize は数値で指定してください", http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.historyUseCase.List(r.Context(), usecases.HistoryListInput{
+		GeneratorType: query.Get("type"),
+		Client:        query.Get("client"),
+		Page:          page,
+		PageSize:      pageSize,
+	})
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidHistoryQuery) {
+			sendError(w, "type は tryon, imagen, veo, nanobanana のいずれかを指定してください", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to list generation history: %v", err)
+		sendError(w, "履歴の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	records := make([]map[string]any, len(output.Records))
+	for i, record := range output.Records {
+		records[i] = h.createRecordResponse(record)
+	}
+
+	h.sendJSON(w, map[string]any{
+		"records":  records,
+		"total":    output.Total,
+		"page":     output.Page,
+		"pageSize": output.PageSize,
+		"hasNext":  output.Page*output.PageSize < output.Total,
+	})
+}
+
+// HandleGetHistory - 生成履歴を1件返す
+func (h *HistoryHandler) HandleGetHistory(w http.ResponseWriter, r *http.Request) {
+	id := entities.GenerationRecordID(mux.Vars(r)["id"])
+
+	record, err := h.historyUseCase.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			sendError(w, "履歴が見つかりません", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to get generation history: %v", err)
+		sendError(w, "履歴の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, h.createRecordResponse(record))
+}
+
+// HandleHistoryAsset - 履歴の入力・出力ファイルをダウンロードする
+func (h *HistoryHandler) HandleHistoryAsset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := entities.GenerationRecordID(vars["id"])
+	role := usecases.GenerationAssetRole(vars["role"])
+
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil {
+		sendError(w, "ファイル番号が不正です", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.historyUseCase.GetAsset(r.Context(), id, role, index)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			sendError(w, "ファイルが見つかりません", http.StatusNotFound)
+		case errors.Is(err, usecases.ErrInvalidHistoryQuery):
+			sendError(w, "ファイルの種類が不正です", http.StatusBadRequest)
+		default:
+			log.Printf("Failed to get generation asset: %v", err)
+			sendError(w, "ファイルの取得に失敗しました", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", asset.MimeType)
+	// 内容は変わらないため長めにキャッシュさせる
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	// 動画をシークできるようRangeリクエストに対応する
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(asset.Data))
+}
+
+// createRecordResponse - 履歴1件分のレスポンスを生成
+func (h *HistoryHandler) createRecordResponse(record *usecases.GenerationRecordOutput) map[string]any {
+	response := map[string]any{
+		"id":               record.ID,
+		"type":             record.GeneratorType,
+		"model":            record.Model,
+		"prompt":           record.Prompt,
+		"translatedPrompt": record.TranslatedPrompt,
+		"parameters":       record.Parameters,
+		"inputs":           h.createAssetResponses(record.ID, usecases.AssetRoleInput, record.Inputs),
+		"outputs":          h.createAssetResponses(record.ID, usecases.AssetRoleOutput, record.Outputs),
+		"status":           record.Status,
+		"startedAt":        record.StartedAt,
+		"finishedAt":       record.FinishedAt,
+		"durationMs":       record.Duration.Milliseconds(),
+	}
+
+	if record.Error != "" {
+		response["error"] = record.Error
+	}
+	// クライアントを記録する前の履歴にはない
+	if record.Client != "" {
+		response["client"] = record.Client
+	}
+
+	return response
+}
+
+// createAssetResponses - ダウンロードURL付きのファイル一覧を生成
+func (h *HistoryHandler) createAssetResponses(
+	id entities.GenerationRecordID,
+	role usecases.GenerationAssetRole,
+	assets []usecases.GenerationAssetOutput,
+) []map[string]any {
+	responses := make([]map[string]any, len(assets))
+	for i, asset := range assets {
+		responses[i] = map[string]any{
+			"url":      fmt.Sprintf("/api/history/%s/%s/%d", id, role, i),
+			"mimeType": asset.MimeType,
+			"size":     asset.Size,
+		}
+	}
+	return responses
+}
+
+// parseOptionalInt - 空文字の場合は0を返す
+func (h *HistoryHandler) parseOptionalInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// sendJSON - JSONレスポンスを送信
+func (h *HistoryHandler) sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
+
+
+// HandleHistoryIndex - 生成履歴のギャラリー画面を表示
+func (h *HistoryHandler) HandleHistoryIndex(w http.ResponseWriter, r *http.Request) {
+	html := `<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="UTF-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1.0"/>
+<title>生成履歴</title>
+<script src="https://cdn.tailwindcss.com"></script>
+<style>
+body { font-family: Inter, system-ui, -apple-system, Segoe UI, Roboto, sans-serif; }
+.thumb{width:100%;height:180px;background:#f3f4f6;display:flex;align-items:center;justify-content:center;overflow:hidden;border-radius:8px}
+.thumb img,.thumb video{max-width:100%;max-height:100%;object-fit:contain}
+</style>
+</head>
+<body class="bg-gray-50 text-gray-800">
+<div class="container mx-auto p-4 md:p-8 max-w-6xl">
+<!-- ナビゲーションバー -->
+<nav class="bg-white shadow-sm rounded-lg mb-6 p-4">
+<div class="flex flex-wrap justify-center gap-3">
+<button onclick="location.href='/'" class="px-4 py-2 bg-indigo-600 text-white rounded-lg hover:bg-indigo-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M16 7a4 4 0 11-8 0 4 4 0 018 0zM12 14a7 7 0 00-7 7h14a7 7 0 00-7-7z"/></svg>
+Virtual Try-On
+</button>
+<button onclick="location.href='/imagen'" class="px-4 py-2 bg-green-600 text-white rounded-lg hover:bg-green-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 16l4.586-4.586a2 2 0 012.828 0L16 16m-2-2l1.586-1.586a2 2 0 012.828 0L20 14m-6-6h.01M6 20h12a2 2 0 002-2V6a2 2 0 00-2-2H6a2 2 0 00-2 2v12a2 2 0 002 2z"/></svg>
+Imagen画像生成
+</button>
+<button onclick="location.href='/veo'" class="px-4 py-2 bg-purple-600 text-white rounded-lg hover:bg-purple-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M15 10l4.553-2.276A1 1 0 0121 8.618v6.764a1 1 0 01-1.447.894L15 14M5 18h8a2 2 0 002-2V8a2 2 0 00-2-2H5a2 2 0 00-2 2v8a2 2 0 002 2z"/></svg>
+Veo動画生成
+</button>
+<button onclick="location.href='/nanobanana/image-editing'" class="px-4 py-2 bg-orange-600 text-white rounded-lg hover:bg-orange-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z"/></svg>
+Nanobanana画像編集
+</button>
+<button onclick="location.href='/history'" class="px-4 py-2 bg-gray-700 text-white rounded-lg shadow-md font-medium ring-2 ring-gray-300">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>
+生成履歴
+</button>
+</div>
+</nav>
+
+<header class="text-center mb-8">
+<h1 class="text-3xl md:text-4xl font-bold text-gray-900">生成履歴</h1>
+<p class="text-gray-600 mt-2">過去の生成結果を確認・再ダウンロードできます</p>
+</header>
+<main class="bg-white p-6 md:p-8 rounded-2xl shadow-lg">
+<div class="flex flex-wrap items-center gap-3 mb-6">
+<label for="typeFilter" class="font-semibold text-gray-700">生成機能</label>
+<select id="typeFilter" class="border-gray-300 rounded-md shadow-sm p-2 border">
+<option value="">すべて</option>
+<option value="tryon">Virtual Try-On</option>
+<option value="imagen">Imagen</option>
+<option value="veo">Veo</option>
+<option value="nanobanana">Nanobanana</option>
+</select>
+<span id="totalCount" class="text-sm text-gray-500 ml-auto"></span>
+</div>
+<div id="error" class="hidden mb-4 p-4 bg-red-100 text-red-700 rounded-lg"></div>
+<div id="records" class="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6"></div>
+<p id="empty" class="hidden text-center text-gray-500 py-12">履歴はまだありません</p>
+<div class="flex justify-center items-center gap-4 mt-8">
+<button id="prevPage" class="px-4 py-2 bg-gray-200 rounded-lg disabled:opacity-50" disabled>前へ</button>
+<span id="pageInfo" class="text-sm text-gray-600"></span>
+<button id="nextPage" class="px-4 py-2 bg-gray-200 rounded-lg disabled:opacity-50" disabled>次へ</button>
+</div>
+</main>
+</div>
+<script>
+const pageSize = 12;
+let currentPage = 1;
+
+const typeLabels = { tryon: 'Virtual Try-On', imagen: 'Imagen', veo: 'Veo', nanobanana: 'Nanobanana' };
+
+function escapeHtml(value) {
+  return String(value || '').replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+}
+
+function renderPreview(asset) {
+  if (!asset) {
+    return '<span class="text-gray-400 text-sm">出力なし</span>';
+  }
+  if (asset.mimeType.startsWith('video/')) {
+    return '<video src="' + asset.url + '" controls muted></video>';
+  }
+  return '<img src="' + asset.url + '" loading="lazy" alt="output">';
+}
+
+function renderRecord(record) {
+  const startedAt = new Date(record.startedAt).toLocaleString('ja-JP');
+  const seconds = (record.durationMs / 1000).toFixed(1);
+  const statusBadge = record.status === 'succeeded'
+    ? '<span class="text-xs px-2 py-1 rounded bg-green-100 text-green-700">成功</span>'
+    : '<span class="text-xs px-2 py-1 rounded bg-red-100 text-red-700">失敗</span>';
+  const downloads = record.outputs.map((asset, i) =>
+    '<a href="' + asset.url + '" download class="text-indigo-600 hover:underline text-sm mr-3">出力' + (i + 1) + '</a>'
+  ).join('');
+
+  return '<div class="border rounded-xl p-4 shadow-sm">' +
+    '<div class="thumb mb-3">' + renderPreview(record.outputs[0]) + '</div>' +
+    '<div class="flex items-center justify-between mb-2">' +
+      '<span class="font-semibold">' + escapeHtml(typeLabels[record.type] || record.type) + '</span>' + statusBadge +
+    '</div>' +
+    '<p class="text-xs text-gray-500 mb-2">' + escapeHtml(startedAt) + '（' + seconds + '秒）' + (record.model ? ' / ' + escapeHtml(record.model) : '') + '</p>' +
+    (record.prompt ? '<p class="text-sm text-gray-700 mb-2 break-words">' + escapeHtml(record.prompt) + '</p>' : '') +
+    (record.error ? '<p class="text-sm text-red-600 mb-2 break-words">' + escapeHtml(record.error) + '</p>' : '') +
+    '<div>' + downloads + '</div>' +
+  '</div>';
+}
+
+async function loadHistory() {
+  const errorBox = document.getElementById('error');
+  errorBox.classList.add('hidden');
+
+  const params = new URLSearchParams({ page: currentPage, pageSize: pageSize });
+  const type = document.getElementById('typeFilter').value;
+  if (type) {
+    params.set('type', type);
+  }
+
+  try {
+    const res = await fetch('/api/history?' + params.toString());
+    const data = await res.json();
+    if (!res.ok) {
+      throw new Error(data.error || '履歴の取得に失敗しました');
+    }
+
+    document.getElementById('records').innerHTML = data.records.map(renderRecord).join('');
+    document.getElementById('empty').classList.toggle('hidden', data.records.length > 0);
+    document.getElementById('totalCount').textContent = '全' + data.total + '件';
+    const totalPages = Math.max(1, Math.ceil(data.total / data.pageSize));
+    document.getElementById('pageInfo').textContent = data.page + ' / ' + totalPages;
+    document.getElementById('prevPage').disabled = data.page <= 1;
+    document.getElementById('nextPage').disabled = !data.hasNext;
+  } catch (err) {
+    errorBox.textContent = err.message;
+    errorBox.classList.remove('hidden');
+  }
+}
+
+document.getElementById('typeFilter').addEventListener('change', () => { currentPage = 1; loadHistory(); });
+document.getElementById('prevPage').addEventListener('click', () => { currentPage--; loadHistory(); });
+document.getElementById('nextPage').addEventListener('click', () => { currentPage++; loadHistory(); });
+
+loadHistory();
+</script>
+</body>
+</html>`
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
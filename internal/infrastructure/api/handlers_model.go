@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/services"
+)
+
+type ModelHandler struct {
+	catalog *services.ModelCatalog
+}
+
+func NewModelHandler(catalog *services.ModelCatalog) *ModelHandler {
+	return &ModelHandler{
+		catalog: catalog,
+	}
+}
+
+// ModelsResponse 選べるモデルの一覧（モデルカタログの登録順）
+type ModelsResponse struct {
+	Success bool            `json:"success"`
+	Models  []ModelResponse `json:"models"`
+}
+
+type ModelResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Generator   string `json:"generator" enum:"tryon,imagen,veo,nanobanana"`
+	// 指定しなかった場合に使うモデル
+	Default      bool                      `json:"default"`
+	Capabilities ModelCapabilitiesResponse `json:"capabilities"`
+	// 料金が分からないモデルでは省略する
+	Price *ModelPriceResponse `json:"price,omitempty"`
+}
+
+// ModelCapabilitiesResponse モデルごとに指定できる範囲（省略した項目は制限なし、または指定できない）
+type ModelCapabilitiesResponse struct {
+	MaxImages      int      `json:"maxImages,omitempty"`
+	MaxInputImages int      `json:"maxInputImages,omitempty"`
+	AspectRatios   []string `json:"aspectRatios,omitempty"`
+	Seed           bool     `json:"seed"`
+	Audio          bool     `json:"audio"`
+	CandidateCount int      `json:"candidateCount,omitempty"`
+}
+
+// ModelPriceResponse 料金の目安
+type ModelPriceResponse struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency" enum:"USD"`
+	Unit     string  `json:"unit" enum:"image,second,request,input_token,output_token"`
+}
+
+// HandleListModels - モデルカタログの一覧を返す（?generator= で生成機能を絞り込める）
+func (h *ModelHandler) HandleListModels(w http.ResponseWriter, r *http.Request) {
+	generator := entities.GeneratorType(r.URL.Query().Get("generator"))
+	if generator != "" && !generator.IsValid() {
+		sendError(w, "generator は tryon・imagen・veo・nanobanana のいずれかを指定してください", http.StatusBadRequest)
+		return
+	}
+
+	models := h.catalog.Models(generator)
+	response := ModelsResponse{
+		Success: true,
+		Models:  make([]ModelResponse, 0, len(models)),
+	}
+	for _, model := range models {
+		defaultModel, _ := h.catalog.Default(model.Generator)
+		response.Models = append(response.Models, toModelResponse(model, model.ID == defaultModel.ID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
+
+func toModelResponse(model entities.GenerativeModel, isDefault bool) ModelResponse {
+	response := ModelResponse{
+		ID:          model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		Generator:   string(model.Generator),
+		Default:     isDefault,
+		Capabilities: ModelCapabilitiesResponse{
+			MaxImages:      model.Capabilities.MaxImages,
+			MaxInputImages: model.Capabilities.MaxInputImages,
+			AspectRatios:   model.Capabilities.AspectRatios,
+			Seed:           model.Capabilities.Seed,
+			Audio:          model.Capabilities.Audio,
+			CandidateCount: model.Capabilities.CandidateCount,
+		},
+	}
+	if model.Price != nil {
+		response.Price = &ModelPriceResponse{
+			Amount:   model.Price.Amount,
+			Currency: "USD",
+			Unit:     string(model.Price.Unit),
+		}
+	}
+	return response
+}
+
+// modelOptionsHTML - 画面のモデル選択肢（既定のモデルを選択済みにする）
+func modelOptionsHTML(catalog *services.ModelCatalog, generator entities.GeneratorType) string {
+	defaultModel, _ := catalog.Default(generator)
+
+	var options []string
+	for _, model := range catalog.Models(generator) {
+		selected := ""
+		if model.ID == defaultModel.ID {
+			selected = " selected"
+		}
+
+		label := model.Name
+		if label == "" {
+			label = model.ID
+		}
+		if model.Description != "" {
+			label += " - " + model.Description
+		}
+		options = append(options, fmt.Sprintf(
+			`<option value="%s"%s>%s</option>`,
+			html.EscapeString(model.ID),
+			selected,
+			html.EscapeString(label),
+		))
+	}
+	return strings.Join(options, "\n")
+}
@@ -1,26 +1,37 @@
 package api
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	domainservices "tryon-demo/internal/domain/services"
 	"tryon-demo/internal/domain/valueobjects"
 )
 
 type NanobananaHandler struct {
 	nanobananaUseCase *usecases.NanobananaUseCase
+	models            *domainservices.ModelCatalog
+	imageFetcher      ImageFetcher
+	results           *resultResponder
 	location          string // Vertex AIのリージョン情報
 }
 
-func NewNanobananaHandler(nanobananaUseCase *usecases.NanobananaUseCase, location string) *NanobananaHandler {
+func NewNanobananaHandler(
+	nanobananaUseCase *usecases.NanobananaUseCase,
+	models *domainservices.ModelCatalog,
+	assetUseCase *usecases.AssetUseCase,
+	imageFetcher ImageFetcher,
+	location string,
+) *NanobananaHandler {
 	return &NanobananaHandler{
 		nanobananaUseCase: nanobananaUseCase,
+		models:            models,
+		imageFetcher:      imageFetcher,
+		results:           newResultResponder(assetUseCase),
 		location:          location,
 	}
 }
@@ -29,6 +40,14 @@ func (h *NanobananaHandler) HandleNanobananaIndex(w http.ResponseWriter, r *http
 	// 現在のVertex AIリージョン情報をツールチップに含める
 	locationInfo := fmt.Sprintf(" 現在のVertex AIリージョン: %s", h.location)
 
+	// 一度に指定できる画像の枚数はモデルカタログから決める
+	model, ok := h.models.Default(entities.GeneratorNanobanana)
+	if !ok {
+		sendError(w, "画像編集に使えるモデルがありません", http.StatusServiceUnavailable)
+		return
+	}
+	maxImages := strconv.Itoa(model.Capabilities.MaxInputImages)
+
 	html := `<!DOCTYPE html>
 <html lang="ja">
 <head>
@@ -121,12 +140,16 @@ Veo動画生成
 <svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z"/></svg>
 Nanobanana画像編集
 </button>
+<button onclick="location.href='/history'" class="px-4 py-2 bg-gray-600 text-white rounded-lg hover:bg-gray-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>
+生成履歴
+</button>
 </div>
 </nav>
 
 <header class="text-center mb-8">
 <h1 class="text-3xl md:text-4xl font-bold text-gray-900">Nanobanana 画像編集</h1>
-<p class="text-gray-600 mt-2">画像とプロンプトを使用して画像を編集します（最大3枚まで）</p>
+<p class="text-gray-600 mt-2">画像とプロンプトを使用して画像を編集します（最大` + maxImages + `枚まで）</p>
 </header>
 
 <main class="bg-white p-6 md:p-8 rounded-2xl shadow-lg">
@@ -135,20 +158,20 @@ Nanobanana画像編集
 <!-- 画像アップロード -->
 <div>
 <label class="block text-lg font-semibold mb-2 text-gray-700">
-編集する画像をアップロード（最大3枚）
+編集する画像をアップロード（最大` + maxImages + `枚）
 <div class="tooltip">
 <span class="info-icon">?</span>
-<span class="tooltiptext">編集したい画像をアップロードしてください。最大3枚まで対応形式: JPG, PNG` + locationInfo + `</span>
+<span class="tooltiptext">編集したい画像をアップロードしてください。最大` + maxImages + `枚まで対応形式: JPG, PNG` + locationInfo + `</span>
 </div>
 </label>
 <div class="image-upload-area p-8 text-center" id="image-upload-area">
-<input type="file" id="image-input" name="images" accept="image/*" class="hidden" multiple>
+<input type="file" id="image-input" name="images" accept="image/*,.heic,.heif,.avif" class="hidden" multiple>
 <div id="upload-content">
 <svg class="mx-auto h-12 w-12 text-gray-400 mb-4" stroke="currentColor" fill="none" viewBox="0 0 48 48">
 <path d="M28 8H12a4 4 0 00-4 4v20m32-12v8m0 0v8a4 4 0 01-4 4H12a4 4 0 01-4-4v-4m32-4l-3.172-3.172a4 4 0 00-5.656 0L28 28M8 32l9.172-9.172a4 4 0 015.656 0L28 28m0 0l4 4m4-24h8m-4-4v8m-12 4h.02" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"/>
 </svg>
 <p class="text-lg text-gray-600 mb-2">画像をドラッグ&ドロップするか、クリックして選択</p>
-<p class="text-sm text-gray-500">JPG, PNG形式をサポート（最大3枚まで）</p>
+<p class="text-sm text-gray-500">JPG, PNG形式をサポート（最大` + maxImages + `枚まで）</p>
 </div>
 <div id="image-preview" class="hidden">
 <div id="image-preview-grid" class="image-preview-grid"></div>
@@ -203,6 +226,7 @@ class="px-6 py-2 text-sm rounded-lg border border-gray-300 text-gray-600 hover:b
 </main>
 
 </div>
+` + queueStatusScript + `
 <script>
 const form = document.getElementById('nanobanana-form');
 const imageInput = document.getElementById('image-input');
@@ -262,9 +286,9 @@ function handleFiles(files) {
         return;
     }
     
-    // 最大3枚まで制限
-    if (selectedFiles.length + imageFiles.length > 3) {
-        errorMessage.textContent = '画像は最大3枚までアップロードできます';
+    // 最大枚数まで制限
+    if (selectedFiles.length + imageFiles.length > ` + maxImages + `) {
+        errorMessage.textContent = '画像は最大` + maxImages + `枚までアップロードできます';
         errorMessage.classList.remove('hidden');
         return;
     }
@@ -388,7 +412,9 @@ form.addEventListener('submit', async (event) => {
     });
 
     try {
-        const resp = await fetch('/nanobanana/image-editing', { method: 'POST', body: formData });
+        const resp = await fetchWithQueue('/nanobanana/image-editing', { method: 'POST', body: formData }, status => {
+            submitBtn.textContent = status ? queueStatusText(status) : '編集中...';
+        });
         if (!resp.ok) {
             let msg = 'HTTP ' + resp.status;
             try {
@@ -479,166 +505,171 @@ form.addEventListener('submit', async (event) => {
 	w.Write([]byte(html))
 }
 
-func (h *NanobananaHandler) getDefaultNanobananaModel() string {
-	return "gemini-2.5-flash-image-preview"
+// NanobananaResponse - 画像編集の結果
+type NanobananaResponse struct {
+	Success bool                  `json:"success"`
+	Image   GeneratedFileResponse `json:"image"`
 }
 
 func (h *NanobananaHandler) HandleNanobanana(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		sendError(w, "POST method required", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// モデルはサーバー側で固定（カタログの既定のモデル）
+	model, ok := h.models.Default(entities.GeneratorNanobanana)
+	if !ok {
+		sendError(w, "画像編集に使えるモデルがありません", http.StatusServiceUnavailable)
+		return
+	}
+	maxImages := model.Capabilities.MaxInputImages
+
+	var (
+		prompt     string
+		imageDatas []*valueobjects.ImageData
+	)
+	if isJSONRequest(r) {
+		prompt, imageDatas, ok = h.parseNanobananaJSON(w, r, maxImages)
+	} else {
+		prompt, imageDatas, ok = h.parseNanobananaForm(w, r, maxImages)
+	}
+	if !ok {
+		return
+	}
+
+	input := usecases.NanobananaInput{
+		Model:      model.ID,
+		Prompt:     prompt,
+		ImageDatas: imageDatas,
+	}
+
+	// UseCase実行
+	ctx := r.Context()
+	output, err := h.nanobananaUseCase.ModifyImage(ctx, input)
+	if err != nil {
+		log.Printf("Error executing Nanobanana use case: %v", err)
+		sendGenerationError(w, err, "画像編集")
+		return
+	}
+
+	// 画像データがない場合はエラー
+	if output.Image == nil {
+		log.Printf("No image data in output, response text: %s", output.Response)
+		sendError(w, "画像データが返されませんでした", http.StatusInternalServerError)
 		return
 	}
 
+	log.Printf("Successfully received image data, size: %d bytes", len(output.Image.Data()))
+
+	mode := negotiateResultMode(r)
+	files := []resultFile{{
+		ID:       "image_0",
+		Data:     output.Image.Data(),
+		MimeType: output.Image.MimeType(),
+	}}
+
+	images, err := h.results.fileEntries(ctx, mode, files)
+	if err != nil {
+		log.Printf("Failed to create response: %v", err)
+		sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	// レスポンスの構築
+	response := NanobananaResponse{
+		Success: true,
+		Image:   images[0],
+	}
+
+	if err := h.results.write(w, mode, response, files); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// parseNanobananaForm - multipart/form-data の画像編集リクエストを読み取る。失敗した場合はエラーレスポンスを送信する
+func (h *NanobananaHandler) parseNanobananaForm(w http.ResponseWriter, r *http.Request, maxImages int) (string, []*valueobjects.ImageData, bool) {
 	// フォームデータの解析
 	err := r.ParseMultipartForm(32 << 20) // 32MB
 	if err != nil {
-		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
-		return
+		sendError(w, "フォームデータの解析に失敗しました", http.StatusBadRequest)
+		return "", nil, false
 	}
 
 	prompt := r.FormValue("prompt")
 	if prompt == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "プロンプトが必要です",
-		})
-		return
+		sendError(w, "プロンプトが必要です", http.StatusBadRequest)
+		return "", nil, false
 	}
 
 	// 複数画像ファイルの取得
 	form := r.MultipartForm
 	if form == nil || form.File == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "画像ファイルが必要です",
-		})
-		return
+		sendError(w, "画像ファイルが必要です", http.StatusBadRequest)
+		return "", nil, false
 	}
 
 	imageFiles, exists := form.File["images"]
 	if !exists || len(imageFiles) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "画像ファイルが必要です",
-		})
-		return
+		sendError(w, "画像ファイルが必要です", http.StatusBadRequest)
+		return "", nil, false
 	}
 
-	// 最大3枚まで制限
-	if len(imageFiles) > 3 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "画像は最大3枚までアップロードできます",
-		})
-		return
+	// モデルが受け付ける枚数まで制限
+	if len(imageFiles) > maxImages {
+		sendError(w, fmt.Sprintf("画像は最大%d枚までアップロードできます", maxImages), http.StatusBadRequest)
+		return "", nil, false
 	}
 
 	// 画像データの読み込み
 	var imageDatas []*valueobjects.ImageData
-	for _, fileHeader := range imageFiles {
-		file, err := fileHeader.Open()
+	for i, fileHeader := range imageFiles {
+		imageData, err := readImageUpload(fileHeader)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   "画像ファイルの読み込みに失敗しました",
-			})
-			return
+			sendUploadError(w, err, fmt.Sprintf("画像%d", i+1))
+			return "", nil, false
 		}
-		defer file.Close()
 
-		imageData, err := io.ReadAll(file)
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   "画像ファイルの読み込みに失敗しました",
-			})
-			return
-		}
-
-		// MIMEタイプの検証
-		contentType := http.DetectContentType(imageData)
-		if !strings.HasPrefix(contentType, "image/") {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   "有効な画像ファイルを選択してください",
-			})
-			return
-		}
+		imageDatas = append(imageDatas, imageData)
+	}
 
-		// UseCase実行用の入力データを準備
-		imageDataObj, err := valueobjects.NewImageData(imageData, contentType)
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   fmt.Sprintf("画像データの作成に失敗しました: %v", err),
-			})
-			return
-		}
+	return prompt, imageDatas, true
+}
 
-		imageDatas = append(imageDatas, imageDataObj)
+// parseNanobananaJSON - JSONの画像編集リクエストを読み取る。画像はBase64またはURLで指定する
+func (h *NanobananaHandler) parseNanobananaJSON(w http.ResponseWriter, r *http.Request, maxImages int) (string, []*valueobjects.ImageData, bool) {
+	var request NanobananaRequest
+	if !decodeJSONRequest(w, r, maxJSONBodySize*int64(maxImages), &request) {
+		return "", nil, false
 	}
 
-	input := usecases.NanobananaInput{
-		Model:      h.getDefaultNanobananaModel(),
-		Prompt:     prompt,
-		ImageDatas: imageDatas,
+	if request.Prompt == "" {
+		sendError(w, "プロンプトが必要です", http.StatusBadRequest)
+		return "", nil, false
 	}
-
-	// UseCase実行
-	ctx := r.Context()
-	output, err := h.nanobananaUseCase.ModifyImage(ctx, input)
-	if err != nil {
-		log.Printf("Error executing Nanobanana use case: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("画像編集に失敗しました: %v", err),
-		})
-		return
+	if len(request.Images) == 0 {
+		sendError(w, "画像（images）を指定してください", http.StatusBadRequest)
+		return "", nil, false
 	}
-
-	// レスポンスの構築
-	response := map[string]interface{}{
-		"success": true,
-		// "response": output.Response,
+	if len(request.Images) > maxImages {
+		sendError(w, fmt.Sprintf("画像は最大%d枚まで指定できます", maxImages), http.StatusBadRequest)
+		return "", nil, false
 	}
 
-	// 画像データがある場合は追加
-	if output.Image != nil {
-		log.Printf("Successfully received image data, size: %d bytes", len(output.Image.Data()))
-		imageBase64 := base64.StdEncoding.EncodeToString(output.Image.Data())
-		response["image"] = map[string]string{
-			"data": imageBase64,
-			"type": output.Image.MimeType(),
+	imageDatas := make([]*valueobjects.ImageData, 0, len(request.Images))
+	for i, source := range request.Images {
+		label := fmt.Sprintf("画像%d", i+1)
+		imageData, err := loadImageSource(r.Context(), h.imageFetcher, source)
+		if err != nil {
+			sendUploadError(w, err, label)
+			return "", nil, false
 		}
-	} else {
-		log.Printf("No image data in output, response text: %s", output.Response)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "画像データが返されませんでした",
-		})
-		return
+		if imageData == nil {
+			sendError(w, label+"の data または url を指定してください", http.StatusBadRequest)
+			return "", nil, false
+		}
+
+		imageDatas = append(imageDatas, imageData)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return request.Prompt, imageDatas, true
 }
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"tryon-demo/internal/application/services"
+)
+
+type ParameterHandler struct {
+	parameterService *services.ParameterService
+}
+
+func NewParameterHandler(parameterService *services.ParameterService) *ParameterHandler {
+	return &ParameterHandler{
+		parameterService: parameterService,
+	}
+}
+
+// ParameterSchemaResponse 生成機能ごとに受け付けるパラメータ
+type ParameterSchemaResponse struct {
+	Success bool `json:"success"`
+	// strict: 不正な値は400 / lenient: 不正な値は既定値に置き換える
+	Validation string                  `json:"validation"`
+	TryOn      generatorSchemaResponse `json:"tryon"`
+	Imagen     generatorSchemaResponse `json:"imagen"`
+}
+
+type generatorSchemaResponse struct {
+	Fields []parameterFieldResponse `json:"fields"`
+	Rules  []parameterRuleResponse  `json:"rules"`
+}
+
+type parameterFieldResponse struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Default     any      `json:"default,omitempty"`
+	Minimum     *int64   `json:"minimum,omitempty"`
+	Maximum     *int64   `json:"maximum,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Description string   `json:"description"`
+}
+
+type parameterRuleResponse struct {
+	Fields      []string `json:"fields"`
+	Description string   `json:"description"`
+}
+
+// HandleParameterSchema - 各パラメータの型・既定値・範囲・選択肢と、項目をまたがる規則を返す
+func (h *ParameterHandler) HandleParameterSchema(w http.ResponseWriter, r *http.Request) {
+	schema := h.parameterService.Schema()
+
+	h.sendJSON(w, ParameterSchemaResponse{
+		Success:    true,
+		Validation: string(schema.Mode),
+		TryOn:      toGeneratorSchemaResponse(schema.TryOn),
+		Imagen:     toGeneratorSchemaResponse(schema.Imagen),
+	})
+}
+
+func toGeneratorSchemaResponse(schema services.GeneratorParameterSchema) generatorSchemaResponse {
+	response := generatorSchemaResponse{
+		Fields: make([]parameterFieldResponse, len(schema.Fields)),
+		Rules:  make([]parameterRuleResponse, len(schema.Rules)),
+	}
+
+	for i, field := range schema.Fields {
+		response.Fields[i] = parameterFieldResponse{
+			Name:        field.Name,
+			Type:        string(field.Type),
+			Default:     field.Default,
+			Enum:        field.Choices,
+			Format:      field.Format,
+			Description: field.Description,
+		}
+		if field.Range != nil {
+			response.Fields[i].Minimum = &field.Range.Min
+			response.Fields[i].Maximum = &field.Range.Max
+		}
+	}
+	for i, rule := range schema.Rules {
+		response.Rules[i] = parameterRuleResponse{
+			Fields:      rule.Fields,
+			Description: rule.Description,
+		}
+	}
+
+	return response
+}
+
+// sendJSON - JSONレスポンスを送信
+func (h *ParameterHandler) sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/services"
+)
+
+// プリセットの作成・更新で受け付けるJSONの大きさ
+const maxPresetBodySize = 64 * 1024
+
+type PresetHandler struct {
+	presetUseCase *usecases.ParameterPresetUseCase
+	models        *services.ModelCatalog
+}
+
+func NewPresetHandler(presetUseCase *usecases.ParameterPresetUseCase, models *services.ModelCatalog) *PresetHandler {
+	return &PresetHandler{
+		presetUseCase: presetUseCase,
+		models:        models,
+	}
+}
+
+// presetRequest プリセットの作成・更新内容。parameters の形式は type によって異なる
+type presetRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// tryOnPresetParameters 試着のプリセットのパラメータ（POST /tryon の項目に対応）
+type tryOnPresetParameters struct {
+	AddWatermark       bool   `json:"addWatermark"`
+	BaseSteps          int    `json:"baseSteps"`
+	PersonGeneration   string `json:"personGeneration"`
+	SafetySetting      string `json:"safetySetting"`
+	SampleCount        int    `json:"sampleCount"`
+	Seed               int    `json:"seed"`
+	StorageURI         string `json:"storageUri,omitempty"`
+	OutputMimeType     string `json:"outputMimeType"`
+	CompressionQuality int    `json:"compressionQuality"`
+}
+
+// imagenPresetParameters Imagenのプリセットの設定（POST /imagen の項目に対応）
+type imagenPresetParameters struct {
+	ImagenModel      string `json:"imagenModel,omitempty"`
+	NumberOfImages   int    `json:"numberOfImages"`
+	AspectRatio      string `json:"aspectRatio"`
+	NegativePrompt   string `json:"negativePrompt,omitempty"`
+	Seed             int64  `json:"seed"`
+	IncludeRaiReason bool   `json:"includeRaiReason"`
+}
+
+type presetResponse struct {
+	ID          entities.ParameterPresetID `json:"id"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Type        entities.GeneratorType     `json:"type"`
+	BuiltIn     bool                       `json:"builtIn"`
+	Parameters  any                        `json:"parameters"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+	UpdatedAt   time.Time                  `json:"updatedAt"`
+}
+
+// HandleListPresets - プリセットを一覧で返す（?type=tryon|imagen）
+func (h *PresetHandler) HandleListPresets(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.presetUseCase.List(r.Context(), r.URL.Query().Get("type"))
+	if err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	presets := make([]presetResponse, len(outputs))
+	for i, output := range outputs {
+		presets[i] = h.createPresetResponse(output)
+	}
+
+	h.sendJSON(w, http.StatusOK, map[string]any{
+		"presets": presets,
+	})
+}
+
+// HandleGetPreset - プリセットを1件返す
+func (h *PresetHandler) HandleGetPreset(w http.ResponseWriter, r *http.Request) {
+	output, err := h.presetUseCase.Get(r.Context(), entities.ParameterPresetID(mux.Vars(r)["id"]))
+	if err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.createPresetResponse(output))
+}
+
+// HandleCreatePreset - プリセットを保存する。parameters で省略した項目は既定値になる
+func (h *PresetHandler) HandleCreatePreset(w http.ResponseWriter, r *http.Request) {
+	input, ok := h.parsePresetRequest(w, r, "")
+	if !ok {
+		return
+	}
+
+	output, err := h.presetUseCase.Create(r.Context(), input)
+	if err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, h.createPresetResponse(output))
+}
+
+// HandleUpdatePreset - 保存したプリセットを置き換える（組み込みのプリセットは変更できない）
+func (h *PresetHandler) HandleUpdatePreset(w http.ResponseWriter, r *http.Request) {
+	id := entities.ParameterPresetID(mux.Vars(r)["id"])
+
+	current, err := h.presetUseCase.Get(r.Context(), id)
+	if err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	input, ok := h.parsePresetRequest(w, r, current.GeneratorType)
+	if !ok {
+		return
+	}
+
+	output, err := h.presetUseCase.Update(r.Context(), id, input)
+	if err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.createPresetResponse(output))
+}
+
+// HandleDeletePreset - 保存したプリセットを削除する
+func (h *PresetHandler) HandleDeletePreset(w http.ResponseWriter, r *http.Request) {
+	if err := h.presetUseCase.Delete(r.Context(), entities.ParameterPresetID(mux.Vars(r)["id"])); err != nil {
+		h.sendPresetError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parsePresetRequest - JSONを読み取る。generatorType が空の場合はリクエストの type を使う
+func (h *PresetHandler) parsePresetRequest(w http.ResponseWriter, r *http.Request, generatorType entities.GeneratorType) (usecases.ParameterPresetInput, bool) {
+	var request presetRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPresetBodySize)).Decode(&request); err != nil {
+		sendError(w, "リクエストのJSONが不正です", http.StatusBadRequest)
+		return usecases.ParameterPresetInput{}, false
+	}
+
+	if generatorType == "" {
+		generatorType = entities.GeneratorType(request.Type)
+	} else if request.Type != "" && entities.GeneratorType(request.Type) != generatorType {
+		sendError(w, "プリセットの type は変更できません", http.StatusBadRequest)
+		return usecases.ParameterPresetInput{}, false
+	}
+
+	input := usecases.ParameterPresetInput{
+		Name:          request.Name,
+		Description:   request.Description,
+		GeneratorType: string(generatorType),
+	}
+
+	switch generatorType {
+	case entities.GeneratorTryOn:
+		defaults := usecases.DefaultTryOnParametersInput()
+		parameters := tryOnPresetParameters{
+			AddWatermark:       defaults.AddWatermark,
+			BaseSteps:          defaults.BaseSteps,
+			PersonGeneration:   defaults.PersonGeneration,
+			SafetySetting:      defaults.SafetySetting,
+			SampleCount:        defaults.SampleCount,
+			Seed:               defaults.Seed,
+			OutputMimeType:     defaults.OutputMimeType,
+			CompressionQuality: defaults.CompressionQuality,
+		}
+		if !h.decodeParameters(w, request.Parameters, &parameters) {
+			return usecases.ParameterPresetInput{}, false
+		}
+		input.TryOn = &usecases.TryOnParametersInput{
+			AddWatermark:       parameters.AddWatermark,
+			BaseSteps:          parameters.BaseSteps,
+			PersonGeneration:   parameters.PersonGeneration,
+			SafetySetting:      parameters.SafetySetting,
+			SampleCount:        parameters.SampleCount,
+			Seed:               parameters.Seed,
+			StorageURI:         parameters.StorageURI,
+			OutputMimeType:     parameters.OutputMimeType,
+			CompressionQuality: parameters.CompressionQuality,
+		}
+	case entities.GeneratorImagen:
+		defaults := usecases.DefaultImagenParametersInput()
+		parameters := imagenPresetParameters{
+			NumberOfImages: defaults.NumberOfImages,
+			AspectRatio:    defaults.AspectRatio,
+		}
+		if !h.decodeParameters(w, request.Parameters, &parameters) {
+			return usecases.ParameterPresetInput{}, false
+		}
+		if _, err := h.models.Resolve(entities.GeneratorImagen, parameters.ImagenModel); parameters.ImagenModel != "" && err != nil {
+			sendError(w, "サポートされていないモデルです: "+parameters.ImagenModel, http.StatusBadRequest)
+			return usecases.ParameterPresetInput{}, false
+		}
+		input.Imagen = &usecases.ImagenParametersInput{
+			ImagenModel:      parameters.ImagenModel,
+			NumberOfImages:   parameters.NumberOfImages,
+			AspectRatio:      parameters.AspectRatio,
+			NegativePrompt:   parameters.NegativePrompt,
+			Seed:             parameters.Seed,
+			IncludeRaiReason: parameters.IncludeRaiReason,
+		}
+	default:
+		sendError(w, "type は tryon または imagen を指定してください", http.StatusBadRequest)
+		return usecases.ParameterPresetInput{}, false
+	}
+
+	return input, true
+}
+
+func (h *PresetHandler) decodeParameters(w http.ResponseWriter, raw json.RawMessage, parameters any) bool {
+	if len(raw) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(raw, parameters); err != nil {
+		sendError(w, "parameters のJSONが不正です", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *PresetHandler) createPresetResponse(output *usecases.ParameterPresetOutput) presetResponse {
+	response := presetResponse{
+		ID:          output.ID,
+		Name:        output.Name,
+		Description: output.Description,
+		Type:        output.GeneratorType,
+		BuiltIn:     output.BuiltIn,
+		CreatedAt:   output.CreatedAt,
+		UpdatedAt:   output.UpdatedAt,
+	}
+
+	if p := output.TryOn; p != nil {
+		response.Parameters = tryOnPresetParameters{
+			AddWatermark:       p.AddWatermark,
+			BaseSteps:          p.BaseSteps,
+			PersonGeneration:   p.PersonGeneration,
+			SafetySetting:      p.SafetySetting,
+			SampleCount:        p.SampleCount,
+			Seed:               p.Seed,
+			StorageURI:         p.StorageURI,
+			OutputMimeType:     p.OutputMimeType,
+			CompressionQuality: p.CompressionQuality,
+		}
+	}
+	if p := output.Imagen; p != nil {
+		response.Parameters = imagenPresetParameters{
+			ImagenModel:      p.ImagenModel,
+			NumberOfImages:   p.NumberOfImages,
+			AspectRatio:      p.AspectRatio,
+			NegativePrompt:   p.NegativePrompt,
+			Seed:             p.Seed,
+			IncludeRaiReason: p.IncludeRaiReason,
+		}
+	}
+
+	return response
+}
+
+// sendPresetError - プリセット操作のエラーをステータスに変換する
+func (h *PresetHandler) sendPresetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		sendError(w, "プリセットが見つかりません", http.StatusNotFound)
+	case errors.Is(err, entities.ErrParameterPresetReadOnly):
+		sendError(w, "組み込みのプリセットは変更・削除できません", http.StatusConflict)
+	case domainerrors.KindOf(err) == domainerrors.KindInvalidInput:
+		sendError(w, "プリセットの内容が不正です: "+err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Failed to handle preset: %v", err)
+		sendError(w, "プリセットの処理に失敗しました", http.StatusInternalServerError)
+	}
+}
+
+// sendJSON - JSONレスポンスを送信
+func (h *PresetHandler) sendJSON(w http.ResponseWriter, statusCode int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/infrastructure/limiter"
+)
+
+type QueueHandler struct {
+	limiter *limiter.Limiter
+}
+
+func NewQueueHandler(limiter *limiter.Limiter) *QueueHandler {
+	return &QueueHandler{
+		limiter: limiter,
+	}
+}
+
+// QueueStatsResponse モデルごとの混雑状況
+type QueueStatsResponse struct {
+	Success bool                 `json:"success"`
+	Models  []limiter.QueueStats `json:"models"`
+}
+
+// TicketStatusResponse チケットの順番待ちの状況
+type TicketStatusResponse struct {
+	Success bool `json:"success"`
+	limiter.TicketStatus
+}
+
+// HandleQueueStats - モデルごとの実行数・順番待ち数を返す
+func (h *QueueHandler) HandleQueueStats(w http.ResponseWriter, r *http.Request) {
+	h.sendJSON(w, QueueStatsResponse{Success: true, Models: h.limiter.Stats()})
+}
+
+// HandleTicketStatus - チケットの順番待ちの位置と待ち時間の見積もりを返す
+func (h *QueueHandler) HandleTicketStatus(w http.ResponseWriter, r *http.Request) {
+	ticket := mux.Vars(r)["ticket"]
+
+	status, ok := h.limiter.TicketStatus(ticket)
+	if !ok {
+		// 順番待ちも実行中もしていない（完了済み、または未到着）
+		sendError(w, "順番待ち中のリクエストが見つかりません", http.StatusNotFound)
+		return
+	}
+
+	h.sendJSON(w, TicketStatusResponse{Success: true, TicketStatus: status})
+}
+
+// sendJSON - JSONレスポンスを送信
+func (h *QueueHandler) sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
+
+// queueStatusScript - 生成ページ共通の順番待ち表示。
+// リクエストごとにチケットを発行して X-Queue-Ticket で送り、処理が終わるまで /api/queue/{ticket} を問い合わせる。
+const queueStatusScript = `<script>
+function newQueueTicket() {
+    const bytes = new Uint8Array(16);
+    crypto.getRandomValues(bytes);
+    return Array.from(bytes, b => b.toString(16).padStart(2, '0')).join('');
+}
+
+// 順番待ちの状況を定期的に取得してonStatusに渡す（順番待ちでなければnull）。戻り値の関数で停止する
+function watchQueue(ticket, onStatus) {
+    let stopped = false;
+    (async () => {
+        while (!stopped) {
+            await new Promise(resolve => setTimeout(resolve, 2000));
+            if (stopped) break;
+            try {
+                const resp = await fetch('/api/queue/' + ticket, { cache: 'no-store' });
+                const status = resp.ok ? await resp.json() : null;
+                if (!stopped) onStatus(status && status.state === 'waiting' ? status : null);
+            } catch {}
+        }
+    })();
+    return () => { stopped = true; };
+}
+
+function queueStatusText(status) {
+    return '順番待ち: ' + status.position + '番目（約' + Math.ceil(status.estimatedWaitSeconds) + '秒）';
+}
+
+// 順番待ちの状況をonStatusに渡しながらfetchする
+async function fetchWithQueue(url, options, onStatus) {
+    const ticket = newQueueTicket();
+    const headers = new Headers(options.headers || {});
+    headers.set('X-Queue-Ticket', ticket);
+
+    const stop = watchQueue(ticket, onStatus);
+    try {
+        return await fetch(url, { ...options, headers });
+    } finally {
+        stop();
+    }
+}
+</script>`
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+
+	"tryon-demo/internal/application/usecases"
+)
+
+// ClientHeader 利用量を集計するクライアントを指定するヘッダー
+const ClientHeader = "X-Client-ID"
+
+// クライアントが指定できるIDの形式
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ClientMiddleware X-Client-ID のクライアントをコンテキストに設定する。
+// ヘッダーがない、または形式が正しくない場合は anonymous として集計する。
+func ClientMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := r.Header.Get(ClientHeader)
+		if !clientIDPattern.MatchString(client) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(usecases.WithClient(r.Context(), client)))
+	})
+}
+
+type UsageHandler struct {
+	usageUseCase *usecases.UsageUseCase
+}
+
+func NewUsageHandler(usageUseCase *usecases.UsageUseCase) *UsageHandler {
+	return &UsageHandler{
+		usageUseCase: usageUseCase,
+	}
+}
+
+// UsageResponse 期間内の利用量と料金の見積もり
+type UsageResponse struct {
+	Success bool   `json:"success"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	// 絞り込んだクライアント（全てのクライアントの場合は省略）
+	Client   string `json:"client,omitempty"`
+	Currency string `json:"currency" enum:"USD"`
+	// 期間内の料金の見積もりの合計
+	TotalCost float64              `json:"totalCost"`
+	Totals    []UsageTotalResponse `json:"totals"`
+	Today     UsageTodayResponse   `json:"today"`
+}
+
+// UsageTotalResponse 1日・1クライアント・1モデル・1単位ごとの合計
+type UsageTotalResponse struct {
+	Day      string  `json:"day"`
+	Client   string  `json:"client"`
+	Model    string  `json:"model"`
+	Unit     string  `json:"unit" enum:"image,second,request,input_token,output_token"`
+	Quantity float64 `json:"quantity"`
+	Cost     float64 `json:"cost"`
+	// 料金表に単価がない場合は false（cost は0）
+	Priced bool `json:"priced"`
+}
+
+// UsageTodayResponse 今日の利用額と1日の上限（上限が0の場合は無制限）
+type UsageTodayResponse struct {
+	Day    string  `json:"day"`
+	Cost   float64 `json:"cost"`
+	Budget float64 `json:"budget"`
+	// client を指定した場合のみ
+	ClientCost   float64 `json:"clientCost,omitempty"`
+	ClientBudget float64 `json:"clientBudget,omitempty"`
+}
+
+// HandleUsage - 利用量と料金の見積もりを返す（?from=&to=&client= で期間とクライアントを絞り込める）
+func (h *UsageHandler) HandleUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	summary, err := h.usageUseCase.Summary(r.Context(), usecases.UsageQuery{
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Client: query.Get("client"),
+	})
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidUsageQuery) {
+			sendError(w, "from・to は YYYY-MM-DD 形式で、from から to までが366日以内になるように指定してください", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to get usage: %v", err)
+		sendError(w, "利用量の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	response := UsageResponse{
+		Success:   true,
+		From:      summary.From,
+		To:        summary.To,
+		Client:    summary.Client,
+		Currency:  "USD",
+		TotalCost: summary.TotalCost,
+		Totals:    make([]UsageTotalResponse, len(summary.Totals)),
+		Today: UsageTodayResponse{
+			Day:          summary.Today.Day,
+			Cost:         summary.Today.Cost,
+			Budget:       summary.Today.Budget,
+			ClientCost:   summary.Today.ClientCost,
+			ClientBudget: summary.Today.ClientBudget,
+		},
+	}
+	for i, total := range summary.Totals {
+		response.Totals[i] = UsageTotalResponse{
+			Day:      total.Day,
+			Client:   total.Client,
+			Model:    total.Model,
+			Unit:     string(total.Unit),
+			Quantity: total.Quantity,
+			Cost:     total.Cost,
+			Priced:   total.Priced,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
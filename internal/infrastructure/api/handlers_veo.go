@@ -1,209 +1,290 @@
 package api
 
 import (
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
 )
 
 // HandleVeo - 動画生成API
 func (h *VeoHandler) HandleVeo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		h.sendError(w, "POST method required", http.StatusMethodNotAllowed)
+		sendError(w, "POST method required", http.StatusMethodNotAllowed)
 		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
-	if err := r.ParseMultipartForm(maxFileSize); err != nil {
-		h.sendError(w, "画像が大きすぎます（10MBまで対応）", http.StatusRequestEntityTooLarge)
-		return
-	}
+	// multipart/form-data とJSONで同じ項目を受け付け、検証は共通で行う
+	var (
+		imagenPrompt, videoPrompt, veoModel string
+		image                               *valueobjects.ImageData
+	)
+	if isJSONRequest(r) {
+		var request VeoRequest
+		if !decodeJSONRequest(w, r, maxJSONBodySize, &request) {
+			return
+		}
+		imagenPrompt, videoPrompt, veoModel = request.ImagenPrompt, request.VideoPrompt, request.VeoModel
 
-	// 画像プロンプト（オプション）
-	imagenPrompt := r.FormValue("imagenPrompt")
+		var err error
+		image, err = loadImageSource(r.Context(), h.imageFetcher, request.Image)
+		if err != nil {
+			sendUploadError(w, err, "画像")
+			return
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
+		if err := r.ParseMultipartForm(maxFileSize); err != nil {
+			sendError(w, "画像が大きすぎます（10MBまで対応）", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// 画像プロンプト（オプション）
+		imagenPrompt = r.FormValue("imagenPrompt")
+		videoPrompt = r.FormValue("videoPrompt")
+		veoModel = r.FormValue("veoModel")
+
+		var err error
+		image, err = formImageUpload(r, "image")
+		if err != nil {
+			sendUploadError(w, err, "画像")
+			return
+		}
+	}
 
 	// 動画プロンプト（必須）
-	videoPrompt := r.FormValue("videoPrompt")
 	if videoPrompt == "" {
-		h.sendError(w, "動画プロンプトを入力してください", http.StatusBadRequest)
+		sendError(w, "動画プロンプトを入力してください", http.StatusBadRequest)
 		return
 	}
 
-	veoModel := r.FormValue("veoModel")
 	if veoModel == "" {
-		h.sendError(w, "Veoモデルを選択してください", http.StatusBadRequest)
+		sendError(w, "Veoモデルを選択してください", http.StatusBadRequest)
 		return
 	}
 
-	isValidVeoModel := h.isValidVeoModel(veoModel)
-	if !isValidVeoModel {
-		h.sendError(w, "無効なモデルです", http.StatusBadRequest)
+	if _, err := h.models.Resolve(entities.GeneratorVeo, veoModel); err != nil {
+		sendError(w, "無効なモデルです", http.StatusBadRequest)
 		return
 	}
 
-	// 画像ファイルまたはプロンプトのいずれかは必須
-	imageFile, imageFileHeader, err := r.FormFile("image")
-	hasImageFile := err == nil
+	// 画像を生成する場合のImagenのモデルはサーバー側で固定（カタログの既定のモデル）
+	imagenModel, ok := h.models.Default(entities.GeneratorImagen)
+	if !ok && imagenPrompt != "" {
+		sendError(w, "画像生成に使えるモデルがありません", http.StatusServiceUnavailable)
+		return
+	}
 
-	if !hasImageFile && imagenPrompt == "" {
-		h.sendError(w, "画像ファイルまたは画像生成プロンプトのいずれかを指定してください", http.StatusBadRequest)
+	// 画像ファイルまたはプロンプトのいずれかは必須
+	if image == nil && imagenPrompt == "" {
+		sendError(w, "画像ファイルまたは画像生成プロンプトのいずれかを指定してください", http.StatusBadRequest)
 		return
 	}
 
 	var imageData []byte
-	var imageMimeType string
-
-	if hasImageFile {
-		defer imageFile.Close()
-		imageMimeType = imageFileHeader.Header.Get("Content-Type")
-
-		imageData, err = io.ReadAll(imageFile)
-		if err != nil {
-			h.sendError(w, "画像の読み込みに失敗しました", http.StatusInternalServerError)
-			return
-		}
+	if image != nil {
+		imageData = image.Data()
 	}
 
 	// VeoUseCaseの入力を準備
 	input := usecases.VeoInput{
-		ImagenPrompt:  imagenPrompt,
-		ImagenModel:   h.getDefaultImagenModelForVeo(), // サーバー側で固定
-		ImageData:     imageData,
-		ImageMimeType: imageMimeType,
-		VideoPrompt:   videoPrompt,
-		VideoModel:    veoModel,
+		ImagenPrompt: imagenPrompt,
+		ImagenModel:  imagenModel.ID,
+		ImageData:    imageData,
+		VideoPrompt:  videoPrompt,
+		VideoModel:   veoModel,
 	}
 
-	output, err := h.veoUseCase.Execute(r.Context(), input)
+	// ジョブとして登録し、完了を待たずにジョブIDを返す
+	job, err := h.veoUseCase.Submit(r.Context(), input)
 	if err != nil {
-		log.Printf("Video generation failed: %v", err)
-
-		if h.isQuotaError(err) {
-			h.sendError(w, "現在サーバーが混雑しています。しばらく待ってから再試行してください。", http.StatusTooManyRequests)
-			return
-		}
-
-		h.sendError(w, fmt.Sprintf("動画生成に失敗しました: %v", err), http.StatusInternalServerError)
+		log.Printf("Failed to submit video generation job: %v", err)
+		sendGenerationError(w, err, "動画生成ジョブの登録")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.Header().Set("Location", h.jobStatusURL(r, job.ID))
+	w.WriteHeader(http.StatusAccepted)
 
-	response := h.createVeoResponse(output.Videos)
-
+	// 登録・キャンセル直後のジョブは動画を持たない
+	response, _ := h.createVeoJobResponse(r, resultModeBase64, job, nil)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Failed to encode JSON response: %v", err)
-		h.sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
 		return
 	}
 }
 
-// createVeoResponse - Veo用のレスポンスを生成
-func (h *VeoHandler) createVeoResponse(videosData [][]byte) map[string]any {
-	log.Printf("[DEBUG] createVeoResponse called with %d videos", len(videosData))
+// HandleVeoJob - 動画生成ジョブの状態を返す
+func (h *VeoHandler) HandleVeoJob(w http.ResponseWriter, r *http.Request) {
+	jobID := entities.VeoJobID(mux.Vars(r)["id"])
 
-	if len(videosData) == 0 {
-		log.Printf("[WARNING] No video data")
-		return map[string]any{
-			"success": false,
-			"error":   "動画データがありません",
+	job, err := h.veoUseCase.GetJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			sendError(w, "ジョブが見つかりません", http.StatusNotFound)
+			return
 		}
+		log.Printf("Failed to get video generation job: %v", err)
+		sendError(w, "ジョブの取得に失敗しました", http.StatusInternalServerError)
+		return
 	}
 
-	videos := make([]map[string]string, 0, len(videosData))
-	totalSize := 0
-	for i, videoData := range videosData {
-		if len(videoData) == 0 {
-			log.Printf("[WARNING] Empty video data at index %d", i)
-			continue
-		}
+	// 完了したジョブの動画は指定された形式で返す
+	mode := negotiateResultMode(r)
+	var files []resultFile
+	if job.Status == entities.VeoJobStatusSucceeded {
+		files = h.createVeoResultFiles(job.Videos)
+	}
 
-		base64Data := base64.StdEncoding.EncodeToString(videoData)
-		totalSize += len(videoData)
-		log.Printf("[DEBUG] Video %d: size=%d bytes, base64 length=%d characters", i, len(videoData), len(base64Data))
+	response, err := h.createVeoJobResponse(r, mode, job, files)
+	if err != nil {
+		log.Printf("Failed to create response: %v", err)
+		sendError(w, "レスポンスの生成に失敗しました", http.StatusInternalServerError)
+		return
+	}
 
-		videos = append(videos, map[string]string{
-			"data": base64Data,
-			"type": "video/mp4",
-		})
+	if err := h.results.write(w, mode, response, files); err != nil {
+		log.Printf("Failed to write response: %v", err)
+		return
 	}
+}
 
-	if len(videos) == 0 {
-		log.Printf("[WARNING] All video data is empty")
-		return map[string]any{
-			"success": false,
-			"error":   "すべての動画データが空です",
+// HandleCancelVeoJob - 動画生成ジョブをキャンセルする
+func (h *VeoHandler) HandleCancelVeoJob(w http.ResponseWriter, r *http.Request) {
+	jobID := entities.VeoJobID(mux.Vars(r)["id"])
+
+	job, err := h.veoUseCase.CancelJob(r.Context(), jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			sendError(w, "ジョブが見つかりません", http.StatusNotFound)
+		case errors.Is(err, usecases.ErrVeoJobFinished):
+			sendError(w, "ジョブは既に終了しています", http.StatusConflict)
+		default:
+			log.Printf("Failed to cancel video generation job: %v", err)
+			sendError(w, "ジョブのキャンセルに失敗しました", http.StatusInternalServerError)
 		}
+		return
 	}
 
-	log.Printf("[DEBUG] Total %d videos, total size: %d bytes", len(videos), totalSize)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
 
-	response := map[string]any{
-		"success": true,
-		"videos":  videos,
-		"model":   h.getDefaultVeoModel(),
+	// 登録・キャンセル直後のジョブは動画を持たない
+	response, _ := h.createVeoJobResponse(r, resultModeBase64, job, nil)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+		return
 	}
-
-	return response
 }
 
-// isValidVeoModel - 指定されたモデルIDが有効かどうかチェック
-func (h *VeoHandler) isValidVeoModel(modelID string) bool {
-	for _, model := range supportedVeoModels {
-		if model.ID == modelID {
-			return true
-		}
+// jobStatusURL - ジョブ状態取得用のURLを生成（/api/v1 で登録したジョブは /api/v1 のURLを返す）
+func (h *VeoHandler) jobStatusURL(r *http.Request, id entities.VeoJobID) string {
+	if strings.HasPrefix(r.URL.Path, apiV1Prefix+"/") {
+		return apiV1Prefix + "/veo/jobs/" + string(id)
 	}
-	return false
+	return "/veo/jobs/" + string(id)
 }
 
-// getDefaultVeoModel - デフォルトのVeoモデルIDを取得
-func (h *VeoHandler) getDefaultVeoModel() string {
-	return "veo-3.0-generate-preview" // 固定モデル
+// VeoJobResponse - 動画生成ジョブの状態。成功したジョブには動画を含める
+type VeoJobResponse struct {
+	Success        bool                    `json:"success"`
+	JobID          entities.VeoJobID       `json:"jobId"`
+	Status         entities.VeoJobStatus   `json:"status" enum:"queued,running,succeeded,failed,canceled"`
+	Stage          entities.VeoJobStage    `json:"stage" enum:"queued,generating_image,generating_video,completed"`
+	Progress       int                     `json:"progress"`
+	Model          string                  `json:"model"`
+	StatusURL      string                  `json:"statusUrl"`
+	CreatedAt      time.Time               `json:"createdAt"`
+	UpdatedAt      time.Time               `json:"updatedAt"`
+	ElapsedSeconds int                     `json:"elapsedSeconds"`
+	Error          string                  `json:"error,omitempty"`
+	Code           domainerrors.Kind       `json:"code,omitempty"`
+	Videos         []GeneratedFileResponse `json:"videos,omitempty"`
 }
 
-// サポートされるVeoモデル一覧（サーバー側で固定）
-var supportedVeoModels = []VeoModel{
-	{
-		ID:          "veo-3.0-generate-preview",
-		Name:        "Veo 3.0 Preview",
-		Description: "最新動画生成モデル（プレビュー版）",
-	},
-	{
-		ID:          "veo-3.0-fast-generate-preview",
-		Name:        "Veo 3.0 Fast",
-		Description: "最新動画生成モデル（高速版）",
-	},
-	{
-		ID:          "veo-2.0-generate-001",
-		Name:        "Veo 2.0",
-		Description: "動画生成モデル（旧バージョン）",
-	},
-}
+// createVeoJobResponse - ジョブ状態のレスポンスを生成
+func (h *VeoHandler) createVeoJobResponse(
+	r *http.Request,
+	mode resultMode,
+	job *usecases.VeoJobOutput,
+	files []resultFile,
+) (*VeoJobResponse, error) {
+	response := &VeoJobResponse{
+		Success:        job.Status != entities.VeoJobStatusFailed,
+		JobID:          job.ID,
+		Status:         job.Status,
+		Stage:          job.Stage,
+		Progress:       job.Progress,
+		Model:          job.Model,
+		StatusURL:      h.jobStatusURL(r, job.ID),
+		CreatedAt:      job.CreatedAt,
+		UpdatedAt:      job.UpdatedAt,
+		ElapsedSeconds: int(job.UpdatedAt.Sub(job.CreatedAt).Seconds()),
+	}
 
-// isQuotaError - クォータエラーかどうかを判定
-func (h *VeoHandler) isQuotaError(err error) bool {
-	return fmt.Sprintf("%v", err) != "" &&
-		(fmt.Sprintf("%v", err) == "service temporarily unavailable due to high demand")
-}
+	if job.Error != "" {
+		response.Error = generationErrorMessage(job.ErrorKind, errors.New(job.Error), "動画生成")
+		response.Code = job.ErrorKind
+	}
 
-// sendError - エラーレスポンスを送信
-func (h *VeoHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	// 完了したジョブには動画を含める
+	if job.Status == entities.VeoJobStatusSucceeded {
+		if len(files) == 0 {
+			log.Printf("[WARNING] No video data")
+			response.Success = false
+			response.Error = "動画データがありません"
+			return response, nil
+		}
+
+		videos, err := h.results.fileEntries(r.Context(), mode, files)
+		if err != nil {
+			return nil, err
+		}
+
+		totalSize := 0
+		for _, file := range files {
+			totalSize += len(file.Data)
+		}
+		slog.Debug("Created video result response", "videos", len(videos), "bytes", totalSize, "mode", mode)
+
+		response.Videos = videos
+	}
+
+	return response, nil
 }
 
-// getDefaultImagenModelForVeo - Veo用のデフォルトImagenモデルIDを取得
-func (h *VeoHandler) getDefaultImagenModelForVeo() string {
-	return "imagen-3.0-generate-002" // 安定版を推奨
+// createVeoResultFiles - 生成動画をレスポンス用のファイルに変換
+func (h *VeoHandler) createVeoResultFiles(videosData [][]byte) []resultFile {
+	files := make([]resultFile, 0, len(videosData))
+	for i, videoData := range videosData {
+		if len(videoData) == 0 {
+			log.Printf("[WARNING] Empty video data at index %d", i)
+			continue
+		}
+
+		files = append(files, resultFile{
+			ID:       fmt.Sprintf("video_%d", i),
+			Data:     videoData,
+			MimeType: "video/mp4",
+		})
+	}
+
+	return files
 }
 
 // HandleVeoIndex - Veo動画生成画面を表示
@@ -211,24 +292,8 @@ func (h *VeoHandler) HandleVeoIndex(w http.ResponseWriter, r *http.Request) {
 	// 現在のVertex AIリージョン情報をツールチップに含める
 	locationInfo := fmt.Sprintf(" 現在のVertex AIリージョン: %s", h.location)
 
-	// モデル選択肢を動的に生成
-	var modelOptions strings.Builder
-	for i, model := range supportedVeoModels {
-		selected := ""
-		if model.ID == h.getDefaultVeoModel() {
-			selected = " selected"
-		}
-
-		modelOptions.WriteString(fmt.Sprintf(
-			`<option value="%s"%s>%s</option>`,
-			model.ID,
-			selected,
-			fmt.Sprintf("%s - %s", model.Name, model.Description),
-		))
-		if i < len(supportedImagenModels)-1 {
-			modelOptions.WriteString("\n")
-		}
-	}
+	// モデル選択肢はモデルカタログから生成
+	modelOptions := modelOptionsHTML(h.models, entities.GeneratorVeo)
 
 	html := `<!DOCTYPE html>
 <html lang="ja">
@@ -287,6 +352,10 @@ Veo動画生成
 <svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z"/></svg>
 Nanobanana画像編集
 </button>
+<button onclick="location.href='/history'" class="px-4 py-2 bg-gray-600 text-white rounded-lg hover:bg-gray-700 transition-colors font-medium shadow-sm">
+<svg class="w-4 h-4 inline mr-2" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>
+生成履歴
+</button>
 </div>
 </nav>
 
@@ -344,7 +413,7 @@ Nanobanana画像編集
 </button>
 </div>
 </div>
-<input type="file" id="image-input" name="image" accept="image/*" class="hidden">
+<input type="file" id="image-input" name="image" accept="image/*,.heic,.heif,.avif" class="hidden">
 </div>
 
 <!-- 画像生成用UI（非表示がデフォルト） -->
@@ -370,7 +439,7 @@ Imagenモデル
 </div>
 </label>
 <select id="veoModel" name="veoModel" class="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-indigo-500">
-` + modelOptions.String() + `
+` + modelOptions + `
 </select>
 </div>
 <div>
@@ -411,6 +480,7 @@ class="px-6 py-2 text-sm rounded-lg border border-gray-300 text-gray-600 hover:b
 </main>
 
 </div>
+` + queueStatusScript + `
 <script>
 const form = document.getElementById('veo-form');
 const videoPromptInput = document.getElementById('videoPrompt');
@@ -551,6 +621,55 @@ clearBtn.addEventListener('click', () => {
     }
 });
 
+// 進捗表示
+const stageLabels = {
+    queued: '待機中',
+    generating_image: '画像を生成中',
+    generating_video: '動画を生成中',
+    completed: '完了'
+};
+
+// 順番待ちの状況（順番待ちでなければnull）
+let queueStatus = null;
+
+function showJobProgress(job) {
+    const label = stageLabels[job.stage] || job.stage;
+    resultDisplay.innerHTML =
+        '<div class="flex flex-col items-center gap-3">' +
+        '<div class="loader"></div>' +
+        '<p class="text-gray-600">' + label + '... ' + job.progress + '% (' + job.elapsedSeconds + '秒経過)</p>' +
+        (queueStatus ? '<p class="text-sm text-amber-600">' + queueStatusText(queueStatus) + '</p>' : '') +
+        '<button type="button" id="cancel-job-btn" class="px-4 py-1 text-sm rounded-lg border border-red-300 text-red-600 hover:bg-red-50">キャンセル</button>' +
+        '</div>';
+    document.getElementById('cancel-job-btn').onclick = async () => {
+        await fetch(job.statusUrl, { method: 'DELETE' });
+    };
+}
+
+// ジョブが終了するまで状態を取得し続ける
+async function waitForJob(job) {
+    while (true) {
+        if (job.status === 'succeeded') return job;
+        if (job.status === 'failed') throw new Error(job.error || '動画の生成に失敗しました');
+        if (job.status === 'canceled') throw new Error('動画生成をキャンセルしました');
+
+        showJobProgress(job);
+        await new Promise(resolve => setTimeout(resolve, 5000));
+
+        // 動画はBase64ではなくURLで受け取る（シーク可能にするため）
+        const resp = await fetch(job.statusUrl + '?response=url', { cache: 'no-store' });
+        if (!resp.ok) {
+            let msg = 'HTTP ' + resp.status;
+            try {
+                const j = await resp.json();
+                if (j && j.error) msg = j.error;
+            } catch {}
+            throw new Error(msg);
+        }
+        job = await resp.json();
+    }
+}
+
 // フォーム送信
 form.addEventListener('submit', async (event) => {
     event.preventDefault();
@@ -610,7 +729,9 @@ form.addEventListener('submit', async (event) => {
     }
 
     try {
-        const resp = await fetch('/veo', { method: 'POST', body: formData });
+        // ジョブは同じチケットで順番待ちするので、完了するまで状況を問い合わせる
+        const ticket = newQueueTicket();
+        const resp = await fetch('/veo', { method: 'POST', body: formData, headers: { 'X-Queue-Ticket': ticket } });
         if (!resp.ok) {
             let msg = 'HTTP ' + resp.status;
             try {
@@ -619,8 +740,17 @@ form.addEventListener('submit', async (event) => {
             } catch {}
             throw new Error(msg);
         }
-        
-        const data = await resp.json();
+
+        // ジョブIDを受け取り、完了するまでポーリングする
+        const job = await resp.json();
+        const stopWatchingQueue = watchQueue(ticket, status => { queueStatus = status; });
+        let data;
+        try {
+            data = await waitForJob(job);
+        } finally {
+            stopWatchingQueue();
+            queueStatus = null;
+        }
 		console.log(data);
         if (data.success && data.videos && data.videos.length > 0) {
             resultDisplay.innerHTML = '';
@@ -631,7 +761,7 @@ form.addEventListener('submit', async (event) => {
                 videoContainer.className = 'relative w-full h-full flex items-center justify-center mb-4';
                 
                 const videoElement = document.createElement('video');
-                videoElement.src = 'data:' + video.type + ';base64,' + video.data;
+                videoElement.src = video.url || ('data:' + video.type + ';base64,' + video.data);
                 videoElement.alt = 'Generated Video ' + (index + 1);
                 videoElement.className = 'max-w-full max-h-full object-contain rounded-lg shadow-md';
                 videoElement.controls = true;
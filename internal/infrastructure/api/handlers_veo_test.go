@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	domainservices "tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestVeoHandler_HandleVeo_BudgetExceeded(t *testing.T) {
+	// 今日の利用額が上限（$1）に達している
+	usageRepository := repositories.NewMemoryUsageRepository()
+	if err := usageRepository.Add(context.Background(), []entities.UsageTotal{{
+		Day: entities.UsageDay(time.Now()), Model: "veo-2.0-generate-001", Unit: entities.PriceUnitSecond, Quantity: 8, Cost: 4,
+	}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	models := domainservices.DefaultModelCatalog()
+	usage := usecases.NewUsageUseCase(usageRepository, domainservices.DefaultPriceTable(models), usecases.UsageBudget{Daily: 1})
+
+	config := fake.Config{}
+	text := fake.NewTextAIService(config)
+	normalizeOptions := valueobjects.DefaultNormalizeOptions()
+	veoUseCase := usecases.NewVeoUseCase(
+		domainservices.NewVeoDomainService(fake.NewVeoAIService(config), text, normalizeOptions),
+		domainservices.NewImagenDomainService(fake.NewImagenAIService(config), text),
+		repositories.NewMemoryVeoJobRepository(), nil, usage,
+	)
+	handler := NewVeoHandler(veoUseCase, models, nil, nil, "test")
+
+	r := mux.NewRouter()
+	r.HandleFunc("/veo", handler.HandleVeo).Methods(http.MethodPost)
+	NewV1Handler(nil, nil, handler, nil, nil, nil).RegisterRoutes(r)
+
+	image := testPNG(t)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("videoPrompt", "walk")
+	_ = writer.WriteField("veoModel", "veo-2.0-generate-001")
+	part, _ := writer.CreateFormFile("image", "person.png")
+	_, _ = part.Write(image)
+	_ = writer.Close()
+	formRequest := httptest.NewRequest(http.MethodPost, "/veo", &form)
+	formRequest.Header.Set("Content-Type", writer.FormDataContentType())
+
+	body, _ := json.Marshal(map[string]any{
+		"videoPrompt": "walk", "veoModel": "veo-2.0-generate-001",
+		"image": map[string]any{"data": base64.StdEncoding.EncodeToString(image)},
+	})
+	jsonRequest := httptest.NewRequest(http.MethodPost, "/api/v1/veo", bytes.NewReader(body))
+	jsonRequest.Header.Set("Content-Type", "application/json")
+
+	for _, req := range []*http.Request{formRequest, jsonRequest} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusPaymentRequired {
+			t.Fatalf("%s: status = %d, want %d, body = %s", req.URL.Path, rec.Code, http.StatusPaymentRequired, rec.Body.String())
+		}
+		var response ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: Decode() error = %v", req.URL.Path, err)
+		}
+		if response.Code != string(domainerrors.KindBudgetExceeded) {
+			t.Errorf("%s: code = %s, want %s", req.URL.Path, response.Code, domainerrors.KindBudgetExceeded)
+		}
+	}
+}
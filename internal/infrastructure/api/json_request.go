@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	appservices "tryon-demo/internal/application/services"
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// fieldCodeInvalidType JSONの値の型が合わない（文字列の項目に数値を指定したなど）
+const fieldCodeInvalidType = "invalid_type"
+
+// JSONのリクエストで受け付ける大きさ（Base64にした画像を含む。アップロードの上限の4/3倍と項目分）
+const maxJSONBodySize = maxFileSize/3*4 + 64*1024
+
+var (
+	// errImageSourceAmbiguous data と url の両方が指定された
+	errImageSourceAmbiguous = errors.New("specify either data or url, not both")
+	// errImageURLDisabled URLで指定された画像を取得できない設定
+	errImageURLDisabled = errors.New("image URLs are not supported")
+	// errImageFetchFailed URLの画像を取得できなかった
+	errImageFetchFailed = errors.New("failed to fetch image")
+)
+
+// ImageFetcher URLで指定された画像を取得する
+type ImageFetcher interface {
+	Fetch(ctx context.Context, rawURL string) ([]byte, error)
+}
+
+// ImageSource JSONで受け取る画像。data（Base64、data: URLも可）と url のどちらかを指定する
+type ImageSource struct {
+	Data string `json:"data,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// GarmentImageSource 試着の衣服画像（種類は省略可）
+type GarmentImageSource struct {
+	ImageSource
+	Category string `json:"category,omitempty" enum:"tops,bottoms,shoes,accessories"`
+}
+
+// TryOnRequest POST /tryon（/api/v1/tryon）のJSONリクエスト。パラメータの項目名はフォームと同じ
+type TryOnRequest struct {
+	PersonImage   ImageSource          `json:"person_image"`
+	GarmentImages []GarmentImageSource `json:"garment_images"`
+	TryOnMode     string               `json:"tryon_mode,omitempty" enum:"separate,outfit"`
+
+	Preset             string `json:"preset,omitempty"`
+	AddWatermark       *bool  `json:"add_watermark,omitempty"`
+	BaseSteps          *int   `json:"base_steps,omitempty"`
+	PersonGeneration   string `json:"person_generation,omitempty"`
+	SafetySetting      string `json:"safety_setting,omitempty"`
+	SampleCount        *int   `json:"sample_count,omitempty"`
+	Seed               *int64 `json:"seed,omitempty"`
+	StorageURI         string `json:"storage_uri,omitempty"`
+	OutputMimeType     string `json:"output_mime_type,omitempty"`
+	CompressionQuality *int   `json:"compression_quality,omitempty"`
+}
+
+// parameterValues 生成パラメータをフォームと同じ形にする（検証はフォームと同じ処理で行う）
+func (r *TryOnRequest) parameterValues() url.Values {
+	values := url.Values{}
+	setStringValue(values, "preset", r.Preset)
+	setBoolValue(values, "add_watermark", r.AddWatermark)
+	setIntValue(values, "base_steps", r.BaseSteps)
+	setStringValue(values, "person_generation", r.PersonGeneration)
+	setStringValue(values, "safety_setting", r.SafetySetting)
+	setIntValue(values, "sample_count", r.SampleCount)
+	setIntValue(values, "seed", r.Seed)
+	setStringValue(values, "storage_uri", r.StorageURI)
+	setStringValue(values, "output_mime_type", r.OutputMimeType)
+	setIntValue(values, "compression_quality", r.CompressionQuality)
+	return values
+}
+
+// ImagenRequest POST /imagen（/api/v1/imagen）のJSONリクエスト
+type ImagenRequest struct {
+	Prompt string `json:"prompt"`
+
+	Preset           string `json:"preset,omitempty"`
+	ImagenModel      string `json:"imagenModel,omitempty"`
+	NumberOfImages   *int   `json:"numberOfImages,omitempty"`
+	AspectRatio      string `json:"aspectRatio,omitempty"`
+	NegativePrompt   string `json:"negativePrompt,omitempty"`
+	Seed             *int64 `json:"seed,omitempty"`
+	IncludeRaiReason *bool  `json:"includeRaiReason,omitempty"`
+}
+
+func (r *ImagenRequest) parameterValues() url.Values {
+	values := url.Values{}
+	setStringValue(values, "preset", r.Preset)
+	setStringValue(values, "imagenModel", r.ImagenModel)
+	setIntValue(values, "numberOfImages", r.NumberOfImages)
+	setStringValue(values, "aspectRatio", r.AspectRatio)
+	setStringValue(values, "negativePrompt", r.NegativePrompt)
+	setIntValue(values, "seed", r.Seed)
+	setBoolValue(values, "includeRaiReason", r.IncludeRaiReason)
+	return values
+}
+
+// VeoRequest POST /veo（/api/v1/veo）のJSONリクエスト。image と imagenPrompt のどちらかを指定する
+type VeoRequest struct {
+	VideoPrompt  string      `json:"videoPrompt"`
+	VeoModel     string      `json:"veoModel"`
+	ImagenPrompt string      `json:"imagenPrompt,omitempty"`
+	Image        ImageSource `json:"image,omitempty"`
+}
+
+// NanobananaRequest POST /nanobanana/image-editing（/api/v1/nanobanana/image-editing）のJSONリクエスト
+type NanobananaRequest struct {
+	Prompt string        `json:"prompt"`
+	Images []ImageSource `json:"images"`
+}
+
+func setStringValue(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+func setIntValue[T int | int64](values url.Values, key string, value *T) {
+	if value != nil {
+		values.Set(key, strconv.FormatInt(int64(*value), 10))
+	}
+}
+
+func setBoolValue(values url.Values, key string, value *bool) {
+	if value != nil {
+		values.Set(key, strconv.FormatBool(*value))
+	}
+}
+
+// isJSONRequest - Content-Type が application/json のリクエストかどうか
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// decodeJSONRequest - JSONのリクエストを読み取る。失敗した場合はエラーレスポンスを送信して false を返す。
+// 型が合わない項目は、フォームの検証エラーと同じ形式（fields）で返す。
+func decodeJSONRequest(w http.ResponseWriter, r *http.Request, maxBytes int64, request any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(request)
+	if err == nil && decoder.More() {
+		err = errors.New("request body must contain a single JSON object")
+	}
+	if err == nil {
+		return true
+	}
+
+	var (
+		maxBytesErr *http.MaxBytesError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		sendError(w, "リクエストが大きすぎます", http.StatusRequestEntityTooLarge)
+	case errors.As(err, &typeErr):
+		code := fieldCodeInvalidType
+		switch typeErr.Type.Kind() {
+		case reflect.Int, reflect.Int64:
+			code = appservices.ParameterCodeInvalidNumber
+		case reflect.Bool:
+			code = appservices.ParameterCodeInvalidBoolean
+		}
+		sendGenerationError(w, domainerrors.NewValidation([]domainerrors.FieldError{{
+			Field:   typeErr.Field,
+			Code:    code,
+			Message: fmt.Sprintf("must be %s, got %s", typeErr.Type, typeErr.Value),
+		}}), "リクエストの読み込み")
+	default:
+		sendError(w, "リクエストのJSONが不正です: "+err.Error(), http.StatusBadRequest)
+	}
+	return false
+}
+
+// loadImageSource - JSONで指定された画像を読み込む。指定がない場合は nil を返す。
+// 形式の判定はアップロードと同じく中身から行う。
+func loadImageSource(ctx context.Context, fetcher ImageFetcher, source ImageSource) (*valueobjects.ImageData, error) {
+	var data []byte
+	switch {
+	case source.Data != "" && source.URL != "":
+		return nil, errImageSourceAmbiguous
+	case source.Data != "":
+		encoded := source.Data
+		// data:image/png;base64,... の形式も受け付ける
+		if strings.HasPrefix(encoded, "data:") {
+			if _, after, ok := strings.Cut(encoded, ";base64,"); ok {
+				encoded = after
+			}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 image: %w", err)
+		}
+		data = decoded
+	case source.URL != "":
+		if fetcher == nil {
+			return nil, errImageURLDisabled
+		}
+		fetched, err := fetcher.Fetch(ctx, source.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errImageFetchFailed, err)
+		}
+		data = fetched
+	default:
+		return nil, nil
+	}
+
+	return valueobjects.NewImageData(data)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// OpenAPI 3 のドキュメント。/api/v1 のルート定義と、リクエスト・レスポンスの型から生成する
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// openAPIPathItem HTTPメソッド（小文字）ごとの操作
+type openAPIPathItem map[string]*openAPIOperation
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Enum       []string                  `json:"enum,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	// false（定義されていない項目を含まない）、またはマップの値のスキーマ
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+// openAPISchemas Goの型からスキーマを生成し、名前のある構造体は components に登録する。
+// 項目名は json タグ、選択肢は enum タグ（カンマ区切り）から読み取り、omitempty とポインタ以外の項目を必須にする。
+type openAPISchemas struct {
+	schemas map[string]*openAPISchema
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{
+		schemas: make(map[string]*openAPISchema),
+	}
+}
+
+func (g *openAPISchemas) schemaFor(t reflect.Type) *openAPISchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// 自身を参照する型でも止まるよう、先に名前を登録する
+			g.schemas[t.Name()] = nil
+			g.schemas[t.Name()] = g.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		// any など、型を決められない値
+		return &openAPISchema{}
+	}
+}
+
+func (g *openAPISchemas) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{
+		Type:                 "object",
+		Properties:           make(map[string]*openAPISchema),
+		AdditionalProperties: false,
+	}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *openAPISchemas) addFields(schema *openAPISchema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		// 埋め込んだ構造体の項目は同じ階層に並ぶ
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		schema.Properties[name] = property
+
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"tryon-demo/internal/application/usecases"
+)
+
+// 生成結果の返し方
+type resultMode string
+
+const (
+	// JSONにBase64で埋め込む（従来の形式）
+	resultModeBase64 resultMode = "base64"
+	// サーバー側に保存し、JSONには /api/assets/{id} のURLを含める
+	resultModeURL resultMode = "url"
+	// 1つ目のパートにJSON、以降のパートにファイル本体を入れる
+	resultModeMultipart resultMode = "multipart"
+)
+
+// resultFile - レスポンスに含める生成ファイル
+type resultFile struct {
+	ID       string
+	Data     []byte
+	MimeType string
+	// Storage URI指定時の保存先（Data は空で、返し方によらずURIのみを返す）
+	URI string
+}
+
+// negotiateResultMode - クエリ（?response=url|multipart|base64）またはAcceptヘッダーから返し方を決める
+func negotiateResultMode(r *http.Request) resultMode {
+	switch resultMode(r.URL.Query().Get("response")) {
+	case resultModeURL:
+		return resultModeURL
+	case resultModeMultipart:
+		return resultModeMultipart
+	case resultModeBase64:
+		return resultModeBase64
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == "multipart/mixed" {
+			return resultModeMultipart
+		}
+	}
+
+	return resultModeBase64
+}
+
+// resultResponder - 生成結果を指定された形式で書き出す
+type resultResponder struct {
+	assetUseCase *usecases.AssetUseCase
+}
+
+func newResultResponder(assetUseCase *usecases.AssetUseCase) *resultResponder {
+	return &resultResponder{
+		assetUseCase: assetUseCase,
+	}
+}
+
+// GeneratedFileResponse - JSONに含める生成ファイル。返し方に応じて data / url / contentId のいずれか、
+// Storage URI指定時は uri だけを含む
+type GeneratedFileResponse struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Size      int    `json:"size,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+	ContentID string `json:"contentId,omitempty"`
+	URI       string `json:"uri,omitempty"`
+}
+
+// fileEntries - JSONに含めるファイル情報を生成する
+func (rr *resultResponder) fileEntries(ctx context.Context, mode resultMode, files []resultFile) ([]GeneratedFileResponse, error) {
+	entries := make([]GeneratedFileResponse, 0, len(files))
+	for _, file := range files {
+		if file.URI != "" {
+			entries = append(entries, GeneratedFileResponse{
+				ID:   file.ID,
+				Type: file.MimeType,
+				URI:  file.URI,
+			})
+			continue
+		}
+
+		entry := GeneratedFileResponse{
+			ID:   file.ID,
+			Type: file.MimeType,
+			Size: len(file.Data),
+		}
+
+		switch mode {
+		case resultModeURL:
+			asset, err := rr.assetUseCase.Store(ctx, file.Data, file.MimeType)
+			if err != nil {
+				return nil, err
+			}
+			entry.URL = "/api/assets/" + asset.ID
+		case resultModeMultipart:
+			// 対応するパートの Content-ID
+			entry.ContentID = file.ID
+		default:
+			entry.Data = base64.StdEncoding.EncodeToString(file.Data)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// write - JSON、またはJSONとファイル本体をmultipart/mixedで書き出す
+func (rr *resultResponder) write(w http.ResponseWriter, mode resultMode, response any, files []resultFile) error {
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+
+	if mode != resultModeMultipart {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(response)
+	}
+
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+
+	metadata, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"application/json"},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(metadata); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.URI != "" {
+			continue
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":   {file.MimeType},
+			"Content-Length": {strconv.Itoa(len(file.Data))},
+			"Content-ID":     {"<" + file.ID + ">"},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
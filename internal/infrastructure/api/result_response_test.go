@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+func TestNegotiateResultMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		want   resultMode
+	}{
+		{name: "既定", target: "/imagen", want: resultModeBase64},
+		{name: "base64", target: "/imagen?response=base64", want: resultModeBase64},
+		{name: "url", target: "/imagen?response=url", want: resultModeURL},
+		{name: "multipart", target: "/imagen?response=multipart", want: resultModeMultipart},
+		{name: "未知の値は既定", target: "/imagen?response=xml", want: resultModeBase64},
+		{name: "Accept", target: "/imagen", accept: "multipart/mixed", want: resultModeMultipart},
+		{name: "Acceptの候補の1つ", target: "/imagen", accept: "application/json, multipart/mixed; q=0.9", want: resultModeMultipart},
+		{name: "Accept: JSON", target: "/imagen", accept: "application/json", want: resultModeBase64},
+		{name: "クエリを優先", target: "/imagen?response=url", accept: "multipart/mixed", want: resultModeURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if got := negotiateResultMode(req); got != tt.want {
+				t.Errorf("negotiateResultMode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type testResultResponse struct {
+	Images []GeneratedFileResponse `json:"images"`
+}
+
+// writeResult fileEntries と write で生成結果を書き出す
+func writeResult(t *testing.T, rr *resultResponder, mode resultMode, files []resultFile) *httptest.ResponseRecorder {
+	t.Helper()
+
+	entries, err := rr.fileEntries(context.Background(), mode, files)
+	if err != nil {
+		t.Fatalf("fileEntries() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	if err := rr.write(rec, mode, testResultResponse{Images: entries}, files); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	return rec
+}
+
+var testResultFiles = []resultFile{
+	{ID: "image-1", Data: []byte("first"), MimeType: "image/png"},
+	{ID: "image-2", Data: []byte("second"), MimeType: "image/jpeg"},
+	{ID: "image-3", MimeType: "image/png", URI: "gs://bucket/out/3.png"},
+}
+
+func TestResultResponder_Base64(t *testing.T) {
+	rr := newResultResponder(usecases.NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20)))
+	rec := writeResult(t, rr, resultModeBase64, testResultFiles)
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", got)
+	}
+	var response testResultResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(response.Images) != 3 {
+		t.Fatalf("images = %d, want 3", len(response.Images))
+	}
+	if response.Images[0].Data != base64.StdEncoding.EncodeToString([]byte("first")) || response.Images[0].Size != 5 {
+		t.Errorf("images[0] = %+v, want the data in base64", response.Images[0])
+	}
+	// Storage URI指定時はURIのみ
+	if image := response.Images[2]; image.URI != "gs://bucket/out/3.png" || image.Data != "" {
+		t.Errorf("images[2] = %+v, want the URI only", image)
+	}
+}
+
+func TestResultResponder_URL(t *testing.T) {
+	assetUseCase := usecases.NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20))
+	rr := newResultResponder(assetUseCase)
+	rec := writeResult(t, rr, resultModeURL, testResultFiles)
+
+	var response testResultResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	image := response.Images[1]
+	if image.Data != "" || !strings.HasPrefix(image.URL, "/api/assets/") || !strings.HasSuffix(image.URL, ".jpg") {
+		t.Fatalf("images[1] = %+v, want an asset URL without data", image)
+	}
+
+	// URLの先に保存されている
+	asset, err := assetUseCase.Get(context.Background(), strings.TrimPrefix(image.URL, "/api/assets/"))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(asset.Data) != "second" || asset.MimeType != "image/jpeg" {
+		t.Errorf("asset = %q %s, want second image/jpeg", asset.Data, asset.MimeType)
+	}
+}
+
+func TestResultResponder_Multipart(t *testing.T) {
+	rr := newResultResponder(usecases.NewAssetUseCase(repositories.NewMemoryBlobStore(1 << 20)))
+	rec := writeResult(t, rr, resultModeMultipart, testResultFiles)
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %s, want multipart/mixed", rec.Header().Get("Content-Type"))
+	}
+	reader := multipart.NewReader(rec.Body, params["boundary"])
+
+	// 1つ目のパートはJSON
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart() error = %v", err)
+	}
+	if got := part.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("metadata Content-Type = %s", got)
+	}
+	var response testResultResponse
+	if err := json.NewDecoder(part).Decode(&response); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if image := response.Images[0]; image.ContentID != "image-1" || image.Data != "" {
+		t.Errorf("images[0] = %+v, want a content ID without data", image)
+	}
+
+	// 以降のパートはファイル本体（URIのみのファイルは含めない）
+	for _, want := range testResultFiles[:2] {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if part.Header.Get("Content-ID") != "<"+want.ID+">" || part.Header.Get("Content-Type") != want.MimeType || string(data) != string(want.Data) {
+			t.Errorf("part %s = %v %q, want %s %q", want.ID, part.Header, data, want.MimeType, want.Data)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("NextPart() error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/external"
+)
+
+// readImageUpload アップロードされた画像を読み込む。
+// クライアントが送る Content-Type は使わず、中身から形式とMIMEタイプを判定する。
+func readImageUpload(fileHeader *multipart.FileHeader) (*valueobjects.ImageData, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	return valueobjects.NewImageData(data)
+}
+
+// formImageUpload multipart の項目から1枚目の画像を読み込む。項目がない場合は nil を返す。
+func formImageUpload(r *http.Request, field string) (*valueobjects.ImageData, error) {
+	if r.MultipartForm == nil || len(r.MultipartForm.File[field]) == 0 {
+		return nil, nil
+	}
+	return readImageUpload(r.MultipartForm.File[field][0])
+}
+
+// sendUploadError - 画像の読み込みエラーを、形式の問題とそれ以外に分けて返す（JSONで指定された画像の取得エラーを含む）
+func sendUploadError(w http.ResponseWriter, err error, label string) {
+	switch {
+	case errors.Is(err, errImageSourceAmbiguous):
+		sendError(w, label+"は data と url のどちらか一方を指定してください", http.StatusBadRequest)
+	case errors.Is(err, errImageURLDisabled), errors.Is(err, external.ErrImageURLNotAllowed):
+		sendError(w, label+"のURLからは画像を取得できません", http.StatusBadRequest)
+	case errors.Is(err, external.ErrImageTooLarge):
+		sendError(w, label+"が大きすぎます（10MBまで対応）", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errImageFetchFailed):
+		sendError(w, label+"をURLから取得できませんでした", http.StatusBadRequest)
+	case errors.Is(err, valueobjects.ErrTooManyPixels):
+		sendError(w, label+"の画素数が多すぎます（HEIC / AVIF は5000万画素まで対応）", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, valueobjects.ErrAnimatedImage):
+		sendError(w, label+"はアニメーション画像のため使用できません。静止画を選んでください", http.StatusUnsupportedMediaType)
+	case errors.Is(err, valueobjects.ErrUnsupportedImageFormat):
+		sendError(w, label+"の形式に対応していません（JPEG / PNG / GIF / WebP / HEIC / AVIF に対応）", http.StatusUnsupportedMediaType)
+	default:
+		sendError(w, label+"の読み込みに失敗しました", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// バージョン付きのAPIのパス。画面（GET /imagen など）とは別に、JSONのAPIだけを公開する
+const apiV1Prefix = "/api/v1"
+
+// v1Operation /api/v1 の1つの操作。ルートの登録とOpenAPIのドキュメントの両方をここから作る
+type v1Operation struct {
+	method      string
+	path        string // apiV1Prefix からのパス（gorilla/mux の形式）
+	operationID string
+	summary     string
+	handler     http.HandlerFunc
+	parameters  []openAPIParameter
+	// JSONのリクエスト本文（nilの場合は本文なし）
+	request any
+	// 成功時のステータスとレスポンス（nilの場合は型を持たないJSON）
+	status   int
+	response any
+	// ?response= で生成結果の返し方を選べる
+	results bool
+}
+
+type V1Handler struct {
+	operations []v1Operation
+	document   openAPIDocument
+}
+
+func NewV1Handler(
+	tryOn *TryOnHandler,
+	imagen *ImagenHandler,
+	veo *VeoHandler,
+	nanobanana *NanobananaHandler,
+	models *ModelHandler,
+	usage *UsageHandler,
+) *V1Handler {
+	h := &V1Handler{}
+
+	jobIDParameter := openAPIParameter{
+		Name: "id", In: "path", Required: true, Description: "ジョブID", Schema: &openAPISchema{Type: "string"},
+	}
+	h.operations = []v1Operation{
+		{
+			method: http.MethodPost, path: "/tryon", operationID: "tryOn", summary: "バーチャル試着を実行する",
+			handler: tryOn.HandleTryOn, request: TryOnRequest{}, status: http.StatusOK, response: TryOnResponse{}, results: true,
+		},
+		{
+			method: http.MethodPost, path: "/imagen", operationID: "generateImages", summary: "Imagenで画像を生成する",
+			handler: imagen.HandleImagen, request: ImagenRequest{}, status: http.StatusOK, response: ImagenResponse{}, results: true,
+		},
+		{
+			method: http.MethodPost, path: "/veo", operationID: "submitVideoJob", summary: "動画生成ジョブを登録する",
+			handler: veo.HandleVeo, request: VeoRequest{}, status: http.StatusAccepted, response: VeoJobResponse{},
+		},
+		{
+			method: http.MethodGet, path: "/veo/jobs/{id}", operationID: "getVideoJob", summary: "動画生成ジョブの状態を取得する",
+			handler: veo.HandleVeoJob, parameters: []openAPIParameter{jobIDParameter},
+			status: http.StatusOK, response: VeoJobResponse{}, results: true,
+		},
+		{
+			method: http.MethodDelete, path: "/veo/jobs/{id}", operationID: "cancelVideoJob", summary: "動画生成ジョブをキャンセルする",
+			handler: veo.HandleCancelVeoJob, parameters: []openAPIParameter{jobIDParameter},
+			status: http.StatusOK, response: VeoJobResponse{},
+		},
+		{
+			method: http.MethodPost, path: "/nanobanana/image-editing", operationID: "editImage", summary: "画像を編集する",
+			handler: nanobanana.HandleNanobanana, request: NanobananaRequest{}, status: http.StatusOK, response: NanobananaResponse{}, results: true,
+		},
+		{
+			method: http.MethodGet, path: "/samples", operationID: "listSampleImages", summary: "サンプル画像の一覧を取得する",
+			handler: tryOn.HandleSampleImages,
+			parameters: []openAPIParameter{{
+				Name: "category", In: "query", Required: true, Description: "人物画像（person）または衣服画像（garment）",
+				Schema: &openAPISchema{Type: "string", Enum: []string{"person", "garment"}},
+			}},
+			status: http.StatusOK, response: SampleImagesResponse{},
+		},
+		{
+			method: http.MethodGet, path: "/models", operationID: "listModels", summary: "選べるモデルの一覧を取得する",
+			handler: models.HandleListModels,
+			parameters: []openAPIParameter{{
+				Name: "generator", In: "query", Description: "生成機能で絞り込む",
+				Schema: &openAPISchema{Type: "string", Enum: []string{"tryon", "imagen", "veo", "nanobanana"}},
+			}},
+			status: http.StatusOK, response: ModelsResponse{},
+		},
+		{
+			method: http.MethodGet, path: "/usage", operationID: "getUsage", summary: "利用量と料金の見積もりを取得する",
+			handler: usage.HandleUsage,
+			parameters: []openAPIParameter{
+				{Name: "from", In: "query", Description: "集計日の開始（YYYY-MM-DD、既定は今日）", Schema: &openAPISchema{Type: "string", Format: "date"}},
+				{Name: "to", In: "query", Description: "集計日の終了（YYYY-MM-DD、既定は from と同じ日）", Schema: &openAPISchema{Type: "string", Format: "date"}},
+				{Name: "client", In: "query", Description: "クライアント（X-Client-ID）で絞り込む", Schema: &openAPISchema{Type: "string"}},
+			},
+			status: http.StatusOK, response: UsageResponse{},
+		},
+		{
+			method: http.MethodGet, path: "/openapi.json", operationID: "getOpenAPIDocument", summary: "このAPIのOpenAPIドキュメントを取得する",
+			handler: h.HandleOpenAPI, status: http.StatusOK,
+		},
+	}
+	h.document = h.buildDocument()
+
+	return h
+}
+
+// RegisterRoutes - /api/v1 のルートを登録する
+func (h *V1Handler) RegisterRoutes(r *mux.Router) {
+	v1 := r.PathPrefix(apiV1Prefix).Subrouter()
+	for _, op := range h.operations {
+		v1.HandleFunc(op.path, op.handler).Methods(op.method)
+	}
+}
+
+// HandleOpenAPI - /api/v1 のOpenAPIドキュメントを返す
+func (h *V1Handler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	h.sendJSON(w, h.document)
+}
+
+// buildDocument - ルート定義からOpenAPIドキュメントを生成する
+func (h *V1Handler) buildDocument() openAPIDocument {
+	schemas := newOpenAPISchemas()
+	errorSchema := schemas.schemaFor(reflect.TypeOf(ErrorResponse{}))
+
+	document := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "Virtual Try-On Demo API",
+			Version: "1",
+			Description: "画像はJSONの data（Base64）または url で指定する。" +
+				"生成結果は ?response=base64|url|multipart で返し方を選べる。",
+		},
+		Paths: make(map[string]openAPIPathItem),
+	}
+
+	for _, op := range h.operations {
+		operation := &openAPIOperation{
+			OperationID: op.operationID,
+			Summary:     op.summary,
+			Parameters:  op.parameters,
+			Responses: map[string]openAPIResponse{
+				"default": {
+					Description: "エラー",
+					Content:     map[string]openAPIMediaType{"application/json": {Schema: errorSchema}},
+				},
+			},
+		}
+
+		if op.request != nil {
+			operation.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content: map[string]openAPIMediaType{
+					"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(op.request))},
+				},
+			}
+		}
+
+		responseSchema := &openAPISchema{Type: "object"}
+		if op.response != nil {
+			responseSchema = schemas.schemaFor(reflect.TypeOf(op.response))
+		}
+		content := map[string]openAPIMediaType{"application/json": {Schema: responseSchema}}
+		if op.results {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name: "response", In: "query", Description: "生成結果の返し方（既定は base64）",
+				Schema: &openAPISchema{Type: "string", Enum: []string{
+					string(resultModeBase64), string(resultModeURL), string(resultModeMultipart),
+				}},
+			})
+			// 1つ目のパートにJSON、以降のパートにファイル本体
+			content["multipart/mixed"] = openAPIMediaType{Schema: &openAPISchema{Type: "string", Format: "binary"}}
+		}
+		operation.Responses[strconv.Itoa(op.status)] = openAPIResponse{
+			Description: http.StatusText(op.status),
+			Content:     content,
+		}
+
+		path := apiV1Prefix + op.path
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(openAPIPathItem)
+		}
+		document.Paths[path][strings.ToLower(op.method)] = operation
+	}
+
+	document.Components.Schemas = schemas.schemas
+	return document
+}
+
+// sendJSON - JSONレスポンスを送信
+func (h *V1Handler) sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
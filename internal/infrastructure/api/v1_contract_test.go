@@ -0,0 +1,368 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/application/services"
+	"tryon-demo/internal/application/usecases"
+	domainservices "tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/external"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+// newContractTestRouter フェイクバックエンドで /api/v1 のルートを組み立てる
+func newContractTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+
+	objectStorage, err := repositories.NewLocalObjectStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalObjectStorage() error = %v", err)
+	}
+
+	config := fake.Config{}
+	text := fake.NewTextAIService(config)
+	normalizeOptions := valueobjects.DefaultNormalizeOptions()
+	imagenDomainService := domainservices.NewImagenDomainService(fake.NewImagenAIService(config), text)
+
+	blobStore := repositories.NewMemoryBlobStore(64 << 20)
+	history := usecases.NewHistoryUseCase(repositories.NewMemoryGenerationRecordRepository(), blobStore)
+	assets := usecases.NewAssetUseCase(blobStore)
+	models := domainservices.DefaultModelCatalog()
+	parameterService := services.NewParameterService(nil, models, objectStorage, services.ValidationStrict)
+	fetcher := external.NewImageFetcher(external.WithImageFetcherPrivateNetworks())
+	usage := usecases.NewUsageUseCase(
+		repositories.NewMemoryUsageRepository(), domainservices.DefaultPriceTable(models), usecases.UsageBudget{},
+	)
+
+	tryOnUseCase := usecases.NewTryOnUseCase(
+		repositories.NewMemoryTryOnRepository(),
+		domainservices.NewTryOnDomainService(fake.NewVertexAIService(config, objectStorage), normalizeOptions),
+		domainservices.NewTryOnPreflightService(domainservices.DefaultPreflightRules(), normalizeOptions, nil),
+		nil, objectStorage, history, usage, "virtual-try-on-preview-08-04",
+	)
+	veoUseCase := usecases.NewVeoUseCase(
+		domainservices.NewVeoDomainService(fake.NewVeoAIService(config), text, normalizeOptions),
+		imagenDomainService, repositories.NewMemoryVeoJobRepository(), history, usage,
+	)
+	nanobananaUseCase := usecases.NewNanobananaUseCase(
+		domainservices.NewNanobananaDomainService(fake.NewNanobananaAIService(config), text, normalizeOptions), history, usage,
+	)
+
+	v1 := NewV1Handler(
+		NewTryOnHandler(tryOnUseCase, parameterService, assets, fetcher, "test"),
+		NewImagenHandler(usecases.NewImagenUseCase(imagenDomainService, history, usage), parameterService, models, assets, "test"),
+		NewVeoHandler(veoUseCase, models, assets, fetcher, "test"),
+		NewNanobananaHandler(nanobananaUseCase, models, assets, fetcher, "test"),
+		NewModelHandler(models),
+		NewUsageHandler(usage),
+	)
+
+	r := mux.NewRouter()
+	v1.RegisterRoutes(r)
+	return r
+}
+
+// testPNG 入力画像のチェック（短辺256px以上）を通るPNG
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 320, 480))
+	for y := range 480 {
+		for x := range 320 {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// contractChecker 実際のレスポンスを /api/v1/openapi.json のスキーマと照合する
+type contractChecker struct {
+	t       *testing.T
+	router  *mux.Router
+	spec    map[string]any
+	covered map[string]bool // 呼び出した操作（"GET /api/v1/models" の形式）
+}
+
+func (c *contractChecker) do(method, path string, body any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("json.Marshal() error = %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := httptest.NewRecorder()
+	c.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// check リクエストを送り、ステータスとレスポンスがドキュメントどおりであることを確認してJSONを返す
+func (c *contractChecker) check(method, template, path string, body any, wantStatus int) map[string]any {
+	c.t.Helper()
+
+	rec := c.do(method, path, body)
+	if rec.Code != wantStatus {
+		c.t.Fatalf("%s %s: status = %d, want %d, body = %s", method, path, rec.Code, wantStatus, rec.Body.String())
+	}
+
+	operation, ok := lookup(c.spec, "paths", template, strings.ToLower(method)).(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s is not documented", method, template)
+	}
+	c.covered[method+" "+template] = true
+
+	responses := operation["responses"].(map[string]any)
+	response, ok := responses[strconv.Itoa(rec.Code)]
+	if !ok {
+		response, ok = responses["default"]
+	}
+	if !ok {
+		c.t.Fatalf("%s %s: status %d is not documented", method, template, rec.Code)
+	}
+
+	schema, ok := lookup(response, "content", "application/json", "schema").(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s: status %d has no JSON schema", method, template, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		c.t.Fatalf("%s %s: Content-Type = %q, want application/json", method, path, contentType)
+	}
+
+	var decoded any
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		c.t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+	}
+	for _, problem := range c.validate(schema, decoded, "$") {
+		c.t.Errorf("%s %s: %s", method, path, problem)
+	}
+
+	result, _ := decoded.(map[string]any)
+	return result
+}
+
+// validate JSONの値をスキーマと照合し、食い違いを返す（このAPIのドキュメントが使う範囲のみ）
+func (c *contractChecker) validate(schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := lookup(c.spec, "components", "schemas", name).(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", at, ref)}
+		}
+		return c.validate(resolved, value, at)
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want object, got %T", at, value))
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %q", at, name))
+			}
+		}
+		for name, v := range object {
+			if property, ok := properties[name].(map[string]any); ok {
+				problems = append(problems, c.validate(property, v, at+"."+name)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %q", at, name))
+				}
+			case map[string]any:
+				problems = append(problems, c.validate(additional, v, at+"."+name)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: want array, got %T", at, value))
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range array {
+			problems = append(problems, c.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want string, got %T", at, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			problems = append(problems, fmt.Sprintf("%s: want integer, got %v", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want number, got %T", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: want boolean, got %T", at, value))
+		}
+	}
+	return problems
+}
+
+func lookup(value any, keys ...string) any {
+	for _, key := range keys {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func TestV1Contract(t *testing.T) {
+	router := newContractTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiV1Prefix+"/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/openapi.json: status = %d", rec.Code)
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+
+	c := &contractChecker{t: t, router: router, spec: spec, covered: make(map[string]bool)}
+
+	imageData := testPNG(t)
+	encoded := base64.StdEncoding.EncodeToString(imageData)
+	imageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(imageData)
+	}))
+	t.Cleanup(imageServer.Close)
+
+	c.check(http.MethodGet, "/api/v1/openapi.json", "/api/v1/openapi.json", nil, http.StatusOK)
+	c.check(http.MethodGet, "/api/v1/models", "/api/v1/models", nil, http.StatusOK)
+	c.check(http.MethodGet, "/api/v1/models", "/api/v1/models?generator=veo", nil, http.StatusOK)
+	c.check(http.MethodGet, "/api/v1/samples", "/api/v1/samples?category=garment", nil, http.StatusOK)
+	c.check(http.MethodGet, "/api/v1/samples", "/api/v1/samples?category=shoes", nil, http.StatusBadRequest)
+
+	tryOn := c.check(http.MethodPost, "/api/v1/tryon", "/api/v1/tryon", map[string]any{
+		"person_image": map[string]any{"url": imageServer.URL + "/person.png"},
+		"garment_images": []map[string]any{
+			{"data": encoded, "category": "tops"},
+			{"data": "data:image/png;base64," + encoded, "category": "bottoms"},
+		},
+		"tryon_mode":   "outfit",
+		"sample_count": 1,
+	}, http.StatusOK)
+	if intermediates, _ := tryOn["intermediates"].([]any); len(intermediates) != 2 {
+		t.Errorf("tryon: intermediates = %v, want 2 step images", tryOn["intermediates"])
+	}
+	c.check(http.MethodPost, "/api/v1/tryon", "/api/v1/tryon", map[string]any{
+		"person_image":   map[string]any{"data": encoded},
+		"garment_images": []map[string]any{{"data": encoded}},
+		"sample_count":   "two",
+	}, http.StatusBadRequest)
+
+	c.check(http.MethodPost, "/api/v1/imagen", "/api/v1/imagen?response=url", map[string]any{
+		"prompt": "a red dress", "numberOfImages": 2,
+	}, http.StatusOK)
+	c.check(http.MethodPost, "/api/v1/imagen", "/api/v1/imagen", map[string]any{
+		"prompt": "a red dress", "imagenModel": "imagen-0",
+	}, http.StatusBadRequest)
+
+	c.check(http.MethodPost, "/api/v1/nanobanana/image-editing", "/api/v1/nanobanana/image-editing", map[string]any{
+		"prompt": "make it blue", "images": []map[string]any{{"data": encoded}},
+	}, http.StatusOK)
+
+	spend := c.check(http.MethodGet, "/api/v1/usage", "/api/v1/usage", nil, http.StatusOK)
+	if totals, _ := spend["totals"].([]any); len(totals) == 0 {
+		t.Errorf("usage: totals = %v, want the generations above", spend["totals"])
+	}
+	c.check(http.MethodGet, "/api/v1/usage", "/api/v1/usage?from=2025-01-31&to=2025-01-01", nil, http.StatusBadRequest)
+
+	job := c.check(http.MethodPost, "/api/v1/veo", "/api/v1/veo", map[string]any{
+		"videoPrompt": "walk", "veoModel": "veo-2.0-generate-001", "image": map[string]any{"data": encoded},
+	}, http.StatusAccepted)
+	jobID, _ := job["jobId"].(string)
+	if want := "/api/v1/veo/jobs/" + jobID; job["statusUrl"] != want {
+		t.Errorf("veo: statusUrl = %v, want %s", job["statusUrl"], want)
+	}
+	c.check(http.MethodGet, "/api/v1/veo/jobs/{id}", "/api/v1/veo/jobs/"+jobID, nil, http.StatusOK)
+	c.check(http.MethodGet, "/api/v1/veo/jobs/{id}", "/api/v1/veo/jobs/missing", nil, http.StatusNotFound)
+	// フェイクのジョブはすぐに終わることがあり、キャンセルの成否が定まらないため 404 で確認する
+	c.check(http.MethodDelete, "/api/v1/veo/jobs/{id}", "/api/v1/veo/jobs/missing", nil, http.StatusNotFound)
+
+	// ドキュメントの操作とルートが一致し、すべて確認されていること
+	var routes []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes = append(routes, method+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	var documented []string
+	for path, item := range spec["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+	slices.Sort(routes)
+	slices.Sort(documented)
+	if !slices.Equal(routes, documented) {
+		t.Errorf("routes = %v, documented = %v", routes, documented)
+	}
+
+	for _, operation := range documented {
+		if !c.covered[operation] {
+			t.Errorf("%s is not covered by the contract test", operation)
+		}
+	}
+}
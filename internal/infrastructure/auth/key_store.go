@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// キーのIDの形式（利用量・履歴のクライアントとしてそのまま使う）
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Key APIキー1つ分の設定
+type Key struct {
+	// 利用量・履歴を集計するクライアントのID
+	ID   string
+	Name string
+	// 1分あたりのリクエスト数の上限（0は無制限）
+	RatePerMinute int
+	// 生成機能ごとの1日あたりの生成リクエスト数の上限（ない生成機能は無制限、0は使わせない）
+	DailyQuota map[entities.GeneratorType]int
+	// 全てのクライアントの生成ジョブ・履歴・利用量を参照できる（false の場合は自分のもののみ）
+	Admin bool
+}
+
+// KeyStore APIキーの一覧。キーそのものは保持せず、SHA-256のハッシュ値で照合する
+type KeyStore struct {
+	keys map[[sha256.Size]byte]Key
+	ids  map[string]bool
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		keys: make(map[[sha256.Size]byte]Key),
+		ids:  make(map[string]bool),
+	}
+}
+
+// Add キーを登録する
+func (s *KeyStore) Add(secret string, key Key) error {
+	if secret == "" {
+		return fmt.Errorf("key %q: secret is required", key.ID)
+	}
+	return s.AddHash(sha256.Sum256([]byte(secret)), key)
+}
+
+// AddHash キーのハッシュ値で登録する（設定ファイルにキーを平文で置かない場合）
+func (s *KeyStore) AddHash(hash [sha256.Size]byte, key Key) error {
+	if !keyIDPattern.MatchString(key.ID) {
+		return fmt.Errorf("invalid key id %q (letters, digits, '_', '.' and '-', up to 64 characters)", key.ID)
+	}
+	if s.ids[key.ID] {
+		return fmt.Errorf("duplicate key id %q", key.ID)
+	}
+	if _, ok := s.keys[hash]; ok {
+		return fmt.Errorf("key %q: the same secret is already registered", key.ID)
+	}
+	if key.RatePerMinute < 0 {
+		return fmt.Errorf("key %q: ratePerMinute must not be negative", key.ID)
+	}
+	for generator, quota := range key.DailyQuota {
+		if !generator.IsValid() {
+			return fmt.Errorf("key %q: unknown generator %q in dailyQuota", key.ID, generator)
+		}
+		if quota < 0 {
+			return fmt.Errorf("key %q: dailyQuota for %s must not be negative", key.ID, generator)
+		}
+	}
+
+	s.keys[hash] = key
+	s.ids[key.ID] = true
+	return nil
+}
+
+// Lookup キーに対応する設定（登録されていない場合は false）
+func (s *KeyStore) Lookup(secret string) (Key, bool) {
+	if secret == "" {
+		return Key{}, false
+	}
+	key, ok := s.keys[sha256.Sum256([]byte(secret))]
+	return key, ok
+}
+
+// Len 登録されているキーの数
+func (s *KeyStore) Len() int {
+	return len(s.keys)
+}
+
+// keyStoreFile API_KEYS_FILE で指定するJSONファイル
+type keyStoreFile struct {
+	Keys []keyStoreEntry `json:"keys"`
+}
+
+type keyStoreEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// key（平文）か keySha256（SHA-256の16進数）のどちらかを指定する
+	Key           string                         `json:"key"`
+	KeySHA256     string                         `json:"keySha256"`
+	RatePerMinute int                            `json:"ratePerMinute"`
+	DailyQuota    map[entities.GeneratorType]int `json:"dailyQuota"`
+	Admin         bool                           `json:"admin"`
+}
+
+// LoadKeyStore JSONファイルからAPIキーの一覧を読み込む
+func LoadKeyStore(path string) (*KeyStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+
+	var file keyStoreFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys %s: %w", path, err)
+	}
+
+	store := NewKeyStore()
+	for _, entry := range file.Keys {
+		key := Key{
+			ID:            entry.ID,
+			Name:          entry.Name,
+			RatePerMinute: entry.RatePerMinute,
+			DailyQuota:    entry.DailyQuota,
+			Admin:         entry.Admin,
+		}
+
+		switch {
+		case entry.Key != "" && entry.KeySHA256 != "":
+			return nil, fmt.Errorf("key %q: specify either key or keySha256, not both", entry.ID)
+		case entry.Key != "":
+			err = store.Add(entry.Key, key)
+		case entry.KeySHA256 != "":
+			var hash [sha256.Size]byte
+			decoded, decodeErr := hex.DecodeString(entry.KeySHA256)
+			if decodeErr != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("key %q: keySha256 must be a hex-encoded SHA-256 hash", entry.ID)
+			}
+			copy(hash[:], decoded)
+			err = store.AddHash(hash, key)
+		default:
+			return nil, fmt.Errorf("key %q: key or keySha256 is required", entry.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if store.Len() == 0 {
+		return nil, fmt.Errorf("API keys %s: no keys are defined", path)
+	}
+
+	return store, nil
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func writeKeysFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoadKeyStore(t *testing.T) {
+	hash := sha256.Sum256([]byte("batch-secret"))
+	path := writeKeysFile(t, `{
+		"keys": [
+			{ "id": "web", "key": "web-secret", "ratePerMinute": 30, "dailyQuota": { "veo": 5 } },
+			{ "id": "batch", "keySha256": "`+hex.EncodeToString(hash[:])+`", "admin": true }
+		]
+	}`)
+
+	store, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore() error = %v", err)
+	}
+
+	key, ok := store.Lookup("web-secret")
+	if !ok || key.ID != "web" || key.RatePerMinute != 30 || key.DailyQuota[entities.GeneratorVeo] != 5 || key.Admin {
+		t.Errorf("Lookup(web-secret) = %+v, %v", key, ok)
+	}
+	if key, ok := store.Lookup("batch-secret"); !ok || key.ID != "batch" || !key.Admin {
+		t.Errorf("Lookup(batch-secret) = %+v, %v", key, ok)
+	}
+	for _, secret := range []string{"", "web", "unknown"} {
+		if _, ok := store.Lookup(secret); ok {
+			t.Errorf("Lookup(%q) should not match", secret)
+		}
+	}
+}
+
+func TestLoadKeyStore_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no keys":           `{ "keys": [] }`,
+		"no secret":         `{ "keys": [{ "id": "web" }] }`,
+		"both secrets":      `{ "keys": [{ "id": "web", "key": "a", "keySha256": "00" }] }`,
+		"malformed hash":    `{ "keys": [{ "id": "web", "keySha256": "zz" }] }`,
+		"invalid id":        `{ "keys": [{ "id": "web key", "key": "a" }] }`,
+		"duplicate id":      `{ "keys": [{ "id": "web", "key": "a" }, { "id": "web", "key": "b" }] }`,
+		"duplicate secret":  `{ "keys": [{ "id": "web", "key": "a" }, { "id": "batch", "key": "a" }] }`,
+		"unknown generator": `{ "keys": [{ "id": "web", "key": "a", "dailyQuota": { "audio": 1 } }] }`,
+		"negative quota":    `{ "keys": [{ "id": "web", "key": "a", "dailyQuota": { "veo": -1 } }] }`,
+		"unknown field":     `{ "keys": [{ "id": "web", "key": "a", "rate": 1 }] }`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadKeyStore(writeKeysFile(t, content)); err == nil {
+				t.Error("LoadKeyStore() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+// LimitKind 上限の種類
+type LimitKind string
+
+const (
+	// 1分あたりのリクエスト数
+	LimitRate LimitKind = "rate"
+	// 生成機能ごとの1日あたりの生成リクエスト数
+	LimitDaily LimitKind = "daily"
+)
+
+// LimitError APIキーの上限に達した場合に返す
+type LimitError struct {
+	Kind LimitKind
+	// LimitDaily の場合のみ
+	Generator entities.GeneratorType
+	Max       int
+	// 次に受け付けられるまでの目安
+	After time.Duration
+}
+
+func (e *LimitError) Error() string {
+	if e.Kind == LimitDaily {
+		return fmt.Sprintf("daily %s quota of %d requests exceeded", e.Generator, e.Max)
+	}
+	return fmt.Sprintf("rate limit of %d requests per minute exceeded", e.Max)
+}
+
+// RetryAfter 再試行までの目安（Retry-After ヘッダー）
+func (e *LimitError) RetryAfter() time.Duration {
+	return e.After
+}
+
+// RateStatus 1分あたりの上限の状況（X-RateLimit-* ヘッダー）
+type RateStatus struct {
+	Limit     int
+	Remaining int
+	// 上限いっぱいまで回復するまでの時間
+	Reset time.Duration
+}
+
+// Quotas APIキーごとのリクエスト数を数える。
+// 1分あたりの上限はトークンバケット（上限の数まで連続で受け付け、1分かけて回復する）で、
+// 1日あたりの上限は日付（サーバーのローカル時刻）ごとに数える。数はプロセス内にのみ保持する。
+type Quotas struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets map[string]*tokenBucket
+	day     string
+	daily   map[dailyQuotaKey]int
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type dailyQuotaKey struct {
+	keyID     string
+	generator entities.GeneratorType
+}
+
+func NewQuotas() *Quotas {
+	return &Quotas{
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+		daily:   make(map[dailyQuotaKey]int),
+	}
+}
+
+// AllowRequest 1分あたりの上限の範囲内なら1回分を数える。上限に達していれば *LimitError を返す
+func (q *Quotas) AllowRequest(key Key) (RateStatus, error) {
+	if key.RatePerMinute <= 0 {
+		return RateStatus{}, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	limit := float64(key.RatePerMinute)
+	perSecond := limit / 60
+
+	bucket, ok := q.buckets[key.ID]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit, updated: now}
+		q.buckets[key.ID] = bucket
+	}
+	bucket.tokens = math.Min(limit, bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return RateStatus{Limit: key.RatePerMinute, Reset: secondsDuration((limit - bucket.tokens) / perSecond)},
+			&LimitError{
+				Kind:  LimitRate,
+				Max:   key.RatePerMinute,
+				After: secondsDuration((1 - bucket.tokens) / perSecond),
+			}
+	}
+	bucket.tokens--
+
+	return RateStatus{
+		Limit:     key.RatePerMinute,
+		Remaining: int(bucket.tokens),
+		Reset:     secondsDuration((limit - bucket.tokens) / perSecond),
+	}, nil
+}
+
+// AllowGeneration 生成機能の1日あたりの上限の範囲内なら1回分を数える。上限に達していれば *LimitError を返す。
+// 返した release を呼ぶと数えた1回分を取り消す（リクエストを受け付けなかった場合）。
+func (q *Quotas) AllowGeneration(key Key, generator entities.GeneratorType) (release func(), err error) {
+	max, ok := key.DailyQuota[generator]
+	if !ok {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	// 日付が変わったら数え直す
+	if day := now.Format(time.DateOnly); day != q.day {
+		q.day = day
+		clear(q.daily)
+	}
+
+	counterKey := dailyQuotaKey{keyID: key.ID, generator: generator}
+	if q.daily[counterKey] >= max {
+		year, month, day := now.Date()
+		tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+		return nil, &LimitError{Kind: LimitDaily, Generator: generator, Max: max, After: tomorrow.Sub(now)}
+	}
+	q.daily[counterKey]++
+
+	day := q.day
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			// 日付が変わった後は数え直しているので取り消さない
+			if q.day == day && q.daily[counterKey] > 0 {
+				q.daily[counterKey]--
+			}
+		})
+	}, nil
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func newTestQuotas(now *time.Time) *Quotas {
+	q := NewQuotas()
+	q.now = func() time.Time { return *now }
+	return q
+}
+
+func TestQuotas_AllowRequest(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	q := newTestQuotas(&now)
+	key := Key{ID: "alice", RatePerMinute: 3}
+
+	// 上限の数までは連続で受け付ける
+	for i := range 3 {
+		status, err := q.AllowRequest(key)
+		if err != nil {
+			t.Fatalf("request %d: AllowRequest() error = %v", i+1, err)
+		}
+		if status.Limit != 3 || status.Remaining != 2-i {
+			t.Errorf("request %d: status = %+v", i+1, status)
+		}
+	}
+
+	_, err := q.AllowRequest(key)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Kind != LimitRate {
+		t.Fatalf("AllowRequest() over the limit error = %v, want a rate limit error", err)
+	}
+	if limitErr.RetryAfter() != 20*time.Second {
+		t.Errorf("RetryAfter() = %v, want 20s", limitErr.RetryAfter())
+	}
+
+	// 1回分が回復すれば受け付ける
+	now = now.Add(20 * time.Second)
+	if _, err := q.AllowRequest(key); err != nil {
+		t.Errorf("AllowRequest() after recovery error = %v", err)
+	}
+
+	// 別のキーは数えない
+	if _, err := q.AllowRequest(Key{ID: "bob", RatePerMinute: 1}); err != nil {
+		t.Errorf("AllowRequest(bob) error = %v", err)
+	}
+	// 上限なし
+	if status, err := q.AllowRequest(Key{ID: "unlimited"}); err != nil || status.Limit != 0 {
+		t.Errorf("AllowRequest(unlimited) = %+v, %v", status, err)
+	}
+}
+
+func TestQuotas_AllowGeneration(t *testing.T) {
+	now := time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC)
+	q := newTestQuotas(&now)
+	key := Key{ID: "alice", DailyQuota: map[entities.GeneratorType]int{
+		entities.GeneratorVeo:   2,
+		entities.GeneratorTryOn: 0,
+	}}
+
+	var releases []func()
+	for i := range 2 {
+		release, err := q.AllowGeneration(key, entities.GeneratorVeo)
+		if err != nil {
+			t.Fatalf("generation %d: AllowGeneration() error = %v", i+1, err)
+		}
+		releases = append(releases, release)
+	}
+
+	_, err := q.AllowGeneration(key, entities.GeneratorVeo)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Kind != LimitDaily || limitErr.Generator != entities.GeneratorVeo {
+		t.Fatalf("AllowGeneration() over the quota error = %v, want a daily quota error", err)
+	}
+	if limitErr.RetryAfter() != time.Hour {
+		t.Errorf("RetryAfter() = %v, want 1h until midnight", limitErr.RetryAfter())
+	}
+
+	// 取り消した1回分は使える（何度呼んでも1回分のみ）
+	releases[0]()
+	releases[0]()
+	if _, err := q.AllowGeneration(key, entities.GeneratorVeo); err != nil {
+		t.Errorf("AllowGeneration() after release error = %v", err)
+	}
+	if _, err := q.AllowGeneration(key, entities.GeneratorVeo); err == nil {
+		t.Error("AllowGeneration() should fail again after using the released generation")
+	}
+
+	// 0は使わせない、上限のない生成機能は数えない
+	if _, err := q.AllowGeneration(key, entities.GeneratorTryOn); err == nil {
+		t.Error("AllowGeneration(tryon) with a zero quota should fail")
+	}
+	if _, err := q.AllowGeneration(key, entities.GeneratorImagen); err != nil {
+		t.Errorf("AllowGeneration(imagen) error = %v", err)
+	}
+
+	// 日付が変わったら数え直す。前日の分を取り消しても今日の数は減らさない
+	now = now.Add(time.Hour)
+	for i := range 2 {
+		if _, err := q.AllowGeneration(key, entities.GeneratorVeo); err != nil {
+			t.Errorf("generation %d on the next day: AllowGeneration() error = %v", i+1, err)
+		}
+	}
+	releases[1]()
+	if _, err := q.AllowGeneration(key, entities.GeneratorVeo); err == nil {
+		t.Error("releasing a previous day's generation should not free today's quota")
+	}
+}
@@ -0,0 +1,192 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	genai_std "google.golang.org/genai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+// classifyError 外部APIのエラーを種類付きのドメインエラーにする（genai・REST・gRPC に対応）
+func classifyError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *domainerrors.Error
+	if errors.As(err, &domainErr) {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	kind := errorKind(err)
+
+	// genaiのエラーはRetryInfoの待ち時間をリトライに伝える
+	var genaiErr genai_std.APIError
+	if errors.As(err, &genaiErr) {
+		if delay := retryInfoDelay(genaiErr.Details); delay > 0 {
+			err = &retryAfterError{err: err, delay: delay}
+		}
+	}
+
+	return domainerrors.Wrap(kind, err, message)
+}
+
+// retryAfterError 待ち時間の指定を保持する（retry.RetryAfterError を満たす）
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// retryInfoDelay エラー詳細の google.rpc.RetryInfo から待ち時間（例: "30s"）を読み取る
+func retryInfoDelay(details []map[string]any) time.Duration {
+	for _, detail := range details {
+		if typ, _ := detail["@type"].(string); !strings.HasSuffix(typ, "google.rpc.RetryInfo") {
+			continue
+		}
+		value, _ := detail["retryDelay"].(string)
+		if delay, err := time.ParseDuration(value); err == nil && delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// parseRetryAfterHeader Retry-Afterヘッダー（秒数またはHTTP日付）を読み取る
+func parseRetryAfterHeader(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+func errorKind(err error) domainerrors.Kind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domainerrors.KindUpstreamTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return domainerrors.KindUpstreamTimeout
+	}
+
+	var genaiErr genai_std.APIError
+	if errors.As(err, &genaiErr) {
+		return kindFromStatus(genaiErr.Code, genaiErr.Status, genaiErr.Message)
+	}
+	var vertexErr *VertexAPIError
+	if errors.As(err, &vertexErr) {
+		return kindFromStatus(vertexErr.StatusCode, vertexErr.Status, vertexErr.Message)
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		return kindFromGRPC(s.Code(), s.Message())
+	}
+
+	return domainerrors.KindInternal
+}
+
+// kindFromStatus HTTPステータスとGoogle APIのステータス名から種類を決める
+func kindFromStatus(statusCode int, statusName, message string) domainerrors.Kind {
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusName == "RESOURCE_EXHAUSTED":
+		return domainerrors.KindQuotaExhausted
+	case statusCode == http.StatusBadRequest || statusName == "INVALID_ARGUMENT" || statusName == "FAILED_PRECONDITION":
+		if isSafetyMessage(message) {
+			return domainerrors.KindSafetyBlocked
+		}
+		return domainerrors.KindInvalidInput
+	case statusCode == http.StatusNotFound || statusCode == http.StatusServiceUnavailable ||
+		statusName == "NOT_FOUND" || statusName == "UNAVAILABLE":
+		return domainerrors.KindModelUnavailable
+	case statusCode == http.StatusGatewayTimeout || statusName == "DEADLINE_EXCEEDED":
+		return domainerrors.KindUpstreamTimeout
+	default:
+		return domainerrors.KindInternal
+	}
+}
+
+func kindFromGRPC(code codes.Code, message string) domainerrors.Kind {
+	switch code {
+	case codes.ResourceExhausted:
+		return domainerrors.KindQuotaExhausted
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		if isSafetyMessage(message) {
+			return domainerrors.KindSafetyBlocked
+		}
+		return domainerrors.KindInvalidInput
+	case codes.NotFound, codes.Unavailable:
+		return domainerrors.KindModelUnavailable
+	case codes.DeadlineExceeded:
+		return domainerrors.KindUpstreamTimeout
+	default:
+		return domainerrors.KindInternal
+	}
+}
+
+// isSafetyMessage 入力が安全性フィルタに掛かったことを示すメッセージか
+func isSafetyMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, keyword := range []string{"safety", "responsible ai", "usage guidelines", "prohibited", "blocked", "sensitive"} {
+		if strings.Contains(message, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// operationErrorKind 長時間オペレーションのエラー（google.rpc.Status）の種類を決める
+func operationErrorKind(operationError map[string]any) domainerrors.Kind {
+	message, _ := operationError["message"].(string)
+	code, ok := operationError["code"].(float64)
+	if !ok {
+		return domainerrors.KindInternal
+	}
+	return kindFromGRPC(codes.Code(code), message)
+}
+
+// checkContentBlocked Geminiの応答が安全性フィルタで止められていればエラーを返す
+func checkContentBlocked(resp *genai_std.GenerateContentResponse) error {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" &&
+		resp.PromptFeedback.BlockReason != genai_std.BlockedReasonUnspecified {
+		return domainerrors.Newf(domainerrors.KindSafetyBlocked, "prompt blocked: %s", resp.PromptFeedback.BlockReason)
+	}
+
+	for _, candidate := range resp.Candidates {
+		switch candidate.FinishReason {
+		case genai_std.FinishReasonSafety,
+			genai_std.FinishReasonImageSafety,
+			genai_std.FinishReasonProhibitedContent,
+			genai_std.FinishReasonBlocklist,
+			genai_std.FinishReasonSPII:
+			return domainerrors.Newf(domainerrors.KindSafetyBlocked, "response blocked: %s", candidate.FinishReason)
+		}
+	}
+
+	return nil
+}
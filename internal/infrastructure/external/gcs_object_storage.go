@@ -0,0 +1,154 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"tryon-demo/internal/domain/repositories"
+)
+
+// GCSObjectStorageScheme Cloud Storage のURIのスキーム
+const GCSObjectStorageScheme = "gs"
+
+// GCSObjectStorage gs://bucket/object をCloud StorageのJSON APIで読み書きする
+type GCSObjectStorage struct {
+	baseURL     string
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+}
+
+// GCSObjectStorageOption 接続先・認証を上書きする
+type GCSObjectStorageOption func(*GCSObjectStorage)
+
+// WithGCSBaseURL APIのベースURL（例: http://localhost:4443）を指定する
+func WithGCSBaseURL(baseURL string) GCSObjectStorageOption {
+	return func(s *GCSObjectStorage) {
+		s.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithGCSTokenSource アクセストークンの取得元を指定する
+func WithGCSTokenSource(tokenSource oauth2.TokenSource) GCSObjectStorageOption {
+	return func(s *GCSObjectStorage) {
+		s.tokenSource = tokenSource
+	}
+}
+
+func NewGCSObjectStorage(opts ...GCSObjectStorageOption) repositories.ObjectStorage {
+	s := &GCSObjectStorage{
+		baseURL:    "https://storage.googleapis.com",
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *GCSObjectStorage) Supports(uri string) bool {
+	return strings.HasPrefix(uri, GCSObjectStorageScheme+"://")
+}
+
+func (s *GCSObjectStorage) Get(ctx context.Context, uri string) ([]byte, error) {
+	bucket, object, err := parseGCSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+		s.baseURL, url.PathEscape(bucket), url.PathEscape(object))
+	resp, err := s.do(ctx, http.MethodGet, endpoint, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("object %s: %w", uri, repositories.ErrNotFound)
+	default:
+		return nil, newVertexAPIError(resp.StatusCode, resp.Header, body)
+	}
+}
+
+func (s *GCSObjectStorage) Put(ctx context.Context, uri string, data []byte, contentType string) error {
+	bucket, object, err := parseGCSURI(uri)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		s.baseURL, url.PathEscape(bucket), url.QueryEscape(object))
+	resp, err := s.do(ctx, http.MethodPost, endpoint, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return newVertexAPIError(resp.StatusCode, resp.Header, body)
+	}
+	return nil
+}
+
+func (s *GCSObjectStorage) do(ctx context.Context, method, endpoint string, body []byte, contentType string) (*http.Response, error) {
+	tokenSource := s.tokenSource
+	if tokenSource == nil {
+		creds, err := google.FindDefaultCredentials(ctx,
+			"https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find default credentials: %w", err)
+		}
+		tokenSource = creds.TokenSource
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, classifyError(err, "failed to send storage request")
+	}
+	return resp, nil
+}
+
+// parseGCSURI gs://bucket/object をバケット名とオブジェクト名に分ける
+func parseGCSURI(uri string) (bucket, object string, err error) {
+	rest, ok := strings.CutPrefix(uri, GCSObjectStorageScheme+"://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid Cloud Storage URI: %q", uri)
+	}
+	bucket, object, _ = strings.Cut(rest, "/")
+	if bucket == "" || object == "" {
+		return "", "", fmt.Errorf("invalid Cloud Storage URI: %q", uri)
+	}
+	return bucket, object, nil
+}
@@ -2,7 +2,6 @@ package external
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"strings"
 	"tryon-demo/internal/domain/entities"
@@ -11,6 +10,32 @@ import (
 	genai_std "google.golang.org/genai"
 )
 
+// GeminiTextModel プロンプトの生成・翻訳に使うモデル
+const GeminiTextModel = "gemini-2.5-flash"
+
+// TranslationTemplateVersion 翻訳に使うプロンプトのテンプレートの版。
+// テンプレートを変えたら上げて、翻訳のキャッシュを使わないようにする
+const TranslationTemplateVersion = "1"
+
+// 翻訳に使うテンプレートの系統（TranslationFamily）
+const (
+	TranslationFamilyVeo     = "veo"
+	TranslationFamilyImage   = "image"
+	TranslationFamilyDefault = "default"
+)
+
+// TranslationFamily 生成先のモデル名から翻訳に使うテンプレートの系統を決める
+func TranslationFamily(model string) string {
+	switch {
+	case strings.Contains(model, "veo"):
+		return TranslationFamilyVeo
+	case strings.Contains(model, "image"):
+		return TranslationFamilyImage
+	default:
+		return TranslationFamilyDefault
+	}
+}
+
 type GeminiAIService struct {
 	genAIClient *genai_std.Client
 }
@@ -22,19 +47,38 @@ func NewGeminiAIService(genAIClient *genai_std.Client) repositories.TextAIServic
 }
 
 func (s *GeminiAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	parts := []*genai_std.Part{
+		genai_std.NewPartFromText(request.Prompt()),
+	}
+	// 画像がある場合はプロンプトのあとに並べる
+	for _, image := range request.Images() {
+		parts = append(parts, genai_std.NewPartFromBytes(image.Data(), image.MimeType()))
+	}
+
+	var config *genai_std.GenerateContentConfig
+	if request.ResponseMimeType() != "" {
+		config = &genai_std.GenerateContentConfig{
+			ResponseMIMEType: request.ResponseMimeType(),
+		}
+	}
 
 	resp, err := s.genAIClient.Models.GenerateContent(ctx,
-		"gemini-2.5-flash",
-		genai_std.Text(request.Prompt()),
-		nil,
+		GeminiTextModel,
+		[]*genai_std.Content{genai_std.NewContentFromParts(parts, genai_std.RoleUser)},
+		config,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return nil, classifyError(err, "failed to generate content")
+	}
+	if err := checkContentBlocked(resp); err != nil {
+		return nil, err
 	}
 
 	respText := resp.Text()
 
-	return entities.NewTextResult(respText), nil
+	result := entities.NewTextResult(respText)
+	result.SetUsage(tokenUsage(GeminiTextModel, resp))
+	return result, nil
 }
 
 func (s *GeminiAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
@@ -42,15 +86,16 @@ func (s *GeminiAIService) TranslateToEnglish(ctx context.Context, request *entit
 
 	slog.Info("TranslateToEnglish", "request", request)
 
-	// veoが含まれている場合はveoのプロンプトを生成
-	if strings.Contains(request.Model(), "veo") {
-		slog.Info("TranslateToEnglish", "use prompt template", "veo")
+	family := TranslationFamily(request.Model())
+	slog.Info("TranslateToEnglish", "use prompt template", family)
+
+	// 動画・画像の生成先にはそれぞれ向けのプロンプトを生成
+	switch family {
+	case TranslationFamilyVeo:
 		translatePrompt = buildVeoPrompt(request.Prompt(), request.Model())
-	} else if strings.Contains(request.Model(), "image") {
-		slog.Info("TranslateToEnglish", "use prompt template", "image")
+	case TranslationFamilyImage:
 		translatePrompt = buildImageGenerationPrompt(request.Prompt(), request.Model())
-	} else {
-		slog.Info("TranslateToEnglish", "use prompt template", "default")
+	default:
 		translatePrompt = "Translate the following text into English. The translation should be accurate and natural in tone.\n"
 		translatePrompt += "Target Text: '" + request.Prompt() + "'\n"
 		translatePrompt += "English Translation:"
@@ -59,19 +104,34 @@ func (s *GeminiAIService) TranslateToEnglish(ctx context.Context, request *entit
 	slog.Info("TranslateToEnglish", "translatePrompt", translatePrompt)
 
 	resp, err := s.genAIClient.Models.GenerateContent(ctx,
-		"gemini-2.5-flash",
+		GeminiTextModel,
 		genai_std.Text(translatePrompt),
 		nil,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return nil, classifyError(err, "failed to generate content")
+	}
+	if err := checkContentBlocked(resp); err != nil {
+		return nil, err
 	}
 
 	respText := resp.Text()
 
 	slog.Info("TranslateToEnglish", "after generate content", respText)
 
-	return entities.NewTextResult(respText), nil
+	result := entities.NewTextResult(respText)
+	result.SetUsage(tokenUsage(GeminiTextModel, resp))
+	return result, nil
+}
+
+// tokenUsage レスポンスの usage metadata から使ったトークン数を取り出す（思考のトークンは出力に含める）
+func tokenUsage(model string, resp *genai_std.GenerateContentResponse) entities.TokenUsage {
+	usage := entities.TokenUsage{Model: model}
+	if resp.UsageMetadata != nil {
+		usage.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+		usage.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount)
+	}
+	return usage
 }
 
 func buildVeoPrompt(inputPrompt string, model string) string {
@@ -0,0 +1,123 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrImageURLNotAllowed 取得できないURL（http/https以外、プライベートネットワークのアドレスなど）
+	ErrImageURLNotAllowed = errors.New("image URL is not allowed")
+	// ErrImageTooLarge 取得した画像が上限を超えている
+	ErrImageTooLarge = errors.New("image is too large")
+)
+
+// ImageFetcher リクエストでURLを指定された画像を取得する。
+// サーバーから内部のサービスを呼べないよう、既定ではループバック・プライベート・リンクローカルのアドレスには接続しない。
+type ImageFetcher struct {
+	httpClient   *http.Client
+	maxBytes     int64
+	allowPrivate bool
+}
+
+// ImageFetcherOption 取得の制限を上書きする
+type ImageFetcherOption func(*ImageFetcher)
+
+// WithImageFetcherMaxBytes 1枚あたりの上限（バイト）を指定する
+func WithImageFetcherMaxBytes(maxBytes int64) ImageFetcherOption {
+	return func(f *ImageFetcher) {
+		f.maxBytes = maxBytes
+	}
+}
+
+// WithImageFetcherPrivateNetworks ループバック・プライベートネットワークのアドレスへの接続を許可する（ローカルでの検証用）
+func WithImageFetcherPrivateNetworks() ImageFetcherOption {
+	return func(f *ImageFetcher) {
+		f.allowPrivate = true
+	}
+}
+
+func NewImageFetcher(opts ...ImageFetcherOption) *ImageFetcher {
+	f := &ImageFetcher{
+		maxBytes: 10 * 1024 * 1024,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !f.allowPrivate {
+		// 名前解決後の接続先で確認するため、リダイレクト先やDNSで内部を指すURLも拒否できる
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrImageURLNotAllowed, host)
+			}
+			return nil
+		}
+	}
+
+	f.httpClient = &http.Client{
+		Timeout: 30 * time.Second,
+		// プロキシ経由では接続先のアドレスを確認できないため使わない
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	return f
+}
+
+// Fetch 画像を取得する。形式の判定は呼び出し側で行う。
+func (f *ImageFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: only http and https URLs are supported", ErrImageURLNotAllowed)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrImageURLNotAllowed) {
+			return nil, fmt.Errorf("%w: %s", ErrImageURLNotAllowed, u.Host)
+		}
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrImageTooLarge, resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, f.maxBytes)
+	}
+	return data, nil
+}
+
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,65 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImageFetcher_Fetch(t *testing.T) {
+	body := bytes.Repeat([]byte{0xff}, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image":
+			w.Write(body)
+		case "/redirect":
+			http.Redirect(w, r, "/image", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("private network is rejected by default", func(t *testing.T) {
+		_, err := NewImageFetcher().Fetch(ctx, server.URL+"/image")
+		if !errors.Is(err, ErrImageURLNotAllowed) {
+			t.Errorf("Fetch() error = %v, want ErrImageURLNotAllowed", err)
+		}
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := NewImageFetcher(WithImageFetcherPrivateNetworks()).Fetch(ctx, "file:///etc/passwd")
+		if !errors.Is(err, ErrImageURLNotAllowed) {
+			t.Errorf("Fetch() error = %v, want ErrImageURLNotAllowed", err)
+		}
+	})
+
+	t.Run("follows redirects", func(t *testing.T) {
+		data, err := NewImageFetcher(WithImageFetcherPrivateNetworks()).Fetch(ctx, server.URL+"/redirect")
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if !bytes.Equal(data, body) {
+			t.Errorf("Fetch() returned %d bytes, want %d", len(data), len(body))
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		fetcher := NewImageFetcher(WithImageFetcherPrivateNetworks(), WithImageFetcherMaxBytes(99))
+		if _, err := fetcher.Fetch(ctx, server.URL+"/image"); !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("Fetch() error = %v, want ErrImageTooLarge", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := NewImageFetcher(WithImageFetcherPrivateNetworks()).Fetch(ctx, server.URL+"/missing")
+		if err == nil || errors.Is(err, ErrImageURLNotAllowed) {
+			t.Errorf("Fetch() error = %v, want status error", err)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	genai_std "google.golang.org/genai"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
 	"tryon-demo/internal/domain/valueobjects"
@@ -53,20 +54,30 @@ func (s *ImagenAIService) GenerateImage(
 		config,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate images: %w", err)
+		return nil, classifyError(err, "failed to generate images")
 	}
 
-	images := make([]*valueobjects.ImageData, len(imagenResponse.GeneratedImages))
+	images := make([]*valueobjects.ImageData, 0, len(imagenResponse.GeneratedImages))
+	var filteredReason string
 
-	for i, GeneratedImages := range imagenResponse.GeneratedImages {
-		image, err := valueobjects.NewImageData(
-			GeneratedImages.Image.ImageBytes,
-			GeneratedImages.Image.MIMEType,
-		)
+	for _, GeneratedImages := range imagenResponse.GeneratedImages {
+		// 安全性フィルタで除外された画像は理由だけが返る
+		if GeneratedImages.Image == nil || len(GeneratedImages.Image.ImageBytes) == 0 {
+			if GeneratedImages.RAIFilteredReason != "" {
+				filteredReason = GeneratedImages.RAIFilteredReason
+			}
+			continue
+		}
+
+		image, err := valueobjects.NewImageData(GeneratedImages.Image.ImageBytes)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create image data: %w", err)
 		}
-		images[i] = image
+		images = append(images, image)
+	}
+
+	if len(images) == 0 && filteredReason != "" {
+		return nil, domainerrors.Newf(domainerrors.KindSafetyBlocked, "all images were filtered: %s", filteredReason)
 	}
 
 	return entities.NewImagenResult(images), nil
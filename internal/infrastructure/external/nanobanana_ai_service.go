@@ -59,10 +59,17 @@ func (s *NanobananaAIService) ModifyImage(ctx context.Context, request *entities
 	)
 
 	if errGenerateContent != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", errGenerateContent)
+		return nil, classifyError(errGenerateContent, "failed to generate content")
+	}
+	if err := checkContentBlocked(resultGenerateContent); err != nil {
+		return nil, err
+	}
+	if len(resultGenerateContent.Candidates) == 0 || resultGenerateContent.Candidates[0].Content == nil {
+		return nil, fmt.Errorf("no candidates in response")
 	}
 
 	result := entities.NewNanobananaResult("", nil)
+	result.SetUsage(tokenUsage(request.Model(), resultGenerateContent))
 
 	// レスポンスの詳細をログ出力
 	slog.Info("Gemini API response",
@@ -79,7 +86,7 @@ func (s *NanobananaAIService) ModifyImage(ctx context.Context, request *entities
 			imageBytes := part.InlineData.Data
 			slog.Info("Processing image data", "mimeType", part.InlineData.MIMEType, "dataSize", len(imageBytes))
 
-			imageData, err := valueobjects.NewImageData(imageBytes, part.InlineData.MIMEType)
+			imageData, err := valueobjects.NewImageData(imageBytes)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create image data: %w", err)
 			}
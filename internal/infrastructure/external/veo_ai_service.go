@@ -3,27 +3,41 @@ package external
 import (
 	"context"
 	"fmt"
-	"log"
 	"log/slog"
 	"time"
 
 	genai_std "google.golang.org/genai"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
 	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/retry"
 )
 
+// 動画生成オペレーションの状態確認間隔
+const defaultVeoPollInterval = 10 * time.Second
+
 type VeoAIService struct {
-	genAIClient *genai_std.Client
+	genAIClient  *genai_std.Client
+	pollInterval time.Duration
+	// 生成の開始・状態確認・ダウンロードをそれぞれ再試行する（ゼロ値は再試行しない）。
+	// 全体をやり直すと有料の動画生成を始め直すため、呼び出しごとに再試行する
+	retryPolicy retry.Policy
 }
 
 func NewVeoAIService(genAIClient *genai_std.Client) repositories.VeoAIService {
 	return &VeoAIService{
-		genAIClient: genAIClient,
+		genAIClient:  genAIClient,
+		pollInterval: defaultVeoPollInterval,
 	}
 }
 
+// SetRetryPolicy 外部APIの呼び出しごとの再試行の方針を設定する
+func (s *VeoAIService) SetRetryPolicy(policy retry.Policy) {
+	s.retryPolicy = policy
+}
+
 func (s *VeoAIService) GenerateVideo(
 	ctx context.Context,
 	request *entities.VeoRequest,
@@ -37,30 +51,45 @@ func (s *VeoAIService) GenerateVideo(
 	}
 
 	// 動画生成
-	operation, err := s.genAIClient.Models.GenerateVideos(
-		ctx,
-		request.VeoModel(),
-		request.VideoPrompt(),
-		image,
-		// 2025/08/28時点で、対応していないらしい：　generateAudio parameter is not supported in Gemini API
-		// GenerateAudio: request.GenerateAudio(),
-		&genai_std.GenerateVideosConfig{
-			NumberOfVideos: 1,
-		},
-	)
+	var operation *genai_std.GenerateVideosOperation
+	err := s.retryPolicy.Do(ctx, "veo.generate_videos", func(ctx context.Context) error {
+		var err error
+		operation, err = s.genAIClient.Models.GenerateVideos(
+			ctx,
+			request.VeoModel(),
+			request.VideoPrompt(),
+			image,
+			// 2025/08/28時点で、対応していないらしい：　generateAudio parameter is not supported in Gemini API
+			// GenerateAudio: request.GenerateAudio(),
+			&genai_std.GenerateVideosConfig{
+				NumberOfVideos: 1,
+			},
+		)
+		if err != nil {
+			return classifyError(err, "failed to start video generation")
+		}
+		return nil
+	})
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// 動画生成が完了するまで待つ
-	for !operation.Done {
-		log.Println("Waiting for video generation to complete...")
-		time.Sleep(10 * time.Second)
-		operation, _ = s.genAIClient.Operations.GetVideosOperation(ctx, operation, nil)
+	// 動画生成が完了するまで待つ（コンテキストのキャンセル・タイムアウトで中断する）
+	operation, err = s.waitForOperation(ctx, operation)
+	if err != nil {
+		return nil, err
 	}
 
 	if operation.Error != nil {
-		return nil, fmt.Errorf("video generation failed: %v", operation.Error)
+		return nil, domainerrors.Newf(operationErrorKind(operation.Error), "video generation failed: %v", operation.Error)
+	}
+
+	// 安全性フィルタで全ての動画が除外された
+	if operation.Response == nil || len(operation.Response.GeneratedVideos) == 0 {
+		if operation.Response != nil && operation.Response.RAIMediaFilteredCount > 0 {
+			return nil, domainerrors.Newf(domainerrors.KindSafetyBlocked, "all videos were filtered: %v", operation.Response.RAIMediaFilteredReasons)
+		}
+		return nil, fmt.Errorf("no video generated")
 	}
 
 	slog.Info("operation.Metadata", "value", operation.Metadata)
@@ -73,7 +102,15 @@ func (s *VeoAIService) GenerateVideo(
 		generatedVideos[i] = video.Video
 
 		// 動画をダウンロードする: genai_std.Videoはgenai_std.DownloadURIの実装を満たす。渡すことでsetVideoBytes()を通じてダウンロードされる。
-		s.genAIClient.Files.Download(ctx, generatedVideos[i], nil)
+		err := s.retryPolicy.Do(ctx, "veo.download_video", func(ctx context.Context) error {
+			if _, err := s.genAIClient.Files.Download(ctx, generatedVideos[i], nil); err != nil {
+				return classifyError(err, "failed to download video")
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
 
 		// fname := fmt.Sprintf("veo3_with_image_input_%s.mp4", time.Now().Format("20060102150405"))
 		// _ = os.WriteFile(fname, generatedVideos[i].VideoBytes, 0644)
@@ -89,6 +126,39 @@ func (s *VeoAIService) GenerateVideo(
 	return veoResults, nil
 }
 
+// waitForOperation オペレーションが完了するまでポーリングする
+func (s *VeoAIService) waitForOperation(
+	ctx context.Context,
+	operation *genai_std.GenerateVideosOperation,
+) (*genai_std.GenerateVideosOperation, error) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for !operation.Done {
+		slog.Info("Waiting for video generation to complete...", "operation", operation.Name)
+
+		select {
+		case <-ctx.Done():
+			return nil, classifyError(ctx.Err(), "video generation aborted")
+		case <-ticker.C:
+		}
+
+		err := s.retryPolicy.Do(ctx, "veo.get_operation", func(ctx context.Context) error {
+			next, err := s.genAIClient.Operations.GetVideosOperation(ctx, operation, nil)
+			if err != nil {
+				return classifyError(err, "failed to get video operation")
+			}
+			operation = next
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return operation, nil
+}
+
 func (s *VeoAIService) Close() error {
 	if s.genAIClient != nil {
 		// GenAI Clientはリソースクリーンアップ不要
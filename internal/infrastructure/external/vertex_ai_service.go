@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/vertexai/genai"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
+	"tryon-demo/internal/domain/domainerrors"
 	"tryon-demo/internal/domain/entities"
 	"tryon-demo/internal/domain/repositories"
 	"tryon-demo/internal/domain/valueobjects"
@@ -25,20 +28,103 @@ type VertexAIService struct {
 	vtoModel       string
 	vertexAIClient *genai.Client
 	useSDK         bool
+
+	// REST API用の接続先・認証（未指定の場合は本番のエンドポイントとADCを使う）
+	baseURL     string
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+}
+
+// VertexAIOption REST API呼び出しの設定を上書きする
+type VertexAIOption func(*VertexAIService)
+
+// WithVertexAIBaseURL predictエンドポイントのベースURL（例: http://localhost:8090）を指定する
+func WithVertexAIBaseURL(baseURL string) VertexAIOption {
+	return func(s *VertexAIService) {
+		s.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithVertexAITokenSource アクセストークンの取得元を指定する
+func WithVertexAITokenSource(tokenSource oauth2.TokenSource) VertexAIOption {
+	return func(s *VertexAIService) {
+		s.tokenSource = tokenSource
+	}
+}
+
+// WithVertexAIHTTPClient REST API呼び出しに使うHTTPクライアントを指定する
+func WithVertexAIHTTPClient(httpClient *http.Client) VertexAIOption {
+	return func(s *VertexAIService) {
+		s.httpClient = httpClient
+	}
 }
 
 func NewVertexAIService(
 	projectID, location, vtoModel string,
 	useSDK bool,
 	vertexAIClient *genai.Client,
+	opts ...VertexAIOption,
 ) repositories.VertexAIService {
-	return &VertexAIService{
+	s := &VertexAIService{
 		projectID:      projectID,
 		location:       location,
 		vtoModel:       vtoModel,
 		vertexAIClient: vertexAIClient,
 		useSDK:         useSDK,
+		baseURL:        fmt.Sprintf("https://%s-aiplatform.googleapis.com", location),
+		httpClient:     &http.Client{Timeout: 300 * time.Second},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// VertexAPIError predictエンドポイントが200以外を返したときのエラー
+type VertexAPIError struct {
+	StatusCode int
+	// Status Googleのエラーステータス（例: RESOURCE_EXHAUSTED）。本文がJSONでない場合は空
+	Status  string
+	Message string
+	// RetryAfterDelay Retry-Afterヘッダー、またはエラー詳細のRetryInfoで指定された待ち時間
+	RetryAfterDelay time.Duration
+}
+
+// RetryAfter リトライまでの待ち時間（指定がなければ0）
+func (e *VertexAPIError) RetryAfter() time.Duration {
+	return e.RetryAfterDelay
+}
+
+func (e *VertexAPIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("API request failed with status %d (%s): %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// newVertexAPIError Googleのエラー形式（{"error":{"code","message","status","details"}}）を読み取る
+func newVertexAPIError(statusCode int, header http.Header, body []byte) *VertexAPIError {
+	apiErr := &VertexAPIError{
+		StatusCode:      statusCode,
+		Message:         string(body),
+		RetryAfterDelay: parseRetryAfterHeader(header.Get("Retry-After")),
+	}
+
+	var payload struct {
+		Error struct {
+			Message string           `json:"message"`
+			Status  string           `json:"status"`
+			Details []map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		apiErr.Status = payload.Error.Status
+		apiErr.Message = payload.Error.Message
+		if apiErr.RetryAfterDelay == 0 {
+			apiErr.RetryAfterDelay = retryInfoDelay(payload.Error.Details)
+		}
+	}
+	return apiErr
 }
 
 func (s *VertexAIService) GenerateTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
@@ -49,10 +135,15 @@ func (s *VertexAIService) GenerateTryOn(ctx context.Context, request *entities.T
 }
 
 func (s *VertexAIService) generateWithSDK(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
+	if request.Parameters().StorageURI() != "" {
+		return nil, domainerrors.New(domainerrors.KindInvalidInput, "storageURI is not supported with the SDK client")
+	}
+
 	model := s.vertexAIClient.GenerativeModel(s.vtoModel)
 
-	personPart := genai.ImageData("image/jpeg", request.PersonImage().Data())
-	garmentPart := genai.ImageData("image/jpeg", request.GarmentImage().Data())
+	// ImageData は "image/" を付けて MIME タイプにするため、形式名だけを渡す
+	personPart := genai.ImageData(string(request.PersonImage().Format()), request.PersonImage().Data())
+	garmentPart := genai.ImageData(string(request.GarmentImage().Format()), request.GarmentImage().Data())
 
 	prompt := []genai.Part{
 		genai.Text("person:"),
@@ -69,10 +160,13 @@ func (s *VertexAIService) generateWithSDK(ctx context.Context, request *entities
 
 	resp, err := model.GenerateContent(ctx, prompt...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return nil, classifyError(err, "failed to generate content")
 	}
 
 	if len(resp.Candidates) == 0 {
+		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockedReasonUnspecified {
+			return nil, domainerrors.Newf(domainerrors.KindSafetyBlocked, "prompt blocked: %s", resp.PromptFeedback.BlockReason)
+		}
 		return nil, fmt.Errorf("no candidates in response")
 	}
 
@@ -84,7 +178,7 @@ func (s *VertexAIService) generateWithSDK(ctx context.Context, request *entities
 	for _, part := range candidate.Content.Parts {
 		if blob, ok := part.(genai.Blob); ok {
 			if blob.MIMEType == "image/jpeg" || blob.MIMEType == "image/png" {
-				imageData, err := valueobjects.NewImageData(blob.Data, blob.MIMEType)
+				imageData, err := valueobjects.NewImageData(blob.Data)
 				if err != nil {
 					return nil, fmt.Errorf("failed to create image data: %w", err)
 				}
@@ -132,6 +226,11 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 		parameters["seed"] = params.Seed()
 	}
 
+	// Storage URIを指定した場合、画像は保存先に書き込まれ、レスポンスにはURIのみが返る
+	if prefix := request.OutputURIPrefix(); prefix != "" {
+		parameters["storageUri"] = prefix
+	}
+
 	apiRequest := map[string]interface{}{
 		"instances": []map[string]interface{}{
 			{
@@ -170,8 +269,8 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 	debugJSON, _ := json.MarshalIndent(debugRequest, "", "  ")
 	fmt.Printf("[DEBUG] API Request (without image data): %s\n", string(debugJSON))
 
-	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
-		s.location, s.projectID, s.location, s.vtoModel)
+	url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+		s.baseURL, s.projectID, s.location, s.vtoModel)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
@@ -181,10 +280,9 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 300 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, classifyError(err, "failed to send request")
 	}
 	defer resp.Body.Close()
 
@@ -194,7 +292,7 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, classifyError(newVertexAPIError(resp.StatusCode, resp.Header, respBody), "predict request failed")
 	}
 
 	predResp, err := s.parseResponse(respBody)
@@ -202,13 +300,29 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	// 安全性フィルタで除外された結果はpredictionsに含まれない
 	if len(predResp.Predictions) == 0 {
-		return nil, fmt.Errorf("no predictions in response")
+		return nil, domainerrors.New(domainerrors.KindSafetyBlocked, "no predictions in response")
+	}
+
+	if request.OutputURIPrefix() != "" {
+		var uris []string
+		for _, prediction := range predResp.Predictions {
+			if uri := prediction.OutputURI(); uri != "" {
+				uris = append(uris, uri)
+			}
+		}
+
+		if len(uris) == 0 {
+			return nil, fmt.Errorf("no output URI found in response")
+		}
+
+		return entities.NewTryOnResultWithURIs(request.ID(), uris), nil
 	}
 
 	// 通常の画像データ処理（Storage URI未指定時）
 	var images []*valueobjects.ImageData
-	for i, prediction := range predResp.Predictions {
+	for _, prediction := range predResp.Predictions {
 		imageB64 := prediction.BytesBase64Encoded
 		if imageB64 == "" {
 			continue
@@ -219,13 +333,12 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 			continue
 		}
 
-		imageData, err := valueobjects.NewImageData(imageBytes, prediction.MimeType)
+		imageData, err := valueobjects.NewImageData(imageBytes)
 		if err != nil {
 			continue
 		}
 
 		images = append(images, imageData)
-		_ = i // avoid unused variable
 	}
 
 	if len(images) == 0 {
@@ -236,13 +349,17 @@ func (s *VertexAIService) generateWithREST(ctx context.Context, request *entitie
 }
 
 func (s *VertexAIService) getAccessToken(ctx context.Context) (string, error) {
-	creds, err := google.FindDefaultCredentials(ctx,
-		"https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		return "", fmt.Errorf("failed to find default credentials: %w", err)
+	tokenSource := s.tokenSource
+	if tokenSource == nil {
+		creds, err := google.FindDefaultCredentials(ctx,
+			"https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return "", fmt.Errorf("failed to find default credentials: %w", err)
+		}
+		tokenSource = creds.TokenSource
 	}
 
-	token, err := creds.TokenSource.Token()
+	token, err := tokenSource.Token()
 	if err != nil {
 		return "", fmt.Errorf("failed to get access token: %w", err)
 	}
@@ -0,0 +1,226 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/internal/infrastructure/repositories"
+	"tryon-demo/internal/infrastructure/vertexstub"
+)
+
+const stubToken = "test-token"
+
+func newStubVertexAIService(t *testing.T, config vertexstub.Config) *VertexAIService {
+	t.Helper()
+
+	server := httptest.NewServer(vertexstub.NewServer(config).Handler())
+	t.Cleanup(server.Close)
+
+	service := NewVertexAIService("test-project", "us-central1", "virtual-try-on-preview-08-04", false, nil,
+		WithVertexAIBaseURL(server.URL),
+		WithVertexAITokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: stubToken})),
+		WithVertexAIHTTPClient(server.Client()),
+	)
+	return service.(*VertexAIService)
+}
+
+func newTestTryOnRequest(t *testing.T, sampleCount int, mimeType valueobjects.MimeType) *entities.TryOnRequest {
+	t.Helper()
+	return newTestTryOnRequestWithStorage(t, sampleCount, mimeType, "")
+}
+
+func newTestTryOnRequestWithStorage(t *testing.T, sampleCount int, mimeType valueobjects.MimeType, storageURI string) *entities.TryOnRequest {
+	t.Helper()
+
+	params, err := valueobjects.NewTryOnParameters(false, 32, valueobjects.AllowAdult, valueobjects.BlockMediumAndAbove, sampleCount, 42, storageURI, mimeType, 80)
+	if err != nil {
+		t.Fatalf("NewTryOnParameters() error = %v", err)
+	}
+
+	request, err := entities.NewTryOnRequest(newTestImage(t, 64, 96), newTestImage(t, 32, 32), params)
+	if err != nil {
+		t.Fatalf("NewTryOnRequest() error = %v", err)
+	}
+	return request
+}
+
+func newTestImage(t *testing.T, width, height int) *valueobjects.ImageData {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 2), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	data, err := valueobjects.NewImageData(buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewImageData() error = %v", err)
+	}
+	return data
+}
+
+func TestVertexAIService_GenerateWithREST(t *testing.T) {
+	tests := []struct {
+		name        string
+		sampleCount int
+		mimeType    valueobjects.MimeType
+		wantFormat  valueobjects.ImageFormat
+	}{
+		{name: "PNG 1枚", sampleCount: 1, mimeType: valueobjects.MimeTypePNG, wantFormat: valueobjects.PNG},
+		{name: "JPEG 4枚", sampleCount: 4, mimeType: valueobjects.MimeTypeJPEG, wantFormat: valueobjects.JPEG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newStubVertexAIService(t, vertexstub.Config{Token: stubToken})
+			request := newTestTryOnRequest(t, tt.sampleCount, tt.mimeType)
+
+			result, err := service.GenerateTryOn(context.Background(), request)
+			if err != nil {
+				t.Fatalf("GenerateTryOn() error = %v", err)
+			}
+
+			if result.RequestID() != request.ID() {
+				t.Errorf("RequestID() = %v, want %v", result.RequestID(), request.ID())
+			}
+			if len(result.Images()) != tt.sampleCount {
+				t.Fatalf("len(Images()) = %d, want %d", len(result.Images()), tt.sampleCount)
+			}
+			for i, img := range result.Images() {
+				if img.Format() != tt.wantFormat {
+					t.Errorf("Images()[%d].Format() = %v, want %v", i, img.Format(), tt.wantFormat)
+				}
+				if img.MimeType() != string(tt.mimeType) {
+					t.Errorf("Images()[%d].MimeType() = %v, want %v", i, img.MimeType(), tt.mimeType)
+				}
+			}
+		})
+	}
+}
+
+func TestVertexAIService_GenerateWithREST_StorageURI(t *testing.T) {
+	storage, err := repositories.NewLocalObjectStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalObjectStorage() error = %v", err)
+	}
+	service := newStubVertexAIService(t, vertexstub.Config{Token: stubToken, Storage: storage})
+	request := newTestTryOnRequestWithStorage(t, 2, valueobjects.MimeTypeJPEG, "local://outputs/tryon")
+
+	result, err := service.GenerateTryOn(context.Background(), request)
+	if err != nil {
+		t.Fatalf("GenerateTryOn() error = %v", err)
+	}
+
+	if len(result.Images()) != 0 {
+		t.Errorf("len(Images()) = %d, want 0", len(result.Images()))
+	}
+	if len(result.OutputURIs()) != 2 {
+		t.Fatalf("len(OutputURIs()) = %d, want 2", len(result.OutputURIs()))
+	}
+	for i, uri := range result.OutputURIs() {
+		if !strings.HasPrefix(uri, request.OutputURIPrefix()) {
+			t.Errorf("OutputURIs()[%d] = %q, want prefix %q", i, uri, request.OutputURIPrefix())
+		}
+
+		data, err := storage.Get(context.Background(), uri)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", uri, err)
+		}
+		img, err := valueobjects.NewImageData(data)
+		if err != nil {
+			t.Fatalf("NewImageData() error = %v", err)
+		}
+		if img.Format() != valueobjects.JPEG {
+			t.Errorf("stored image format = %v, want %v", img.Format(), valueobjects.JPEG)
+		}
+	}
+}
+
+func TestVertexAIService_GenerateWithREST_ErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     vertexstub.Config
+		wantStatus int
+		wantCode   string
+		wantKind   domainerrors.Kind
+		wantRetry  time.Duration
+	}{
+		{
+			name:       "トークン不一致",
+			config:     vertexstub.Config{Token: "another-token"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "UNAUTHENTICATED",
+			wantKind:   domainerrors.KindInternal,
+		},
+		{
+			name:       "クォータ超過",
+			config:     vertexstub.Config{Fake: fake.Config{FailEvery: 1}, FailStatus: http.StatusTooManyRequests, RetryAfter: 7},
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "RESOURCE_EXHAUSTED",
+			wantKind:   domainerrors.KindQuotaExhausted,
+			wantRetry:  7 * time.Second,
+		},
+		{
+			name:       "モデル停止中",
+			config:     vertexstub.Config{Fake: fake.Config{FailEvery: 1}, FailStatus: http.StatusServiceUnavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "UNAVAILABLE",
+			wantKind:   domainerrors.KindModelUnavailable,
+		},
+		{
+			name:       "サーバーエラー",
+			config:     vertexstub.Config{Fake: fake.Config{FailEvery: 1}},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "INTERNAL",
+			wantKind:   domainerrors.KindInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newStubVertexAIService(t, tt.config)
+			request := newTestTryOnRequest(t, 1, valueobjects.MimeTypePNG)
+
+			_, err := service.GenerateTryOn(context.Background(), request)
+
+			var apiErr *VertexAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateTryOn() error = %v, want *VertexAPIError", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.Status != tt.wantCode {
+				t.Errorf("Status = %q, want %q", apiErr.Status, tt.wantCode)
+			}
+			if kind := domainerrors.KindOf(err); kind != tt.wantKind {
+				t.Errorf("KindOf() = %v, want %v", kind, tt.wantKind)
+			}
+			if apiErr.RetryAfter() != tt.wantRetry {
+				t.Errorf("RetryAfter() = %v, want %v", apiErr.RetryAfter(), tt.wantRetry)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+// ErrorTrigger プロンプトにこの文字列を含めると、そのリクエストはエラーになる
+const ErrorTrigger = "[fake:error]"
+
+// kindTriggers プロンプトに含めると、その種類のエラーになる文字列
+var kindTriggers = map[string]domainerrors.Kind{
+	"[fake:quota]":       domainerrors.KindQuotaExhausted,
+	"[fake:safety]":      domainerrors.KindSafetyBlocked,
+	"[fake:timeout]":     domainerrors.KindUpstreamTimeout,
+	"[fake:unavailable]": domainerrors.KindModelUnavailable,
+	"[fake:invalid]":     domainerrors.KindInvalidInput,
+}
+
+// ErrInjected 設定により意図的に発生させたエラー
+var ErrInjected = errors.New("fake backend: injected error")
+
+// Config オフライン用のフェイクバックエンドの動作設定
+type Config struct {
+	// Latency 応答までの待ち時間
+	Latency time.Duration
+	// FailEvery N回に1回エラーを返す（0の場合は無効）
+	FailEvery int
+}
+
+// ConfigFromEnv 環境変数 FAKE_LATENCY（例: 2s）と FAKE_FAIL_EVERY から設定を読み込む
+func ConfigFromEnv() (Config, error) {
+	var config Config
+
+	if value := os.Getenv("FAKE_LATENCY"); value != "" {
+		latency, err := time.ParseDuration(value)
+		if err != nil || latency < 0 {
+			return Config{}, fmt.Errorf("invalid FAKE_LATENCY: %q", value)
+		}
+		config.Latency = latency
+	}
+
+	if value := os.Getenv("FAKE_FAIL_EVERY"); value != "" {
+		failEvery, err := strconv.Atoi(value)
+		if err != nil || failEvery < 0 {
+			return Config{}, fmt.Errorf("invalid FAKE_FAIL_EVERY: %q", value)
+		}
+		config.FailEvery = failEvery
+	}
+
+	return config, nil
+}
+
+// simulator 待ち時間とエラーを設定どおりに再現する。呼び出し回数はサービスごとに数える。
+type simulator struct {
+	config Config
+	calls  atomic.Int64
+}
+
+func newSimulator(config Config) *simulator {
+	return &simulator{config: config}
+}
+
+// simulate 待ち時間を経過させ、エラーにすべき呼び出しならエラーを返す
+func (s *simulator) simulate(ctx context.Context, prompt string) error {
+	if s.config.Latency > 0 {
+		timer := time.NewTimer(s.config.Latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	call := s.calls.Add(1)
+
+	for trigger, kind := range kindTriggers {
+		if strings.Contains(prompt, trigger) {
+			return domainerrors.Wrap(kind, ErrInjected, "prompt contains "+trigger)
+		}
+	}
+	if strings.Contains(prompt, ErrorTrigger) {
+		return fmt.Errorf("%w (prompt contains %s)", ErrInjected, ErrorTrigger)
+	}
+	if s.config.FailEvery > 0 && call%int64(s.config.FailEvery) == 0 {
+		return fmt.Errorf("%w (call %d)", ErrInjected, call)
+	}
+
+	return nil
+}
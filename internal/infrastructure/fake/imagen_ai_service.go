@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// ImagenAIService プロンプトを描画したプレースホルダー画像を生成する
+type ImagenAIService struct {
+	simulator *simulator
+}
+
+func NewImagenAIService(config Config) repositories.ImagenAIService {
+	return &ImagenAIService{
+		simulator: newSimulator(config),
+	}
+}
+
+func (s *ImagenAIService) GenerateImage(ctx context.Context, request *entities.ImagenRequest) (*entities.ImagenResult, error) {
+	if err := s.simulator.simulate(ctx, request.Prompt()); err != nil {
+		return nil, err
+	}
+
+	width, height := aspectRatioSize(request.AspectRatio())
+
+	count := request.NumberOfImages()
+	if count < 1 {
+		count = 1
+	}
+
+	images := make([]*valueobjects.ImageData, count)
+	for i := range images {
+		// 同じプロンプト・シードなら同じ画像になる
+		seed := fmt.Sprintf("%s|%d|%d", request.Prompt(), request.Seed(), i)
+		img := placeholder(width, height, seed, []string{
+			"FAKE IMAGEN",
+			request.ImagenModel(),
+			fmt.Sprintf("#%d seed=%d", i+1, request.Seed()),
+			"",
+			request.Prompt(),
+		})
+
+		data, err := encodeImage(img, "image/png", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		imageData, err := valueobjects.NewImageData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create image data: %w", err)
+		}
+		images[i] = imageData
+	}
+
+	return entities.NewImagenResult(images), nil
+}
+
+func (s *ImagenAIService) Close() error {
+	return nil
+}
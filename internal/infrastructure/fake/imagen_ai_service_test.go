@@ -0,0 +1,50 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func generateFakeImages(t *testing.T, prompt string, seed int64) [][]byte {
+	t.Helper()
+
+	request, err := entities.NewImagenRequestWithConfig(prompt, "", 2, "1:1", "", seed, false)
+	if err != nil {
+		t.Fatalf("NewImagenRequestWithConfig() error = %v", err)
+	}
+	result, err := NewImagenAIService(Config{}).GenerateImage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	images := make([][]byte, len(result.Images()))
+	for i, image := range result.Images() {
+		images[i] = image.Data()
+	}
+	return images
+}
+
+func TestImagenAIService_GenerateImage_Deterministic(t *testing.T) {
+	first := generateFakeImages(t, "夕焼けの海", 42)
+	if len(first) != 2 {
+		t.Fatalf("images = %d, want 2", len(first))
+	}
+
+	// 同じプロンプト・シードなら同じ画像
+	for i, data := range generateFakeImages(t, "夕焼けの海", 42) {
+		if !bytes.Equal(data, first[i]) {
+			t.Errorf("image %d differs for the same prompt and seed", i)
+		}
+	}
+
+	// 1枚ごと・シードごとに違う画像
+	if bytes.Equal(first[0], first[1]) {
+		t.Error("images in one request are identical")
+	}
+	if bytes.Equal(first[0], generateFakeImages(t, "夕焼けの海", 7)[0]) {
+		t.Error("different seeds produced the same image")
+	}
+}
@@ -0,0 +1,340 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// 小さなH.264（Baseline）のMP4を外部ライブラリなしで生成する。
+// 各フレームは全マクロブロックを I_PCM（非圧縮）で符号化したIDRピクチャ1枚で構成する。
+// 圧縮はしないが、ブラウザやffmpegでそのまま再生できる。
+
+const (
+	// 1フレームの長さ（タイムスケールはfpsの倍数にして、再生時間を丸めずに表す）
+	mp4SampleDelta = 1000
+	macroblockLen  = 16
+)
+
+// encodeMP4 フレームを指定したfpsのMP4にする。フレームの幅・高さは16の倍数であること。
+func encodeMP4(frames []*image.RGBA, fps int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("at least one frame is required")
+	}
+	if fps <= 0 {
+		return nil, fmt.Errorf("fps must be positive, got %d", fps)
+	}
+
+	bounds := frames[0].Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width%macroblockLen != 0 || height%macroblockLen != 0 || width == 0 || height == 0 {
+		return nil, fmt.Errorf("frame size must be a multiple of 16, got %dx%d", width, height)
+	}
+
+	sps := nalUnit(0x67, spsRBSP(width/macroblockLen, height/macroblockLen))
+	pps := nalUnit(0x68, ppsRBSP())
+
+	samples := make([][]byte, len(frames))
+	for i, frame := range frames {
+		if frame.Bounds().Dx() != width || frame.Bounds().Dy() != height {
+			return nil, fmt.Errorf("frame %d has a different size", i)
+		}
+		nal := nalUnit(0x65, idrSliceRBSP(frame, i))
+
+		// MP4内のNALは長さ（4バイト）を前に付ける
+		sample := make([]byte, 4+len(nal))
+		binary.BigEndian.PutUint32(sample, uint32(len(nal)))
+		copy(sample[4:], nal)
+		samples[i] = sample
+	}
+
+	timescale := mp4SampleDelta * fps
+	duration := mp4SampleDelta * len(frames)
+
+	ftyp := mp4Box("ftyp", []byte("isom"), u32(0x200), []byte("isomiso2avc1mp41"))
+
+	// mdatの位置はmoovの大きさで決まるため、まず仮のオフセットで組み立てる
+	moov := buildMoov(width, height, samples, timescale, duration, sps, pps, 0)
+	mdatOffset := len(ftyp) + len(moov) + 8
+	moov = buildMoov(width, height, samples, timescale, duration, sps, pps, mdatOffset)
+
+	mdat := mp4Box("mdat", samples...)
+
+	var buf bytes.Buffer
+	buf.Write(ftyp)
+	buf.Write(moov)
+	buf.Write(mdat)
+	return buf.Bytes(), nil
+}
+
+func buildMoov(width, height int, samples [][]byte, timescale, duration int, sps, pps []byte, mdatOffset int) []byte {
+	identity := identityMatrix()
+
+	mvhd := fullBox("mvhd", 0, 0,
+		u32(0), u32(0), u32(uint32(timescale)), u32(uint32(duration)),
+		u32(0x00010000), u16(0x0100), make([]byte, 10),
+		identity, make([]byte, 24), u32(2),
+	)
+
+	tkhd := fullBox("tkhd", 0, 0x000003,
+		u32(0), u32(0), u32(1), u32(0), u32(uint32(duration)),
+		make([]byte, 8), u16(0), u16(0), u16(0), u16(0),
+		identity, u32(uint32(width)<<16), u32(uint32(height)<<16),
+	)
+
+	mdhd := fullBox("mdhd", 0, 0,
+		u32(0), u32(0), u32(uint32(timescale)), u32(uint32(duration)),
+		u16(0x55c4), u16(0), // 言語: und
+	)
+	hdlr := fullBox("hdlr", 0, 0,
+		u32(0), []byte("vide"), make([]byte, 12), []byte("VideoHandler\x00"),
+	)
+
+	vmhd := fullBox("vmhd", 0, 1, u16(0), make([]byte, 6))
+	dinf := mp4Box("dinf", fullBox("dref", 0, 0, u32(1), fullBox("url ", 0, 1)))
+
+	avcC := mp4Box("avcC",
+		[]byte{1, sps[1], sps[2], sps[3], 0xff, 0xe1},
+		u16(uint16(len(sps))), sps,
+		[]byte{1}, u16(uint16(len(pps))), pps,
+	)
+	avc1 := mp4Box("avc1",
+		make([]byte, 6), u16(1), // data_reference_index
+		make([]byte, 16),
+		u16(uint16(width)), u16(uint16(height)),
+		u32(0x00480000), u32(0x00480000), u32(0),
+		u16(1), make([]byte, 32), u16(0x0018), u16(0xffff),
+		avcC,
+	)
+	stsd := fullBox("stsd", 0, 0, u32(1), avc1)
+
+	stts := fullBox("stts", 0, 0, u32(1), u32(uint32(len(samples))), u32(mp4SampleDelta))
+	stsc := fullBox("stsc", 0, 0, u32(1), u32(1), u32(uint32(len(samples))), u32(1))
+
+	sizes := make([][]byte, 0, len(samples)+2)
+	sizes = append(sizes, u32(0), u32(uint32(len(samples))))
+	for _, sample := range samples {
+		sizes = append(sizes, u32(uint32(len(sample))))
+	}
+	stsz := fullBox("stsz", 0, 0, sizes...)
+	stco := fullBox("stco", 0, 0, u32(1), u32(uint32(mdatOffset)))
+
+	stbl := mp4Box("stbl", stsd, stts, stsc, stsz, stco)
+	minf := mp4Box("minf", vmhd, dinf, stbl)
+	mdia := mp4Box("mdia", mdhd, hdlr, minf)
+	trak := mp4Box("trak", tkhd, mdia)
+
+	return mp4Box("moov", mvhd, trak)
+}
+
+// spsRBSP Baseline Profile / Level 3.0 のSPS
+func spsRBSP(widthInMBs, heightInMBs int) []byte {
+	w := &bitWriter{}
+	w.writeBits(66, 8)   // profile_idc: Baseline
+	w.writeBits(0xc0, 8) // constraint_set0_flag, constraint_set1_flag
+	w.writeBits(30, 8)   // level_idc
+	w.writeUE(0)         // seq_parameter_set_id
+	w.writeUE(0)         // log2_max_frame_num_minus4
+	w.writeUE(2)         // pic_order_cnt_type（表示順 = 復号順）
+	w.writeUE(1)         // max_num_ref_frames
+	w.writeBits(0, 1)    // gaps_in_frame_num_value_allowed_flag
+	w.writeUE(uint(widthInMBs - 1))
+	w.writeUE(uint(heightInMBs - 1))
+	w.writeBits(1, 1) // frame_mbs_only_flag
+	w.writeBits(1, 1) // direct_8x8_inference_flag
+	w.writeBits(0, 1) // frame_cropping_flag
+	w.writeBits(0, 1) // vui_parameters_present_flag
+	w.writeTrailingBits()
+	return w.bytes()
+}
+
+// ppsRBSP CAVLCのPPS
+func ppsRBSP() []byte {
+	w := &bitWriter{}
+	w.writeUE(0)      // pic_parameter_set_id
+	w.writeUE(0)      // seq_parameter_set_id
+	w.writeBits(0, 1) // entropy_coding_mode_flag: CAVLC
+	w.writeBits(0, 1) // bottom_field_pic_order_in_frame_present_flag
+	w.writeUE(0)      // num_slice_groups_minus1
+	w.writeUE(0)      // num_ref_idx_l0_default_active_minus1
+	w.writeUE(0)      // num_ref_idx_l1_default_active_minus1
+	w.writeBits(0, 1) // weighted_pred_flag
+	w.writeBits(0, 2) // weighted_bipred_idc
+	w.writeSE(0)      // pic_init_qp_minus26
+	w.writeSE(0)      // pic_init_qs_minus26
+	w.writeSE(0)      // chroma_qp_index_offset
+	w.writeBits(0, 1) // deblocking_filter_control_present_flag
+	w.writeBits(0, 1) // constrained_intra_pred_flag
+	w.writeBits(0, 1) // redundant_pic_cnt_present_flag
+	w.writeTrailingBits()
+	return w.bytes()
+}
+
+// idrSliceRBSP 全マクロブロックをI_PCMで符号化したIDRスライス
+func idrSliceRBSP(frame *image.RGBA, index int) []byte {
+	w := &bitWriter{}
+	w.writeUE(0)               // first_mb_in_slice
+	w.writeUE(7)               // slice_type: I
+	w.writeUE(0)               // pic_parameter_set_id
+	w.writeBits(0, 4)          // frame_num
+	w.writeUE(uint(index % 2)) // idr_pic_id（連続するIDRで異なる値にする）
+	w.writeBits(0, 1)          // no_output_of_prior_pics_flag
+	w.writeBits(0, 1)          // long_term_reference_flag
+	w.writeSE(0)               // slice_qp_delta
+
+	bounds := frame.Bounds()
+	for mbY := 0; mbY < bounds.Dy()/macroblockLen; mbY++ {
+		for mbX := 0; mbX < bounds.Dx()/macroblockLen; mbX++ {
+			w.writeUE(25) // mb_type: I_PCM
+			w.alignZero()
+			writePCMSamples(w, frame, bounds.Min.X+mbX*macroblockLen, bounds.Min.Y+mbY*macroblockLen)
+		}
+	}
+
+	w.writeTrailingBits()
+	return w.bytes()
+}
+
+// writePCMSamples 16x16の輝度と8x8の色差（4:2:0）をそのまま書き込む
+func writePCMSamples(w *bitWriter, frame *image.RGBA, x0, y0 int) {
+	var cb, cr [64]int
+
+	for y := 0; y < macroblockLen; y++ {
+		for x := 0; x < macroblockLen; x++ {
+			c := frame.RGBAAt(x0+x, y0+y)
+			luma, cbValue, crValue := color.RGBToYCbCr(c.R, c.G, c.B)
+			w.writeBits(uint64(pcmSample(luma)), 8)
+
+			i := (y/2)*8 + x/2
+			cb[i] += int(cbValue)
+			cr[i] += int(crValue)
+		}
+	}
+
+	for _, sum := range cb {
+		w.writeBits(uint64(pcmSample(uint8(sum/4))), 8)
+	}
+	for _, sum := range cr {
+		w.writeBits(uint64(pcmSample(uint8(sum/4))), 8)
+	}
+}
+
+// pcmSample 古いデコーダーのためにPCMサンプルの0を避ける
+func pcmSample(v uint8) uint8 {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// nalUnit NALヘッダーを付け、スタートコードと誤認されるバイト列にエスケープを入れる
+func nalUnit(header byte, rbsp []byte) []byte {
+	out := make([]byte, 0, len(rbsp)+len(rbsp)/64+1)
+	out = append(out, header)
+
+	zeros := 0
+	for _, b := range rbsp {
+		if zeros >= 2 && b <= 0x03 {
+			out = append(out, 0x03)
+			zeros = 0
+		}
+		out = append(out, b)
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+	return out
+}
+
+// bitWriter H.264のビット列を書き込む
+type bitWriter struct {
+	buf   []byte
+	cur   byte
+	nbits uint
+}
+
+func (w *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.cur = w.cur<<1 | byte(v>>uint(i)&1)
+		w.nbits++
+		if w.nbits == 8 {
+			w.buf = append(w.buf, w.cur)
+			w.cur, w.nbits = 0, 0
+		}
+	}
+}
+
+// writeUE 符号なし指数ゴロム符号
+func (w *bitWriter) writeUE(v uint) {
+	value := uint64(v) + 1
+	length := 0
+	for tmp := value; tmp > 1; tmp >>= 1 {
+		length++
+	}
+	w.writeBits(0, length)
+	w.writeBits(value, length+1)
+}
+
+// writeSE 符号付き指数ゴロム符号
+func (w *bitWriter) writeSE(v int) {
+	if v > 0 {
+		w.writeUE(uint(2*v - 1))
+	} else {
+		w.writeUE(uint(-2 * v))
+	}
+}
+
+func (w *bitWriter) alignZero() {
+	for w.nbits != 0 {
+		w.writeBits(0, 1)
+	}
+}
+
+func (w *bitWriter) writeTrailingBits() {
+	w.writeBits(1, 1)
+	w.alignZero()
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
+
+func mp4Box(boxType string, payloads ...[]byte) []byte {
+	size := 8
+	for _, payload := range payloads {
+		size += len(payload)
+	}
+
+	box := make([]byte, 0, size)
+	box = append(box, u32(uint32(size))...)
+	box = append(box, boxType...)
+	for _, payload := range payloads {
+		box = append(box, payload...)
+	}
+	return box
+}
+
+func fullBox(boxType string, version byte, flags uint32, payloads ...[]byte) []byte {
+	header := u32(uint32(version)<<24 | flags&0xffffff)
+	return mp4Box(boxType, append([][]byte{header}, payloads...)...)
+}
+
+func identityMatrix() []byte {
+	var matrix []byte
+	for _, v := range []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000} {
+		matrix = append(matrix, u32(v)...)
+	}
+	return matrix
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func u16(v uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, v)
+}
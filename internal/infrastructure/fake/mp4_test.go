@@ -0,0 +1,100 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"golang.org/x/image/draw"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// readBoxes 同じ階層のボックスを type と中身に分ける（大きさが合わない場合はテストを失敗させる）
+func readBoxes(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	boxes := make(map[string][]byte)
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < 8 {
+			t.Fatalf("truncated box header at %d", offset)
+		}
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		if size < 8 || offset+size > len(data) {
+			t.Fatalf("invalid box size %d at %d", size, offset)
+		}
+		boxes[string(data[offset+4:offset+8])] = data[offset+8 : offset+size]
+		offset += size
+	}
+	return boxes
+}
+
+func solidFrames(count, width, height int) []*image.RGBA {
+	frames := make([]*image.RGBA, count)
+	for i := range frames {
+		frame := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(frame, frame.Bounds(), image.NewUniform(color.RGBA{R: uint8(i * 30), G: 128, B: 200, A: 255}), image.Point{}, draw.Src)
+		frames[i] = frame
+	}
+	return frames
+}
+
+func TestEncodeMP4_Parses(t *testing.T) {
+	data, err := encodeMP4(solidFrames(6, 32, 16), 3)
+	if err != nil {
+		t.Fatalf("encodeMP4() error = %v", err)
+	}
+
+	top := readBoxes(t, data)
+	for _, boxType := range []string{"ftyp", "moov", "mdat"} {
+		if _, ok := top[boxType]; !ok {
+			t.Fatalf("missing %s box", boxType)
+		}
+	}
+	stbl := readBoxes(t, readBoxes(t, readBoxes(t, readBoxes(t, readBoxes(t, top["moov"])["trak"])["mdia"])["minf"])["stbl"])
+
+	// stsz のサンプルの大きさの合計が mdat の大きさと一致する
+	stsz := stbl["stsz"]
+	count := int(binary.BigEndian.Uint32(stsz[8:12]))
+	if count != 6 {
+		t.Fatalf("sample count = %d, want 6", count)
+	}
+	total := 0
+	for i := range count {
+		total += int(binary.BigEndian.Uint32(stsz[12+4*i:]))
+	}
+	if total != len(top["mdat"]) {
+		t.Errorf("sum of sample sizes = %d, want the mdat size %d", total, len(top["mdat"]))
+	}
+
+	// stco は mdat の中身の先頭を指す
+	offset := int(binary.BigEndian.Uint32(stbl["stco"][8:12]))
+	if !bytes.Equal(data[offset:offset+total], top["mdat"]) {
+		t.Errorf("chunk offset %d does not point at the mdat payload", offset)
+	}
+
+	// 6フレーム・3fpsで2秒
+	duration, ok := valueobjects.NewVideoData(data).Duration()
+	if !ok || duration != 2*time.Second {
+		t.Errorf("Duration() = %s, %v, want 2s", duration, ok)
+	}
+}
+
+func TestEncodeMP4_RejectsInvalidFrames(t *testing.T) {
+	if _, err := encodeMP4(nil, 4); err == nil {
+		t.Error("encodeMP4(no frames) error = nil")
+	}
+	if _, err := encodeMP4(solidFrames(1, 16, 16), 0); err == nil {
+		t.Error("encodeMP4(fps 0) error = nil")
+	}
+	if _, err := encodeMP4(solidFrames(1, 20, 16), 4); err == nil {
+		t.Error("encodeMP4(20x16) error = nil, want a multiple of 16")
+	}
+	frames := append(solidFrames(1, 16, 16), solidFrames(1, 32, 16)...)
+	if _, err := encodeMP4(frames, 4); err == nil {
+		t.Error("encodeMP4(mixed sizes) error = nil")
+	}
+}
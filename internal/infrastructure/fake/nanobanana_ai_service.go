@@ -0,0 +1,80 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// 編集結果の1枚あたりの高さ
+const nanobananaTileHeight = 512
+
+// NanobananaAIService 入力画像を横に並べ、プロンプトを書き込んだ画像を返す
+type NanobananaAIService struct {
+	simulator *simulator
+}
+
+func NewNanobananaAIService(config Config) repositories.NanobananaAIService {
+	return &NanobananaAIService{
+		simulator: newSimulator(config),
+	}
+}
+
+func (s *NanobananaAIService) ModifyImage(ctx context.Context, request *entities.NanobananaModifyRequest) (*entities.NanobananaResult, error) {
+	if len(request.ImageDatas()) == 0 {
+		return nil, fmt.Errorf("image data is required")
+	}
+	if err := s.simulator.simulate(ctx, request.Prompt()); err != nil {
+		return nil, err
+	}
+
+	inputs := make([]image.Image, len(request.ImageDatas()))
+	for i, imageData := range request.ImageDatas() {
+		img, err := decodeImage(imageData.Data())
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		inputs[i] = img
+	}
+
+	// 高さを揃えて横に並べる
+	tiles := make([]image.Rectangle, len(inputs))
+	width := 0
+	for i, img := range inputs {
+		tileWidth := img.Bounds().Dx() * nanobananaTileHeight / img.Bounds().Dy()
+		tiles[i] = image.Rect(width, 0, width+tileWidth, nanobananaTileHeight)
+		width += tileWidth
+	}
+
+	bandHeight := nanobananaTileHeight / 4
+	canvas := image.NewRGBA(image.Rect(0, 0, width, nanobananaTileHeight+bandHeight))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, img := range inputs {
+		draw.CatmullRom.Scale(canvas, tiles[i], img, img.Bounds(), draw.Over, nil)
+	}
+
+	band := image.Rect(0, nanobananaTileHeight, width, nanobananaTileHeight+bandHeight)
+	draw.Draw(canvas, band, image.NewUniform(seedColor(request.Prompt())), image.Point{}, draw.Src)
+	drawTextBlock(canvas, band, []string{"FAKE NANOBANANA", request.Prompt()})
+
+	data, err := encodeImage(canvas, "image/png", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	imageData, err := valueobjects.NewImageData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image data: %w", err)
+	}
+
+	result := entities.NewNanobananaResult("fake edit: "+request.Prompt(), imageData)
+	result.SetUsage(estimateTokenUsage(request.Model(), request.Prompt(), ""))
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package fake
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp"
+)
+
+// 文字は低解像度で描画してから拡大する（basicfontは7x13ピクセルのため）
+const textScale = 4
+
+// seedColor 文字列から決まる淡い背景色
+func seedColor(seed string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	sum := h.Sum32()
+
+	return color.RGBA{
+		R: uint8(128 + sum%128),
+		G: uint8(128 + (sum>>8)%128),
+		B: uint8(128 + (sum>>16)%128),
+		A: 255,
+	}
+}
+
+// placeholder 背景色と文字だけのプレースホルダー画像を生成する
+func placeholder(width, height int, seed string, lines []string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{seedColor(seed)}, image.Point{}, draw.Src)
+	drawTextBlock(img, img.Bounds(), lines)
+	return img
+}
+
+// drawTextBlock 指定した範囲の中央に複数行の文字を描画する
+func drawTextBlock(dst draw.Image, area image.Rectangle, lines []string) {
+	small := image.NewRGBA(image.Rect(0, 0, area.Dx()/textScale, area.Dy()/textScale))
+	if small.Rect.Empty() {
+		return
+	}
+
+	face := basicfont.Face7x13
+	maxChars := small.Rect.Dx()/face.Advance - 2
+	if maxChars < 1 {
+		return
+	}
+
+	var wrapped []string
+	for _, line := range lines {
+		wrapped = append(wrapped, wrapText(line, maxChars)...)
+	}
+
+	lineHeight := face.Height + 2
+	top := (small.Rect.Dy()-len(wrapped)*lineHeight)/2 + face.Ascent
+	drawer := &font.Drawer{
+		Dst:  small,
+		Src:  image.NewUniform(color.RGBA{R: 20, G: 20, B: 20, A: 255}),
+		Face: face,
+	}
+	for i, line := range wrapped {
+		x := (small.Rect.Dx() - len(line)*face.Advance) / 2
+		drawer.Dot = fixed.P(x, top+i*lineHeight)
+		drawer.DrawString(line)
+	}
+
+	draw.NearestNeighbor.Scale(dst, area, small, small.Bounds(), draw.Over, nil)
+}
+
+// wrapText 半角換算でmaxChars文字ごとに折り返す。basicfontにない文字は「?」にする。
+func wrapText(text string, maxChars int) []string {
+	var (
+		lines   []string
+		current strings.Builder
+	)
+	for _, r := range text {
+		if r == '\n' {
+			lines = append(lines, current.String())
+			current.Reset()
+			continue
+		}
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		current.WriteRune(r)
+		if current.Len() >= maxChars {
+			lines = append(lines, current.String())
+			current.Reset()
+		}
+	}
+	if current.Len() > 0 || len(lines) == 0 {
+		lines = append(lines, current.String())
+	}
+	return lines
+}
+
+// decodeImage 入力画像をデコードする
+func decodeImage(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// fitInside srcの縦横比を保ったままboundsに収まる矩形を返す
+func fitInside(src image.Rectangle, bounds image.Rectangle) image.Rectangle {
+	width, height := bounds.Dx(), bounds.Dy()
+	if src.Dx()*height > src.Dy()*width {
+		height = src.Dy() * width / src.Dx()
+	} else {
+		width = src.Dx() * height / src.Dy()
+	}
+
+	x := bounds.Min.X + (bounds.Dx()-width)/2
+	y := bounds.Min.Y + (bounds.Dy()-height)/2
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// encodeImage 画像をMIMEタイプに応じてエンコードする（PNG以外はJPEG）
+func encodeImage(img image.Image, mimeType string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if mimeType == "image/jpeg" {
+		if quality <= 0 {
+			quality = 90
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// aspectRatioSize Imagenのアスペクト比に対応する画像サイズ
+func aspectRatioSize(aspectRatio string) (int, int) {
+	switch aspectRatio {
+	case "16:9":
+		return 1024, 576
+	case "9:16":
+		return 576, 1024
+	case "4:3":
+		return 1024, 768
+	case "3:4":
+		return 768, 1024
+	default:
+		return 1024, 1024
+	}
+}
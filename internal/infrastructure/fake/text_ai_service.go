@@ -0,0 +1,52 @@
+package fake
+
+import (
+	"context"
+	"strings"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// textModel 本物のバックエンドがプロンプトの生成・翻訳に使うモデル（利用量の集計先）
+const textModel = "gemini-2.5-flash"
+
+// TextAIService 翻訳はせず、入力をそのまま返す
+type TextAIService struct {
+	simulator *simulator
+}
+
+func NewTextAIService(config Config) repositories.TextAIService {
+	return &TextAIService{
+		simulator: newSimulator(config),
+	}
+}
+
+func (s *TextAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	if err := s.simulator.simulate(ctx, request.Prompt()); err != nil {
+		return nil, err
+	}
+
+	result := entities.NewTextResult("fake response: " + request.Prompt())
+	result.SetUsage(estimateTokenUsage(textModel, request.Prompt(), result.Text()))
+	return result, nil
+}
+
+func (s *TextAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	if err := s.simulator.simulate(ctx, request.Prompt()); err != nil {
+		return nil, err
+	}
+
+	result := entities.NewTextResult(strings.TrimSpace(request.Prompt()))
+	result.SetUsage(estimateTokenUsage(textModel, request.Prompt(), result.Text()))
+	return result, nil
+}
+
+// estimateTokenUsage 文字数からおおよそのトークン数を見積もる（4バイトで1トークン）
+func estimateTokenUsage(model, input, output string) entities.TokenUsage {
+	return entities.TokenUsage{
+		Model:        model,
+		InputTokens:  (len(input) + 3) / 4,
+		OutputTokens: (len(output) + 3) / 4,
+	}
+}
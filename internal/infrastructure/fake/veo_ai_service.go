@@ -0,0 +1,74 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// 生成する動画の大きさ（16の倍数、約16:9）とフレーム数
+const (
+	fakeVideoWidth  = 112
+	fakeVideoHeight = 64
+	fakeVideoFrames = 8
+	fakeVideoFPS    = 4
+)
+
+// VeoAIService 入力画像の上をバーが横切る2秒の動画を生成する
+type VeoAIService struct {
+	simulator *simulator
+}
+
+func NewVeoAIService(config Config) repositories.VeoAIService {
+	return &VeoAIService{
+		simulator: newSimulator(config),
+	}
+}
+
+func (s *VeoAIService) GenerateVideo(ctx context.Context, request *entities.VeoRequest) ([]*entities.VeoResult, error) {
+	if err := s.simulator.simulate(ctx, request.VideoPrompt()); err != nil {
+		return nil, err
+	}
+
+	background := image.NewRGBA(image.Rect(0, 0, fakeVideoWidth, fakeVideoHeight))
+	draw.Draw(background, background.Bounds(), image.NewUniform(seedColor(request.VideoPrompt())), image.Point{}, draw.Src)
+
+	if request.Images() != nil {
+		img, err := decodeImage(request.Images().Data())
+		if err != nil {
+			return nil, fmt.Errorf("input image: %w", err)
+		}
+		draw.ApproxBiLinear.Scale(background, fitInside(img.Bounds(), background.Bounds()), img, img.Bounds(), draw.Over, nil)
+	}
+
+	frames := make([]*image.RGBA, fakeVideoFrames)
+	barWidth := fakeVideoWidth / fakeVideoFrames
+	for i := range frames {
+		frame := image.NewRGBA(background.Bounds())
+		draw.Draw(frame, frame.Bounds(), background, image.Point{}, draw.Src)
+
+		bar := image.Rect(i*barWidth, fakeVideoHeight-8, (i+1)*barWidth, fakeVideoHeight)
+		draw.Draw(frame, bar, image.NewUniform(color.White), image.Point{}, draw.Src)
+		frames[i] = frame
+	}
+
+	video, err := encodeMP4(frames, fakeVideoFPS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode video: %w", err)
+	}
+
+	return []*entities.VeoResult{
+		entities.NewVeoResult(valueobjects.NewVideoData(video)),
+	}, nil
+}
+
+func (s *VeoAIService) Close() error {
+	return nil
+}
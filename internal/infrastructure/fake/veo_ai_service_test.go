@@ -0,0 +1,44 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+)
+
+func generateFakeVideo(t *testing.T, prompt string) []byte {
+	t.Helper()
+
+	results, err := NewVeoAIService(Config{}).GenerateVideo(context.Background(), entities.NewVeoRequest(nil, "veo-3", prompt))
+	if err != nil {
+		t.Fatalf("GenerateVideo() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %d, want 1", len(results))
+	}
+	return results[0].Video().Data()
+}
+
+func TestVeoAIService_GenerateVideo(t *testing.T) {
+	first := generateFakeVideo(t, "夕焼けの海")
+
+	// 同じプロンプトなら同じ動画
+	if !bytes.Equal(first, generateFakeVideo(t, "夕焼けの海")) {
+		t.Error("same prompt produced different videos")
+	}
+	if bytes.Equal(first, generateFakeVideo(t, "朝焼けの山")) {
+		t.Error("different prompts produced the same video")
+	}
+
+	results, err := NewVeoAIService(Config{}).GenerateVideo(context.Background(), entities.NewVeoRequest(nil, "veo-3", "夕焼けの海"))
+	if err != nil {
+		t.Fatalf("GenerateVideo() error = %v", err)
+	}
+	duration, ok := results[0].Video().Duration()
+	if !ok || duration != 2*time.Second {
+		t.Errorf("Duration() = %s, %v, want 2s", duration, ok)
+	}
+}
@@ -0,0 +1,123 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+
+	"tryon-demo/internal/domain/domainerrors"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// VertexAIService 人物画像に衣服画像を重ねて試着結果の代わりにする
+type VertexAIService struct {
+	simulator *simulator
+	// Storage URI指定時の書き込み先（nilの場合はStorage URIを受け付けない）
+	storage repositories.ObjectStorage
+}
+
+func NewVertexAIService(config Config, storage repositories.ObjectStorage) repositories.VertexAIService {
+	return &VertexAIService{
+		simulator: newSimulator(config),
+		storage:   storage,
+	}
+}
+
+func (s *VertexAIService) GenerateTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
+	if err := s.simulator.simulate(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	person, err := decodeImage(request.PersonImage().Data())
+	if err != nil {
+		return nil, fmt.Errorf("person image: %w", err)
+	}
+	garment, err := decodeImage(request.GarmentImage().Data())
+	if err != nil {
+		return nil, fmt.Errorf("garment image: %w", err)
+	}
+
+	parameters := request.Parameters()
+	prefix := request.OutputURIPrefix()
+	if prefix != "" && (s.storage == nil || !s.storage.Supports(prefix)) {
+		return nil, domainerrors.Newf(domainerrors.KindInvalidInput, "unsupported storageURI: %s", parameters.StorageURI())
+	}
+
+	images := make([]*valueobjects.ImageData, parameters.SampleCount())
+	for i := range images {
+		composite := compositeTryOn(person, garment, i)
+		drawLabel(composite, fmt.Sprintf("FAKE TRY-ON #%d seed=%d", i+1, parameters.Seed()))
+
+		data, err := encodeImage(composite, string(parameters.OutputMimeType()), parameters.CompressionQuality())
+		if err != nil {
+			return nil, err
+		}
+
+		imageData, err := valueobjects.NewImageData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create image data: %w", err)
+		}
+		images[i] = imageData
+	}
+
+	if prefix != "" {
+		return s.store(ctx, request, prefix, images)
+	}
+
+	return entities.NewTryOnResult(request.ID(), images), nil
+}
+
+// store 生成画像を保存先に書き込み、URIのみの結果を返す
+func (s *VertexAIService) store(ctx context.Context, request *entities.TryOnRequest, prefix string, images []*valueobjects.ImageData) (*entities.TryOnResult, error) {
+	uris := make([]string, len(images))
+	for i, img := range images {
+		uri := valueobjects.StorageSampleURI(prefix, i, img.Format())
+		if err := s.storage.Put(ctx, uri, img.Data(), img.MimeType()); err != nil {
+			return nil, fmt.Errorf("failed to store image: %w", err)
+		}
+		uris[i] = uri
+	}
+
+	return entities.NewTryOnResultWithURIs(request.ID(), uris), nil
+}
+
+func (s *VertexAIService) Close() error {
+	return nil
+}
+
+// compositeTryOn 人物画像の胴体付近に衣服画像を半透明で重ねる。サンプルごとに位置を少しずらす。
+func compositeTryOn(person, garment image.Image, sample int) *image.RGBA {
+	bounds := person.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), person, bounds.Min, draw.Src)
+
+	width, height := canvas.Rect.Dx(), canvas.Rect.Dy()
+	offset := (sample - 1) * width / 20
+	area := image.Rect(
+		width/4+offset, height/4,
+		width*3/4+offset, height*3/4,
+	)
+	area = fitInside(garment.Bounds(), area)
+
+	mask := image.NewUniform(color.Alpha{A: 200})
+	draw.CatmullRom.Scale(canvas, area, garment, garment.Bounds(), draw.Over, &draw.Options{
+		SrcMask: mask,
+	})
+
+	return canvas
+}
+
+// drawLabel 画像の下部に帯状のラベルを描画する
+func drawLabel(img *image.RGBA, label string) {
+	bounds := img.Bounds()
+	bandHeight := bounds.Dy() / 10
+	band := image.Rect(bounds.Min.X, bounds.Max.Y-bandHeight, bounds.Max.X, bounds.Max.Y)
+
+	draw.Draw(img, band, image.NewUniform(color.NRGBA{R: 255, G: 255, B: 255, A: 200}), image.Point{}, draw.Over)
+	drawTextBlock(img, band, []string{label})
+}
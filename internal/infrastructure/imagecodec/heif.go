@@ -0,0 +1,71 @@
+package imagecodec
+
+import (
+	"bytes"
+	"image"
+	"io"
+
+	"github.com/gen2brain/avif"
+	"github.com/gen2brain/heic"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// HEIC/HEIF・AVIFのデコーダー。gen2brain/heic・gen2brain/avif のWebAssembly版を使うため cgo は不要。
+
+// NewHEIFDecoders valueobjects.SetHEIFDecoders に渡すデコーダー
+func NewHEIFDecoders() valueobjects.HEIFDecoders {
+	return valueobjects.HEIFDecoders{
+		HEIC: heicDecoder{},
+		AVIF: avifDecoder{},
+	}
+}
+
+type heicDecoder struct{}
+
+func (heicDecoder) DecodeConfig(r io.Reader) (image.Config, error) {
+	data, err := readHEIC(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return heic.DecodeConfig(bytes.NewReader(data))
+}
+
+func (heicDecoder) Decode(r io.Reader) (image.Image, error) {
+	data, err := readHEIC(r)
+	if err != nil {
+		return nil, err
+	}
+	return heic.Decode(bytes.NewReader(data))
+}
+
+// readHEIC メジャーブランドを heic にして読み込む。
+// デコーダーはメジャーブランドしか確認しないため、mif1 などで始まるファイル（一部のAndroid端末）も読めるようにする。
+func readHEIC(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 {
+		return data, nil
+	}
+
+	major := string(data[8:12])
+	if major == "heic" || major == "heix" {
+		return data, nil
+	}
+
+	patched := bytes.Clone(data)
+	copy(patched[8:12], "heic")
+	return patched, nil
+}
+
+type avifDecoder struct{}
+
+func (avifDecoder) DecodeConfig(r io.Reader) (image.Config, error) {
+	return avif.DecodeConfig(r)
+}
+
+func (avifDecoder) Decode(r io.Reader) (image.Image, error) {
+	return avif.Decode(r)
+}
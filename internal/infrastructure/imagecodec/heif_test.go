@@ -0,0 +1,90 @@
+package imagecodec
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestHEIFDecoders_DecodeConfigMatchesDecode(t *testing.T) {
+	decoders := NewHEIFDecoders()
+
+	// メジャーブランドが mif1 で、互換ブランドに heic を持つHEIC
+	mif1HEIC := readFixture(t, "sample.heic")
+	copy(mif1HEIC[8:12], "mif1")
+
+	tests := []struct {
+		name    string
+		decoder valueobjects.ImageDecoder
+		data    []byte
+	}{
+		{name: "HEIC", decoder: decoders.HEIC, data: readFixture(t, "sample.heic")},
+		{name: "HEIC with mif1 major brand", decoder: decoders.HEIC, data: mif1HEIC},
+		{name: "AVIF", decoder: decoders.AVIF, data: readFixture(t, "sample.avif")},
+		{name: "AVIF with transparency", decoder: decoders.AVIF, data: readFixture(t, "transparent.avif")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tt.decoder.DecodeConfig(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			img, err := tt.decoder.Decode(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if bounds := img.Bounds(); config.Width == 0 || bounds.Dx() != config.Width || bounds.Dy() != config.Height {
+				t.Errorf("DecodeConfig() = %dx%d, Decode() = %dx%d", config.Width, config.Height, bounds.Dx(), bounds.Dy())
+			}
+		})
+	}
+}
+
+func TestHEIFDecoders_NewImageData(t *testing.T) {
+	valueobjects.SetHEIFDecoders(NewHEIFDecoders())
+	t.Cleanup(func() { valueobjects.SetHEIFDecoders(valueobjects.HEIFDecoders{}) })
+
+	tests := []struct {
+		name       string
+		fixture    string
+		wantFormat valueobjects.ImageFormat
+	}{
+		{name: "HEIC", fixture: "sample.heic", wantFormat: valueobjects.JPEG},
+		{name: "AVIF", fixture: "sample.avif", wantFormat: valueobjects.JPEG},
+		{name: "AVIF with transparency", fixture: "transparent.avif", wantFormat: valueobjects.PNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageData, err := valueobjects.NewImageData(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("NewImageData() error = %v", err)
+			}
+			if imageData.Format() != tt.wantFormat {
+				t.Errorf("Format() = %s, want %s", imageData.Format(), tt.wantFormat)
+			}
+
+			normalized, err := imageData.Normalize(valueobjects.DefaultNormalizeOptions())
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if !normalized.IsJPEG() {
+				t.Errorf("normalized image should be JPEG")
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package limiter
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+// queueRejections モデルごとの順番待ち満杯による拒否回数（/debug/vars の limiter_rejections で確認できる）
+var queueRejections = expvar.NewMap("limiter_rejections")
+
+// 処理時間の移動平均の重み（直近の1件をどれだけ反映するか）
+const ewmaWeight = 0.2
+
+// Config モデルごとの同時実行数と順番待ちの上限
+type Config struct {
+	// Concurrency モデルごとの同時実行数（ModelConcurrency にないモデル）
+	Concurrency int
+	// QueueSize モデルごとに順番待ちできるリクエスト数。超えた分は拒否する
+	QueueSize int
+	// ModelConcurrency モデル名ごとの同時実行数
+	ModelConcurrency map[string]int
+	// InitialEstimate 処理時間の実績がないときに待ち時間の見積もりに使う時間
+	InitialEstimate time.Duration
+}
+
+// DefaultConfig モデルごとに同時2件、順番待ち20件まで
+func DefaultConfig() Config {
+	return Config{
+		Concurrency:     2,
+		QueueSize:       20,
+		InitialEstimate: 15 * time.Second,
+	}
+}
+
+// ConfigFromEnv 環境変数 LIMIT_CONCURRENCY / LIMIT_QUEUE_SIZE / LIMIT_MODEL_CONCURRENCY から読み込む。
+// LIMIT_MODEL_CONCURRENCY は "veo-3.0-generate-001=1,imagen-4.0-generate-001=4" の形式。
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if value := os.Getenv("LIMIT_CONCURRENCY"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency < 1 {
+			return Config{}, fmt.Errorf("invalid LIMIT_CONCURRENCY: %q", value)
+		}
+		config.Concurrency = concurrency
+	}
+
+	if value := os.Getenv("LIMIT_QUEUE_SIZE"); value != "" {
+		queueSize, err := strconv.Atoi(value)
+		if err != nil || queueSize < 0 {
+			return Config{}, fmt.Errorf("invalid LIMIT_QUEUE_SIZE: %q", value)
+		}
+		config.QueueSize = queueSize
+	}
+
+	if value := os.Getenv("LIMIT_MODEL_CONCURRENCY"); value != "" {
+		modelConcurrency, err := parseModelConcurrency(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid LIMIT_MODEL_CONCURRENCY: %w", err)
+		}
+		config.ModelConcurrency = modelConcurrency
+	}
+
+	return config, nil
+}
+
+func parseModelConcurrency(value string) (map[string]int, error) {
+	modelConcurrency := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		model, limit, ok := strings.Cut(entry, "=")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			return nil, fmt.Errorf("expected model=n, got %q", entry)
+		}
+		concurrency, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("invalid concurrency for %s: %q", model, limit)
+		}
+		modelConcurrency[model] = concurrency
+	}
+	return modelConcurrency, nil
+}
+
+// QueueFullError 順番待ちが上限に達したため受け付けなかった
+type QueueFullError struct {
+	Model string
+	// EstimatedWait 今の順番待ちが捌けるまでの見積もり
+	EstimatedWait time.Duration
+}
+
+func (e *QueueFullError) Error() string {
+	return fmt.Sprintf("request queue for %s is full (estimated wait %s)", e.Model, e.EstimatedWait)
+}
+
+// Unwrap 種類は server_busy
+func (e *QueueFullError) Unwrap() error {
+	return domainerrors.ErrServerBusy
+}
+
+// RetryAfter 再試行までの目安（Retry-After ヘッダーに使う）
+func (e *QueueFullError) RetryAfter() time.Duration {
+	return e.EstimatedWait
+}
+
+// QueueStats モデルごとの混雑状況
+type QueueStats struct {
+	Model       string `json:"model"`
+	Concurrency int    `json:"concurrency"`
+	Running     int    `json:"running"`
+	Waiting     int    `json:"waiting"`
+	QueueSize   int    `json:"queueSize"`
+	// AverageSeconds 1件あたりの処理時間の移動平均
+	AverageSeconds float64 `json:"averageSeconds"`
+	// EstimatedWaitSeconds 今から並んだ場合の待ち時間の見積もり
+	EstimatedWaitSeconds float64 `json:"estimatedWaitSeconds"`
+}
+
+// TicketState チケットの状態
+type TicketState string
+
+const (
+	TicketWaiting TicketState = "waiting"
+	TicketRunning TicketState = "running"
+)
+
+// TicketStatus チケット（X-Queue-Ticket）ごとの順番待ちの状況
+type TicketStatus struct {
+	Ticket string      `json:"ticket"`
+	State  TicketState `json:"state"`
+	Model  string      `json:"model"`
+	// Position 順番待ちの何番目か（1始まり、実行中は0）
+	Position             int     `json:"position"`
+	EstimatedWaitSeconds float64 `json:"estimatedWaitSeconds"`
+}
+
+// Limiter モデルごとのセマフォとFIFOの順番待ち
+type Limiter struct {
+	config Config
+
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+func New(config Config) *Limiter {
+	return &Limiter{
+		config: config,
+		queues: make(map[string]*queue),
+	}
+}
+
+// Acquire モデルの実行枠を取得する。空きがなければ順番が来るまで待つ。
+// 順番待ちが上限に達している場合は *QueueFullError を返す。
+// 取得できた場合は、処理が終わったら必ず release を呼ぶ。
+func (l *Limiter) Acquire(ctx context.Context, model string) (release func(), err error) {
+	return l.queue(model).acquire(ctx, TicketFromContext(ctx))
+}
+
+// Do モデルの実行枠を取得してから fn を実行する
+func (l *Limiter) Do(ctx context.Context, model string, fn func(ctx context.Context) error) error {
+	release, err := l.Acquire(ctx, model)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn(ctx)
+}
+
+// Stats 使用されたことのあるモデルの混雑状況（モデル名順）
+func (l *Limiter) Stats() []QueueStats {
+	l.mu.Lock()
+	queues := make([]*queue, 0, len(l.queues))
+	for _, q := range l.queues {
+		queues = append(queues, q)
+	}
+	l.mu.Unlock()
+
+	stats := make([]QueueStats, 0, len(queues))
+	for _, q := range queues {
+		stats = append(stats, q.stats())
+	}
+	slices.SortFunc(stats, func(a, b QueueStats) int {
+		return strings.Compare(a.Model, b.Model)
+	})
+	return stats
+}
+
+// TicketStatus チケットの状況。見つからない場合は false を返す
+func (l *Limiter) TicketStatus(ticket string) (TicketStatus, bool) {
+	if ticket == "" {
+		return TicketStatus{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		found  TicketStatus
+		exists bool
+	)
+	for _, q := range l.queues {
+		status, ok := q.ticketStatus(ticket)
+		if !ok {
+			continue
+		}
+		// 1つのチケットで複数のリクエストがある場合（複数の衣服など）は、最も待たされるものを返す
+		if !exists || status.Position > found.Position {
+			found, exists = status, true
+		}
+	}
+	return found, exists
+}
+
+func (l *Limiter) queue(model string) *queue {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	q, ok := l.queues[model]
+	if !ok {
+		concurrency := l.config.Concurrency
+		if n, ok := l.config.ModelConcurrency[model]; ok {
+			concurrency = n
+		}
+		q = &queue{
+			model:       model,
+			concurrency: max(concurrency, 1),
+			maxWaiting:  l.config.QueueSize,
+			average:     l.config.InitialEstimate,
+			running:     make(map[string]int),
+		}
+		l.queues[model] = q
+	}
+	return q
+}
+
+// waiter 順番待ち中のリクエスト
+type waiter struct {
+	ticket string
+	ready  chan struct{}
+}
+
+// queue 1モデル分の実行枠と順番待ち
+type queue struct {
+	model       string
+	concurrency int
+	maxWaiting  int
+
+	mu      sync.Mutex
+	active  int
+	waiters []*waiter
+	// running 実行中のリクエスト数（チケットごと）
+	running map[string]int
+	// average 処理時間の移動平均
+	average time.Duration
+}
+
+func (q *queue) acquire(ctx context.Context, ticket string) (func(), error) {
+	q.mu.Lock()
+	if q.active < q.concurrency && len(q.waiters) == 0 {
+		q.active++
+		q.running[ticket]++
+		q.mu.Unlock()
+		return q.releaser(ticket), nil
+	}
+
+	if len(q.waiters) >= q.maxWaiting {
+		wait := q.estimate(len(q.waiters) + 1)
+		q.mu.Unlock()
+		queueRejections.Add(q.model, 1)
+		slog.Warn("Request queue is full", "model", q.model, "waiting", q.maxWaiting, "estimatedWait", wait)
+		return nil, &QueueFullError{Model: q.model, EstimatedWait: wait}
+	}
+
+	w := &waiter{ticket: ticket, ready: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	position := len(q.waiters)
+	q.mu.Unlock()
+
+	slog.Info("Request queued", "model", q.model, "position", position, "ticket", ticket)
+
+	select {
+	case <-w.ready:
+		return q.releaser(ticket), nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if i := slices.Index(q.waiters, w); i >= 0 {
+			q.waiters = slices.Delete(q.waiters, i, i+1)
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		q.mu.Unlock()
+
+		// 中断と同時に順番が回ってきた場合は、枠を次に譲る
+		q.releaser(ticket)()
+		return nil, ctx.Err()
+	}
+}
+
+// releaser 実行枠を返す関数。処理時間を移動平均に反映し、先頭の順番待ちに枠を引き継ぐ
+func (q *queue) releaser(ticket string) func() {
+	start := time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			elapsed := time.Since(start)
+
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			q.average = time.Duration(float64(q.average)*(1-ewmaWeight) + float64(elapsed)*ewmaWeight)
+
+			if q.running[ticket]--; q.running[ticket] <= 0 {
+				delete(q.running, ticket)
+			}
+
+			if len(q.waiters) == 0 {
+				q.active--
+				return
+			}
+
+			next := q.waiters[0]
+			q.waiters = q.waiters[1:]
+			q.running[next.ticket]++
+			close(next.ready)
+		})
+	}
+}
+
+// estimate 順番待ちのposition番目（1始まり）が実行されるまでの見積もり。呼び出し側でロックする
+func (q *queue) estimate(position int) time.Duration {
+	if position <= 0 {
+		return 0
+	}
+	rounds := (position + q.concurrency - 1) / q.concurrency
+	return time.Duration(rounds) * q.average
+}
+
+func (q *queue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var wait time.Duration
+	if q.active >= q.concurrency {
+		wait = q.estimate(len(q.waiters) + 1)
+	}
+
+	return QueueStats{
+		Model:                q.model,
+		Concurrency:          q.concurrency,
+		Running:              q.active,
+		Waiting:              len(q.waiters),
+		QueueSize:            q.maxWaiting,
+		AverageSeconds:       q.average.Seconds(),
+		EstimatedWaitSeconds: wait.Seconds(),
+	}
+}
+
+func (q *queue) ticketStatus(ticket string) (TicketStatus, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, w := range q.waiters {
+		if w.ticket == ticket {
+			return TicketStatus{
+				Ticket:               ticket,
+				State:                TicketWaiting,
+				Model:                q.model,
+				Position:             i + 1,
+				EstimatedWaitSeconds: q.estimate(i + 1).Seconds(),
+			}, true
+		}
+	}
+
+	if q.running[ticket] > 0 {
+		return TicketStatus{Ticket: ticket, State: TicketRunning, Model: q.model}, true
+	}
+
+	return TicketStatus{}, false
+}
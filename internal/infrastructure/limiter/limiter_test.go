@@ -0,0 +1,197 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+func newTestLimiter(concurrency, queueSize int) *Limiter {
+	return New(Config{
+		Concurrency:     concurrency,
+		QueueSize:       queueSize,
+		InitialEstimate: 10 * time.Second,
+	})
+}
+
+// waitForWaiting 順番待ちの数がwantになるまで待つ
+func waitForWaiting(t *testing.T, l *Limiter, model string, want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, stats := range l.Stats() {
+			if stats.Model == model && stats.Waiting == want {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waiting count for %s did not become %d: %+v", model, want, l.Stats())
+}
+
+func TestLimiter_GrantsInFIFOOrder(t *testing.T) {
+	l := newTestLimiter(1, 10)
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, "model")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			release, err := l.Acquire(ctx, "model")
+			if err != nil {
+				t.Errorf("Acquire(%d) error = %v", i, err)
+				return
+			}
+			order <- i
+			release()
+		}()
+		waitForWaiting(t, l, "model", i)
+	}
+
+	release()
+
+	for want := 1; want <= 3; want++ {
+		if got := <-order; got != want {
+			t.Errorf("granted %d, want %d", got, want)
+		}
+	}
+}
+
+func TestLimiter_RejectsWhenQueueIsFull(t *testing.T) {
+	l := newTestLimiter(1, 1)
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, "model")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer release()
+
+	go l.Acquire(ctx, "model")
+	waitForWaiting(t, l, "model", 1)
+
+	_, err = l.Acquire(ctx, "model")
+
+	var queueFull *QueueFullError
+	if !errors.As(err, &queueFull) {
+		t.Fatalf("Acquire() error = %v, want *QueueFullError", err)
+	}
+	if kind := domainerrors.KindOf(err); kind != domainerrors.KindServerBusy {
+		t.Errorf("KindOf() = %s, want %s", kind, domainerrors.KindServerBusy)
+	}
+	// 2番目に並ぶことになるので、1件ずつなら2件分
+	if queueFull.RetryAfter() != 20*time.Second {
+		t.Errorf("RetryAfter() = %v, want 20s", queueFull.RetryAfter())
+	}
+
+	// 他のモデルは影響を受けない
+	otherRelease, err := l.Acquire(ctx, "other")
+	if err != nil {
+		t.Fatalf("Acquire(other) error = %v", err)
+	}
+	otherRelease()
+}
+
+func TestLimiter_CanceledWaiterLeavesQueue(t *testing.T) {
+	l := newTestLimiter(1, 10)
+
+	release, err := l.Acquire(context.Background(), "model")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := l.Acquire(ctx, "model")
+		done <- err
+	}()
+	waitForWaiting(t, l, "model", 1)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Acquire() error = %v, want context.Canceled", err)
+	}
+	waitForWaiting(t, l, "model", 0)
+
+	// キャンセルしたリクエストに枠が渡らず、空きに戻る
+	release()
+	next, err := l.Acquire(context.Background(), "model")
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	next()
+
+	if stats := l.Stats()[0]; stats.Running != 0 || stats.Waiting != 0 {
+		t.Errorf("stats = %+v, want no running or waiting requests", stats)
+	}
+}
+
+func TestLimiter_TicketStatus(t *testing.T) {
+	l := newTestLimiter(1, 10)
+
+	release, err := l.Acquire(WithTicket(context.Background(), "running-ticket"), "model")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	go func() {
+		release, err := l.Acquire(WithTicket(context.Background(), "waiting-ticket"), "model")
+		if err == nil {
+			release()
+		}
+	}()
+	waitForWaiting(t, l, "model", 1)
+
+	status, ok := l.TicketStatus("waiting-ticket")
+	if !ok {
+		t.Fatal("TicketStatus(waiting-ticket) not found")
+	}
+	if status.State != TicketWaiting || status.Position != 1 || status.Model != "model" {
+		t.Errorf("TicketStatus(waiting-ticket) = %+v", status)
+	}
+	if status.EstimatedWaitSeconds != 10 {
+		t.Errorf("EstimatedWaitSeconds = %v, want 10", status.EstimatedWaitSeconds)
+	}
+
+	status, ok = l.TicketStatus("running-ticket")
+	if !ok || status.State != TicketRunning {
+		t.Errorf("TicketStatus(running-ticket) = %+v, %v", status, ok)
+	}
+
+	if _, ok := l.TicketStatus("unknown"); ok {
+		t.Error("TicketStatus(unknown) found")
+	}
+
+	release()
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LIMIT_CONCURRENCY", "3")
+	t.Setenv("LIMIT_QUEUE_SIZE", "0")
+	t.Setenv("LIMIT_MODEL_CONCURRENCY", "veo-3.0-generate-001=1, imagen-4.0-generate-001=4")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() error = %v", err)
+	}
+	if config.Concurrency != 3 || config.QueueSize != 0 {
+		t.Errorf("config = %+v", config)
+	}
+	if config.ModelConcurrency["veo-3.0-generate-001"] != 1 || config.ModelConcurrency["imagen-4.0-generate-001"] != 4 {
+		t.Errorf("ModelConcurrency = %v", config.ModelConcurrency)
+	}
+
+	t.Setenv("LIMIT_MODEL_CONCURRENCY", "veo-3.0-generate-001")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("ConfigFromEnv() with invalid LIMIT_MODEL_CONCURRENCY succeeded")
+	}
+}
@@ -0,0 +1,119 @@
+package limiter
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// 外部サービスの呼び出しをモデルごとの同時実行数に制限するデコレーター
+
+type vertexAIService struct {
+	repositories.VertexAIService
+	limiter *Limiter
+	model   string
+}
+
+// NewVertexAIService modelはバーチャル試着のモデル名（リクエストでは選べない）
+func NewVertexAIService(service repositories.VertexAIService, limiter *Limiter, model string) repositories.VertexAIService {
+	return &vertexAIService{VertexAIService: service, limiter: limiter, model: model}
+}
+
+func (s *vertexAIService) GenerateTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
+	var result *entities.TryOnResult
+	err := s.limiter.Do(ctx, s.model, func(ctx context.Context) error {
+		var err error
+		result, err = s.VertexAIService.GenerateTryOn(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type imagenAIService struct {
+	repositories.ImagenAIService
+	limiter *Limiter
+}
+
+func NewImagenAIService(service repositories.ImagenAIService, limiter *Limiter) repositories.ImagenAIService {
+	return &imagenAIService{ImagenAIService: service, limiter: limiter}
+}
+
+func (s *imagenAIService) GenerateImage(ctx context.Context, request *entities.ImagenRequest) (*entities.ImagenResult, error) {
+	var result *entities.ImagenResult
+	err := s.limiter.Do(ctx, request.ImagenModel(), func(ctx context.Context) error {
+		var err error
+		result, err = s.ImagenAIService.GenerateImage(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type veoAIService struct {
+	repositories.VeoAIService
+	limiter *Limiter
+}
+
+func NewVeoAIService(service repositories.VeoAIService, limiter *Limiter) repositories.VeoAIService {
+	return &veoAIService{VeoAIService: service, limiter: limiter}
+}
+
+func (s *veoAIService) GenerateVideo(ctx context.Context, request *entities.VeoRequest) ([]*entities.VeoResult, error) {
+	var results []*entities.VeoResult
+	err := s.limiter.Do(ctx, request.VeoModel(), func(ctx context.Context) error {
+		var err error
+		results, err = s.VeoAIService.GenerateVideo(ctx, request)
+		return err
+	})
+	return results, err
+}
+
+type nanobananaAIService struct {
+	service repositories.NanobananaAIService
+	limiter *Limiter
+}
+
+func NewNanobananaAIService(service repositories.NanobananaAIService, limiter *Limiter) repositories.NanobananaAIService {
+	return &nanobananaAIService{service: service, limiter: limiter}
+}
+
+func (s *nanobananaAIService) ModifyImage(ctx context.Context, request *entities.NanobananaModifyRequest) (*entities.NanobananaResult, error) {
+	var result *entities.NanobananaResult
+	err := s.limiter.Do(ctx, request.Model(), func(ctx context.Context) error {
+		var err error
+		result, err = s.service.ModifyImage(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type textAIService struct {
+	service repositories.TextAIService
+	limiter *Limiter
+	model   string
+}
+
+// NewTextAIService modelはプロンプトの生成・翻訳に使うモデル名
+func NewTextAIService(service repositories.TextAIService, limiter *Limiter, model string) repositories.TextAIService {
+	return &textAIService{service: service, limiter: limiter, model: model}
+}
+
+func (s *textAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	var result *entities.TextResult
+	err := s.limiter.Do(ctx, s.model, func(ctx context.Context) error {
+		var err error
+		result, err = s.service.GenerateText(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+func (s *textAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	var result *entities.TextResult
+	err := s.limiter.Do(ctx, s.model, func(ctx context.Context) error {
+		var err error
+		result, err = s.service.TranslateToEnglish(ctx, request)
+		return err
+	})
+	return result, err
+}
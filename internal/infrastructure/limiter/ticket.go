@@ -0,0 +1,48 @@
+package limiter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// TicketHeader 順番待ちの状況を問い合わせるためのチケットを受け渡すヘッダー
+const TicketHeader = "X-Queue-Ticket"
+
+// クライアントが指定できるチケットの形式
+var ticketPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+type ticketKey struct{}
+
+// WithTicket チケットをコンテキストに設定する
+func WithTicket(ctx context.Context, ticket string) context.Context {
+	return context.WithValue(ctx, ticketKey{}, ticket)
+}
+
+// TicketFromContext コンテキストのチケット（ない場合は空文字）
+func TicketFromContext(ctx context.Context) string {
+	ticket, _ := ctx.Value(ticketKey{}).(string)
+	return ticket
+}
+
+// TicketMiddleware リクエストのチケットをコンテキストに設定し、レスポンスヘッダーでも返す。
+// ヘッダーがない、または形式が正しくない場合は新しく発行する。
+func TicketMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ticket := r.Header.Get(TicketHeader)
+		if !ticketPattern.MatchString(ticket) {
+			ticket = newTicket()
+		}
+
+		w.Header().Set(TicketHeader, ticket)
+		next.ServeHTTP(w, r.WithContext(WithTicket(r.Context(), ticket)))
+	})
+}
+
+func newTicket() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
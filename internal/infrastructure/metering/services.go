@@ -0,0 +1,38 @@
+package metering
+
+import (
+	"context"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// Geminiの呼び出しで使ったトークン数を利用量に記録するデコレーター
+
+type textAIService struct {
+	repositories.TextAIService
+	usage *usecases.UsageUseCase
+}
+
+func NewTextAIService(service repositories.TextAIService, usage *usecases.UsageUseCase) repositories.TextAIService {
+	return &textAIService{TextAIService: service, usage: usage}
+}
+
+func (s *textAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	result, err := s.TextAIService.GenerateText(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.usage.Record(ctx, result.Usage().Items()...)
+	return result, nil
+}
+
+func (s *textAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	result, err := s.TextAIService.TranslateToEnglish(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.usage.Record(ctx, result.Usage().Items()...)
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// OpenBoltDB 組み込みDB(bbolt)のファイルを開く。ディレクトリが無ければ作成する。
+func OpenBoltDB(path string) (*bolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// 別プロセスがロックを保持している場合に無限に待たないようにする
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return db, nil
+}
+
+// createBuckets 必要なバケットを作成する
+func createBuckets(db *bolt.DB, names ...[]byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// putJSON 値をJSONにしてバケットに保存する
+func putJSON(db *bolt.DB, bucket []byte, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	})
+}
+
+// getJSON バケットから値を取得する。存在しない場合はErrNotFoundを返す。
+func getJSON(db *bolt.DB, bucket []byte, key string, value any) error {
+	var data []byte
+	err := db.View(func(tx *bolt.Tx) error {
+		// View内で取得したスライスはトランザクション外で無効になるためコピーする
+		if v := tx.Bucket(bucket).Get([]byte(key)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read record: %w", err)
+	}
+
+	if data == nil {
+		return domainrepos.ErrNotFound
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to unmarshal record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+var (
+	generationRecordsBucket = []byte("generation_records")
+	// 生成機能・クライアントごとの索引。値は持たず、キーの末尾が履歴のIDになる
+	generationRecordIndexBucket = []byte("generation_record_index")
+)
+
+// BoltGenerationRecordRepository 生成履歴をbboltに保存する（ファイル本体はBlobStore側）。
+// 絞り込んだ一覧は索引のキーを辿り、表示する範囲の履歴だけを読み込む。
+type BoltGenerationRecordRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltGenerationRecordRepository(db *bolt.DB) (domainrepos.GenerationRecordRepository, error) {
+	if err := createBuckets(db, generationRecordsBucket); err != nil {
+		return nil, err
+	}
+
+	// 索引のない以前のDBは、既存の履歴から索引を作る
+	err := db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(generationRecordIndexBucket) != nil {
+			return nil
+		}
+		index, err := tx.CreateBucket(generationRecordIndexBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", generationRecordIndexBucket, err)
+		}
+		return tx.Bucket(generationRecordsBucket).ForEach(func(k, v []byte) error {
+			var row generationRecordRecord
+			if err := json.Unmarshal(v, &row); err != nil {
+				return fmt.Errorf("failed to unmarshal record %s: %w", k, err)
+			}
+			return putGenerationRecordIndex(index, row)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build generation record index: %w", err)
+	}
+
+	return &BoltGenerationRecordRepository{db: db}, nil
+}
+
+// generationRecordIndexKeys 履歴を絞り込みの条件ごとに引くための索引のキー（NUL区切り）
+func generationRecordIndexKeys(row generationRecordRecord) [][]byte {
+	keys := [][]byte{generationRecordIndexKey(row.GeneratorType, "", row.ID)}
+	if row.Client != "" {
+		keys = append(keys,
+			generationRecordIndexKey("", row.Client, row.ID),
+			generationRecordIndexKey(row.GeneratorType, row.Client, row.ID),
+		)
+	}
+	return keys
+}
+
+func generationRecordIndexKey(generatorType, client, id string) []byte {
+	switch {
+	case client == "":
+		return []byte(strings.Join([]string{"type", generatorType, id}, "\x00"))
+	case generatorType == "":
+		return []byte(strings.Join([]string{"client", client, id}, "\x00"))
+	default:
+		return []byte(strings.Join([]string{"client_type", client, generatorType, id}, "\x00"))
+	}
+}
+
+// generationRecordIndexPrefix 絞り込みに使う索引のキーの接頭辞（絞り込まない場合はnil）
+func generationRecordIndexPrefix(filter domainrepos.GenerationRecordFilter) []byte {
+	if filter.GeneratorType == "" && filter.Client == "" {
+		return nil
+	}
+	return generationRecordIndexKey(string(filter.GeneratorType), filter.Client, "")
+}
+
+func putGenerationRecordIndex(index *bolt.Bucket, row generationRecordRecord) error {
+	// 生成機能とクライアントは保存し直しても変わらないため、同じキーを上書きするだけでよい
+	for _, key := range generationRecordIndexKeys(row) {
+		if err := index.Put(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type generationAssetRecord struct {
+	Key      string `json:"key"`
+	MimeType string `json:"mimeType"`
+	Size     int    `json:"size"`
+}
+
+type generationRecordRecord struct {
+	ID               string                  `json:"id"`
+	GeneratorType    string                  `json:"generatorType"`
+	Client           string                  `json:"client,omitempty"`
+	Model            string                  `json:"model"`
+	Prompt           string                  `json:"prompt"`
+	TranslatedPrompt string                  `json:"translatedPrompt"`
+	Parameters       map[string]string       `json:"parameters"`
+	Inputs           []generationAssetRecord `json:"inputs"`
+	Outputs          []generationAssetRecord `json:"outputs"`
+	Status           string                  `json:"status"`
+	ErrorMessage     string                  `json:"errorMessage"`
+	StartedAt        time.Time               `json:"startedAt"`
+	FinishedAt       time.Time               `json:"finishedAt"`
+}
+
+func (r *BoltGenerationRecordRepository) Save(ctx context.Context, record *entities.GenerationRecord) error {
+	row := generationRecordRecord{
+		ID:               string(record.ID()),
+		GeneratorType:    string(record.GeneratorType()),
+		Client:           record.Client(),
+		Model:            record.Model(),
+		Prompt:           record.Prompt(),
+		TranslatedPrompt: record.TranslatedPrompt(),
+		Parameters:       record.Parameters(),
+		Inputs:           toGenerationAssetRecords(record.Inputs()),
+		Outputs:          toGenerationAssetRecords(record.Outputs()),
+		Status:           string(record.Status()),
+		ErrorMessage:     record.ErrorMessage(),
+		StartedAt:        record.StartedAt(),
+		FinishedAt:       record.FinishedAt(),
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	// 履歴と索引を同じトランザクションで書き込み、食い違わないようにする
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(generationRecordsBucket).Put([]byte(row.ID), data); err != nil {
+			return err
+		}
+		return putGenerationRecordIndex(tx.Bucket(generationRecordIndexBucket), row)
+	})
+}
+
+func (r *BoltGenerationRecordRepository) FindByID(ctx context.Context, id entities.GenerationRecordID) (*entities.GenerationRecord, error) {
+	var row generationRecordRecord
+	if err := getJSON(r.db, generationRecordsBucket, string(id), &row); err != nil {
+		return nil, fmt.Errorf("generation record %s: %w", id, err)
+	}
+
+	return row.toEntity(), nil
+}
+
+func (r *BoltGenerationRecordRepository) List(ctx context.Context, filter domainrepos.GenerationRecordFilter) ([]*entities.GenerationRecord, int, error) {
+	var records []*entities.GenerationRecord
+	total := 0
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(generationRecordsBucket)
+
+		// 絞り込む場合は索引、絞り込まない場合は履歴そのもののキーを辿る
+		prefix := generationRecordIndexPrefix(filter)
+		c := bucket.Cursor()
+		if prefix != nil {
+			c = tx.Bucket(generationRecordIndexBucket).Cursor()
+		}
+
+		// IDは時刻順に並ぶため、末尾から辿ると新しい順になる。
+		// 件数は数えるだけで、表示する範囲の履歴だけを読み込む
+		for k := lastWithPrefix(c, prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Prev() {
+			inPage := total >= filter.Offset && (filter.Limit <= 0 || len(records) < filter.Limit)
+			total++
+			if !inPage {
+				continue
+			}
+
+			id := k[len(prefix):]
+			v := bucket.Get(id)
+			if v == nil {
+				return fmt.Errorf("indexed record %s not found", id)
+			}
+			var row generationRecordRecord
+			if err := json.Unmarshal(v, &row); err != nil {
+				return fmt.Errorf("failed to unmarshal record %s: %w", id, err)
+			}
+			records = append(records, row.toEntity())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list generation records: %w", err)
+	}
+
+	if records == nil {
+		records = []*entities.GenerationRecord{}
+	}
+
+	return records, total, nil
+}
+
+// lastWithPrefix 接頭辞で始まる最後のキーにカーソルを合わせて返す。
+// 該当するキーがない場合は、接頭辞で始まらないキーかnilを返す
+func lastWithPrefix(c *bolt.Cursor, prefix []byte) []byte {
+	if len(prefix) == 0 {
+		k, _ := c.Last()
+		return k
+	}
+
+	// IDはASCIIのため、接頭辞に0xffを付けたキーは接頭辞で始まるどのキーよりも後ろになる
+	k, _ := c.Seek(append(bytes.Clone(prefix), 0xff))
+	if k == nil {
+		k, _ = c.Last()
+		return k
+	}
+	k, _ = c.Prev()
+	return k
+}
+
+func (row generationRecordRecord) toEntity() *entities.GenerationRecord {
+	return entities.RestoreGenerationRecord(
+		entities.GenerationRecordID(row.ID),
+		entities.GeneratorType(row.GeneratorType),
+		row.Client,
+		row.Model,
+		row.Prompt,
+		row.TranslatedPrompt,
+		row.Parameters,
+		toGenerationAssets(row.Inputs),
+		toGenerationAssets(row.Outputs),
+		entities.GenerationStatus(row.Status),
+		row.ErrorMessage,
+		row.StartedAt,
+		row.FinishedAt,
+	)
+}
+
+func toGenerationAssetRecords(assets []entities.GenerationAsset) []generationAssetRecord {
+	records := make([]generationAssetRecord, len(assets))
+	for i, asset := range assets {
+		records[i] = generationAssetRecord{
+			Key:      asset.Key(),
+			MimeType: asset.MimeType(),
+			Size:     asset.Size(),
+		}
+	}
+	return records
+}
+
+func toGenerationAssets(records []generationAssetRecord) []entities.GenerationAsset {
+	assets := make([]entities.GenerationAsset, len(records))
+	for i, record := range records {
+		assets[i] = entities.NewGenerationAsset(record.Key, record.MimeType, record.Size)
+	}
+	return assets
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+func TestBoltGenerationRecordRepository_SaveAndFind(t *testing.T) {
+	repo, err := NewBoltGenerationRecordRepository(openTestBoltDB(t))
+	if err != nil {
+		t.Fatalf("NewBoltGenerationRecordRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	startedAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	record, err := entities.NewGenerationRecord(entities.GeneratorImagen, "imagen-4", "夕焼けの海", startedAt)
+	if err != nil {
+		t.Fatalf("NewGenerationRecord() error = %v", err)
+	}
+	record.SetClient("alice")
+	record.SetParameter("aspectRatio", "16:9")
+	record.AddOutput(entities.NewGenerationAsset("abc", "image/png", 10))
+	record.Complete(startedAt.Add(3 * time.Second))
+	if err := repo.Save(ctx, record); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, record.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if found.Client() != "alice" || found.Prompt() != "夕焼けの海" || found.Parameters()["aspectRatio"] != "16:9" {
+		t.Errorf("FindByID() = %+v, want the saved record", found)
+	}
+	if found.Status() != entities.GenerationStatusSucceeded || found.Duration() != 3*time.Second {
+		t.Errorf("Status() = %s, Duration() = %s", found.Status(), found.Duration())
+	}
+	if outputs := found.Outputs(); len(outputs) != 1 || outputs[0] != entities.NewGenerationAsset("abc", "image/png", 10) {
+		t.Errorf("Outputs() = %+v", outputs)
+	}
+
+	if _, err := repo.FindByID(ctx, "gen_missing"); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindByID(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+type testGenerationRecord struct {
+	generatorType entities.GeneratorType
+	client        string
+}
+
+// saveTestGenerationRecords 1秒ずつ開始時刻をずらして履歴を保存し、保存した順のIDを返す
+func saveTestGenerationRecords(t *testing.T, repo domainrepos.GenerationRecordRepository, rows ...testGenerationRecord) []entities.GenerationRecordID {
+	t.Helper()
+
+	startedAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	var ids []entities.GenerationRecordID
+	for i, row := range rows {
+		record, err := entities.NewGenerationRecord(row.generatorType, "model", "prompt", startedAt.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("NewGenerationRecord() error = %v", err)
+		}
+		record.SetClient(row.client)
+		if err := repo.Save(context.Background(), record); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		ids = append(ids, record.ID())
+	}
+	return ids
+}
+
+func TestBoltGenerationRecordRepository_List(t *testing.T) {
+	repo, err := NewBoltGenerationRecordRepository(openTestBoltDB(t))
+	if err != nil {
+		t.Fatalf("NewBoltGenerationRecordRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	ids := saveTestGenerationRecords(t, repo,
+		testGenerationRecord{entities.GeneratorImagen, "alice"},
+		testGenerationRecord{entities.GeneratorVeo, "alice"},
+		testGenerationRecord{entities.GeneratorImagen, "bob"},
+	)
+
+	// 新しい順に並ぶ
+	records, total, err := repo.List(ctx, domainrepos.GenerationRecordFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 3 || len(records) != 3 || records[0].ID() != ids[2] || records[2].ID() != ids[0] {
+		t.Errorf("List() = %d records (total %d), want newest first", len(records), total)
+	}
+
+	records, total, err = repo.List(ctx, domainrepos.GenerationRecordFilter{GeneratorType: entities.GeneratorImagen, Client: "alice"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 1 || len(records) != 1 || records[0].ID() != ids[0] {
+		t.Errorf("List(imagen, alice) = %d records (total %d), want only the first record", len(records), total)
+	}
+
+	// totalは絞り込み後・ページ分割前の件数
+	records, total, err = repo.List(ctx, domainrepos.GenerationRecordFilter{Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 3 || len(records) != 1 || records[0].ID() != ids[1] {
+		t.Errorf("List(offset 1, limit 1) = %d records (total %d), want the second newest", len(records), total)
+	}
+}
+
+func TestBoltGenerationRecordRepository_ListScoped(t *testing.T) {
+	repo, err := NewBoltGenerationRecordRepository(openTestBoltDB(t))
+	if err != nil {
+		t.Fatalf("NewBoltGenerationRecordRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	ids := saveTestGenerationRecords(t, repo,
+		testGenerationRecord{entities.GeneratorImagen, "alice"},
+		testGenerationRecord{entities.GeneratorImagen, "alice2"},
+		testGenerationRecord{entities.GeneratorVeo, "alice"},
+		testGenerationRecord{entities.GeneratorImagen, ""},
+		testGenerationRecord{entities.GeneratorImagen, "alice"},
+	)
+
+	tests := []struct {
+		name   string
+		filter domainrepos.GenerationRecordFilter
+		want   []entities.GenerationRecordID
+		total  int
+	}{
+		// 名前が前方一致するだけのクライアント（alice2）は含めない
+		{"client", domainrepos.GenerationRecordFilter{Client: "alice"}, []entities.GenerationRecordID{ids[4], ids[2], ids[0]}, 3},
+		{"type", domainrepos.GenerationRecordFilter{GeneratorType: entities.GeneratorImagen}, []entities.GenerationRecordID{ids[4], ids[3], ids[1], ids[0]}, 4},
+		{"client and type page", domainrepos.GenerationRecordFilter{Client: "alice", GeneratorType: entities.GeneratorImagen, Offset: 1, Limit: 1}, []entities.GenerationRecordID{ids[0]}, 2},
+		{"unknown client", domainrepos.GenerationRecordFilter{Client: "carol"}, nil, 0},
+		{"past the end", domainrepos.GenerationRecordFilter{Client: "alice", Offset: 5}, nil, 3},
+	}
+	for _, tt := range tests {
+		records, total, err := repo.List(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: List() error = %v", tt.name, err)
+		}
+		var got []entities.GenerationRecordID
+		for _, record := range records {
+			got = append(got, record.ID())
+		}
+		if total != tt.total || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: List() = %v (total %d), want %v (total %d)", tt.name, got, total, tt.want, tt.total)
+		}
+	}
+}
+
+func TestBoltGenerationRecordRepository_BuildsIndexForExistingRecords(t *testing.T) {
+	db := openTestBoltDB(t)
+
+	// 索引を持たない以前のDB
+	if err := createBuckets(db, generationRecordsBucket); err != nil {
+		t.Fatalf("createBuckets() error = %v", err)
+	}
+	row := generationRecordRecord{ID: "gen_00000000000000000001", GeneratorType: "veo", Client: "alice", Status: "succeeded"}
+	if err := putJSON(db, generationRecordsBucket, row.ID, row); err != nil {
+		t.Fatalf("putJSON() error = %v", err)
+	}
+
+	repo, err := NewBoltGenerationRecordRepository(db)
+	if err != nil {
+		t.Fatalf("NewBoltGenerationRecordRepository() error = %v", err)
+	}
+	records, total, err := repo.List(context.Background(), domainrepos.GenerationRecordFilter{
+		GeneratorType: entities.GeneratorVeo, Client: "alice",
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 1 || len(records) != 1 || records[0].ID() != entities.GenerationRecordID(row.ID) {
+		t.Errorf("List() = %d records (total %d), want the existing record", len(records), total)
+	}
+}
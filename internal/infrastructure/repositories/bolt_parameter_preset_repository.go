@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+var parameterPresetsBucket = []byte("parameter_presets")
+
+// BoltParameterPresetRepository 利用者が保存したプリセットをbboltに保存する
+type BoltParameterPresetRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltParameterPresetRepository(db *bolt.DB) (domainrepos.ParameterPresetRepository, error) {
+	if err := createBuckets(db, parameterPresetsBucket); err != nil {
+		return nil, err
+	}
+
+	return &BoltParameterPresetRepository{db: db}, nil
+}
+
+type imagenSettingsRecord struct {
+	Model            string `json:"model"`
+	NumberOfImages   int    `json:"numberOfImages"`
+	AspectRatio      string `json:"aspectRatio"`
+	NegativePrompt   string `json:"negativePrompt"`
+	Seed             int64  `json:"seed"`
+	IncludeRaiReason bool   `json:"includeRaiReason"`
+}
+
+type parameterPresetRecord struct {
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	GeneratorType string                 `json:"generatorType"`
+	TryOn         *tryOnParametersRecord `json:"tryOn,omitempty"`
+	Imagen        *imagenSettingsRecord  `json:"imagen,omitempty"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
+}
+
+func (r *BoltParameterPresetRepository) Save(ctx context.Context, preset *entities.ParameterPreset) error {
+	row := parameterPresetRecord{
+		ID:            string(preset.ID()),
+		Name:          preset.Name(),
+		Description:   preset.Description(),
+		GeneratorType: string(preset.GeneratorType()),
+		CreatedAt:     preset.CreatedAt(),
+		UpdatedAt:     preset.UpdatedAt(),
+	}
+
+	if params := preset.TryOnParameters(); params != nil {
+		row.TryOn = &tryOnParametersRecord{
+			AddWatermark:       params.AddWatermark(),
+			BaseSteps:          params.BaseSteps(),
+			PersonGeneration:   string(params.PersonGeneration()),
+			SafetySetting:      string(params.SafetySetting()),
+			SampleCount:        params.SampleCount(),
+			Seed:               params.Seed(),
+			StorageURI:         params.StorageURI(),
+			OutputMimeType:     string(params.OutputMimeType()),
+			CompressionQuality: params.CompressionQuality(),
+		}
+	}
+
+	if settings := preset.ImagenSettings(); settings != nil {
+		row.Imagen = &imagenSettingsRecord{
+			Model:            settings.Model,
+			NumberOfImages:   settings.NumberOfImages,
+			AspectRatio:      settings.AspectRatio,
+			NegativePrompt:   settings.NegativePrompt,
+			Seed:             settings.Seed,
+			IncludeRaiReason: settings.IncludeRaiReason,
+		}
+	}
+
+	return putJSON(r.db, parameterPresetsBucket, row.ID, row)
+}
+
+func (r *BoltParameterPresetRepository) FindByID(ctx context.Context, id entities.ParameterPresetID) (*entities.ParameterPreset, error) {
+	var row parameterPresetRecord
+	if err := getJSON(r.db, parameterPresetsBucket, string(id), &row); err != nil {
+		return nil, fmt.Errorf("preset %s: %w", id, err)
+	}
+
+	return row.toEntity()
+}
+
+func (r *BoltParameterPresetRepository) List(ctx context.Context, generatorType entities.GeneratorType) ([]*entities.ParameterPreset, error) {
+	presets := []*entities.ParameterPreset{}
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		// IDは時刻順に並ぶため、先頭から辿ると作成順になる
+		return tx.Bucket(parameterPresetsBucket).ForEach(func(k, v []byte) error {
+			var row parameterPresetRecord
+			if err := json.Unmarshal(v, &row); err != nil {
+				return fmt.Errorf("failed to unmarshal preset %s: %w", k, err)
+			}
+
+			if generatorType != "" && entities.GeneratorType(row.GeneratorType) != generatorType {
+				return nil
+			}
+
+			preset, err := row.toEntity()
+			if err != nil {
+				return err
+			}
+			presets = append(presets, preset)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %w", err)
+	}
+
+	return presets, nil
+}
+
+func (r *BoltParameterPresetRepository) Delete(ctx context.Context, id entities.ParameterPresetID) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(parameterPresetsBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return fmt.Errorf("preset %s: %w", id, domainrepos.ErrNotFound)
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+func (row parameterPresetRecord) toEntity() (*entities.ParameterPreset, error) {
+	var tryOn *valueobjects.TryOnParameters
+	if p := row.TryOn; p != nil {
+		parameters, err := valueobjects.NewTryOnParameters(
+			p.AddWatermark,
+			p.BaseSteps,
+			valueobjects.PersonGeneration(p.PersonGeneration),
+			valueobjects.SafetySetting(p.SafetySetting),
+			p.SampleCount,
+			p.Seed,
+			p.StorageURI,
+			valueobjects.MimeType(p.OutputMimeType),
+			p.CompressionQuality,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore preset %s: %w", row.ID, err)
+		}
+		tryOn = parameters
+	}
+
+	var imagen *entities.ImagenSettings
+	if s := row.Imagen; s != nil {
+		imagen = &entities.ImagenSettings{
+			Model:            s.Model,
+			NumberOfImages:   s.NumberOfImages,
+			AspectRatio:      s.AspectRatio,
+			NegativePrompt:   s.NegativePrompt,
+			Seed:             s.Seed,
+			IncludeRaiReason: s.IncludeRaiReason,
+		}
+	}
+
+	return entities.RestoreParameterPreset(
+		entities.ParameterPresetID(row.ID),
+		row.Name,
+		row.Description,
+		entities.GeneratorType(row.GeneratorType),
+		tryOn,
+		imagen,
+		false,
+		row.CreatedAt,
+		row.UpdatedAt,
+	), nil
+}
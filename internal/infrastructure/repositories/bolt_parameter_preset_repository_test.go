@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+func TestBoltParameterPresetRepository(t *testing.T) {
+	repo, err := NewBoltParameterPresetRepository(openTestBoltDB(t))
+	if err != nil {
+		t.Fatalf("NewBoltParameterPresetRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tryOn, err := entities.NewTryOnParameterPreset("既定", "試着の既定値", valueobjects.DefaultTryOnParameters())
+	if err != nil {
+		t.Fatalf("NewTryOnParameterPreset() error = %v", err)
+	}
+	imagen, err := entities.NewImagenParameterPreset("横長", "", entities.ImagenSettings{NumberOfImages: 2, AspectRatio: "16:9", Seed: 42})
+	if err != nil {
+		t.Fatalf("NewImagenParameterPreset() error = %v", err)
+	}
+	for _, preset := range []*entities.ParameterPreset{tryOn, imagen} {
+		if err := repo.Save(ctx, preset); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	found, err := repo.FindByID(ctx, imagen.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if found.Name() != "横長" || found.GeneratorType() != entities.GeneratorImagen || found.ImagenSettings() == nil || *found.ImagenSettings() != *imagen.ImagenSettings() {
+		t.Errorf("FindByID() = %+v, want the saved imagen preset", found)
+	}
+
+	found, err = repo.FindByID(ctx, tryOn.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if found.TryOnParameters() == nil || *found.TryOnParameters() != *tryOn.TryOnParameters() {
+		t.Errorf("TryOnParameters() = %+v, want %+v", found.TryOnParameters(), tryOn.TryOnParameters())
+	}
+
+	presets, err := repo.List(ctx, entities.GeneratorImagen)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(presets) != 1 || presets[0].ID() != imagen.ID() {
+		t.Errorf("List(imagen) = %d presets, want the imagen preset only", len(presets))
+	}
+	presets, err = repo.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(presets) != 2 {
+		t.Errorf("List() = %d presets, want 2", len(presets))
+	}
+
+	if err := repo.Delete(ctx, tryOn.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.FindByID(ctx, tryOn.ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindByID(deleted) error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, tryOn.ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("Delete(deleted) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// openTestBoltDB 一時ディレクトリにDBを作る（テスト終了時に閉じる）
+func openTestBoltDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := OpenBoltDB(filepath.Join(t.TempDir(), "data", "tryon.db"))
+	if err != nil {
+		t.Fatalf("OpenBoltDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newTestImage 塗りつぶしの色ごとに内容の異なるPNG画像
+func newTestImage(t *testing.T, fill color.Color) *valueobjects.ImageData {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := range 4 {
+		for x := range 4 {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	data, err := valueobjects.NewImageData(buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewImageData() error = %v", err)
+	}
+	return data
+}
+
+func TestPutJSONAndGetJSON(t *testing.T) {
+	db := openTestBoltDB(t)
+	bucket := []byte("test")
+	if err := createBuckets(db, bucket); err != nil {
+		t.Fatalf("createBuckets() error = %v", err)
+	}
+
+	type row struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	if err := putJSON(db, bucket, "a", row{Name: "alice", Count: 2}); err != nil {
+		t.Fatalf("putJSON() error = %v", err)
+	}
+
+	var got row
+	if err := getJSON(db, bucket, "a", &got); err != nil {
+		t.Fatalf("getJSON() error = %v", err)
+	}
+	if got != (row{Name: "alice", Count: 2}) {
+		t.Errorf("getJSON() = %+v", got)
+	}
+
+	if err := getJSON(db, bucket, "missing", &got); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("getJSON(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+var (
+	tryOnRequestsBucket = []byte("tryon_requests")
+	tryOnResultsBucket  = []byte("tryon_results")
+)
+
+// BoltTryOnRepository メタデータをbboltに、画像をBlobStoreに保存する
+type BoltTryOnRepository struct {
+	db    *bolt.DB
+	blobs domainrepos.BlobStore
+}
+
+func NewBoltTryOnRepository(db *bolt.DB, blobs domainrepos.BlobStore) (domainrepos.TryOnRepository, error) {
+	if err := createBuckets(db, tryOnRequestsBucket, tryOnResultsBucket); err != nil {
+		return nil, err
+	}
+
+	return &BoltTryOnRepository{
+		db:    db,
+		blobs: blobs,
+	}, nil
+}
+
+// 画像はBlobStoreのキーとMIMEタイプのみを保持する
+type imageRecord struct {
+	BlobKey  string `json:"blobKey"`
+	MimeType string `json:"mimeType"`
+}
+
+type tryOnParametersRecord struct {
+	AddWatermark       bool   `json:"addWatermark"`
+	BaseSteps          int    `json:"baseSteps"`
+	PersonGeneration   string `json:"personGeneration"`
+	SafetySetting      string `json:"safetySetting"`
+	SampleCount        int    `json:"sampleCount"`
+	Seed               int    `json:"seed"`
+	StorageURI         string `json:"storageUri"`
+	OutputMimeType     string `json:"outputMimeType"`
+	CompressionQuality int    `json:"compressionQuality"`
+}
+
+type tryOnRequestRecord struct {
+	ID           string                `json:"id"`
+	PersonImage  imageRecord           `json:"personImage"`
+	GarmentImage imageRecord           `json:"garmentImage"`
+	Parameters   tryOnParametersRecord `json:"parameters"`
+	CreatedAt    time.Time             `json:"createdAt"`
+}
+
+type tryOnResultRecord struct {
+	ID        string        `json:"id"`
+	RequestID string        `json:"requestId"`
+	Images    []imageRecord `json:"images"`
+	// Storage URI指定時は画像を保存せず、保存先のURIのみを記録する
+	OutputURIs []string  `json:"outputUris,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (r *BoltTryOnRepository) Save(ctx context.Context, request *entities.TryOnRequest) error {
+	personImage, err := r.putImage(ctx, request.PersonImage())
+	if err != nil {
+		return fmt.Errorf("failed to save person image: %w", err)
+	}
+
+	garmentImage, err := r.putImage(ctx, request.GarmentImage())
+	if err != nil {
+		return fmt.Errorf("failed to save garment image: %w", err)
+	}
+
+	params := request.Parameters()
+	record := tryOnRequestRecord{
+		ID:           string(request.ID()),
+		PersonImage:  personImage,
+		GarmentImage: garmentImage,
+		Parameters: tryOnParametersRecord{
+			AddWatermark:       params.AddWatermark(),
+			BaseSteps:          params.BaseSteps(),
+			PersonGeneration:   string(params.PersonGeneration()),
+			SafetySetting:      string(params.SafetySetting()),
+			SampleCount:        params.SampleCount(),
+			Seed:               params.Seed(),
+			StorageURI:         params.StorageURI(),
+			OutputMimeType:     string(params.OutputMimeType()),
+			CompressionQuality: params.CompressionQuality(),
+		},
+		CreatedAt: request.CreatedAt(),
+	}
+
+	return putJSON(r.db, tryOnRequestsBucket, record.ID, record)
+}
+
+func (r *BoltTryOnRepository) FindByID(ctx context.Context, id entities.TryOnRequestID) (*entities.TryOnRequest, error) {
+	var record tryOnRequestRecord
+	if err := getJSON(r.db, tryOnRequestsBucket, string(id), &record); err != nil {
+		return nil, fmt.Errorf("request %s: %w", id, err)
+	}
+
+	personImage, err := r.getImage(ctx, record.PersonImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load person image: %w", err)
+	}
+
+	garmentImage, err := r.getImage(ctx, record.GarmentImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load garment image: %w", err)
+	}
+
+	p := record.Parameters
+	parameters, err := valueobjects.NewTryOnParameters(
+		p.AddWatermark,
+		p.BaseSteps,
+		valueobjects.PersonGeneration(p.PersonGeneration),
+		valueobjects.SafetySetting(p.SafetySetting),
+		p.SampleCount,
+		p.Seed,
+		p.StorageURI,
+		valueobjects.MimeType(p.OutputMimeType),
+		p.CompressionQuality,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore parameters: %w", err)
+	}
+
+	return entities.RestoreTryOnRequest(
+		entities.TryOnRequestID(record.ID),
+		personImage,
+		garmentImage,
+		parameters,
+		record.CreatedAt,
+	), nil
+}
+
+func (r *BoltTryOnRepository) SaveResult(ctx context.Context, result *entities.TryOnResult) error {
+	images := make([]imageRecord, 0, len(result.Images()))
+	for _, img := range result.Images() {
+		image, err := r.putImage(ctx, img)
+		if err != nil {
+			return fmt.Errorf("failed to save result image: %w", err)
+		}
+		images = append(images, image)
+	}
+
+	record := tryOnResultRecord{
+		ID:         string(result.ID()),
+		RequestID:  string(result.RequestID()),
+		Images:     images,
+		OutputURIs: result.OutputURIs(),
+		CreatedAt:  result.CreatedAt(),
+	}
+
+	// MemoryTryOnRepositoryと同様にリクエストIDをキーにする
+	return putJSON(r.db, tryOnResultsBucket, record.RequestID, record)
+}
+
+func (r *BoltTryOnRepository) FindResultByRequestID(ctx context.Context, requestID entities.TryOnRequestID) (*entities.TryOnResult, error) {
+	var record tryOnResultRecord
+	if err := getJSON(r.db, tryOnResultsBucket, string(requestID), &record); err != nil {
+		return nil, fmt.Errorf("result for request %s: %w", requestID, err)
+	}
+
+	images := make([]*valueobjects.ImageData, 0, len(record.Images))
+	for _, image := range record.Images {
+		img, err := r.getImage(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load result image: %w", err)
+		}
+		images = append(images, img)
+	}
+
+	return entities.RestoreTryOnResult(
+		entities.TryOnResultID(record.ID),
+		entities.TryOnRequestID(record.RequestID),
+		images,
+		record.OutputURIs,
+		record.CreatedAt,
+	), nil
+}
+
+func (r *BoltTryOnRepository) putImage(ctx context.Context, image *valueobjects.ImageData) (imageRecord, error) {
+	key, err := r.blobs.Put(ctx, image.Data())
+	if err != nil {
+		return imageRecord{}, err
+	}
+
+	return imageRecord{BlobKey: key, MimeType: image.MimeType()}, nil
+}
+
+func (r *BoltTryOnRepository) getImage(ctx context.Context, record imageRecord) (*valueobjects.ImageData, error) {
+	data, err := r.blobs.Get(ctx, record.BlobKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return valueobjects.NewImageData(data)
+}
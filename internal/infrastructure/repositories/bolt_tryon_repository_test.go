@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+func TestBoltTryOnRepository_SaveAndFind(t *testing.T) {
+	db := openTestBoltDB(t)
+	blobs, err := NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	repo, err := NewBoltTryOnRepository(db, blobs)
+	if err != nil {
+		t.Fatalf("NewBoltTryOnRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	person := newTestImage(t, color.White)
+	garment := newTestImage(t, color.Black)
+	request, err := entities.NewTryOnRequest(person, garment, valueobjects.DefaultTryOnParameters())
+	if err != nil {
+		t.Fatalf("NewTryOnRequest() error = %v", err)
+	}
+	if err := repo.Save(ctx, request); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, request.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if !bytes.Equal(found.PersonImage().Data(), person.Data()) || !bytes.Equal(found.GarmentImage().Data(), garment.Data()) {
+		t.Error("FindByID() images differ from the saved request")
+	}
+	if found.Parameters().SampleCount() != request.Parameters().SampleCount() {
+		t.Errorf("SampleCount() = %d, want %d", found.Parameters().SampleCount(), request.Parameters().SampleCount())
+	}
+
+	// 結果の画像が入力と同じ内容でも、ブロブは1つにまとまる
+	result := entities.NewTryOnResult(request.ID(), []*valueobjects.ImageData{person})
+	if err := repo.SaveResult(ctx, result); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	foundResult, err := repo.FindResultByRequestID(ctx, request.ID())
+	if err != nil {
+		t.Fatalf("FindResultByRequestID() error = %v", err)
+	}
+	if foundResult.ID() != result.ID() || len(foundResult.Images()) != 1 || !bytes.Equal(foundResult.Images()[0].Data(), person.Data()) {
+		t.Error("FindResultByRequestID() differs from the saved result")
+	}
+
+	if _, err := repo.FindByID(ctx, "req_missing"); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindByID(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.FindResultByRequestID(ctx, "req_missing"); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindResultByRequestID(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBoltTryOnRepository_SaveResultWithURIs(t *testing.T) {
+	repo, err := NewBoltTryOnRepository(openTestBoltDB(t), NewMemoryBlobStore(1<<20))
+	if err != nil {
+		t.Fatalf("NewBoltTryOnRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	result := entities.NewTryOnResultWithURIs("req_1", []string{"gs://bucket/out/1.png"})
+	if err := repo.SaveResult(ctx, result); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	found, err := repo.FindResultByRequestID(ctx, "req_1")
+	if err != nil {
+		t.Fatalf("FindResultByRequestID() error = %v", err)
+	}
+	if found.HasImages() || len(found.OutputURIs()) != 1 || found.OutputURIs()[0] != "gs://bucket/out/1.png" {
+		t.Errorf("OutputURIs() = %v, want the saved URI only", found.OutputURIs())
+	}
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+var usageTotalsBucket = []byte("usage_totals")
+
+// BoltUsageRepository 利用量の集計をbboltに保存する。
+// キーは「集計日・クライアント・モデル・単位」をNUL区切りにしたもので、集計日の順に並ぶ。
+type BoltUsageRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltUsageRepository(db *bolt.DB) (domainrepos.UsageRepository, error) {
+	if err := createBuckets(db, usageTotalsBucket); err != nil {
+		return nil, err
+	}
+
+	return &BoltUsageRepository{db: db}, nil
+}
+
+type usageTotalRecord struct {
+	Day      string  `json:"day"`
+	Client   string  `json:"client"`
+	Model    string  `json:"model"`
+	Unit     string  `json:"unit"`
+	Quantity float64 `json:"quantity"`
+	Cost     float64 `json:"cost"`
+	Priced   bool    `json:"priced"`
+}
+
+func usageTotalKey(total entities.UsageTotal) []byte {
+	return []byte(strings.Join([]string{total.Day, total.Client, total.Model, string(total.Unit)}, "\x00"))
+}
+
+func (r *BoltUsageRepository) Add(ctx context.Context, totals []entities.UsageTotal) error {
+	// 読み取りと書き込みを同じトランザクションで行い、同時の加算を取りこぼさない
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usageTotalsBucket)
+		for _, total := range totals {
+			key := usageTotalKey(total)
+
+			var current entities.UsageTotal
+			if v := bucket.Get(key); v != nil {
+				var row usageTotalRecord
+				if err := json.Unmarshal(v, &row); err != nil {
+					return fmt.Errorf("failed to unmarshal usage %q: %w", key, err)
+				}
+				current = row.toEntity()
+			}
+
+			data, err := json.Marshal(toUsageTotalRecord(addUsageTotal(current, total)))
+			if err != nil {
+				return fmt.Errorf("failed to marshal usage: %w", err)
+			}
+			if err := bucket.Put(key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add usage: %w", err)
+	}
+	return nil
+}
+
+func (r *BoltUsageRepository) List(ctx context.Context, filter domainrepos.UsageFilter) ([]entities.UsageTotal, error) {
+	var totals []entities.UsageTotal
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(usageTotalsBucket).Cursor()
+		for k, v := c.Seek([]byte(filter.From)); k != nil; k, v = c.Next() {
+			day, _, _ := bytes.Cut(k, []byte{0})
+			if filter.To != "" && string(day) > filter.To {
+				break
+			}
+
+			var row usageTotalRecord
+			if err := json.Unmarshal(v, &row); err != nil {
+				return fmt.Errorf("failed to unmarshal usage %q: %w", k, err)
+			}
+			if total := row.toEntity(); matchesUsageFilter(total, filter) {
+				totals = append(totals, total)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+
+	return totals, nil
+}
+
+func toUsageTotalRecord(total entities.UsageTotal) usageTotalRecord {
+	return usageTotalRecord{
+		Day:      total.Day,
+		Client:   total.Client,
+		Model:    total.Model,
+		Unit:     string(total.Unit),
+		Quantity: total.Quantity,
+		Cost:     total.Cost,
+		Priced:   total.Priced,
+	}
+}
+
+func (row usageTotalRecord) toEntity() entities.UsageTotal {
+	return entities.UsageTotal{
+		Day:      row.Day,
+		Client:   row.Client,
+		Model:    row.Model,
+		Unit:     entities.PriceUnit(row.Unit),
+		Quantity: row.Quantity,
+		Cost:     row.Cost,
+		Priced:   row.Priced,
+	}
+}
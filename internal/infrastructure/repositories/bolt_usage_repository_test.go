@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+func TestBoltUsageRepository_AddAndList(t *testing.T) {
+	db := openTestBoltDB(t)
+	repo, err := NewBoltUsageRepository(db)
+	if err != nil {
+		t.Fatalf("NewBoltUsageRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	image := func(day, client string, quantity, cost float64) entities.UsageTotal {
+		return entities.UsageTotal{Day: day, Client: client, Model: "imagen-4", Unit: entities.PriceUnitImage, Quantity: quantity, Cost: cost, Priced: true}
+	}
+	if err := repo.Add(ctx, []entities.UsageTotal{
+		image("2025-09-02", "alice", 1, 0.04),
+		image("2025-09-01", "bob", 2, 0.08),
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	// 同じ日・クライアント・モデル・単位は足し合わせる
+	if err := repo.Add(ctx, []entities.UsageTotal{image("2025-09-02", "alice", 2, 0.08)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := repo.Add(ctx, []entities.UsageTotal{image("2025-09-03", "alice", 1, 0.04)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// 開き直しても集計は残る
+	repo, err = NewBoltUsageRepository(db)
+	if err != nil {
+		t.Fatalf("NewBoltUsageRepository() error = %v", err)
+	}
+
+	totals, err := repo.List(ctx, domainrepos.UsageFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(totals) != 3 || totals[0].Day != "2025-09-01" || totals[1].Quantity != 3 || totals[1].Cost < 0.119 || totals[1].Cost > 0.121 {
+		t.Errorf("List() = %+v, want 3 totals by day with alice's 2025-09-02 usage summed", totals)
+	}
+
+	totals, err = repo.List(ctx, domainrepos.UsageFilter{From: "2025-09-02", To: "2025-09-02", Client: "alice"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(totals) != 1 || totals[0].Client != "alice" || totals[0].Day != "2025-09-02" {
+		t.Errorf("List(2025-09-02, alice) = %+v", totals)
+	}
+
+	totals, err = repo.List(ctx, domainrepos.UsageFilter{Client: "carol"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(totals) != 0 {
+		t.Errorf("List(carol) = %+v, want none", totals)
+	}
+}
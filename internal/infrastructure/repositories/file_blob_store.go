@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// FileBlobStore SHA-256をキーとしてディレクトリにバイナリを保存する
+type FileBlobStore struct {
+	dir string
+}
+
+func NewFileBlobStore(dir string) (domainrepos.BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	return &FileBlobStore{dir: dir}, nil
+}
+
+func (s *FileBlobStore) Put(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+	path := s.pathFor(key)
+
+	// 同じ内容が保存済みであれば書き込まない
+	if _, err := os.Stat(path); err == nil {
+		return key, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// 一時ファイルに書き込んでからリネームし、書きかけのファイルを残さない
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close blob: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *FileBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if !isValidBlobKey(key) {
+		return nil, fmt.Errorf("blob %s: %w", key, domainrepos.ErrNotFound)
+	}
+
+	data, err := os.ReadFile(s.pathFor(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("blob %s: %w", key, domainrepos.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	return data, nil
+}
+
+// pathFor 先頭2文字でディレクトリを分割し、1ディレクトリ内のファイル数を抑える
+func (s *FileBlobStore) pathFor(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// isValidBlobKey SHA-256の16進文字列かどうか（パストラバーサル対策）
+func isValidBlobKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+func TestFileBlobStore_PutAndGet(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	key, err := store.Put(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// SHA-256の16進数
+	if key != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("key = %s, want the SHA-256 of the content", key)
+	}
+
+	// 同じ内容は同じキーで、ファイルは1つだけ
+	again, err := store.Put(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatalf("Put() again error = %v", err)
+	}
+	if again != key {
+		t.Errorf("second key = %s, want %s", again, key)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != key {
+		t.Errorf("files = %v, want a single file named by the key", files)
+	}
+
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Get() = %q, want hello", data)
+	}
+}
+
+func TestFileBlobStore_Get_RejectsInvalidKeys(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+
+	// ディレクトリの外に置いたファイルを読ませない
+	if err := os.WriteFile(filepath.Join(dir, "x"), []byte("secret"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, key := range []string{
+		"",
+		"../x",
+		"../../x",
+		"..",
+		strings.Repeat("z", 64),             // 16進数でない
+		"../" + strings.Repeat("0", 61),     // 64文字のトラバーサル
+		strings.Repeat("0", 63),             // 短い
+		strings.Repeat("0", 64),             // 正しい形式だが存在しない
+		strings.Repeat("0", 62) + "/" + "0", // 区切り文字を含む
+	} {
+		if _, err := store.Get(context.Background(), key); !errors.Is(err, domainrepos.ErrNotFound) {
+			t.Errorf("Get(%q) error = %v, want ErrNotFound", key, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// LocalObjectStorageScheme ローカルのオブジェクトストレージを指すURIのスキーム
+const LocalObjectStorageScheme = "local"
+
+// LocalObjectStorage local://bucket/path をディレクトリ配下のファイルとして扱う。
+// GCSなどを用意せずに Storage URI 出力を試すためのもの。
+type LocalObjectStorage struct {
+	dir string
+}
+
+func NewLocalObjectStorage(dir string) (domainrepos.ObjectStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create object storage directory: %w", err)
+	}
+
+	return &LocalObjectStorage{dir: dir}, nil
+}
+
+func (s *LocalObjectStorage) Supports(uri string) bool {
+	return strings.HasPrefix(uri, LocalObjectStorageScheme+"://")
+}
+
+func (s *LocalObjectStorage) Get(ctx context.Context, uri string) ([]byte, error) {
+	path, err := s.pathFor(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("object %s: %w", uri, domainrepos.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s *LocalObjectStorage) Put(ctx context.Context, uri string, data []byte, contentType string) error {
+	path, err := s.pathFor(uri)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// 一時ファイルに書き込んでからリネームし、書きかけのファイルを残さない
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close object: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return nil
+}
+
+// pathFor バケットをディレクトリとし、その下にオブジェクトのパスを置く。
+// ルートディレクトリの外を指すURIは拒否する（パストラバーサル対策）。
+func (s *LocalObjectStorage) pathFor(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != LocalObjectStorageScheme || u.Host == "" {
+		return "", fmt.Errorf("invalid local object URI: %q", uri)
+	}
+
+	object := strings.TrimPrefix(u.Path, "/")
+	if object == "" || strings.HasSuffix(object, "/") {
+		return "", fmt.Errorf("object name is required: %q", uri)
+	}
+
+	path := filepath.Join(s.dir, u.Host, filepath.FromSlash(object))
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid local object URI: %q", uri)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// MemoryBlobStore メモリ上にバイナリを保持する。上限を超えた場合は古いものから破棄する。
+type MemoryBlobStore struct {
+	blobs    map[string][]byte
+	order    []string // 追加順
+	size     int64
+	maxBytes int64
+	mu       sync.RWMutex
+}
+
+func NewMemoryBlobStore(maxBytes int64) domainrepos.BlobStore {
+	return &MemoryBlobStore{
+		blobs:    make(map[string][]byte),
+		maxBytes: maxBytes,
+	}
+}
+
+func (s *MemoryBlobStore) Put(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.blobs[key]; exists {
+		return key, nil
+	}
+
+	s.blobs[key] = data
+	s.order = append(s.order, key)
+	s.size += int64(len(data))
+
+	// 直前に追加したものは残す
+	for s.maxBytes > 0 && s.size > s.maxBytes && len(s.order) > 1 {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		s.size -= int64(len(s.blobs[oldest]))
+		delete(s.blobs, oldest)
+	}
+
+	return key, nil
+}
+
+func (s *MemoryBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.blobs[key]
+	if !exists {
+		return nil, fmt.Errorf("blob %s: %w", key, domainrepos.ErrNotFound)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// メモリ上に保持する履歴の最大件数（超えた分は古いものから破棄する）
+const maxMemoryGenerationRecords = 200
+
+type MemoryGenerationRecordRepository struct {
+	// 古い順に並ぶ
+	records []*entities.GenerationRecord
+	mu      sync.RWMutex
+}
+
+func NewMemoryGenerationRecordRepository() domainrepos.GenerationRecordRepository {
+	return &MemoryGenerationRecordRepository{}
+}
+
+func (r *MemoryGenerationRecordRepository) Save(ctx context.Context, record *entities.GenerationRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.records {
+		if existing.ID() == record.ID() {
+			r.records[i] = record
+			return nil
+		}
+	}
+
+	r.records = append(r.records, record)
+	if len(r.records) > maxMemoryGenerationRecords {
+		r.records = r.records[len(r.records)-maxMemoryGenerationRecords:]
+	}
+
+	return nil
+}
+
+func (r *MemoryGenerationRecordRepository) FindByID(ctx context.Context, id entities.GenerationRecordID) (*entities.GenerationRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.records {
+		if record.ID() == id {
+			return record, nil
+		}
+	}
+
+	return nil, fmt.Errorf("generation record %s: %w", id, domainrepos.ErrNotFound)
+}
+
+func (r *MemoryGenerationRecordRepository) List(ctx context.Context, filter domainrepos.GenerationRecordFilter) ([]*entities.GenerationRecord, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*entities.GenerationRecord
+	for i := len(r.records) - 1; i >= 0; i-- {
+		record := r.records[i]
+		if filter.GeneratorType != "" && record.GeneratorType() != filter.GeneratorType {
+			continue
+		}
+		if filter.Client != "" && record.Client() != filter.Client {
+			continue
+		}
+		matched = append(matched, record)
+	}
+
+	return paginate(matched, filter.Offset, filter.Limit), len(matched), nil
+}
+
+// paginate offset/limitで切り出す。limitが0以下の場合は残り全件を返す。
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	return items[offset:end]
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+type MemoryParameterPresetRepository struct {
+	presets map[entities.ParameterPresetID]entities.ParameterPreset
+	mu      sync.RWMutex
+}
+
+func NewMemoryParameterPresetRepository() domainrepos.ParameterPresetRepository {
+	return &MemoryParameterPresetRepository{
+		presets: make(map[entities.ParameterPresetID]entities.ParameterPreset),
+	}
+}
+
+// Save プリセットを値コピーで保持する（更新途中のインスタンスを共有しないため）
+func (r *MemoryParameterPresetRepository) Save(ctx context.Context, preset *entities.ParameterPreset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.presets[preset.ID()] = *preset
+	return nil
+}
+
+func (r *MemoryParameterPresetRepository) FindByID(ctx context.Context, id entities.ParameterPresetID) (*entities.ParameterPreset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preset, exists := r.presets[id]
+	if !exists {
+		return nil, fmt.Errorf("preset %s: %w", id, domainrepos.ErrNotFound)
+	}
+
+	return &preset, nil
+}
+
+func (r *MemoryParameterPresetRepository) List(ctx context.Context, generatorType entities.GeneratorType) ([]*entities.ParameterPreset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	presets := make([]*entities.ParameterPreset, 0, len(r.presets))
+	for _, preset := range r.presets {
+		if generatorType != "" && preset.GeneratorType() != generatorType {
+			continue
+		}
+		preset := preset
+		presets = append(presets, &preset)
+	}
+
+	slices.SortFunc(presets, func(a, b *entities.ParameterPreset) int {
+		return a.CreatedAt().Compare(b.CreatedAt())
+	})
+
+	return presets, nil
+}
+
+func (r *MemoryParameterPresetRepository) Delete(ctx context.Context, id entities.ParameterPresetID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.presets[id]; !exists {
+		return fmt.Errorf("preset %s: %w", id, domainrepos.ErrNotFound)
+	}
+
+	delete(r.presets, id)
+	return nil
+}
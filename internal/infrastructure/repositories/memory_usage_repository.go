@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+type MemoryUsageRepository struct {
+	totals map[usageKey]entities.UsageTotal
+	mu     sync.RWMutex
+}
+
+// usageKey 集計の単位
+type usageKey struct {
+	day    string
+	client string
+	model  string
+	unit   entities.PriceUnit
+}
+
+func newUsageKey(total entities.UsageTotal) usageKey {
+	return usageKey{day: total.Day, client: total.Client, model: total.Model, unit: total.Unit}
+}
+
+func NewMemoryUsageRepository() domainrepos.UsageRepository {
+	return &MemoryUsageRepository{
+		totals: make(map[usageKey]entities.UsageTotal),
+	}
+}
+
+func (r *MemoryUsageRepository) Add(ctx context.Context, totals []entities.UsageTotal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, total := range totals {
+		key := newUsageKey(total)
+		r.totals[key] = addUsageTotal(r.totals[key], total)
+	}
+	return nil
+}
+
+func (r *MemoryUsageRepository) List(ctx context.Context, filter domainrepos.UsageFilter) ([]entities.UsageTotal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var totals []entities.UsageTotal
+	for _, total := range r.totals {
+		if matchesUsageFilter(total, filter) {
+			totals = append(totals, total)
+		}
+	}
+	sortUsageTotals(totals)
+	return totals, nil
+}
+
+// addUsageTotal 既存の集計（ゼロ値の場合は新規）に加える
+func addUsageTotal(current, total entities.UsageTotal) entities.UsageTotal {
+	if current.Day == "" {
+		return total
+	}
+	current.Quantity += total.Quantity
+	current.Cost += total.Cost
+	current.Priced = current.Priced || total.Priced
+	return current
+}
+
+func matchesUsageFilter(total entities.UsageTotal, filter domainrepos.UsageFilter) bool {
+	if filter.From != "" && total.Day < filter.From {
+		return false
+	}
+	if filter.To != "" && total.Day > filter.To {
+		return false
+	}
+	return filter.Client == "" || total.Client == filter.Client
+}
+
+func sortUsageTotals(totals []entities.UsageTotal) {
+	slices.SortFunc(totals, func(a, b entities.UsageTotal) int {
+		return cmp.Or(
+			cmp.Compare(a.Day, b.Day),
+			cmp.Compare(a.Client, b.Client),
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.Unit, b.Unit),
+		)
+	})
+}
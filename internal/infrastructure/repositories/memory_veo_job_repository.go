@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+const (
+	// 終了したジョブ（動画のデータを含む）を保持する期間
+	memoryVeoJobTTL = time.Hour
+	// メモリ上に保持する終了したジョブの最大件数（超えた分は終了が古いものから破棄する）
+	maxMemoryFinishedVeoJobs = 50
+)
+
+// MemoryVeoJobRepository 実行中のジョブは全て保持し、終了したジョブは期間と件数を限って保持する
+type MemoryVeoJobRepository struct {
+	jobs map[entities.VeoJobID]entities.VeoJob
+	mu   sync.RWMutex
+	now  func() time.Time
+}
+
+func NewMemoryVeoJobRepository() domainrepos.VeoJobRepository {
+	return &MemoryVeoJobRepository{
+		jobs: make(map[entities.VeoJobID]entities.VeoJob),
+		now:  time.Now,
+	}
+}
+
+// Save ジョブを値コピーで保持する（ワーカーと参照側でインスタンスを共有しないため）
+func (r *MemoryVeoJobRepository) Save(ctx context.Context, job *entities.VeoJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID()] = *job
+	r.pruneFinished()
+	return nil
+}
+
+func (r *MemoryVeoJobRepository) FindByID(ctx context.Context, id entities.VeoJobID) (*entities.VeoJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists || r.expired(&job) {
+		return nil, fmt.Errorf("job %s: %w", id, domainrepos.ErrNotFound)
+	}
+
+	return &job, nil
+}
+
+func (r *MemoryVeoJobRepository) expired(job *entities.VeoJob) bool {
+	return job.IsFinished() && r.now().Sub(job.UpdatedAt()) >= memoryVeoJobTTL
+}
+
+// pruneFinished 期限切れと、件数の上限を超えた終了したジョブを破棄する
+func (r *MemoryVeoJobRepository) pruneFinished() {
+	var finished []entities.VeoJob
+	for id, job := range r.jobs {
+		if !job.IsFinished() {
+			continue
+		}
+		if r.expired(&job) {
+			delete(r.jobs, id)
+			continue
+		}
+		finished = append(finished, job)
+	}
+
+	if len(finished) <= maxMemoryFinishedVeoJobs {
+		return
+	}
+	slices.SortFunc(finished, func(a, b entities.VeoJob) int {
+		return a.UpdatedAt().Compare(b.UpdatedAt())
+	})
+	for _, job := range finished[:len(finished)-maxMemoryFinishedVeoJobs] {
+		delete(r.jobs, job.ID())
+	}
+}
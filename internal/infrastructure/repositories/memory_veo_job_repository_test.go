@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/entities"
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+func newFinishedVeoJob(t *testing.T) *entities.VeoJob {
+	t.Helper()
+
+	job := entities.NewVeoJob("veo-3.0-generate-001")
+	if err := job.Succeed([][]byte{[]byte("video")}); err != nil {
+		t.Fatalf("Succeed() error = %v", err)
+	}
+	return job
+}
+
+func TestMemoryVeoJobRepository_ExpiresFinishedJobs(t *testing.T) {
+	repo := NewMemoryVeoJobRepository().(*MemoryVeoJobRepository)
+	ctx := context.Background()
+
+	running := entities.NewVeoJob("veo-3.0-generate-001")
+	finished := newFinishedVeoJob(t)
+	for _, job := range []*entities.VeoJob{running, finished} {
+		if err := repo.Save(ctx, job); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	repo.now = func() time.Time { return time.Now().Add(memoryVeoJobTTL) }
+
+	if _, err := repo.FindByID(ctx, finished.ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindByID(finished) error = %v, want ErrNotFound after the TTL", err)
+	}
+	// 実行中のジョブは期限がない
+	if _, err := repo.FindByID(ctx, running.ID()); err != nil {
+		t.Errorf("FindByID(running) error = %v", err)
+	}
+
+	// 次の保存で期限切れのジョブを破棄する
+	if err := repo.Save(ctx, running); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(repo.jobs) != 1 {
+		t.Errorf("jobs = %d, want only the running job", len(repo.jobs))
+	}
+}
+
+func TestMemoryVeoJobRepository_CapsFinishedJobs(t *testing.T) {
+	repo := NewMemoryVeoJobRepository().(*MemoryVeoJobRepository)
+	ctx := context.Background()
+
+	var jobs []*entities.VeoJob
+	for range maxMemoryFinishedVeoJobs + 1 {
+		job := newFinishedVeoJob(t)
+		jobs = append(jobs, job)
+		if err := repo.Save(ctx, job); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		// 終了時刻を確実にずらす
+		time.Sleep(time.Microsecond)
+	}
+
+	if _, err := repo.FindByID(ctx, jobs[0].ID()); !errors.Is(err, domainrepos.ErrNotFound) {
+		t.Errorf("FindByID(oldest) error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, jobs[len(jobs)-1].ID()); err != nil {
+		t.Errorf("FindByID(newest) error = %v", err)
+	}
+	if len(repo.jobs) != maxMemoryFinishedVeoJobs {
+		t.Errorf("jobs = %d, want %d", len(repo.jobs), maxMemoryFinishedVeoJobs)
+	}
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+)
+
+// ObjectStorageRouter URIのスキームに応じて保存先を振り分ける
+type ObjectStorageRouter struct {
+	storages []domainrepos.ObjectStorage
+}
+
+// NewObjectStorageRouter 先に渡したものから順に、URIを扱える保存先を使う
+func NewObjectStorageRouter(storages ...domainrepos.ObjectStorage) domainrepos.ObjectStorage {
+	return &ObjectStorageRouter{storages: storages}
+}
+
+func (r *ObjectStorageRouter) Supports(uri string) bool {
+	return r.find(uri) != nil
+}
+
+func (r *ObjectStorageRouter) Get(ctx context.Context, uri string) ([]byte, error) {
+	storage := r.find(uri)
+	if storage == nil {
+		return nil, fmt.Errorf("unsupported object URI: %q", uri)
+	}
+	return storage.Get(ctx, uri)
+}
+
+func (r *ObjectStorageRouter) Put(ctx context.Context, uri string, data []byte, contentType string) error {
+	storage := r.find(uri)
+	if storage == nil {
+		return fmt.Errorf("unsupported object URI: %q", uri)
+	}
+	return storage.Put(ctx, uri, data, contentType)
+}
+
+func (r *ObjectStorageRouter) find(uri string) domainrepos.ObjectStorage {
+	for _, storage := range r.storages {
+		if storage.Supports(uri) {
+			return storage
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+// attempts 操作ごとの試行回数（/debug/vars の retry_attempts で確認できる）
+// キーは "<操作名>.<結果>"。結果は success / retry / failure。
+var attempts = expvar.NewMap("retry_attempts")
+
+// RetryAfterError 次の試行まで待つべき時間（Retry-After）を持つエラー
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// Policy 外部API呼び出しのリトライ方針
+type Policy struct {
+	// MaxAttempts 最初の呼び出しを含む最大試行回数（1の場合はリトライしない）
+	MaxAttempts int
+	// InitialBackoff 1回目のリトライまでの待ち時間。以降は倍々に増やす
+	InitialBackoff time.Duration
+	// MaxBackoff 待ち時間の上限
+	MaxBackoff time.Duration
+	// RetryableKinds リトライするエラーの種類
+	RetryableKinds []domainerrors.Kind
+
+	// テストで差し替える
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// DefaultPolicy 3回まで、1秒から始めて最大30秒待つ
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		RetryableKinds: []domainerrors.Kind{
+			domainerrors.KindQuotaExhausted,
+			domainerrors.KindModelUnavailable,
+			domainerrors.KindUpstreamTimeout,
+		},
+	}
+}
+
+// PolicyFromEnv 環境変数 RETRY_MAX_ATTEMPTS / RETRY_INITIAL_BACKOFF / RETRY_MAX_BACKOFF / RETRY_ON から読み込む
+func PolicyFromEnv() (Policy, error) {
+	policy := DefaultPolicy()
+
+	if value := os.Getenv("RETRY_MAX_ATTEMPTS"); value != "" {
+		maxAttempts, err := strconv.Atoi(value)
+		if err != nil || maxAttempts < 1 {
+			return Policy{}, fmt.Errorf("invalid RETRY_MAX_ATTEMPTS: %q", value)
+		}
+		policy.MaxAttempts = maxAttempts
+	}
+
+	if value := os.Getenv("RETRY_INITIAL_BACKOFF"); value != "" {
+		backoff, err := time.ParseDuration(value)
+		if err != nil || backoff <= 0 {
+			return Policy{}, fmt.Errorf("invalid RETRY_INITIAL_BACKOFF: %q", value)
+		}
+		policy.InitialBackoff = backoff
+	}
+
+	if value := os.Getenv("RETRY_MAX_BACKOFF"); value != "" {
+		backoff, err := time.ParseDuration(value)
+		if err != nil || backoff <= 0 {
+			return Policy{}, fmt.Errorf("invalid RETRY_MAX_BACKOFF: %q", value)
+		}
+		policy.MaxBackoff = backoff
+	}
+
+	if value := os.Getenv("RETRY_ON"); value != "" {
+		kinds, err := parseKinds(value)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid RETRY_ON: %w", err)
+		}
+		policy.RetryableKinds = kinds
+	}
+
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return Policy{}, fmt.Errorf("RETRY_MAX_BACKOFF (%s) must not be less than RETRY_INITIAL_BACKOFF (%s)", policy.MaxBackoff, policy.InitialBackoff)
+	}
+
+	return policy, nil
+}
+
+func parseKinds(value string) ([]domainerrors.Kind, error) {
+	var kinds []domainerrors.Kind
+	for _, name := range strings.Split(value, ",") {
+		kind := domainerrors.Kind(strings.TrimSpace(name))
+		switch kind {
+		case domainerrors.KindQuotaExhausted,
+			domainerrors.KindModelUnavailable,
+			domainerrors.KindUpstreamTimeout,
+			domainerrors.KindInternal:
+			kinds = append(kinds, kind)
+		case "":
+		default:
+			return nil, fmt.Errorf("unsupported error kind %q", kind)
+		}
+	}
+	return kinds, nil
+}
+
+// Do fnをリトライ方針に従って実行する。
+// コンテキストの期限までに次の試行を始められない場合や、Retry-After が MaxBackoff より長い場合は、最後のエラーを返す。
+func (p Policy) Do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	maxAttempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			attempts.Add(operation+".success", 1)
+			if attempt > 1 {
+				slog.Info("Upstream call succeeded after retry", "operation", operation, "attempt", attempt)
+			}
+			return nil
+		}
+
+		if attempt >= maxAttempts || !p.retryable(ctx, err) {
+			attempts.Add(operation+".failure", 1)
+			return err
+		}
+
+		wait, ok := p.backoff(attempt, err)
+		if !ok {
+			attempts.Add(operation+".failure", 1)
+			slog.Warn("Retry abandoned: Retry-After exceeds max backoff", "operation", operation, "attempt", attempt, "wait", wait, "maxBackoff", p.MaxBackoff, "error", err)
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			attempts.Add(operation+".failure", 1)
+			slog.Warn("Retry abandoned: not enough time before deadline", "operation", operation, "attempt", attempt, "wait", wait, "error", err)
+			return err
+		}
+
+		attempts.Add(operation+".retry", 1)
+		slog.Warn("Upstream call failed, retrying", "operation", operation, "attempt", attempt, "maxAttempts", maxAttempts, "wait", wait, "error", err)
+
+		if sleepErr := p.wait(ctx, wait); sleepErr != nil {
+			attempts.Add(operation+".failure", 1)
+			return err
+		}
+	}
+}
+
+// retryable 呼び出し元が中断していない、かつリトライ対象の種類のエラーか
+func (p Policy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	kind := domainerrors.KindOf(err)
+	for _, retryable := range p.RetryableKinds {
+		if kind == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff 指数バックオフにジッター（待ち時間の後半50%をランダム）を加える。
+// エラーがRetry-Afterを持つ場合はそちらを優先するが、MaxBackoff より長い場合は待たずに諦める（falseを返す）。
+func (p Policy) backoff(attempt int, err error) (time.Duration, bool) {
+	var retryAfter RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.RetryAfter() > 0 {
+		return retryAfter.RetryAfter(), retryAfter.RetryAfter() <= p.MaxBackoff
+	}
+
+	backoff := p.InitialBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	half := backoff / 2
+	return half + rand.N(half+1), true
+}
+
+func (p Policy) wait(ctx context.Context, d time.Duration) error {
+	if p.sleep != nil {
+		return p.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tryon-demo/internal/domain/domainerrors"
+)
+
+type retryAfterTestError struct {
+	error
+	delay time.Duration
+}
+
+func (e *retryAfterTestError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// newTestPolicy 実際には待たず、待ち時間を記録する
+func newTestPolicy(maxAttempts int) (Policy, *[]time.Duration) {
+	var waits []time.Duration
+	policy := DefaultPolicy()
+	policy.MaxAttempts = maxAttempts
+	policy.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return policy, &waits
+}
+
+func TestPolicy_Do_RetriesRetryableErrors(t *testing.T) {
+	policy, waits := newTestPolicy(3)
+	quotaErr := domainerrors.New(domainerrors.KindQuotaExhausted, "429")
+
+	calls := 0
+	err := policy.Do(context.Background(), "test.retryable", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return quotaErr
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("waits = %v, want 2 waits", *waits)
+	}
+
+	// 1回目は0.5〜1秒、2回目は1〜2秒
+	if w := (*waits)[0]; w < 500*time.Millisecond || w > time.Second {
+		t.Errorf("first wait = %v, want between 500ms and 1s", w)
+	}
+	if w := (*waits)[1]; w < time.Second || w > 2*time.Second {
+		t.Errorf("second wait = %v, want between 1s and 2s", w)
+	}
+}
+
+func TestPolicy_Do_StopsAtMaxAttempts(t *testing.T) {
+	policy, _ := newTestPolicy(3)
+	unavailable := domainerrors.New(domainerrors.KindModelUnavailable, "503")
+
+	calls := 0
+	err := policy.Do(context.Background(), "test.max_attempts", func(ctx context.Context) error {
+		calls++
+		return unavailable
+	})
+
+	if !errors.Is(err, unavailable) {
+		t.Errorf("Do() error = %v, want %v", err, unavailable)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestPolicy_Do_DoesNotRetryOtherErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "入力エラー", err: domainerrors.New(domainerrors.KindInvalidInput, "400")},
+		{name: "安全性フィルタ", err: domainerrors.New(domainerrors.KindSafetyBlocked, "blocked")},
+		{name: "種類なし", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, waits := newTestPolicy(3)
+
+			calls := 0
+			err := policy.Do(context.Background(), "test.non_retryable", func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Do() error = %v, want %v", err, tt.err)
+			}
+			if calls != 1 || len(*waits) != 0 {
+				t.Errorf("calls = %d, waits = %v, want a single call", calls, *waits)
+			}
+		})
+	}
+}
+
+func TestPolicy_Do_HonoursRetryAfter(t *testing.T) {
+	policy, waits := newTestPolicy(2)
+	err := domainerrors.Wrap(domainerrors.KindQuotaExhausted,
+		&retryAfterTestError{error: errors.New("429"), delay: 7 * time.Second}, "predict request failed")
+
+	calls := 0
+	_ = policy.Do(context.Background(), "test.retry_after", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return err
+		}
+		return nil
+	})
+
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s]", *waits)
+	}
+}
+
+func TestPolicy_Do_GivesUpWhenRetryAfterExceedsMaxBackoff(t *testing.T) {
+	policy, waits := newTestPolicy(3)
+	err := domainerrors.Wrap(domainerrors.KindQuotaExhausted,
+		&retryAfterTestError{error: errors.New("429"), delay: time.Hour}, "predict request failed")
+
+	calls := 0
+	got := policy.Do(context.Background(), "test.retry_after_too_long", func(ctx context.Context) error {
+		calls++
+		return err
+	})
+
+	// 待たずに、Retry-After を持つ元のエラーを返す
+	if !errors.Is(got, err) {
+		t.Errorf("Do() error = %v, want %v", got, err)
+	}
+	var retryAfter RetryAfterError
+	if !errors.As(got, &retryAfter) || retryAfter.RetryAfter() != time.Hour {
+		t.Errorf("Do() error = %v, want to keep the Retry-After of 1h", got)
+	}
+	if calls != 1 || len(*waits) != 0 {
+		t.Errorf("calls = %d, waits = %v, want a single call without waiting", calls, *waits)
+	}
+}
+
+func TestPolicy_Do_RespectsDeadline(t *testing.T) {
+	policy, waits := newTestPolicy(3)
+	policy.InitialBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	err := policy.Do(ctx, "test.deadline", func(ctx context.Context) error {
+		calls++
+		return domainerrors.New(domainerrors.KindQuotaExhausted, "429")
+	})
+
+	if err == nil {
+		t.Fatalf("Do() error = nil, want error")
+	}
+	if calls != 1 || len(*waits) != 0 {
+		t.Errorf("calls = %d, waits = %v, want to give up before waiting past the deadline", calls, *waits)
+	}
+}
+
+func TestPolicy_Do_StopsWhenCanceled(t *testing.T) {
+	policy, _ := newTestPolicy(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	_ = policy.Do(ctx, "test.canceled", func(ctx context.Context) error {
+		calls++
+		cancel()
+		return domainerrors.New(domainerrors.KindUpstreamTimeout, "timeout")
+	})
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv("RETRY_MAX_ATTEMPTS", "5")
+	t.Setenv("RETRY_INITIAL_BACKOFF", "200ms")
+	t.Setenv("RETRY_MAX_BACKOFF", "5s")
+	t.Setenv("RETRY_ON", "quota_exhausted, upstream_timeout")
+
+	policy, err := PolicyFromEnv()
+	if err != nil {
+		t.Fatalf("PolicyFromEnv() error = %v", err)
+	}
+
+	if policy.MaxAttempts != 5 || policy.InitialBackoff != 200*time.Millisecond || policy.MaxBackoff != 5*time.Second {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+	want := []domainerrors.Kind{domainerrors.KindQuotaExhausted, domainerrors.KindUpstreamTimeout}
+	if len(policy.RetryableKinds) != len(want) || policy.RetryableKinds[0] != want[0] || policy.RetryableKinds[1] != want[1] {
+		t.Errorf("RetryableKinds = %v, want %v", policy.RetryableKinds, want)
+	}
+
+	t.Setenv("RETRY_ON", "safety_blocked")
+	if _, err := PolicyFromEnv(); err == nil {
+		t.Errorf("Expected error for non-retryable kind")
+	}
+}
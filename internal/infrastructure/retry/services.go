@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// 外部サービスの呼び出しをリトライ方針に従って再試行するデコレーター
+
+type vertexAIService struct {
+	repositories.VertexAIService
+	policy Policy
+}
+
+func NewVertexAIService(service repositories.VertexAIService, policy Policy) repositories.VertexAIService {
+	return &vertexAIService{VertexAIService: service, policy: policy}
+}
+
+func (s *vertexAIService) GenerateTryOn(ctx context.Context, request *entities.TryOnRequest) (*entities.TryOnResult, error) {
+	var result *entities.TryOnResult
+	err := s.policy.Do(ctx, "vertex_ai.generate_try_on", func(ctx context.Context) error {
+		var err error
+		result, err = s.VertexAIService.GenerateTryOn(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type imagenAIService struct {
+	repositories.ImagenAIService
+	policy Policy
+}
+
+func NewImagenAIService(service repositories.ImagenAIService, policy Policy) repositories.ImagenAIService {
+	return &imagenAIService{ImagenAIService: service, policy: policy}
+}
+
+func (s *imagenAIService) GenerateImage(ctx context.Context, request *entities.ImagenRequest) (*entities.ImagenResult, error) {
+	var result *entities.ImagenResult
+	err := s.policy.Do(ctx, "imagen.generate_image", func(ctx context.Context) error {
+		var err error
+		result, err = s.ImagenAIService.GenerateImage(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type nanobananaAIService struct {
+	service repositories.NanobananaAIService
+	policy  Policy
+}
+
+func NewNanobananaAIService(service repositories.NanobananaAIService, policy Policy) repositories.NanobananaAIService {
+	return &nanobananaAIService{service: service, policy: policy}
+}
+
+func (s *nanobananaAIService) ModifyImage(ctx context.Context, request *entities.NanobananaModifyRequest) (*entities.NanobananaResult, error) {
+	var result *entities.NanobananaResult
+	err := s.policy.Do(ctx, "nanobanana.modify_image", func(ctx context.Context) error {
+		var err error
+		result, err = s.service.ModifyImage(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+type textAIService struct {
+	service repositories.TextAIService
+	policy  Policy
+}
+
+func NewTextAIService(service repositories.TextAIService, policy Policy) repositories.TextAIService {
+	return &textAIService{service: service, policy: policy}
+}
+
+func (s *textAIService) GenerateText(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	var result *entities.TextResult
+	err := s.policy.Do(ctx, "text.generate_text", func(ctx context.Context) error {
+		var err error
+		result, err = s.service.GenerateText(ctx, request)
+		return err
+	})
+	return result, err
+}
+
+func (s *textAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	var result *entities.TextResult
+	err := s.policy.Do(ctx, "text.translate_to_english", func(ctx context.Context) error {
+		var err error
+		result, err = s.service.TranslateToEnglish(ctx, request)
+		return err
+	})
+	return result, err
+}
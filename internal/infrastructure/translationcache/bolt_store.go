@@ -0,0 +1,103 @@
+package translationcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var translationsBucket = []byte("translation_cache")
+
+// BoltStore 翻訳をbboltに保存する。キーは Key.String()
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore 有効期限が切れた翻訳は開いたときにまとめて消す
+func NewBoltStore(db *bolt.DB) (*BoltStore, error) {
+	store := &BoltStore{db: db}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(translationsBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", translationsBucket, err)
+		}
+		return deleteExpired(bucket, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+type translationRecord struct {
+	Text      string    `json:"text"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+func (s *BoltStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	var (
+		entry Entry
+		found bool
+	)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(translationsBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		var row translationRecord
+		if err := json.Unmarshal(v, &row); err != nil {
+			return fmt.Errorf("failed to unmarshal translation %s: %w", key, err)
+		}
+		entry = Entry(row)
+		found = true
+		return nil
+	})
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return entry, found, nil
+}
+
+func (s *BoltStore) Put(ctx context.Context, key string, entry Entry) error {
+	data, err := json.Marshal(translationRecord(entry))
+	if err != nil {
+		return fmt.Errorf("failed to marshal translation: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(translationsBucket).Put([]byte(key), data)
+	})
+}
+
+func (s *BoltStore) Delete(ctx context.Context, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(translationsBucket).Delete([]byte(key))
+	})
+}
+
+// deleteExpired 有効期限が切れた翻訳を消す（読めない値も消す）
+func deleteExpired(bucket *bolt.Bucket, now time.Time) error {
+	var keys [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var row translationRecord
+		if err := json.Unmarshal(v, &row); err != nil || Entry(row).expired(now) {
+			keys = append(keys, bytes.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
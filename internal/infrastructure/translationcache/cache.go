@@ -0,0 +1,207 @@
+package translationcache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metrics キャッシュのヒット・ミスの回数（/debug/vars の translation_cache で確認できる）。
+// hits は store_hits（メモリになく永続化先にあった件数）を含む
+var metrics = expvar.NewMap("translation_cache")
+
+// Config メモリに置く件数と有効期限
+type Config struct {
+	// Size メモリに置く翻訳の件数。超えた分は最も使われていないものから捨てる
+	Size int
+	// TTL 翻訳を使い回す期間（0は無期限）
+	TTL time.Duration
+}
+
+// DefaultConfig 1000件を24時間
+func DefaultConfig() Config {
+	return Config{
+		Size: 1000,
+		TTL:  24 * time.Hour,
+	}
+}
+
+// ConfigFromEnv 環境変数 TRANSLATION_CACHE_SIZE / TRANSLATION_CACHE_TTL から読み込む
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if value := os.Getenv("TRANSLATION_CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return Config{}, fmt.Errorf("invalid TRANSLATION_CACHE_SIZE: %q", value)
+		}
+		config.Size = size
+	}
+
+	if value := os.Getenv("TRANSLATION_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return Config{}, fmt.Errorf("invalid TRANSLATION_CACHE_TTL: %q", value)
+		}
+		config.TTL = ttl
+	}
+
+	return config, nil
+}
+
+// Key 翻訳を使い回す単位。テンプレートを変えた場合は TemplateVersion が変わり、以前の翻訳は使わない
+type Key struct {
+	Prompt string
+	// 生成先のモデルの系統（翻訳に使うテンプレートの種類）
+	Family          string
+	TemplateVersion string
+}
+
+// String プロンプトの長さによらない固定長のキー
+func (k Key) String() string {
+	sum := sha256.Sum256([]byte(k.TemplateVersion + "\x00" + k.Family + "\x00" + k.Prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// Entry キャッシュした翻訳
+type Entry struct {
+	Text string
+	// ゼロ値は無期限
+	ExpiresAt time.Time
+}
+
+func (e Entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Store 再起動後も翻訳を使い回すための永続化先
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Put(ctx context.Context, key string, entry Entry) error
+	Delete(ctx context.Context, key string) error
+}
+
+type lruItem struct {
+	key   string
+	entry Entry
+}
+
+// Cache 翻訳のLRUキャッシュ。メモリにない翻訳は永続化先（nilの場合はなし）から読み込む。
+// 永続化先の読み書きに失敗しても翻訳は止めない。
+type Cache struct {
+	config Config
+	store  Store
+	now    func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // 先頭が最近使ったもの
+	items map[string]*list.Element
+}
+
+func New(config Config, store Store) *Cache {
+	return &Cache{
+		config: config,
+		store:  store,
+		now:    time.Now,
+		order:  list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+// Get 有効期限内の翻訳を返す
+func (c *Cache) Get(ctx context.Context, key Key) (string, bool) {
+	id := key.String()
+
+	if entry, ok := c.getMemory(id); ok {
+		metrics.Add("hits", 1)
+		return entry.Text, true
+	}
+
+	if c.store != nil {
+		entry, ok, err := c.store.Get(ctx, id)
+		if err != nil {
+			slog.Warn("Failed to read translation cache", "error", err)
+		} else if ok && entry.expired(c.now()) {
+			if err := c.store.Delete(context.WithoutCancel(ctx), id); err != nil {
+				slog.Warn("Failed to delete expired translation", "error", err)
+			}
+		} else if ok {
+			c.putMemory(id, entry)
+			metrics.Add("hits", 1)
+			metrics.Add("store_hits", 1)
+			return entry.Text, true
+		}
+	}
+
+	metrics.Add("misses", 1)
+	return "", false
+}
+
+// Put 翻訳をメモリと永続化先に保存する
+func (c *Cache) Put(ctx context.Context, key Key, text string) {
+	id := key.String()
+
+	entry := Entry{Text: text}
+	if c.config.TTL > 0 {
+		entry.ExpiresAt = c.now().Add(c.config.TTL)
+	}
+	c.putMemory(id, entry)
+
+	if c.store != nil {
+		if err := c.store.Put(context.WithoutCancel(ctx), id, entry); err != nil {
+			slog.Warn("Failed to write translation cache", "error", err)
+		}
+	}
+}
+
+// Len メモリに置いている翻訳の件数
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) getMemory(id string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[id]
+	if !ok {
+		return Entry{}, false
+	}
+	item := element.Value.(*lruItem)
+	if item.entry.expired(c.now()) {
+		c.order.Remove(element)
+		delete(c.items, id)
+		return Entry{}, false
+	}
+	c.order.MoveToFront(element)
+	return item.entry, true
+}
+
+func (c *Cache) putMemory(id string, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[id]; ok {
+		element.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[id] = c.order.PushFront(&lruItem{key: id, entry: entry})
+	for c.order.Len() > max(1, c.config.Size) {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+		metrics.Add("evictions", 1)
+	}
+}
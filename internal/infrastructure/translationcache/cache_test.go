@@ -0,0 +1,181 @@
+package translationcache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// countingTextAIService 翻訳を呼んだ回数を数える
+type countingTextAIService struct {
+	repositories.TextAIService
+	calls int
+}
+
+func (s *countingTextAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	s.calls++
+	result := entities.NewTextResult("translated: " + request.Prompt())
+	result.SetUsage(entities.TokenUsage{Model: "gemini", InputTokens: 10, OutputTokens: 5})
+	return result, nil
+}
+
+func family(model string) string {
+	if model == "veo-3" || model == "veo-2" {
+		return "veo"
+	}
+	return "image"
+}
+
+func openTestDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := New(Config{Size: 2}, nil)
+	ctx := context.Background()
+	a, b, c := Key{Prompt: "a"}, Key{Prompt: "b"}, Key{Prompt: "c"}
+
+	cache.Put(ctx, a, "A")
+	cache.Put(ctx, b, "B")
+	// aを使ったので、次に追い出されるのはb
+	if _, ok := cache.Get(ctx, a); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	cache.Put(ctx, c, "C")
+
+	if _, ok := cache.Get(ctx, b); ok {
+		t.Error("Get(b) hit, want evicted")
+	}
+	for _, key := range []Key{a, c} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Errorf("Get(%s) missed", key.Prompt)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	store, err := NewBoltStore(openTestDB(t))
+	if err != nil {
+		t.Fatalf("NewBoltStore() error = %v", err)
+	}
+	cache := New(Config{Size: 10, TTL: time.Hour}, store)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+	key := Key{Prompt: "猫"}
+
+	cache.Put(ctx, key, "cat")
+	cache.now = func() time.Time { return now.Add(59 * time.Minute) }
+	if text, ok := cache.Get(ctx, key); !ok || text != "cat" {
+		t.Fatalf("Get() = %q, %v, want cat", text, ok)
+	}
+
+	cache.now = func() time.Time { return now.Add(time.Hour) }
+	if _, ok := cache.Get(ctx, key); ok {
+		t.Error("Get() after TTL hit, want miss")
+	}
+	// 期限切れの翻訳は永続化先からも消える
+	if _, ok, _ := store.Get(ctx, key.String()); ok {
+		t.Error("expired translation remains in the store")
+	}
+}
+
+func TestCache_ReadsThroughStore(t *testing.T) {
+	db := openTestDB(t)
+	store, err := NewBoltStore(db)
+	if err != nil {
+		t.Fatalf("NewBoltStore() error = %v", err)
+	}
+	ctx := context.Background()
+	key := Key{Prompt: "犬", Family: "image", TemplateVersion: "1"}
+
+	New(DefaultConfig(), store).Put(ctx, key, "dog")
+
+	// 再起動後（メモリが空）でも永続化先の翻訳を使う
+	restarted := New(DefaultConfig(), store)
+	if text, ok := restarted.Get(ctx, key); !ok || text != "dog" {
+		t.Fatalf("Get() = %q, %v, want dog", text, ok)
+	}
+	if restarted.Len() != 1 {
+		t.Errorf("Len() = %d, want the store hit to be kept in memory", restarted.Len())
+	}
+
+	// 開き直すと期限切れの翻訳を消す
+	if err := store.Put(ctx, "expired", Entry{Text: "old", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := NewBoltStore(db); err != nil {
+		t.Fatalf("NewBoltStore() error = %v", err)
+	}
+	if _, ok, _ := store.Get(ctx, "expired"); ok {
+		t.Error("expired translation was not deleted on open")
+	}
+	if _, ok, _ := store.Get(ctx, key.String()); !ok {
+		t.Error("valid translation was deleted on open")
+	}
+}
+
+func TestTextAIService_TranslateToEnglish(t *testing.T) {
+	inner := &countingTextAIService{}
+	ctx := context.Background()
+	cache := New(DefaultConfig(), nil)
+	service := NewTextAIService(inner, cache, family, "1")
+
+	translate := func(prompt, model string) *entities.TextResult {
+		t.Helper()
+		result, err := service.TranslateToEnglish(ctx, entities.NewTextRequest(prompt, model))
+		if err != nil {
+			t.Fatalf("TranslateToEnglish() error = %v", err)
+		}
+		return result
+	}
+
+	first := translate("夕焼けの海", "veo-3")
+	if first.Usage().InputTokens != 10 {
+		t.Errorf("first usage = %+v, want the model's usage", first.Usage())
+	}
+
+	// 同じ系統のモデルは翻訳を使い回し、トークンを使わない
+	second := translate("夕焼けの海", "veo-2")
+	if inner.calls != 1 {
+		t.Errorf("calls = %d, want 1", inner.calls)
+	}
+	if second.Text() != first.Text() || second.Usage() != (entities.TokenUsage{}) {
+		t.Errorf("cached result = %q %+v, want the same text without usage", second.Text(), second.Usage())
+	}
+
+	// 系統・プロンプト・テンプレートの版が違えば翻訳し直す
+	translate("夕焼けの海", "imagen-4")
+	translate("朝焼けの海", "veo-3")
+	NewTextAIService(inner, cache, family, "2").TranslateToEnglish(ctx, entities.NewTextRequest("夕焼けの海", "veo-3"))
+	if inner.calls != 4 {
+		t.Errorf("calls = %d, want 4", inner.calls)
+	}
+
+	// 応答の形式を指定した問い合わせは使い回さない
+	request := entities.NewImageTextRequest("夕焼けの海", "veo-3", nil, "application/json")
+	for range 2 {
+		if _, err := service.TranslateToEnglish(ctx, request); err != nil {
+			t.Fatalf("TranslateToEnglish() error = %v", err)
+		}
+	}
+	if inner.calls != 6 {
+		t.Errorf("calls = %d, want 6", inner.calls)
+	}
+}
@@ -0,0 +1,59 @@
+package translationcache
+
+import (
+	"context"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+)
+
+// 同じプロンプトの英語への翻訳を使い回すデコレーター。
+// キャッシュから返した翻訳はGeminiを呼ばないため、使ったトークン数はゼロになる。
+
+type textAIService struct {
+	repositories.TextAIService
+	cache           *Cache
+	family          func(model string) string
+	templateVersion string
+}
+
+// NewTextAIService familyは生成先のモデル名から翻訳テンプレートの系統を求める関数、
+// templateVersionは翻訳テンプレートの版
+func NewTextAIService(
+	service repositories.TextAIService,
+	cache *Cache,
+	family func(model string) string,
+	templateVersion string,
+) repositories.TextAIService {
+	return &textAIService{
+		TextAIService:   service,
+		cache:           cache,
+		family:          family,
+		templateVersion: templateVersion,
+	}
+}
+
+func (s *textAIService) TranslateToEnglish(ctx context.Context, request *entities.TextRequest) (*entities.TextResult, error) {
+	// 画像付き・応答形式の指定がある問い合わせはプロンプトだけでは決まらないので使い回さない
+	if len(request.Images()) > 0 || request.ResponseMimeType() != "" {
+		return s.TextAIService.TranslateToEnglish(ctx, request)
+	}
+
+	key := Key{
+		Prompt:          request.Prompt(),
+		Family:          s.family(request.Model()),
+		TemplateVersion: s.templateVersion,
+	}
+	if text, ok := s.cache.Get(ctx, key); ok {
+		return entities.NewTextResult(text), nil
+	}
+
+	result, err := s.TextAIService.TranslateToEnglish(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.Text() != "" {
+		s.cache.Put(ctx, key, result.Text())
+	}
+	return result, nil
+}
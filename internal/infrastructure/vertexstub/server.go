@@ -0,0 +1,319 @@
+package vertexstub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/domain/valueobjects"
+	"tryon-demo/internal/infrastructure/fake"
+	"tryon-demo/model"
+)
+
+// PredictPath Vertex AI の predict エンドポイントと同じパス
+const PredictPath = "/v1/projects/{project}/locations/{location}/publishers/google/models/{model}:predict"
+
+// Config スタブサーバーの動作設定
+type Config struct {
+	// Fake 画像生成の待ち時間・エラー注入（FAKE_LATENCY / FAKE_FAIL_EVERY）
+	Fake fake.Config
+	// FailStatus 注入したエラーを返すときのHTTPステータス（0の場合は500）
+	FailStatus int
+	// RetryAfter 注入したエラーに付けるRetry-Afterヘッダー（秒、0の場合は付けない）
+	RetryAfter int
+	// Token 指定した場合、Bearerトークンが一致しないリクエストは401にする
+	Token string
+	// Storage storageUri指定時に生成画像を書き込む先（nilの場合はstorageUriを400にする）
+	Storage repositories.ObjectStorage
+}
+
+// Server Virtual Try-On の predict API を模倣する。画像はフェイクバックエンドで合成する。
+type Server struct {
+	config    Config
+	generator repositories.VertexAIService
+}
+
+func NewServer(config Config) *Server {
+	if config.FailStatus == 0 {
+		config.FailStatus = http.StatusInternalServerError
+	}
+	return &Server{
+		config:    config,
+		generator: fake.NewVertexAIService(config.Fake, nil),
+	}
+}
+
+// Handler ルーティング済みのハンドラーを返す
+func (s *Server) Handler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(strings.Replace(PredictPath, "{model}", "{model:[^/:]+}", 1), s.handlePredict).Methods("POST")
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.sendError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint: %s %s", r.Method, r.URL.Path))
+	})
+	return r
+}
+
+type predictRequest struct {
+	Instances  []predictInstance  `json:"instances"`
+	Parameters *predictParameters `json:"parameters"`
+}
+
+type predictInstance struct {
+	PersonImage   *imageInput  `json:"personImage"`
+	ProductImages []imageInput `json:"productImages"`
+}
+
+type imageInput struct {
+	Image struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+	} `json:"image"`
+}
+
+type predictParameters struct {
+	AddWatermark     *bool  `json:"addWatermark"`
+	BaseSteps        *int   `json:"baseSteps"`
+	PersonGeneration string `json:"personGeneration"`
+	SafetySetting    string `json:"safetySetting"`
+	SampleCount      *int   `json:"sampleCount"`
+	Seed             int    `json:"seed"`
+	StorageURI       string `json:"storageUri"`
+	OutputOptions    *struct {
+		MimeType           string `json:"mimeType"`
+		CompressionQuality *int   `json:"compressionQuality"`
+	} `json:"outputOptions"`
+}
+
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	log.Printf("[vertex-stub] predict project=%s location=%s model=%s", vars["project"], vars["location"], vars["model"])
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		s.sendError(w, http.StatusUnauthorized, "Request is missing required authentication credential.")
+		return
+	}
+	if s.config.Token != "" && token != s.config.Token {
+		s.sendError(w, http.StatusUnauthorized, "Request had invalid authentication credentials.")
+		return
+	}
+
+	var body predictRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload received: %v", err))
+		return
+	}
+
+	request, err := s.toTryOnRequest(&body)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var storageURI string
+	if body.Parameters != nil {
+		storageURI = body.Parameters.StorageURI
+	}
+	if storageURI != "" && (s.config.Storage == nil || !s.config.Storage.Supports(storageURI)) {
+		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("unsupported storageUri: %q", storageURI))
+		return
+	}
+
+	result, err := s.generator.GenerateTryOn(r.Context(), request)
+	if err != nil {
+		if errors.Is(err, fake.ErrInjected) {
+			if s.config.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(s.config.RetryAfter))
+			}
+			s.sendError(w, s.config.FailStatus, err.Error())
+			return
+		}
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := model.VirtualTryOnResponse{
+		Predictions: make([]model.Prediction, len(result.Images())),
+	}
+	for i, image := range result.Images() {
+		// storageUri指定時は本番と同じく画像を書き込み、保存先のURIのみを返す
+		if storageURI != "" {
+			uri := valueobjects.StorageSampleURI(storageURI, i, image.Format())
+			if err := s.config.Storage.Put(r.Context(), uri, image.Data(), image.MimeType()); err != nil {
+				s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("failed to write output: %v", err))
+				return
+			}
+			response.Predictions[i] = model.Prediction{
+				MimeType: image.MimeType(),
+				GcsUri:   uri,
+			}
+			continue
+		}
+
+		response.Predictions[i] = model.Prediction{
+			MimeType:           image.MimeType(),
+			BytesBase64Encoded: base64.StdEncoding.EncodeToString(image.Data()),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[vertex-stub] failed to encode response: %v", err)
+	}
+}
+
+// toTryOnRequest 本番APIと同じ制約で入力を検証し、フェイク生成用のリクエストにする
+func (s *Server) toTryOnRequest(body *predictRequest) (*entities.TryOnRequest, error) {
+	if len(body.Instances) != 1 {
+		return nil, fmt.Errorf("exactly one instance is required, got %d", len(body.Instances))
+	}
+	instance := body.Instances[0]
+
+	if instance.PersonImage == nil {
+		return nil, fmt.Errorf("instances[0].personImage is required")
+	}
+	person, err := decodeImageInput(instance.PersonImage)
+	if err != nil {
+		return nil, fmt.Errorf("instances[0].personImage: %w", err)
+	}
+
+	if len(instance.ProductImages) != 1 {
+		return nil, fmt.Errorf("exactly one product image is required, got %d", len(instance.ProductImages))
+	}
+	garment, err := decodeImageInput(&instance.ProductImages[0])
+	if err != nil {
+		return nil, fmt.Errorf("instances[0].productImages[0]: %w", err)
+	}
+
+	parameters, err := toTryOnParameters(body.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.NewTryOnRequest(person, garment, parameters)
+}
+
+// toTryOnParameters 省略された項目は本番APIと同じ既定値にする
+func toTryOnParameters(p *predictParameters) (*valueobjects.TryOnParameters, error) {
+	defaults := valueobjects.DefaultTryOnParameters()
+	if p == nil {
+		return defaults, nil
+	}
+
+	addWatermark := defaults.AddWatermark()
+	if p.AddWatermark != nil {
+		addWatermark = *p.AddWatermark
+	}
+	baseSteps := defaults.BaseSteps()
+	if p.BaseSteps != nil {
+		baseSteps = *p.BaseSteps
+	}
+	sampleCount := defaults.SampleCount()
+	if p.SampleCount != nil {
+		sampleCount = *p.SampleCount
+	}
+
+	personGeneration := defaults.PersonGeneration()
+	switch valueobjects.PersonGeneration(p.PersonGeneration) {
+	case "":
+	case valueobjects.AllowAdult, valueobjects.AllowAll, valueobjects.DontAllow:
+		personGeneration = valueobjects.PersonGeneration(p.PersonGeneration)
+	default:
+		return nil, fmt.Errorf("unsupported personGeneration: %q", p.PersonGeneration)
+	}
+
+	safetySetting := defaults.SafetySetting()
+	switch valueobjects.SafetySetting(p.SafetySetting) {
+	case "":
+	case valueobjects.BlockMediumAndAbove, valueobjects.BlockLowAndAbove, valueobjects.BlockOnlyHigh, valueobjects.BlockNone:
+		safetySetting = valueobjects.SafetySetting(p.SafetySetting)
+	default:
+		return nil, fmt.Errorf("unsupported safetySetting: %q", p.SafetySetting)
+	}
+
+	mimeType := defaults.OutputMimeType()
+	compressionQuality := defaults.CompressionQuality()
+	if p.OutputOptions != nil {
+		switch valueobjects.MimeType(p.OutputOptions.MimeType) {
+		case "":
+		case valueobjects.MimeTypePNG, valueobjects.MimeTypeJPEG:
+			mimeType = valueobjects.MimeType(p.OutputOptions.MimeType)
+		default:
+			return nil, fmt.Errorf("unsupported outputOptions.mimeType: %q", p.OutputOptions.MimeType)
+		}
+		if p.OutputOptions.CompressionQuality != nil {
+			compressionQuality = *p.OutputOptions.CompressionQuality
+		}
+	}
+
+	if p.Seed != 0 && addWatermark {
+		return nil, fmt.Errorf("seed is not supported when addWatermark is true")
+	}
+
+	// storageUriへの書き込みはスタブ側で行うため、生成には渡さない
+	return valueobjects.NewTryOnParameters(
+		addWatermark,
+		baseSteps,
+		personGeneration,
+		safetySetting,
+		sampleCount,
+		p.Seed,
+		"",
+		mimeType,
+		compressionQuality,
+	)
+}
+
+func decodeImageInput(input *imageInput) (*valueobjects.ImageData, error) {
+	if input.Image.BytesBase64Encoded == "" {
+		return nil, fmt.Errorf("image.bytesBase64Encoded is required")
+	}
+	data, err := base64.StdEncoding.DecodeString(input.Image.BytesBase64Encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 image: %w", err)
+	}
+	return valueobjects.NewImageData(data)
+}
+
+// sendError Google API と同じ形式のエラーを返す
+func (s *Server) sendError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    statusCode,
+			"message": message,
+			"status":  grpcStatus(statusCode),
+		},
+	})
+}
+
+// grpcStatus HTTPステータスに対応するGoogle APIのステータス名
+func grpcStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	case http.StatusGatewayTimeout:
+		return "DEADLINE_EXCEEDED"
+	default:
+		return "INTERNAL"
+	}
+}
@@ -2,33 +2,37 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/gorilla/mux"
 
 	appservices "tryon-demo/internal/application/services"
 	"tryon-demo/internal/application/usecases"
+	domainrepos "tryon-demo/internal/domain/repositories"
 	domainservices "tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
 	"tryon-demo/internal/infrastructure/api"
+	"tryon-demo/internal/infrastructure/auth"
 	"tryon-demo/internal/infrastructure/external"
+	"tryon-demo/internal/infrastructure/imagecodec"
+	"tryon-demo/internal/infrastructure/limiter"
 	"tryon-demo/internal/infrastructure/repositories"
-	"tryon-demo/internal/infrastructure/services"
+	"tryon-demo/internal/infrastructure/retry"
+	"tryon-demo/internal/infrastructure/translationcache"
 )
 
 func main() {
-	geminiApiKey := os.Getenv("GEMINI_API_KEY")
-	if geminiApiKey == "" {
-		log.Fatal("環境変数 GEMINI_API_KEY が未設定です")
+	// 生成AIの接続先（google: Vertex AI / Gemini API、fake: オフライン用のフェイク）
+	backendType := os.Getenv("BACKEND")
+	if backendType == "" {
+		backendType = "google"
 	}
 
 	// 環境変数から設定を取得
-	projectID := os.Getenv("PROJECT_ID")
-	if projectID == "" {
-		log.Fatal("環境変数 PROJECT_ID が未設定です")
-	}
-
 	location := os.Getenv("LOCATION")
 	if location == "" {
 		location = "us-central1"
@@ -41,73 +45,354 @@ func main() {
 
 	useSDK := os.Getenv("USE_SDK") == "true"
 
+	// 永続化データの保存先（TRYON_REPOSITORY=bolt の場合に使用）
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+
+	// Storage URI指定時の生成画像の保存先（local:// の場合）
+	objectStorageDir := os.Getenv("OBJECT_STORAGE_DIR")
+	if objectStorageDir == "" {
+		objectStorageDir = filepath.Join(dataDir, "objects")
+	}
+
+	tryOnRepositoryType := os.Getenv("TRYON_REPOSITORY")
+	if tryOnRepositoryType == "" {
+		tryOnRepositoryType = "memory"
+	}
+
+	// 生成履歴の保存先（未指定の場合は TRYON_REPOSITORY と同じ）
+	historyRepositoryType := os.Getenv("HISTORY_REPOSITORY")
+	if historyRepositoryType == "" {
+		historyRepositoryType = tryOnRepositoryType
+	}
+
+	// 衣服画像の種類の自動判定（Geminiを呼ぶため、未指定の場合は google のときのみ有効）
+	garmentClassification := os.Getenv("GARMENT_CLASSIFICATION")
+	if garmentClassification == "" {
+		garmentClassification = "off"
+		if backendType == "google" {
+			garmentClassification = "on"
+		}
+	}
+
+	// 試着前に人物が写っているかの確認（Geminiを呼ぶため、未指定の場合は google のときのみ有効）
+	personDetection := os.Getenv("PERSON_DETECTION")
+	if personDetection == "" {
+		personDetection = "off"
+		if backendType == "google" {
+			personDetection = "on"
+		}
+	}
+
+	// パラメータの検証方法（strict: 不正な値は400を返す / lenient: 不正な値は既定値に置き換える）
+	parameterValidation := appservices.ValidationMode(os.Getenv("PARAMETER_VALIDATION"))
+	if parameterValidation == "" {
+		parameterValidation = appservices.ValidationStrict
+	}
+	if !parameterValidation.IsValid() {
+		log.Fatalf("環境変数 PARAMETER_VALIDATION の値が不正です: %s (strict または lenient)", parameterValidation)
+	}
+
+	// JSONのリクエストでURL指定された画像の取得先にプライベートネットワークを許可するか（ローカルでの検証用）
+	imageURLAllowPrivate := os.Getenv("IMAGE_URL_ALLOW_PRIVATE")
+	if imageURLAllowPrivate == "" {
+		imageURLAllowPrivate = "off"
+	}
+
+	// 使えるモデルの一覧（組み込みのモデルに重ねて読み込むJSONファイル）
+	modelCatalogPath := os.Getenv("MODEL_CATALOG")
+
+	// 利用量の集計の保存先（未指定の場合は TRYON_REPOSITORY と同じ）
+	usageRepositoryType := os.Getenv("USAGE_REPOSITORY")
+	if usageRepositoryType == "" {
+		usageRepositoryType = tryOnRepositoryType
+	}
+
+	// 料金の見積もりに使う単価（モデルカタログの料金に重ねて読み込むJSONファイル）
+	priceTablePath := os.Getenv("PRICE_TABLE")
+
+	// プロンプトの翻訳のキャッシュ（off: 使わない / memory: メモリのみ / bolt: DATA_DIR にも保存する）
+	translationCacheType := os.Getenv("TRANSLATION_CACHE")
+	if translationCacheType == "" {
+		translationCacheType = "memory"
+	}
+	translationCacheConfig, err := translationcache.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load translation cache config: %v", err)
+	}
+
+	// APIキーの一覧（未指定の場合は認証しない）
+	apiKeysPath := os.Getenv("API_KEYS_FILE")
+
+	// 内部カウンター（/debug/vars）の公開（未指定の場合はAPIキーで認証するときのみ有効）
+	debugVars := os.Getenv("DEBUG_VARS")
+	if debugVars == "" {
+		debugVars = "off"
+		if apiKeysPath != "" {
+			debugVars = "on"
+		}
+	}
+
+	// 1日あたりの利用額の上限
+	usageBudget, err := usageBudgetFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load usage budget: %v", err)
+	}
+
+	log.Printf("[boot] BACKEND=%s", backendType)
 	log.Printf("[boot] Using VTO_MODEL=%s", vtoModel)
 	log.Printf("[boot] USE_SDK=%v (false=REST API, true=genai.Client)", useSDK)
+	log.Printf("[boot] TRYON_REPOSITORY=%s, HISTORY_REPOSITORY=%s, DATA_DIR=%s", tryOnRepositoryType, historyRepositoryType, dataDir)
+	log.Printf("[boot] GARMENT_CLASSIFICATION=%s, PERSON_DETECTION=%s", garmentClassification, personDetection)
+	log.Printf("[boot] OBJECT_STORAGE_DIR=%s", objectStorageDir)
+	log.Printf("[boot] PARAMETER_VALIDATION=%s", parameterValidation)
+	log.Printf("[boot] IMAGE_URL_ALLOW_PRIVATE=%s", imageURLAllowPrivate)
+	log.Printf("[boot] MODEL_CATALOG=%s", modelCatalogPath)
+	log.Printf("[boot] USAGE_REPOSITORY=%s, PRICE_TABLE=%s", usageRepositoryType, priceTablePath)
+	log.Printf("[boot] TRANSLATION_CACHE=%s, TRANSLATION_CACHE_SIZE=%d, TRANSLATION_CACHE_TTL=%s (0=no expiry)",
+		translationCacheType, translationCacheConfig.Size, translationCacheConfig.TTL)
+	log.Printf("[boot] API_KEYS_FILE=%s, DEBUG_VARS=%s", apiKeysPath, debugVars)
+	log.Printf("[boot] USAGE_DAILY_BUDGET=%v, USAGE_CLIENT_DAILY_BUDGET=%v (0=unlimited)", usageBudget.Daily, usageBudget.ClientDaily)
 
 	ctx := context.Background()
 
-	// Client Pool Service初期化
-	clientPoolService := services.NewClientPoolService(projectID, location)
-	defer clientPoolService.Close()
+	// Storage URIの読み書き（local:// はローカルのディレクトリ、gs:// はCloud Storage）。
+	// 本物のVertex AIは local:// に書き込めないため、フェイクバックエンドかスタブサーバー（REST API経由）の場合のみ受け付ける。
+	objectStorages := []domainrepos.ObjectStorage{external.NewGCSObjectStorage()}
+	allowLocalObjectStorage := backendType == "fake" || (os.Getenv("VERTEX_API_BASE_URL") != "" && !useSDK)
+	if allowLocalObjectStorage {
+		localObjectStorage, err := repositories.NewLocalObjectStorage(objectStorageDir)
+		if err != nil {
+			log.Fatalf("Failed to create object storage: %v", err)
+		}
+		objectStorages = append([]domainrepos.ObjectStorage{localObjectStorage}, objectStorages...)
+	}
+	log.Printf("[boot] local:// storage URIs enabled=%v", allowLocalObjectStorage)
+	objectStorage := repositories.NewObjectStorageRouter(objectStorages...)
+
+	// インフラ層を初期化
+	var backend *aiBackend
+	switch backendType {
+	case "google":
+		backend = newGoogleBackend(ctx, location, vtoModel, useSDK)
+	case "fake":
+		backend = newFakeBackend(objectStorage)
+	default:
+		log.Fatalf("環境変数 BACKEND の値が不正です: %s (google または fake)", backendType)
+	}
+	defer backend.Close()
 
-	// VertexAI Client取得 (TryOn用)
-	vertexClient, err := clientPoolService.VertexAIPool().GetVertexAIClient(ctx)
+	// 一時的なエラー（クォータ超過など）は外部API呼び出しを再試行する
+	retryPolicy, err := retry.PolicyFromEnv()
 	if err != nil {
-		log.Fatalf("Failed to get Vertex AI client: %v", err)
+		log.Fatalf("Failed to load retry policy: %v", err)
 	}
+	log.Printf("[boot] RETRY_MAX_ATTEMPTS=%d, RETRY_INITIAL_BACKOFF=%s, RETRY_MAX_BACKOFF=%s, RETRY_ON=%v",
+		retryPolicy.MaxAttempts, retryPolicy.InitialBackoff, retryPolicy.MaxBackoff, retryPolicy.RetryableKinds)
+	backend.withRetry(retryPolicy)
 
-	defer vertexClient.Close()
-
-	// GenAI Client取得 (Imagen/Veo用)
-	genaiClient, err := clientPoolService.GenAIPool().GetGenAIClient(ctx, geminiApiKey)
+	// モデルごとの同時実行数を制限し、超えた分は順番待ちにする
+	limiterConfig, err := limiter.ConfigFromEnv()
 	if err != nil {
-		log.Fatalf("Failed to get Gen AI client: %v", err)
+		log.Fatalf("Failed to load limiter config: %v", err)
 	}
+	log.Printf("[boot] LIMIT_CONCURRENCY=%d, LIMIT_QUEUE_SIZE=%d, LIMIT_MODEL_CONCURRENCY=%v",
+		limiterConfig.Concurrency, limiterConfig.QueueSize, limiterConfig.ModelConcurrency)
+	requestLimiter := limiter.New(limiterConfig)
+	backend.withLimiter(requestLimiter, vtoModel)
 
-	// インフラ層を初期化
+	// リポジトリ層を初期化
+	storage := newBoltStorage(dataDir)
+	defer storage.Close()
+
+	var tryOnRepository domainrepos.TryOnRepository
+	switch tryOnRepositoryType {
+	case "memory":
+		tryOnRepository = repositories.NewMemoryTryOnRepository()
+	case "bolt":
+		tryOnRepository, err = repositories.NewBoltTryOnRepository(storage.DB(), storage.BlobStore())
+		if err != nil {
+			log.Fatalf("Failed to create try-on repository: %v", err)
+		}
+	default:
+		log.Fatalf("環境変数 TRYON_REPOSITORY の値が不正です: %s (memory または bolt)", tryOnRepositoryType)
+	}
 
-	// VertexAI Service初期化
-	vertexAIService := external.NewVertexAIService(
-		projectID, location, vtoModel, useSDK, vertexClient,
+	// 保存したパラメータのプリセットは試着結果と同じ保存先に置く
+	var parameterPresetRepository domainrepos.ParameterPresetRepository
+	switch tryOnRepositoryType {
+	case "memory":
+		parameterPresetRepository = repositories.NewMemoryParameterPresetRepository()
+	case "bolt":
+		parameterPresetRepository, err = repositories.NewBoltParameterPresetRepository(storage.DB())
+		if err != nil {
+			log.Fatalf("Failed to create parameter preset repository: %v", err)
+		}
+	}
+
+	var (
+		generationRecordRepository domainrepos.GenerationRecordRepository
+		historyBlobStore           domainrepos.BlobStore
 	)
-	defer vertexAIService.Close()
+	switch historyRepositoryType {
+	case "memory":
+		generationRecordRepository = repositories.NewMemoryGenerationRecordRepository()
+		historyBlobStore = repositories.NewMemoryBlobStore(memoryHistoryBlobBytes)
+	case "bolt":
+		generationRecordRepository, err = repositories.NewBoltGenerationRecordRepository(storage.DB())
+		if err != nil {
+			log.Fatalf("Failed to create generation record repository: %v", err)
+		}
+		historyBlobStore = storage.BlobStore()
+	default:
+		log.Fatalf("環境変数 HISTORY_REPOSITORY の値が不正です: %s (memory または bolt)", historyRepositoryType)
+	}
+	veoJobRepository := repositories.NewMemoryVeoJobRepository()
 
-	// Imagen AI Service初期化
-	imagenAIService := external.NewImagenAIService(genaiClient)
-	defer imagenAIService.Close()
+	var usageRepository domainrepos.UsageRepository
+	switch usageRepositoryType {
+	case "memory":
+		usageRepository = repositories.NewMemoryUsageRepository()
+	case "bolt":
+		usageRepository, err = repositories.NewBoltUsageRepository(storage.DB())
+		if err != nil {
+			log.Fatalf("Failed to create usage repository: %v", err)
+		}
+	default:
+		log.Fatalf("環境変数 USAGE_REPOSITORY の値が不正です: %s (memory または bolt)", usageRepositoryType)
+	}
 
-	// Veo AI Service初期化
-	veoAIService := external.NewVeoAIService(genaiClient)
+	modelCatalog, err := loadModelCatalog(modelCatalogPath, vtoModel)
+	if err != nil {
+		log.Fatalf("モデルカタログの読み込みに失敗しました: %v", err)
+	}
+
+	priceTable, err := loadPriceTable(priceTablePath, modelCatalog)
+	if err != nil {
+		log.Fatalf("料金表の読み込みに失敗しました: %v", err)
+	}
 
-	// Nanobanana AI Service初期化
-	nanobananaAIService := external.NewNanobananaAIService(genaiClient)
+	// 生成の利用量と料金の見積もりを集計する。プロンプトの翻訳などで使ったGeminiのトークン数も数える
+	usageUseCase := usecases.NewUsageUseCase(usageRepository, priceTable, usageBudget)
+	backend.withMetering(usageUseCase)
 
-	// リポジトリ層を初期化
-	tryOnRepository := repositories.NewMemoryTryOnRepository()
+	// 同じプロンプトの翻訳はGeminiを呼ばずに使い回す
+	switch translationCacheType {
+	case "off":
+	case "memory":
+		backend.withTranslationCache(translationcache.New(translationCacheConfig, nil))
+	case "bolt":
+		translationStore, err := translationcache.NewBoltStore(storage.DB())
+		if err != nil {
+			log.Fatalf("Failed to create translation cache store: %v", err)
+		}
+		backend.withTranslationCache(translationcache.New(translationCacheConfig, translationStore))
+	default:
+		log.Fatalf("環境変数 TRANSLATION_CACHE の値が不正です: %s (off・memory または bolt)", translationCacheType)
+	}
+
+	// 外部APIに送る前の画像の整え方（向きの補正・メタデータ除去・縮小・透過の塗りつぶし）
+	normalizeOptions, err := normalizeOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load image normalization options: %v", err)
+	}
+	log.Printf("[boot] IMAGE_MAX_EDGE=%d, IMAGE_BACKGROUND=%s, IMAGE_JPEG_QUALITY=%d",
+		normalizeOptions.MaxEdge, formatHexColor(normalizeOptions.Background), normalizeOptions.Quality)
+
+	// アップロードされたHEIC/HEIF・AVIFをJPEG・PNGに変換するデコーダー
+	valueobjects.SetHEIFDecoders(imagecodec.NewHEIFDecoders())
 
 	// ドメイン層を初期化
-	textAIService := external.NewGeminiAIService(genaiClient)
-	tryOnDomainService := domainservices.NewTryOnDomainService(vertexAIService)
-	imagenDomainService := domainservices.NewImagenDomainService(imagenAIService, textAIService)
-	veoDomainService := domainservices.NewVeoDomainService(veoAIService, textAIService)
-	nanobananaDomainService := domainservices.NewNanobananaDomainService(nanobananaAIService, textAIService)
+	tryOnDomainService := domainservices.NewTryOnDomainService(backend.vertexAI, normalizeOptions)
+	imagenDomainService := domainservices.NewImagenDomainService(backend.imagen, backend.text)
+	veoDomainService := domainservices.NewVeoDomainService(backend.veo, backend.text, normalizeOptions)
+	nanobananaDomainService := domainservices.NewNanobananaDomainService(backend.nanobanana, backend.text, normalizeOptions)
+
+	var personDetectionService domainrepos.TextAIService
+	switch personDetection {
+	case "on":
+		personDetectionService = backend.text
+	case "off":
+	default:
+		log.Fatalf("環境変数 PERSON_DETECTION の値が不正です: %s (on または off)", personDetection)
+	}
+	tryOnPreflightService := domainservices.NewTryOnPreflightService(domainservices.DefaultPreflightRules(), normalizeOptions, personDetectionService)
+
+	var garmentClassificationService *domainservices.GarmentClassificationService
+	switch garmentClassification {
+	case "on":
+		garmentClassificationService = domainservices.NewGarmentClassificationService(backend.text)
+	case "off":
+	default:
+		log.Fatalf("環境変数 GARMENT_CLASSIFICATION の値が不正です: %s (on または off)", garmentClassification)
+	}
 
 	// アプリケーション層を初期化
-	tryOnUseCase := usecases.NewTryOnUseCase(tryOnRepository, tryOnDomainService)
-	imagenUseCase := usecases.NewImagenUseCase(imagenDomainService)
-	veoUseCase := usecases.NewVeoUseCase(veoDomainService, imagenDomainService)
-	nanobananaUseCase := usecases.NewNanobananaUseCase(nanobananaDomainService)
-	parameterService := appservices.NewParameterService()
+	historyUseCase := usecases.NewHistoryUseCase(generationRecordRepository, historyBlobStore)
+	// URL形式で返す生成結果も履歴と同じ場所に保存する（内容が同じなら共有される）
+	assetUseCase := usecases.NewAssetUseCase(historyBlobStore)
+	tryOnUseCase := usecases.NewTryOnUseCase(
+		tryOnRepository, tryOnDomainService, tryOnPreflightService, garmentClassificationService, objectStorage, historyUseCase,
+		usageUseCase, vtoModel,
+	)
+	imagenUseCase := usecases.NewImagenUseCase(imagenDomainService, historyUseCase, usageUseCase)
+	veoUseCase := usecases.NewVeoUseCase(veoDomainService, imagenDomainService, veoJobRepository, historyUseCase, usageUseCase)
+	nanobananaUseCase := usecases.NewNanobananaUseCase(nanobananaDomainService, historyUseCase, usageUseCase)
+	parameterPresetUseCase := usecases.NewParameterPresetUseCase(parameterPresetRepository)
+
+	parameterService := appservices.NewParameterService(parameterPresetUseCase, modelCatalog, objectStorage, parameterValidation)
+
+	var imageFetcherOptions []external.ImageFetcherOption
+	switch imageURLAllowPrivate {
+	case "on":
+		imageFetcherOptions = append(imageFetcherOptions, external.WithImageFetcherPrivateNetworks())
+	case "off":
+	default:
+		log.Fatalf("環境変数 IMAGE_URL_ALLOW_PRIVATE の値が不正です: %s (on または off)", imageURLAllowPrivate)
+	}
+	imageFetcher := external.NewImageFetcher(imageFetcherOptions...)
 
 	// API層を初期化
-	handler := api.NewTryOnHandler(tryOnUseCase, parameterService, location)
-	imagenHandler := api.NewImagenHandler(imagenUseCase, location)
-	veoHandler := api.NewVeoHandler(veoUseCase, location)
-	nanobananaHandler := api.NewNanobananaHandler(nanobananaUseCase, location)
+	handler := api.NewTryOnHandler(tryOnUseCase, parameterService, assetUseCase, imageFetcher, location)
+	imagenHandler := api.NewImagenHandler(imagenUseCase, parameterService, modelCatalog, assetUseCase, location)
+	veoHandler := api.NewVeoHandler(veoUseCase, modelCatalog, assetUseCase, imageFetcher, location)
+	nanobananaHandler := api.NewNanobananaHandler(nanobananaUseCase, modelCatalog, assetUseCase, imageFetcher, location)
+	historyHandler := api.NewHistoryHandler(historyUseCase)
+	assetHandler := api.NewAssetHandler(assetUseCase)
+	queueHandler := api.NewQueueHandler(requestLimiter)
+	presetHandler := api.NewPresetHandler(parameterPresetUseCase, modelCatalog)
+	parameterHandler := api.NewParameterHandler(parameterService)
+	modelHandler := api.NewModelHandler(modelCatalog)
+	usageHandler := api.NewUsageHandler(usageUseCase)
+	v1Handler := api.NewV1Handler(handler, imagenHandler, veoHandler, nanobananaHandler, modelHandler, usageHandler)
 
 	// ルートを設定
 	r := mux.NewRouter()
+	// 順番待ちの状況を問い合わせるチケット（X-Queue-Ticket）
+	r.Use(limiter.TicketMiddleware)
+	if apiKeysPath != "" {
+		// APIキーで認証し、キーごとに1分あたりのリクエスト数と1日の生成数を制限する（キーのIDで利用量・履歴を集計する）
+		keyStore, err := auth.LoadKeyStore(apiKeysPath)
+		if err != nil {
+			log.Fatalf("APIキーの読み込みに失敗しました: %v", err)
+		}
+		log.Printf("[boot] API keys loaded: %d", keyStore.Len())
+		r.Use(api.NewAuthMiddleware(keyStore, auth.NewQuotas()).Middleware)
+	} else {
+		// 利用量を集計するクライアント（X-Client-ID）
+		r.Use(api.ClientMiddleware)
+	}
 	r.HandleFunc("/", handler.HandleIndex).Methods("GET")
+	// リトライ回数などの内部カウンター（APIキーがない場合は誰でも読めるため、明示した場合のみ公開する）
+	switch debugVars {
+	case "on":
+		r.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+	case "off":
+	default:
+		log.Fatalf("環境変数 DEBUG_VARS の値が不正です: %s (on または off)", debugVars)
+	}
 	r.HandleFunc("/tryon", handler.HandleTryOn).Methods("POST")
 	r.HandleFunc("/healthz", handler.HandleHealth).Methods("GET")
 	r.HandleFunc("/api/sample-images", handler.HandleSampleImages).Methods("GET")
@@ -121,11 +406,41 @@ func main() {
 	// Veo関連のルート
 	r.HandleFunc("/veo", veoHandler.HandleVeoIndex).Methods("GET")
 	r.HandleFunc("/veo", veoHandler.HandleVeo).Methods("POST")
+	r.HandleFunc("/veo/jobs/{id}", veoHandler.HandleVeoJob).Methods("GET")
+	r.HandleFunc("/veo/jobs/{id}", veoHandler.HandleCancelVeoJob).Methods("DELETE")
 
 	// Nanobanana関連のルート
 	r.HandleFunc("/nanobanana/image-editing", nanobananaHandler.HandleNanobananaIndex).Methods("GET")
 	r.HandleFunc("/nanobanana/image-editing", nanobananaHandler.HandleNanobanana).Methods("POST")
 
+	// 生成結果ファイルの配信
+	r.HandleFunc("/api/assets/{id}", assetHandler.HandleAsset).Methods("GET", "HEAD")
+
+	// 生成履歴関連のルート
+	r.HandleFunc("/history", historyHandler.HandleHistoryIndex).Methods("GET")
+	r.HandleFunc("/api/history", historyHandler.HandleListHistory).Methods("GET")
+	r.HandleFunc("/api/history/{id}", historyHandler.HandleGetHistory).Methods("GET")
+	r.HandleFunc("/api/history/{id}/{role:inputs|outputs}/{index:[0-9]+}", historyHandler.HandleHistoryAsset).Methods("GET")
+
+	// パラメータの一覧とプリセット
+	r.HandleFunc("/api/parameters/schema", parameterHandler.HandleParameterSchema).Methods("GET")
+	r.HandleFunc("/api/models", modelHandler.HandleListModels).Methods("GET")
+	r.HandleFunc("/api/presets", presetHandler.HandleListPresets).Methods("GET")
+	r.HandleFunc("/api/presets", presetHandler.HandleCreatePreset).Methods("POST")
+	r.HandleFunc("/api/presets/{id}", presetHandler.HandleGetPreset).Methods("GET")
+	r.HandleFunc("/api/presets/{id}", presetHandler.HandleUpdatePreset).Methods("PUT")
+	r.HandleFunc("/api/presets/{id}", presetHandler.HandleDeletePreset).Methods("DELETE")
+
+	// 利用量と料金の見積もり
+	r.HandleFunc("/api/usage", usageHandler.HandleUsage).Methods("GET")
+
+	// 順番待ちの状況
+	r.HandleFunc("/api/queue", queueHandler.HandleQueueStats).Methods("GET")
+	r.HandleFunc("/api/queue/{ticket}", queueHandler.HandleTicketStatus).Methods("GET")
+
+	// バージョン付きのAPI（/api/v1）とOpenAPIドキュメント（/api/v1/openapi.json）
+	v1Handler.RegisterRoutes(r)
+
 	// サーバーを起動
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -133,7 +448,7 @@ func main() {
 	}
 
 	log.Printf("Starting server on port %s", port)
-	log.Printf("Project: %s, Location: %s, Model: %s", projectID, location, vtoModel)
+	log.Printf("Backend: %s, Location: %s, Model: %s", backendType, location, vtoModel)
 	log.Printf("API Mode: %s", func() string {
 		if !useSDK {
 			return "REST API"
@@ -7,10 +7,20 @@ type VirtualTryOnResponse struct {
 
 // Prediction represents a single prediction result
 type Prediction struct {
-	MimeType            string `json:"mimeType"`
-	BytesBase64Encoded  string `json:"bytesBase64Encoded"`
-	// Storage URI指定時に返される保存先情報
-	StorageUri          string `json:"storageUri,omitempty"`
+	MimeType           string `json:"mimeType"`
+	BytesBase64Encoded string `json:"bytesBase64Encoded,omitempty"`
+	// Storage URI指定時に返される保存先（画像データは含まれない）
+	GcsUri string `json:"gcsUri,omitempty"`
+	// Storage URI指定時に返される保存先情報（古い形式）
+	StorageUri string `json:"storageUri,omitempty"`
 	// その他のメタデータフィールド
-	SafetyAttributes    map[string]interface{} `json:"safetyAttributes,omitempty"`
+	SafetyAttributes map[string]interface{} `json:"safetyAttributes,omitempty"`
+}
+
+// OutputURI 保存先のURI（画像データで返された場合は空）
+func (p Prediction) OutputURI() string {
+	if p.GcsUri != "" {
+		return p.GcsUri
+	}
+	return p.StorageUri
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/services"
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// modelCatalogFile MODEL_CATALOG で指定するJSONファイル。組み込みのモデルに重ねて読み込む
+type modelCatalogFile struct {
+	// 追加するモデル（同じIDの組み込みのモデルは置き換える）
+	Models []modelCatalogEntry `json:"models"`
+	// 生成機能ごとの既定のモデル（"imagen": "imagen-4.0-generate-001" など）
+	Defaults map[entities.GeneratorType]string `json:"defaults"`
+	// 使わせないモデルのID
+	Disabled []string `json:"disabled"`
+}
+
+type modelCatalogEntry struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Generator    entities.GeneratorType `json:"generator"`
+	Capabilities struct {
+		MaxImages      int      `json:"maxImages"`
+		MaxInputImages int      `json:"maxInputImages"`
+		AspectRatios   []string `json:"aspectRatios"`
+		Seed           bool     `json:"seed"`
+		Audio          bool     `json:"audio"`
+		CandidateCount int      `json:"candidateCount"`
+	} `json:"capabilities"`
+	// 料金（USD）。分からない場合は省略する
+	Price *struct {
+		Amount float64            `json:"amount"`
+		Unit   entities.PriceUnit `json:"unit"`
+	} `json:"price"`
+}
+
+func (e modelCatalogEntry) toModel() entities.GenerativeModel {
+	model := entities.GenerativeModel{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Generator:   e.Generator,
+		Capabilities: entities.ModelCapabilities{
+			MaxImages:      e.Capabilities.MaxImages,
+			MaxInputImages: e.Capabilities.MaxInputImages,
+			AspectRatios:   e.Capabilities.AspectRatios,
+			Seed:           e.Capabilities.Seed,
+			Audio:          e.Capabilities.Audio,
+			CandidateCount: e.Capabilities.CandidateCount,
+		},
+	}
+	if e.Price != nil {
+		model.Price = &entities.ModelPrice{Amount: e.Price.Amount, Unit: e.Price.Unit}
+	}
+	return model
+}
+
+// loadModelCatalog 組み込みのモデルに MODEL_CATALOG のファイル（path が空の場合はなし）を重ね、
+// 試着のモデルを VTO_MODEL に合わせる（カタログにない場合は最低限の内容で登録する）
+func loadModelCatalog(path, vtoModel string) (*services.ModelCatalog, error) {
+	catalog := services.DefaultModelCatalog()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read model catalog: %w", err)
+		}
+
+		var file modelCatalogFile
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to parse model catalog %s: %w", path, err)
+		}
+
+		for _, entry := range file.Models {
+			if err := catalog.Register(entry.toModel()); err != nil {
+				return nil, err
+			}
+		}
+		for _, id := range file.Disabled {
+			if err := catalog.Unregister(id); err != nil {
+				return nil, fmt.Errorf("failed to disable model: %w", err)
+			}
+		}
+		for generator, id := range file.Defaults {
+			if err := catalog.SetDefault(generator, id); err != nil {
+				return nil, fmt.Errorf("failed to set the default %s model: %w", generator, err)
+			}
+		}
+	}
+
+	if _, ok := catalog.Find(vtoModel); !ok {
+		err := catalog.Register(entities.GenerativeModel{
+			ID:           vtoModel,
+			Name:         vtoModel,
+			Generator:    entities.GeneratorTryOn,
+			Capabilities: entities.ModelCapabilities{MaxImages: valueobjects.MaxSampleCount, Seed: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := catalog.SetDefault(entities.GeneratorTryOn, vtoModel); err != nil {
+		return nil, fmt.Errorf("VTO_MODEL: %w", err)
+	}
+
+	// 生成機能ごとにモデルが1つもないと、その画面・APIが使えない
+	for _, generator := range []entities.GeneratorType{
+		entities.GeneratorImagen, entities.GeneratorVeo, entities.GeneratorNanobanana,
+	} {
+		if _, ok := catalog.Default(generator); !ok {
+			return nil, fmt.Errorf("model catalog has no %s model", generator)
+		}
+	}
+
+	return catalog, nil
+}
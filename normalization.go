@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	"tryon-demo/internal/domain/valueobjects"
+)
+
+// normalizeOptionsFromEnv 環境変数 IMAGE_MAX_EDGE / IMAGE_BACKGROUND / IMAGE_JPEG_QUALITY から
+// 外部APIに送る前の画像の整え方を読み込む
+func normalizeOptionsFromEnv() (valueobjects.NormalizeOptions, error) {
+	opts := valueobjects.DefaultNormalizeOptions()
+
+	if value := os.Getenv("IMAGE_MAX_EDGE"); value != "" {
+		maxEdge, err := strconv.Atoi(value)
+		if err != nil || maxEdge < 0 {
+			return valueobjects.NormalizeOptions{}, fmt.Errorf("invalid IMAGE_MAX_EDGE: %q", value)
+		}
+		opts.MaxEdge = maxEdge
+	}
+
+	if value := os.Getenv("IMAGE_BACKGROUND"); value != "" {
+		background, err := parseHexColor(value)
+		if err != nil {
+			return valueobjects.NormalizeOptions{}, fmt.Errorf("invalid IMAGE_BACKGROUND: %w", err)
+		}
+		opts.Background = background
+	}
+
+	if value := os.Getenv("IMAGE_JPEG_QUALITY"); value != "" {
+		quality, err := strconv.Atoi(value)
+		if err != nil || quality < 1 || quality > 100 {
+			return valueobjects.NormalizeOptions{}, fmt.Errorf("invalid IMAGE_JPEG_QUALITY: %q", value)
+		}
+		opts.Quality = quality
+	}
+
+	return opts, nil
+}
+
+// parseHexColor "#RRGGBB" 形式の色を読み込む
+func parseHexColor(value string) (color.Color, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("%q is not in #RRGGBB format", value)
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not in #RRGGBB format", value)
+	}
+
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xFF}, nil
+}
+
+// formatHexColor 色を "#RRGGBB" 形式で表す
+func formatHexColor(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	return fmt.Sprintf("#%02X%02X%02X", rgba.R, rgba.G, rgba.B)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+
+	domainrepos "tryon-demo/internal/domain/repositories"
+	"tryon-demo/internal/infrastructure/repositories"
+)
+
+// メモリ保存時に履歴ファイルへ使う上限（古いものから破棄する）
+const memoryHistoryBlobBytes = 256 << 20
+
+// boltStorage DATA_DIR 配下のDBとファイル置き場。
+// 試着と生成履歴で同じファイルを使うため、最初に必要になった時点で1度だけ開く。
+type boltStorage struct {
+	dataDir   string
+	db        *bolt.DB
+	blobStore domainrepos.BlobStore
+}
+
+func newBoltStorage(dataDir string) *boltStorage {
+	return &boltStorage{dataDir: dataDir}
+}
+
+func (s *boltStorage) DB() *bolt.DB {
+	if s.db == nil {
+		db, err := repositories.OpenBoltDB(filepath.Join(s.dataDir, "tryon.db"))
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		s.db = db
+	}
+	return s.db
+}
+
+func (s *boltStorage) BlobStore() domainrepos.BlobStore {
+	if s.blobStore == nil {
+		blobStore, err := repositories.NewFileBlobStore(filepath.Join(s.dataDir, "blobs"))
+		if err != nil {
+			log.Fatalf("Failed to create blob store: %v", err)
+		}
+		s.blobStore = blobStore
+	}
+	return s.blobStore
+}
+
+func (s *boltStorage) Close() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
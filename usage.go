@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"tryon-demo/internal/application/usecases"
+	"tryon-demo/internal/domain/entities"
+	"tryon-demo/internal/domain/services"
+)
+
+// usageBudgetFromEnv 環境変数 USAGE_DAILY_BUDGET / USAGE_CLIENT_DAILY_BUDGET から
+// 1日あたりの利用額の上限（USD、未指定は無制限）を読み込む
+func usageBudgetFromEnv() (usecases.UsageBudget, error) {
+	var budget usecases.UsageBudget
+
+	for _, env := range []struct {
+		name  string
+		value *float64
+	}{
+		{"USAGE_DAILY_BUDGET", &budget.Daily},
+		{"USAGE_CLIENT_DAILY_BUDGET", &budget.ClientDaily},
+	} {
+		value := os.Getenv(env.name)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount < 0 {
+			return usecases.UsageBudget{}, fmt.Errorf("invalid %s: %q", env.name, value)
+		}
+		*env.value = amount
+	}
+
+	return budget, nil
+}
+
+// priceTableEntry PRICE_TABLE で指定するJSONファイルの1行
+type priceTableEntry struct {
+	Model  string             `json:"model"`
+	Unit   entities.PriceUnit `json:"unit"`
+	Amount float64            `json:"amount"`
+}
+
+// loadPriceTable モデルカタログの料金と組み込みのトークンの単価に、
+// PRICE_TABLE のファイル（path が空の場合はなし）の単価を重ねる
+func loadPriceTable(path string, catalog *services.ModelCatalog) (*services.PriceTable, error) {
+	table := services.DefaultPriceTable(catalog)
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+
+	var entries []priceTableEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to parse price table %s: %w", path, err)
+	}
+
+	for _, entry := range entries {
+		if err := table.Set(entry.Model, entry.Unit, entry.Amount); err != nil {
+			return nil, fmt.Errorf("invalid price for %q: %w", entry.Model, err)
+		}
+	}
+
+	return table, nil
+}